@@ -0,0 +1,109 @@
+// Package analytics abstracts Verbis's usage-telemetry sink behind a small
+// interface so that boot and sync code don't depend on PostHog directly,
+// and so that telemetry can be disabled or redirected to a local file
+// without changing any call sites.
+package analytics
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/posthog/posthog-go"
+)
+
+// Sink receives identify and capture events. Implementations must be safe
+// for concurrent use.
+type Sink interface {
+	// Identify associates distinctID with the given properties.
+	Identify(distinctID string, properties map[string]interface{}) error
+	// Capture records that distinctID triggered event, with properties.
+	Capture(distinctID string, event string, properties map[string]interface{}) error
+	// Close flushes and releases any resources held by the sink.
+	Close() error
+}
+
+// Mode selects which Sink implementation New returns.
+type Mode string
+
+const (
+	ModePostHog Mode = "on"
+	ModeOff     Mode = "off"
+	ModeLocal   Mode = "local"
+)
+
+// ModeFromEnv reads VERBIS_TELEMETRY ("off", "local", or unset/"on") and
+// returns the corresponding Mode, defaulting to ModePostHog.
+func ModeFromEnv() Mode {
+	switch os.Getenv("VERBIS_TELEMETRY") {
+	case "off":
+		return ModeOff
+	case "local":
+		return ModeLocal
+	default:
+		return ModePostHog
+	}
+}
+
+// New returns the Sink selected by mode. apiKey is only used by
+// ModePostHog and eventsPath only by ModeLocal. A build with no PostHog
+// API key ("n/a") always falls back to a no-op sink, even under
+// ModePostHog, since there is nowhere to send events.
+func New(mode Mode, apiKey string, eventsPath string) (Sink, error) {
+	if mode == ModePostHog && apiKey == "n/a" {
+		mode = ModeOff
+	}
+	switch mode {
+	case ModeOff:
+		return NewNoopSink(), nil
+	case ModeLocal:
+		return NewJSONLSink(eventsPath)
+	default:
+		return NewPostHogSink(apiKey)
+	}
+}
+
+type postHogSink struct {
+	client posthog.Client
+}
+
+// NewPostHogSink returns a Sink backed by a PostHog client.
+func NewPostHogSink(apiKey string) (Sink, error) {
+	client, err := posthog.NewWithConfig(
+		apiKey,
+		posthog.Config{
+			PersonalApiKey: apiKey,
+			Endpoint:       "https://eu.i.posthog.com",
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create posthog client: %w", err)
+	}
+	return &postHogSink{client: client}, nil
+}
+
+func (s *postHogSink) Identify(distinctID string, properties map[string]interface{}) error {
+	props := posthog.NewProperties()
+	for k, v := range properties {
+		props = props.Set(k, v)
+	}
+	return s.client.Enqueue(posthog.Identify{
+		DistinctId: distinctID,
+		Properties: props,
+	})
+}
+
+func (s *postHogSink) Capture(distinctID string, event string, properties map[string]interface{}) error {
+	props := posthog.NewProperties()
+	for k, v := range properties {
+		props = props.Set(k, v)
+	}
+	return s.client.Enqueue(posthog.Capture{
+		DistinctId: distinctID,
+		Event:      event,
+		Properties: props,
+	})
+}
+
+func (s *postHogSink) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,77 @@
+package analytics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// jsonlEvent is the on-disk shape of a single line in the events log.
+type jsonlEvent struct {
+	Type       string                 `json:"type"` // "identify" or "capture"
+	DistinctID string                 `json:"distinct_id"`
+	Event      string                 `json:"event,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+	Timestamp  time.Time              `json:"timestamp"`
+}
+
+// jsonlSink appends events to a local file, one JSON object per line, so
+// operators can inspect exactly what Verbis would otherwise have sent.
+type jsonlSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewJSONLSink returns a Sink that appends events to the file at path,
+// creating its parent directory if necessary.
+func NewJSONLSink(path string) (Sink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create events directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open events file: %w", err)
+	}
+	return &jsonlSink{file: f}, nil
+}
+
+func (s *jsonlSink) write(ev jsonlEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(data)
+	return err
+}
+
+func (s *jsonlSink) Identify(distinctID string, properties map[string]interface{}) error {
+	return s.write(jsonlEvent{
+		Type:       "identify",
+		DistinctID: distinctID,
+		Properties: properties,
+		Timestamp:  time.Now(),
+	})
+}
+
+func (s *jsonlSink) Capture(distinctID string, event string, properties map[string]interface{}) error {
+	return s.write(jsonlEvent{
+		Type:       "capture",
+		DistinctID: distinctID,
+		Event:      event,
+		Properties: properties,
+		Timestamp:  time.Now(),
+	})
+}
+
+func (s *jsonlSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
@@ -0,0 +1,22 @@
+package analytics
+
+// noopSink discards every event. It backs ModeOff, used when
+// VERBIS_TELEMETRY=off or no PostHog API key was built in.
+type noopSink struct{}
+
+// NewNoopSink returns a Sink that discards all events.
+func NewNoopSink() Sink {
+	return noopSink{}
+}
+
+func (noopSink) Identify(distinctID string, properties map[string]interface{}) error {
+	return nil
+}
+
+func (noopSink) Capture(distinctID string, event string, properties map[string]interface{}) error {
+	return nil
+}
+
+func (noopSink) Close() error {
+	return nil
+}
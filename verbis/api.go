@@ -2,36 +2,399 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
-	"math"
+	"net"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
-	"github.com/posthog/posthog-go"
-
-	"github.com/verbis-ai/verbis/verbis/connectors"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/verbis-ai/verbis/verbis/analytics"
+	"github.com/verbis-ai/verbis/verbis/llm"
+	vlog "github.com/verbis-ai/verbis/verbis/log"
+	"github.com/verbis-ai/verbis/verbis/metrics"
+	"github.com/verbis-ai/verbis/verbis/store"
+	"github.com/verbis-ai/verbis/verbis/synclog"
 	"github.com/verbis-ai/verbis/verbis/types"
 )
 
 var (
 	PromptLogFile = ".verbis/logs/prompt.log" // Relative to home
+
+	apiLog = vlog.New("api")
 )
 
+// apiErrorCode is a small, stable taxonomy of error kinds the desktop UI
+// can switch on (to localize copy, offer a retry, prompt re-auth, ...)
+// without parsing Message, which is for humans and free to change wording.
+type apiErrorCode string
+
+const (
+	errCodeBadRequest       apiErrorCode = "bad_request"
+	errCodeNotFound         apiErrorCode = "not_found"
+	errCodeConnectorUnknown apiErrorCode = "connector_unknown"
+	errCodeStoreFailure     apiErrorCode = "store_failure"
+	errCodeLLMFailure       apiErrorCode = "llm_failure"
+	errCodeAuthRequired     apiErrorCode = "auth_required"
+)
+
+// apiError pairs an apiErrorCode and HTTP status with the message
+// writeError sends to the client and, if Err is set, the underlying error
+// it logs server-side but never exposes in the response body.
+type apiError struct {
+	Code    apiErrorCode
+	Status  int
+	Message string
+	Err     error
+}
+
+func (e apiError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+type errorBody struct {
+	Code      apiErrorCode `json:"code"`
+	Message   string       `json:"message"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+// writeError is this package's single path for reporting a handler
+// failure to the client, modeled on etcd's httptypes.HTTPError: every
+// handler writes `{"error":{"code","message","request_id"}}` with the
+// right Content-Type instead of the ad-hoc plain-text bodies
+// w.Write([]byte("Failed to ...")) produced before it, so the desktop UI
+// has one shape to parse regardless of which endpoint failed. Status
+// >= 500 logs at Error (the server's fault, worth paging on); anything
+// else logs at Warn (a client mistake, expected in normal operation).
+func writeError(w http.ResponseWriter, r *http.Request, apiErr apiError) {
+	log := apiLog.WithContext(r.Context())
+	if apiErr.Status >= http.StatusInternalServerError {
+		log.Errorf("%s", apiErr.Error())
+	} else {
+		log.Warnf("%s", apiErr.Error())
+	}
+
+	requestID, _ := vlog.RequestIDFromContext(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(apiErr.Status)
+	json.NewEncoder(w).Encode(errorResponse{Error: errorBody{
+		Code:      apiErr.Code,
+		Message:   apiErr.Message,
+		RequestID: requestID,
+	}})
+}
+
+// requestIDMiddleware assigns each request a request ID (reusing an
+// inbound X-Request-Id if the caller already set one), attaches it to the
+// request's context via vlog.WithRequestID so every log line emitted while
+// handling the request can be correlated, and echoes it back in the
+// response so a client can report it for support.
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-Id")
+		if id == "" {
+			id = uuid.New().String()
+		}
+		w.Header().Set("X-Request-Id", id)
+		ctx := vlog.WithRequestID(r.Context(), id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// unauthenticatedPaths are exempt from bearerAuthMiddleware: they're the
+// liveness/readiness probes a container orchestrator or supervisor polls
+// before the desktop app has had a chance to obtain the bearer token, and
+// they expose nothing beyond the current boot phase.
+var unauthenticatedPaths = map[string]bool{
+	"/health":         true,
+	"/healthz":        true,
+	"/ready/onboard":  true,
+	"/ready/sync":     true,
+	"/ready/generate": true,
+}
+
+// bearerAuthMiddleware requires "Authorization: Bearer <a.AuthToken>" on
+// every request other than unauthenticatedPaths, guarding against any
+// local process (or browser tricked into hitting localhost) reading
+// conversations or mutating connectors. It's opt-in via VERBIS_REQUIRE_AUTH
+// so existing desktop app builds that don't send the header yet keep
+// working until they're updated to read the token verbis writes to disk on
+// first boot (see loadOrCreateAuthToken).
+func (a *API) bearerAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if os.Getenv("VERBIS_REQUIRE_AUTH") == "" || unauthenticatedPaths[r.URL.Path] || a.AuthToken == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		const prefix = "Bearer "
+		header := r.Header.Get("Authorization")
+		if !strings.HasPrefix(header, prefix) {
+			writeError(w, r, apiError{Code: errCodeAuthRequired, Status: http.StatusUnauthorized, Message: "Missing bearer token"})
+			return
+		}
+		token := strings.TrimPrefix(header, prefix)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(a.AuthToken)) != 1 {
+			writeError(w, r, apiError{Code: errCodeAuthRequired, Status: http.StatusUnauthorized, Message: "Invalid bearer token"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// defaultCORSOrigin is the desktop app's webview dev-server origin, kept as
+// the default allowlist entry so existing builds that don't set
+// VERBIS_CORS_ORIGINS yet keep working exactly as before this middleware
+// replaced the gorilla/handlers CORS wrapper that used to hardcode it.
+const defaultCORSOrigin = "http://localhost:3000"
+
+// corsAllowedOrigins is the strict Origin allowlist CORS middleware checks
+// requests against, configured via a comma-separated VERBIS_CORS_ORIGINS
+// (e.g. the desktop app's webview origin).
+func corsAllowedOrigins() map[string]bool {
+	allowed := map[string]bool{}
+	raw := os.Getenv("VERBIS_CORS_ORIGINS")
+	if raw == "" {
+		raw = defaultCORSOrigin
+	}
+	for _, origin := range strings.Split(raw, ",") {
+		origin = strings.TrimSpace(origin)
+		if origin != "" {
+			allowed[origin] = true
+		}
+	}
+	return allowed
+}
+
+// corsMiddleware enforces corsAllowedOrigins against the request's Origin
+// header, answering preflight OPTIONS requests directly and rejecting any
+// actual cross-origin request that isn't on the allowlist outright rather
+// than relying on the browser to honor a missing CORS header.
+func corsMiddleware(next http.Handler) http.Handler {
+	allowed := corsAllowedOrigins()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !allowed[origin] {
+			writeError(w, r, apiError{Code: errCodeAuthRequired, Status: http.StatusForbidden, Message: "Origin not allowed"})
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Vary", "Origin")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// loopbackOnlyMiddleware rejects any request whose RemoteAddr isn't the
+// loopback interface. It's applied to the /internal/* route group
+// regardless of VERBIS_REQUIRE_AUTH, since those routes (e.g.
+// /internal/reinit) trigger process-level operations that should never be
+// reachable from outside the machine even if the bearer token leaked.
+func loopbackOnlyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		ip := net.ParseIP(host)
+		if ip == nil || !ip.IsLoopback() {
+			writeError(w, r, apiError{Code: errCodeAuthRequired, Status: http.StatusForbidden, Message: "Forbidden: internal routes are loopback-only"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// genRequests tracks the cancel funcs of in-flight generation calls
+// (chatStream, RunRerankModel via handlePrompt) so that a shutdown during
+// BootStateGen can cancel them directly instead of waiting for the LLM
+// provider to finish generating.
+var genRequests = struct {
+	mu      sync.Mutex
+	nextID  int
+	cancels map[int]context.CancelFunc
+}{cancels: map[int]context.CancelFunc{}}
+
+// registerGenRequest derives a cancellable child of ctx and registers its
+// cancel func so CancelGenRequests can reach it during shutdown. The
+// returned done func must be deferred by the caller to deregister it once
+// the request completes.
+func registerGenRequest(ctx context.Context) (genCtx context.Context, done func()) {
+	genCtx, cancel := context.WithCancel(ctx)
+
+	genRequests.mu.Lock()
+	id := genRequests.nextID
+	genRequests.nextID++
+	genRequests.cancels[id] = cancel
+	genRequests.mu.Unlock()
+
+	return genCtx, func() {
+		genRequests.mu.Lock()
+		delete(genRequests.cancels, id)
+		genRequests.mu.Unlock()
+		cancel()
+	}
+}
+
+// CancelGenRequests cancels every in-flight generation request. It is
+// called from Halt when the boot state is BootStateGen, so that
+// subprocess teardown isn't blocked waiting for a chat or rerank call to
+// finish.
+func CancelGenRequests() {
+	genRequests.mu.Lock()
+	defer genRequests.mu.Unlock()
+	for _, cancel := range genRequests.cancels {
+		cancel()
+	}
+}
+
+// promptCancels tracks the cancel func of the in-flight streamed prompt on
+// each conversation (if any), keyed by conversation ID, so a client can
+// abort a specific generation via a separate request (e.g. after its
+// EventSource already disconnected) without having to rely on the
+// connection drop reaching chatStream on its own.
+var promptCancels = struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+}{cancels: map[string]context.CancelFunc{}}
+
+// registerPromptCancel records cancel as the way to abort conversationID's
+// in-flight stream. The returned done func must be deferred by the caller
+// to deregister it once the request completes; it does not itself call
+// cancel.
+func registerPromptCancel(conversationID string, cancel context.CancelFunc) (done func()) {
+	promptCancels.mu.Lock()
+	promptCancels.cancels[conversationID] = cancel
+	promptCancels.mu.Unlock()
+
+	return func() {
+		promptCancels.mu.Lock()
+		delete(promptCancels.cancels, conversationID)
+		promptCancels.mu.Unlock()
+	}
+}
+
+// cancelPrompt cancels the in-flight streamed prompt for conversationID, if
+// any, reporting whether one was found.
+func cancelPrompt(conversationID string) bool {
+	promptCancels.mu.Lock()
+	defer promptCancels.mu.Unlock()
+	cancel, ok := promptCancels.cancels[conversationID]
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
 type API struct {
 	Syncer            *Syncer
 	Context           *BootContext
-	Posthog           posthog.Client
+	Analytics         analytics.Sink
 	PosthogDistinctID string
 	Version           string
 	store             types.Store
+
+	// AuthToken is the per-install bearer token generated on first boot
+	// (see loadOrCreateAuthToken). Every request other than the
+	// unauthenticated health/readiness probes must present it as
+	// "Authorization: Bearer <token>" once VERBIS_REQUIRE_AUTH is set; see
+	// bearerAuthMiddleware.
+	AuthToken string
+
+	// LLM is the inference backend handlePrompt and handlePromptStream use
+	// for embeddings and chat completion, selected by llm.NewFromConfig
+	// from types.Config.LLMProvider. It defaults to Ollama but can be
+	// swapped for an OpenAI-compatible or llama.cpp endpoint without
+	// touching their control flow; updateConfig rebuilds it the same way
+	// it rebuilds Analytics.
+	LLM llm.Provider
+
+	// Retriever, Reranker, and PromptTemplate make the retrieval-augmented
+	// generation pipeline in handlePrompt swappable without touching its
+	// control flow: Retriever fetches a topK candidate set, Reranker narrows
+	// it to topN, and PromptTemplate renders the final LLM prompt from the
+	// result.
+	Retriever      Retriever
+	Reranker       Reranker
+	PromptTemplate PromptTemplate
+
+	// Registry overrides which Prometheus Gatherer /metrics serves from.
+	// Left nil, SetupRouter falls back to the global promauto registry
+	// every metric in the metrics package registers itself to; tests that
+	// want to assert on emitted samples without sharing state with other
+	// tests in the same process can set their own prometheus.Registry here.
+	Registry *prometheus.Registry
+}
+
+// embed returns the embedding vector for a single prompt via a.LLM, the
+// llm.Provider-based replacement for the package-level EmbedFromModel.
+func (a *API) embed(ctx context.Context, prompt string) ([]float32, error) {
+	embeddings, err := a.LLM.Embed(ctx, []string{prompt})
+	if err != nil {
+		return nil, err
+	}
+	if len(embeddings) == 0 {
+		return nil, fmt.Errorf("expected 1 embedding, got 0")
+	}
+	return embeddings[0], nil
+}
+
+// chatStream runs a.LLM.Chat and republishes its Tokens as StreamResponse
+// values on out, the shape handlePrompt and handlePromptStream already
+// know how to consume from the pre-abstraction chatWithModelStream.
+func (a *API) chatStream(ctx context.Context, prompt, model string, history []types.HistoryItem, out chan<- StreamResponse) error {
+	tokens := make(chan llm.Token)
+	if err := a.LLM.Chat(ctx, prompt, history, llm.ChatOptions{Model: model}, tokens); err != nil {
+		return err
+	}
+
+	go func() {
+		defer close(out)
+		for tok := range tokens {
+			out <- StreamResponse{
+				Message: types.HistoryItem{Role: "assistant", Content: tok.Content},
+				Done:    tok.Done,
+			}
+		}
+	}()
+	return nil
 }
 
 func (a *API) SetupRouter() *mux.Router {
 	r := mux.NewRouter()
+	r.Use(requestIDMiddleware)
+	r.Use(metrics.Middleware)
+	r.Use(corsMiddleware)
+	r.Use(a.bearerAuthMiddleware)
 	r.HandleFunc("/connectors", a.connectorsList).Methods("GET")
 	r.HandleFunc("/connectors/{type}/init", a.connectorInit).Methods("GET")
 	r.HandleFunc("/connectors/{type}/request", a.connectorRequest).Methods("GET")
@@ -41,19 +404,56 @@ func (a *API) SetupRouter() *mux.Router {
 	r.HandleFunc("/connectors/{connector_id}/auth_setup", a.connectorAuthSetup).Methods("GET")
 	r.HandleFunc("/connectors/{connector_id}/callback", a.handleConnectorCallback).Methods("GET")
 	r.HandleFunc("/connectors/{connector_id}", a.handleConnectorDelete).Methods("DELETE")
+	r.HandleFunc("/connectors/{connector_id}/progress", a.connectorProgress).Methods("GET")
+	r.HandleFunc("/connectors/events", a.connectorEvents).Methods("GET")
 	r.HandleFunc("/connectors/auth_complete", a.authComplete).Methods("GET")
 
 	r.HandleFunc("/conversations", a.listConversations).Methods("GET")
 	r.HandleFunc("/conversations/{conversation_id}", a.getConversation).Methods("GET")
+	r.HandleFunc("/conversations/{conversation_id}/history", a.getConversationHistory).Methods("GET")
 	r.HandleFunc("/conversations", a.createConversation).Methods("POST")
+	r.HandleFunc("/conversations/{conversation_id}", a.deleteConversation).Methods("DELETE")
+	r.HandleFunc("/conversations/{conversation_id}", a.patchConversation).Methods("PATCH")
+	r.HandleFunc("/conversations/{conversation_id}/branch", a.branchConversation).Methods("POST")
+	r.HandleFunc("/conversations/{conversation_id}/messages/{msg_id}/edit", a.editMessage).Methods("POST")
 	r.HandleFunc("/conversations/{conversation_id}/prompt", a.handlePrompt).Methods("POST")
+	r.HandleFunc("/conversations/{conversation_id}/prompt/stream", a.handlePromptStream).Methods("POST")
+	r.HandleFunc("/conversations/{conversation_id}/prompt", a.cancelPromptStream).Methods("DELETE")
+
+	r.HandleFunc("/documents", a.listDocuments).Methods("GET")
+	r.HandleFunc("/search", a.search).Methods("GET")
 
 	r.HandleFunc("/config", a.getConfig).Methods("GET")
 	r.HandleFunc("/config", a.updateConfig).Methods("POST")
 
 	r.HandleFunc("/health", a.health).Methods("GET")
+	r.HandleFunc("/healthz", a.healthz).Methods("GET")
+	r.HandleFunc("/ready/onboard", a.readyOnboard).Methods("GET")
+	r.HandleFunc("/ready/sync", a.readySync).Methods("GET")
+	r.HandleFunc("/ready/generate", a.readyGenerate).Methods("GET")
 	r.HandleFunc("/sync/force", a.forceSync).Methods("GET")
-	r.HandleFunc("/internal/reinit", a.reInit).Methods("POST")
+	r.HandleFunc("/sync/log", a.syncLog).Methods("GET")
+
+	r.HandleFunc("/models/warmup", a.warmupModels).Methods("POST")
+	r.HandleFunc("/models/pull", a.modelPullProgressStream).Methods("GET")
+	r.HandleFunc("/models/pull", a.cancelModelPullHandler).Methods("DELETE")
+
+	internal := r.PathPrefix("/internal").Subrouter()
+	internal.Use(loopbackOnlyMiddleware)
+	internal.HandleFunc("/reinit", a.reInit).Methods("POST")
+
+	r.HandleFunc("/api/system/status", a.systemStatus).Methods("GET")
+	r.HandleFunc("/api/system/telemetry", a.updateTelemetry).Methods("PUT")
+
+	// Only mounted on the public router when VERBIS_METRICS_ADDR is unset;
+	// see maybeServeMetrics in boot.go for the dedicated-port case.
+	if os.Getenv("VERBIS_METRICS_ADDR") == "" {
+		metricsHandler := metrics.Handler()
+		if a.Registry != nil {
+			metricsHandler = metrics.HandlerFor(a.Registry)
+		}
+		r.Handle("/metrics", metricsHandler).Methods("GET")
+	}
 
 	return r
 }
@@ -61,31 +461,171 @@ func (a *API) SetupRouter() *mux.Router {
 type HealthResponse struct {
 	BootState BootState `json:"boot_state"`
 	Version   string    `json:"version"`
+
+	// ModelsWarm reports whether warmupModels last succeeded in loading
+	// each model into Ollama's memory, so the front-end can grey out the
+	// prompt box until models are actually resident rather than just
+	// pulled.
+	ModelsWarm map[string]bool `json:"models_warm"`
 }
 
 func (a *API) health(w http.ResponseWriter, r *http.Request) {
 	// TODO: check for health of subprocesses
-	// TODO: return state of syncs and model downloads, to be used during init
 	json.NewEncoder(w).Encode(HealthResponse{
 		BootState: a.Context.State,
 		Version:   a.Version,
+		ModelsWarm: map[string]bool{
+			generationModelName: isModelWarm(generationModelName),
+			embeddingsModelName: isModelWarm(embeddingsModelName),
+			rerankModelName:     isModelWarm(rerankModelName),
+		},
 	})
 }
 
+// bootPhaseOrder gives each BootState its position in the sequence main
+// drives bootCtx through (Onboard -> Syncing -> Gen), so a /ready/* handler
+// can tell whether the boot state machine has reached or passed the phase
+// it's asking about.
+var bootPhaseOrder = map[BootState]int{
+	BootStateStarted: 0,
+	BootStateOnboard: 1,
+	BootStateSyncing: 2,
+	BootStateGen:     3,
+}
+
+// ReadinessResponse is returned by /healthz and the per-phase /ready/*
+// endpoints. ModelProgress reports the embeddings and generation models'
+// download progress so the front-end can show an accurate "still
+// downloading model X" message during long first-run pulls.
+type ReadinessResponse struct {
+	Ready         bool                         `json:"ready"`
+	BootState     BootState                    `json:"boot_state"`
+	LastError     string                       `json:"last_error,omitempty"`
+	ModelProgress map[string]ModelPullProgress `json:"model_progress"`
+}
+
+func (a *API) writeReadiness(w http.ResponseWriter, phase BootState) {
+	ready := bootPhaseOrder[a.Context.State] >= bootPhaseOrder[phase]
+
+	resp := ReadinessResponse{
+		Ready:     ready,
+		BootState: a.Context.State,
+		ModelProgress: map[string]ModelPullProgress{
+			embeddingsModelName: getModelPullProgress(embeddingsModelName),
+			generationModelName: getModelPullProgress(generationModelName),
+		},
+	}
+	if err := a.Context.LastError(); err != nil {
+		resp.LastError = err.Error()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// healthz reports overall readiness: 200 once the full boot sequence
+// (Onboard -> Syncing -> Gen) has completed, 503 otherwise.
+func (a *API) healthz(w http.ResponseWriter, r *http.Request) {
+	a.writeReadiness(w, BootStateGen)
+}
+
+// readyOnboard reports 200 once BootOnboard has completed, meaning
+// connectors can be added.
+func (a *API) readyOnboard(w http.ResponseWriter, r *http.Request) {
+	a.writeReadiness(w, BootStateOnboard)
+}
+
+// readySync reports 200 once BootSyncing has completed, meaning the
+// embeddings model is pulled and the syncer is running.
+func (a *API) readySync(w http.ResponseWriter, r *http.Request) {
+	a.writeReadiness(w, BootStateSyncing)
+}
+
+// readyGenerate reports 200 once BootGen has completed, meaning the
+// generation model is pulled and prompts can be served.
+func (a *API) readyGenerate(w http.ResponseWriter, r *http.Request) {
+	a.writeReadiness(w, BootStateGen)
+}
+
+type SupervisorStatusResponse struct {
+	Name         string `json:"name"`
+	State        string `json:"state"`
+	LastExitCode int    `json:"last_exit_code"`
+	RestartCount int    `json:"restart_count"`
+	UptimeSecs   int64  `json:"uptime_seconds"`
+}
+
+// systemStatus reports the state of every supervised subprocess (ollama,
+// weaviate), for local operators or tooling to poll.
+func (a *API) systemStatus(w http.ResponseWriter, r *http.Request) {
+	statuses := []SupervisorStatusResponse{}
+	for _, sup := range a.Context.Supervisors {
+		s := sup.Status()
+		statuses = append(statuses, SupervisorStatusResponse{
+			Name:         s.Name,
+			State:        string(s.State),
+			LastExitCode: s.LastExitCode,
+			RestartCount: s.RestartCount,
+			UptimeSecs:   int64(s.Uptime.Seconds()),
+		})
+	}
+
+	err := json.NewEncoder(w).Encode(statuses)
+	if err != nil {
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to encode system status", Err: err})
+		return
+	}
+}
+
+type TelemetryRequest struct {
+	Mode string `json:"mode"` // "on", "off", or "local"
+}
+
+// updateTelemetry switches the active analytics.Sink without requiring a
+// restart, used by the settings UI to let users turn telemetry off or
+// redirect it to the local events log.
+func (a *API) updateTelemetry(w http.ResponseWriter, r *http.Request) {
+	var req TelemetryRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "Failed to decode request", Err: err})
+		return
+	}
+
+	dataDir, err := a.Context.Platform.UserDataDir(appDataDirName)
+	if err != nil {
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to get user data directory", Err: err})
+		return
+	}
+
+	sink, err := analytics.New(analytics.Mode(req.Mode), PosthogAPIKey, filepath.Join(dataDir, eventsLogPath))
+	if err != nil {
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to create analytics sink", Err: err})
+		return
+	}
+
+	old := a.Analytics
+	a.Analytics = sink
+	a.Context.Analytics = sink
+	a.Syncer.analytics = sink
+	old.Close()
+
+	w.WriteHeader(http.StatusOK)
+}
+
 func (a *API) getConfig(w http.ResponseWriter, r *http.Request) {
 	cfg, err := a.store.GetConfig(r.Context())
 	if err != nil {
-		log.Printf("Failed to get config: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("Failed to get config: " + err.Error()))
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to get config", Err: err})
 		return
 	}
 
 	err = json.NewEncoder(w).Encode(cfg)
 	if err != nil {
-		log.Printf("Failed to encode config: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("Failed to encode config: " + err.Error()))
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to encode config", Err: err})
 		return
 	}
 }
@@ -95,46 +635,45 @@ func (a *API) updateConfig(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 	err := json.NewDecoder(r.Body).Decode(&cfg)
 	if err != nil {
-		// return HTTP 400 bad request
-		http.Error(w, "Failed to decode request", http.StatusBadRequest)
+		writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "Failed to decode request", Err: err})
 		return
 	}
 
 	if cfg == nil {
-		http.Error(w, "No config provided", http.StatusBadRequest)
+		writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "No config provided"})
 		return
 	}
 
 	err = a.store.UpdateConfig(r.Context(), cfg)
 	if err != nil {
-		log.Printf("Failed to update config: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("Failed to update config: " + err.Error()))
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to update config", Err: err})
 		return
 	}
 
-	if cfg.EnableTelemetry && a.Posthog == nil {
-		postHogClient, err := posthog.NewWithConfig(
-			PosthogAPIKey,
-			posthog.Config{
-				PersonalApiKey:                     PosthogAPIKey,
-				Endpoint:                           "https://eu.i.posthog.com",
-				DefaultFeatureFlagsPollingInterval: math.MaxInt64,
-			},
-		)
-		if err != nil {
-			log.Printf("Failed to create posthog client: %s", err)
-			w.WriteHeader(http.StatusInternalServerError)
-			w.Write([]byte("Failed to create posthog client: " + err.Error()))
-		}
-		a.Posthog = postHogClient
-		a.Syncer.posthogClient = postHogClient
+	mode := analytics.ModePostHog
+	if !cfg.EnableTelemetry {
+		mode = analytics.ModeOff
+	}
+
+	dataDir, err := a.Context.Platform.UserDataDir(appDataDirName)
+	if err != nil {
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to get user data directory", Err: err})
+		return
 	}
 
-	if !cfg.EnableTelemetry && a.Posthog != nil {
-		a.Posthog = nil
-		a.Syncer.posthogClient = nil
+	sink, err := analytics.New(mode, PosthogAPIKey, filepath.Join(dataDir, eventsLogPath))
+	if err != nil {
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to create analytics sink", Err: err})
+		return
 	}
+
+	old := a.Analytics
+	a.Analytics = sink
+	a.Context.Analytics = sink
+	a.Syncer.analytics = sink
+	old.Close()
+
+	a.LLM = llm.NewFromConfig(cfg, OllamaHost, embeddingsModelName)
 }
 
 func (a *API) reInit(w http.ResponseWriter, r *http.Request) {
@@ -142,12 +681,10 @@ func (a *API) reInit(w http.ResponseWriter, r *http.Request) {
 	// during the restore operation from a weaviate backup.
 	err := a.Syncer.Init(a.Context)
 	if err != nil {
-		log.Printf("Failed to reinit syncer: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("Failed to reinit syncer: " + err.Error()))
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to reinit syncer", Err: err})
 		return
 	}
-	log.Printf("Syncer reinitialized")
+	apiLog.WithContext(r.Context()).Infof("Syncer reinitialized")
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -155,43 +692,40 @@ func (a *API) connectorRequest(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	connectorType, ok := vars["type"]
 	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte("No connector name provided"))
+		writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "No connector name provided"})
 		return
 	}
 
-	if a.Posthog == nil {
-		return
-	}
-
-	err := a.Posthog.Enqueue(posthog.Capture{
-		DistinctId: a.PosthogDistinctID,
-		Event:      "ConnectorRequest",
-		Properties: posthog.NewProperties().
-			Set("connector_type", connectorType).
-			Set("version", a.Version),
+	err := a.Analytics.Capture(a.PosthogDistinctID, "ConnectorRequest", map[string]interface{}{
+		"connector_type": connectorType,
+		"version":        a.Version,
 	})
 	if err != nil {
-		log.Printf("Failed to enqueue connector request: %s\n", err)
-		http.Error(w, "Failed to enqueue connector request", http.StatusInternalServerError)
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to enqueue connector request", Err: err})
 		return
 	}
 }
 
+// pageArgsFromQuery reads the "first" and "after" Relay pagination params
+// shared by every Connection-returning endpoint.
+func pageArgsFromQuery(r *http.Request) types.PageArgs {
+	args := types.PageArgs{After: r.URL.Query().Get("after")}
+	if first, err := strconv.Atoi(r.URL.Query().Get("first")); err == nil {
+		args.First = first
+	}
+	return args
+}
+
 func (a *API) listConversations(w http.ResponseWriter, r *http.Request) {
-	conversations, err := a.store.ListConversations(r.Context())
+	conversations, err := a.store.ListConversations(r.Context(), pageArgsFromQuery(r))
 	if err != nil {
-		log.Printf("Failed to list conversations: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("Failed to list conversations: " + err.Error()))
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to list conversations", Err: err})
 		return
 	}
 
 	b, err := json.Marshal(conversations)
 	if err != nil {
-		log.Printf("Failed to marshal conversations: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("Failed to marshal conversations: " + err.Error()))
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to marshal conversations", Err: err})
 		return
 	}
 
@@ -202,17 +736,167 @@ func (a *API) getConversation(w http.ResponseWriter, r *http.Request) {
 	conversationID := mux.Vars(r)["conversation_id"]
 	conversation, err := a.store.GetConversation(r.Context(), conversationID)
 	if err != nil {
-		log.Printf("Failed to get conversation: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("Failed to get conversation: " + err.Error()))
+		if store.IsErrConversationNotFound(err) {
+			writeError(w, r, apiError{Code: errCodeNotFound, Status: http.StatusNotFound, Message: "Conversation not found", Err: err})
+			return
+		}
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to get conversation", Err: err})
 		return
 	}
 
 	b, err := json.Marshal(conversation)
 	if err != nil {
-		log.Printf("Failed to marshal conversation: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("Failed to marshal conversation: " + err.Error()))
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to marshal conversation", Err: err})
+		return
+	}
+
+	w.Write(b)
+}
+
+func (a *API) getConversationHistory(w http.ResponseWriter, r *http.Request) {
+	conversationID := mux.Vars(r)["conversation_id"]
+	history, err := a.store.GetConversationHistory(r.Context(), conversationID, pageArgsFromQuery(r))
+	if err != nil {
+		if store.IsErrConversationNotFound(err) {
+			writeError(w, r, apiError{Code: errCodeNotFound, Status: http.StatusNotFound, Message: "Conversation not found", Err: err})
+			return
+		}
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to get conversation history", Err: err})
+		return
+	}
+
+	b, err := json.Marshal(history)
+	if err != nil {
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to marshal conversation history", Err: err})
+		return
+	}
+
+	w.Write(b)
+}
+
+// searchOptionsFromQuery builds a types.SearchOptions from /search's query
+// parameters, the same "parse what's present, leave the rest zero-valued"
+// approach pageArgsFromQuery uses for pagination. RequesterIdentities isn't
+// populated here: it's resolved server-side in search from the instance's
+// own authenticated connector states, never taken from the request, since
+// this API has no per-request identity a client-supplied value could be
+// checked against.
+func searchOptionsFromQuery(r *http.Request) (types.SearchOptions, error) {
+	opts := types.SearchOptions{
+		ConnectorIDs: r.URL.Query()["connector_id"],
+		DocNameGlob:  r.URL.Query().Get("doc_name"),
+	}
+	if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil {
+		opts.Limit = limit
+	}
+
+	for param, dst := range map[string]*time.Time{
+		"created_after":  &opts.CreatedAfter,
+		"created_before": &opts.CreatedBefore,
+		"updated_after":  &opts.UpdatedAfter,
+		"updated_before": &opts.UpdatedBefore,
+	} {
+		v := r.URL.Query().Get(param)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return types.SearchOptions{}, fmt.Errorf("invalid %s: %v", param, err)
+		}
+		*dst = t
+	}
+
+	return opts, nil
+}
+
+// search restricts RAG context to a source-scoped, metadata-filtered
+// subset of chunks: connector, document name glob, and createdAt/updatedAt
+// date ranges, e.g. "only Google Drive docs updated in the last 7 days".
+// Unlike /conversations/{id}/prompt, it returns the matched chunks
+// directly rather than feeding them to the LLM.
+func (a *API) search(w http.ResponseWriter, r *http.Request) {
+	opts, err := searchOptionsFromQuery(r)
+	if err != nil {
+		writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	identities, err := requesterIdentitiesFromConnectorStates(r.Context(), a.store)
+	if err != nil {
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to resolve requester identities", Err: err})
+		return
+	}
+	opts.RequesterIdentities = identities
+
+	query := r.URL.Query().Get("q")
+	var embeddings []float32
+	if query != "" {
+		var err error
+		embeddings, err = a.embed(r.Context(), query)
+		if err != nil {
+			writeError(w, r, apiError{Code: errCodeLLMFailure, Status: http.StatusInternalServerError, Message: "Failed to get embeddings", Err: err})
+			return
+		}
+	}
+
+	results, err := a.store.HybridSearch(r.Context(), query, embeddings, opts)
+	if err != nil {
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to search", Err: err})
+		return
+	}
+
+	b, err := json.Marshal(results)
+	if err != nil {
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to marshal search results", Err: err})
+		return
+	}
+	w.Write(b)
+}
+
+// requesterIdentitiesFromConnectorStates returns the User of every
+// connector this instance has authenticated, lowercased and deduped, for
+// use as HybridSearch's RequesterIdentities. This API has a single shared
+// bearer token rather than per-request user sessions, so the only
+// trustworthy notion of "who's asking" is the set of accounts this
+// instance itself has signed in, not anything a caller can put in a query
+// parameter. A connector's resolved Workspace group memberships aren't
+// persisted on ConnectorState, so they aren't included here; a document
+// shared only with a group the user belongs to, never with their email
+// directly, is still visible because an empty/no-match ACL check is
+// permissive, not because this list covers groups explicitly.
+func requesterIdentitiesFromConnectorStates(ctx context.Context, st types.Store) ([]string, error) {
+	states, err := st.AllConnectorStates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list connector states: %v", err)
+	}
+
+	seen := map[string]struct{}{}
+	var identities []string
+	for _, state := range states {
+		user := strings.ToLower(strings.TrimSpace(state.User))
+		if user == "" {
+			continue
+		}
+		if _, ok := seen[user]; ok {
+			continue
+		}
+		seen[user] = struct{}{}
+		identities = append(identities, user)
+	}
+	return identities, nil
+}
+
+func (a *API) listDocuments(w http.ResponseWriter, r *http.Request) {
+	documents, err := a.store.ListDocuments(r.Context(), pageArgsFromQuery(r))
+	if err != nil {
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to list documents", Err: err})
+		return
+	}
+
+	b, err := json.Marshal(documents)
+	if err != nil {
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to marshal documents", Err: err})
 		return
 	}
 
@@ -222,39 +906,151 @@ func (a *API) getConversation(w http.ResponseWriter, r *http.Request) {
 func (a *API) createConversation(w http.ResponseWriter, r *http.Request) {
 	conversationID, err := a.store.CreateConversation(r.Context())
 	if err != nil {
-		log.Printf("Failed to create conversation: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("Failed to create conversation: " + err.Error()))
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to create conversation", Err: err})
 		return
 	}
 
 	b, err := json.Marshal(map[string]string{"id": conversationID})
 	if err != nil {
-		log.Printf("Failed to marshal conversation: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("Failed to marshal conversation: " + err.Error()))
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to marshal conversation", Err: err})
+		return
+	}
+
+	w.Write(b)
+}
+
+func (a *API) deleteConversation(w http.ResponseWriter, r *http.Request) {
+	conversationID := mux.Vars(r)["conversation_id"]
+	if err := a.store.DeleteConversation(r.Context(), conversationID); err != nil {
+		if store.IsErrConversationNotFound(err) {
+			writeError(w, r, apiError{Code: errCodeNotFound, Status: http.StatusNotFound, Message: "Conversation not found", Err: err})
+			return
+		}
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to delete conversation", Err: err})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type renameConversationRequest struct {
+	Title string `json:"title"`
+}
+
+// patchConversation renames a conversation. It's the only field
+// PATCH /conversations/{id} supports today.
+func (a *API) patchConversation(w http.ResponseWriter, r *http.Request) {
+	conversationID := mux.Vars(r)["conversation_id"]
+
+	var req renameConversationRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "Failed to decode request", Err: err})
+		return
+	}
+
+	if err := a.store.RenameConversation(r.Context(), conversationID, req.Title); err != nil {
+		if store.IsErrConversationNotFound(err) {
+			writeError(w, r, apiError{Code: errCodeNotFound, Status: http.StatusNotFound, Message: "Conversation not found", Err: err})
+			return
+		}
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to rename conversation", Err: err})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type branchConversationRequest struct {
+	// AtIndex is the History index to branch at (exclusive): the new
+	// conversation's history is History[:AtIndex].
+	AtIndex int `json:"at_index"`
+}
+
+// branchConversation forks conversationID at a given message index into a
+// new conversation, copying History and the ChunkHashes introduced by
+// those messages so the fork starts with the right retrieval context.
+func (a *API) branchConversation(w http.ResponseWriter, r *http.Request) {
+	conversationID := mux.Vars(r)["conversation_id"]
+
+	var req branchConversationRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "Failed to decode request", Err: err})
+		return
+	}
+
+	newID, err := a.store.BranchConversation(r.Context(), conversationID, req.AtIndex)
+	if err != nil {
+		if store.IsErrConversationNotFound(err) {
+			writeError(w, r, apiError{Code: errCodeNotFound, Status: http.StatusNotFound, Message: "Conversation not found", Err: err})
+			return
+		}
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to branch conversation", Err: err})
 		return
 	}
 
+	b, err := json.Marshal(map[string]string{"id": newID})
+	if err != nil {
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to marshal conversation", Err: err})
+		return
+	}
 	w.Write(b)
 }
 
+// editMessage truncates conversationID's history to the edited message
+// (msg_id, the same History-index identifier GetConversationHistory's
+// cursors use) and re-runs generation from there with the request's new
+// prompt, the same as handlePromptStream does for a fresh message.
+func (a *API) editMessage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	conversationID := vars["conversation_id"]
+	msgIdx, err := strconv.Atoi(vars["msg_id"])
+	if err != nil {
+		writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "Invalid message id", Err: err})
+		return
+	}
+
+	var promptReq PromptRequest
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "Failed to read request", Err: err})
+		return
+	}
+	if err := json.Unmarshal(body, &promptReq); err != nil {
+		writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "Failed to decode request", Err: err})
+		return
+	}
+
+	if _, err := a.store.TruncateConversationHistory(r.Context(), conversationID, msgIdx); err != nil {
+		if store.IsErrConversationNotFound(err) {
+			writeError(w, r, apiError{Code: errCodeNotFound, Status: http.StatusNotFound, Message: "Conversation not found", Err: err})
+			return
+		}
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to truncate conversation", Err: err})
+		return
+	}
+
+	// Re-run generation against the truncated history by delegating to
+	// handlePromptStream on a request carrying the edited prompt; mux.Vars
+	// is stored on the request's context, so the clone keeps resolving
+	// conversation_id to the same value.
+	streamReq := r.Clone(r.Context())
+	streamReq.Body = io.NopCloser(bytes.NewReader(body))
+	a.handlePromptStream(w, streamReq)
+}
+
 func (a *API) connectorsList(w http.ResponseWriter, r *http.Request) {
 	fetch_all := r.URL.Query().Get("all") == "true"
 	states, err := a.Syncer.GetConnectorStates(r.Context(), fetch_all)
 
 	if err != nil {
-		log.Printf("Failed to list connectors: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("Failed to list connectors: " + err.Error()))
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to list connectors", Err: err})
 		return
 	}
 
 	b, err := json.Marshal(states)
 	if err != nil {
-		log.Printf("Failed to marshal connectors: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("Failed to marshal connectors: " + err.Error()))
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to marshal connectors", Err: err})
 		return
 	}
 
@@ -273,15 +1069,13 @@ func (a *API) connectorInit(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	connectorType, ok := vars["type"]
 	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte("No connector name provided"))
+		writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "No connector name provided"})
 		return
 	}
 
-	constructor, ok := connectors.AllConnectors[connectorType]
+	constructor, ok := types.ConnectorFactory(types.ConnectorType(connectorType))
 	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte("Unknown connector name"))
+		writeError(w, r, apiError{Code: errCodeConnectorUnknown, Status: http.StatusBadRequest, Message: "Unknown connector name"})
 		return
 	}
 
@@ -290,24 +1084,20 @@ func (a *API) connectorInit(w http.ResponseWriter, r *http.Request) {
 	// the store, and discovering credentials
 	conn := constructor(a.Context.Credentials, a.store)
 
-	log.Printf("Initializing connector type: %s id: %s", conn.Type(), conn.ID())
+	apiLog.WithContext(r.Context()).Infof("Initializing connector type: %s id: %s", conn.Type(), conn.ID())
 
 	err := conn.Init(a.Context, "")
 	if err != nil {
-		log.Printf("Failed to init connector: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("Failed to init connector: " + err.Error()))
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to init connector", Err: err})
 		return
 	}
 	// Add the connector to the syncer so that it may start syncing
 	err = a.Syncer.AddConnector(conn)
 	if err != nil {
-		log.Printf("Failed to add connector: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("Failed to add connector: " + err.Error()))
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to add connector", Err: err})
 		return
 	}
-	log.Printf("Connector %s %s initialized", conn.Type(), conn.ID())
+	apiLog.WithContext(r.Context()).Infof("Connector %s %s initialized", conn.Type(), conn.ID())
 
 	w.WriteHeader(http.StatusOK)
 	w.Header().Set("Content-Type", "application/json")
@@ -318,22 +1108,18 @@ func (a *API) connectorAuthSetup(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	connectorID, ok := vars["connector_id"]
 	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte("No connector ID provided"))
+		writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "No connector ID provided"})
 		return
 	}
 
 	conn := a.Syncer.GetConnector(connectorID)
 	if conn == nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte("Unknown connector ID"))
+		writeError(w, r, apiError{Code: errCodeConnectorUnknown, Status: http.StatusBadRequest, Message: "Unknown connector ID"})
 		return
 	}
 	err := conn.AuthSetup(r.Context())
 	if err != nil {
-		log.Printf("Failed to perform initial auth with google: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("Failed to perform initial auth with google: " + err.Error()))
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to perform initial auth with google", Err: err})
 		return
 	}
 
@@ -343,17 +1129,111 @@ func (a *API) handleConnectorDelete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	connectorID, ok := vars["connector_id"]
 	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte("No connector ID provided"))
+		writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "No connector ID provided"})
 		return
 	}
 	err := a.Syncer.DeleteConnector(a.Context, connectorID)
 	if err != nil {
-		log.Printf("Failed to remove connector: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("Failed to remove connector: " + err.Error()))
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to remove connector", Err: err})
+		return
+	}
+}
+
+// connectorProgress streams a connector's SyncProgress over Server-Sent
+// Events as it's published, so the frontend can render a live progress
+// bar instead of an indeterminate spinner during a large initial sync.
+// The stream ends when the client disconnects.
+func (a *API) connectorProgress(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	connectorID, ok := vars["connector_id"]
+	if !ok {
+		writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "No connector ID provided"})
 		return
 	}
+
+	connector := a.Syncer.GetConnector(connectorID)
+	if connector == nil {
+		writeError(w, r, apiError{Code: errCodeConnectorUnknown, Status: http.StatusNotFound, Message: "Connector not found"})
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Streaming not supported"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ctx := r.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case progress, ok := <-connector.Progress():
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(progress)
+			if err != nil {
+				apiLog.WithContext(r.Context()).Errorf("Failed to marshal connector progress: %s", err)
+				return
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// connectorEvents streams every ConnectorEvent published by the store
+// (sync progress, chunk-count updates, deletions) across all connectors, so
+// the UI can react to one feed instead of polling /connectors or opening a
+// /connectors/{id}/progress stream per connector.
+func (a *API) connectorEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Streaming not supported"})
+		return
+	}
+
+	ctx := r.Context()
+	events, err := a.store.Subscribe(ctx)
+	if err != nil {
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to subscribe to connector events", Err: err})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				apiLog.WithContext(r.Context()).Errorf("Failed to marshal connector event: %s", err)
+				return
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
 }
 
 func (a *API) handleConnectorCallback(w http.ResponseWriter, r *http.Request) {
@@ -361,67 +1241,61 @@ func (a *API) handleConnectorCallback(w http.ResponseWriter, r *http.Request) {
 	// Google returns it as "code"
 	code := queryParts.Get("code")
 	if code == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte("No code in request"))
+		writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "No code in request"})
 		return
 	}
 
 	errStr := queryParts.Get("error")
 	if errStr != "" {
-		log.Printf("Error in Google callback: %s\n", errStr)
+		apiLog.WithContext(r.Context()).Errorf("Error in Google callback: %s\n", errStr)
 	}
 
 	vars := mux.Vars(r)
 	connectorID, ok := vars["connector_id"]
 	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte("No connector name provided"))
+		writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "No connector name provided"})
 		return
 	}
 	stateParam := queryParts.Get("state")
 
 	// For some connectors, the redirectURI must be static. In that case we
 	// expect the callback URL to be the connector type.
-	if connectors.IsConnectorType(connectorID) {
+	if types.IsConnectorType(types.ConnectorType(connectorID)) {
 		// If any state is provided it must match the connector ID
 		connectorID = stateParam
 	} else {
 		if stateParam != "" && stateParam != connectorID {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte("State does not match connector ID"))
+			writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "State does not match connector ID"})
 			return
 		}
 	}
 
 	conn := a.Syncer.GetConnector(connectorID)
 	if conn == nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte("Unknown connector ID"))
+		writeError(w, r, apiError{Code: errCodeConnectorUnknown, Status: http.StatusBadRequest, Message: "Unknown connector ID"})
 		return
 	}
 	err := conn.AuthCallback(r.Context(), code)
 	if err != nil {
-		log.Printf("Failed to complete auth callback: %s\n", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("Failed to complete auth callback : " + err.Error()))
+		metrics.AuthCallbackResult.WithLabelValues("failure").Inc()
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to complete auth callback", Err: err})
 		return
 	}
 
 	state, err := conn.Status(a.Context)
 	if err != nil {
-		log.Printf("Failed to get connector state: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("Failed to get connector state: " + err.Error()))
+		metrics.AuthCallbackResult.WithLabelValues("failure").Inc()
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to get connector state", Err: err})
 		return
 	}
 	state.AuthValid = true // TODO: delegate this logic to the connector implementation
 	err = conn.UpdateConnectorState(a.Context, state)
 	if err != nil {
-		log.Printf("Failed to update connector state: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("Failed to update connector state: " + err.Error()))
+		metrics.AuthCallbackResult.WithLabelValues("failure").Inc()
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to update connector state", Err: err})
 		return
 	}
+	metrics.AuthCallbackResult.WithLabelValues("success").Inc()
 
 	// Trigger a background sync, it should silently quit if a sync is already
 	// running for this connector
@@ -432,11 +1306,73 @@ func (a *API) handleConnectorCallback(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *API) forceSync(w http.ResponseWriter, r *http.Request) {
-	err := a.Syncer.SyncNow(a.Context)
+	// Run on a.Context, whose lifetime is the whole app rather than this
+	// single HTTP request, but carry the request's ID along so every log
+	// line the sync produces downstream (including in Sync itself) can
+	// still be traced back to this call.
+	ctx := a.Context.Context
+	if id, ok := vlog.RequestIDFromContext(r.Context()); ok {
+		ctx = vlog.WithRequestID(ctx, id)
+	}
+
+	err := a.Syncer.SyncNow(ctx)
+	if err != nil {
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to sync", Err: err})
+	}
+}
+
+// syncLog serves the structured sync audit trail written by the synclog
+// package, optionally filtered by connector_id, event, since and until
+// (RFC3339) query params, as JSON.
+func (a *API) syncLog(w http.ResponseWriter, r *http.Request) {
+	path, err := synclog.DefaultPath()
+	if err != nil {
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to resolve sync log path", Err: err})
+		return
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]synclog.Record{})
+		return
+	} else if err != nil {
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to open sync log", Err: err})
+		return
+	}
+	defer f.Close()
+
+	records, err := synclog.Parse(f)
 	if err != nil {
-		log.Printf("Failed to sync: %s", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("Failed to sync: " + err.Error()))
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to parse sync log", Err: err})
+		return
+	}
+
+	query := r.URL.Query()
+	connectorID := query.Get("connector_id")
+	event := query.Get("event")
+
+	var since, until time.Time
+	if v := query.Get("since"); v != "" {
+		since, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "Invalid since timestamp", Err: err})
+			return
+		}
+	}
+	if v := query.Get("until"); v != "" {
+		until, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "Invalid until timestamp", Err: err})
+			return
+		}
+	}
+
+	filtered := synclog.Filter(records, connectorID, event, since, until)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(filtered); err != nil {
+		apiLog.WithContext(r.Context()).Errorf("Failed to encode sync log response: %s", err)
 	}
 }
 
@@ -446,36 +1382,113 @@ type PullRequestPayload struct {
 }
 
 type PullApiResponse struct {
-	Status string `json:"status"`
+	Status    string `json:"status"`
+	Digest    string `json:"digest"`
+	Total     int64  `json:"total"`
+	Completed int64  `json:"completed"`
+}
+
+// ModelPullProgress is the bytes-downloaded/total snapshot for a model
+// currently (or most recently) being pulled, surfaced by the readiness
+// endpoints so the front-end can show an accurate "still downloading
+// model X" message during long first-run pulls.
+type ModelPullProgress struct {
+	Completed int64 `json:"completed"`
+	Total     int64 `json:"total"`
+	Done      bool  `json:"done"`
+}
+
+var (
+	modelPullMu       sync.Mutex
+	modelPullProgress = map[string]ModelPullProgress{}
+)
+
+// getModelPullProgress returns the last known pull progress for name, or
+// the zero value if it has never been pulled by this process.
+func getModelPullProgress(name string) ModelPullProgress {
+	modelPullMu.Lock()
+	defer modelPullMu.Unlock()
+	return modelPullProgress[name]
+}
+
+func setModelPullProgress(name string, p ModelPullProgress) {
+	modelPullMu.Lock()
+	defer modelPullMu.Unlock()
+	modelPullProgress[name] = p
+}
+
+// modelPullCancels tracks the cancel func of the in-flight pull for each
+// model name (if any), mirroring promptCancels, so a client watching
+// GET /models/pull can abort a long download via DELETE /models/pull
+// instead of only being able to disconnect and hope Ollama notices.
+var modelPullCancels = struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+}{cancels: map[string]context.CancelFunc{}}
+
+// registerModelPullCancel records cancel as the way to abort name's
+// in-flight pull. The returned done func must be deferred by the caller
+// to deregister it once the pull completes; it does not itself call
+// cancel.
+func registerModelPullCancel(name string, cancel context.CancelFunc) (done func()) {
+	modelPullCancels.mu.Lock()
+	modelPullCancels.cancels[name] = cancel
+	modelPullCancels.mu.Unlock()
+
+	return func() {
+		modelPullCancels.mu.Lock()
+		delete(modelPullCancels.cancels, name)
+		modelPullCancels.mu.Unlock()
+	}
 }
 
-// pullModel makes a POST request to the specified URL with the given payload
-// and returns nil only if the response status is "success".
-func pullModel(name string, stream bool) error {
+// cancelModelPull cancels the in-flight pull for name, if any, reporting
+// whether one was found.
+func cancelModelPull(name string) bool {
+	modelPullCancels.mu.Lock()
+	defer modelPullCancels.mu.Unlock()
+	cancel, ok := modelPullCancels.cancels[name]
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// pullModel makes a streaming POST request to Ollama's pull endpoint,
+// recording each reported layer's download progress via
+// setModelPullProgress as it arrives, and returns nil only once Ollama
+// reports the pull's final "success" status line. ctx cancellation (e.g.
+// via cancelModelPull) aborts the request mid-download.
+func pullModel(ctx context.Context, name string, stream bool) (err error) {
+	defer func() {
+		outcome := "success"
+		if err != nil {
+			outcome = "failure"
+		}
+		metrics.ModelPulls.WithLabelValues(name, outcome).Inc()
+	}()
+
 	url := fmt.Sprintf("http://%s/api/pull", OllamaHost)
 
-	// Create the payload
+	// Ollama only reports incremental progress when streaming; request it
+	// regardless of the caller's stream flag so setModelPullProgress has
+	// something to report, and simply read to completion either way.
 	payload := PullRequestPayload{
 		Name:   name,
-		Stream: stream,
+		Stream: true,
 	}
 
-	// Marshal the payload into JSON
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		return err
 	}
 
-	// Create a new HTTP request
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return err
 	}
-
-	// Set the Content-Type header
 	req.Header.Set("Content-Type", "application/json")
 
-	// Make the HTTP request using the default client
 	client := &http.Client{}
 	response, err := client.Do(req)
 	if err != nil {
@@ -483,26 +1496,210 @@ func pullModel(name string, stream bool) error {
 	}
 	defer response.Body.Close()
 
-	// Read the response body
-	responseData, err := io.ReadAll(response.Body)
-	if err != nil {
-		return err
-	}
-
-	// Unmarshal JSON data into ApiResponse struct
-	var apiResponse PullApiResponse
-	if err := json.Unmarshal(responseData, &apiResponse); err != nil {
-		return err
+	decoder := json.NewDecoder(response.Body)
+	var last PullApiResponse
+	for {
+		var apiResponse PullApiResponse
+		if err := decoder.Decode(&apiResponse); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		last = apiResponse
+		setModelPullProgress(name, ModelPullProgress{
+			Completed: apiResponse.Completed,
+			Total:     apiResponse.Total,
+			Done:      apiResponse.Status == "success",
+		})
 	}
 
-	// Check if the status is "success"
-	if apiResponse.Status != "success" {
+	if last.Status != "success" {
 		return fmt.Errorf("API response status is not 'success'")
 	}
 
 	return nil
 }
 
+// pullModelMaxRetries bounds pullModelWithRetry's attempts at a transient
+// pull failure (e.g. Ollama briefly unreachable during its own startup)
+// before giving up and surfacing the error to the caller.
+const pullModelMaxRetries = 3
+
+// pullModelWithRetry retries pullModel with the same exponential backoff
+// downloadRangeWithRetry uses for transient connector downloads, since a
+// model pull racing Ollama's own startup is the common failure mode here.
+// It gives up early, without retrying, if ctx is cancelled (e.g. by
+// cancelModelPull) rather than treating an explicit cancellation as a
+// transient failure worth retrying.
+func pullModelWithRetry(ctx context.Context, name string, stream bool) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cancelDone := registerModelPullCancel(name, cancel)
+	defer cancelDone()
+
+	var lastErr error
+	for attempt := 0; attempt < pullModelMaxRetries; attempt++ {
+		if attempt > 0 {
+			metrics.OllamaPullRetries.WithLabelValues(name).Inc()
+			select {
+			case <-time.After(time.Duration(1<<uint(attempt-1)) * time.Second):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if err := pullModel(ctx, name, stream); err == nil {
+			return nil
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		} else {
+			lastErr = err
+		}
+	}
+	return fmt.Errorf("exceeded %d retries pulling model %s: %v", pullModelMaxRetries, name, lastErr)
+}
+
+var (
+	modelWarmMu sync.Mutex
+	modelWarm   = map[string]bool{}
+)
+
+// isModelWarm reports whether name was successfully warmed up by the most
+// recent call to warmupModels, surfaced via /health so the UI can grey out
+// the prompt box until models are actually resident rather than just
+// pulled.
+func isModelWarm(name string) bool {
+	modelWarmMu.Lock()
+	defer modelWarmMu.Unlock()
+	return modelWarm[name]
+}
+
+func setModelWarm(name string, warm bool) {
+	modelWarmMu.Lock()
+	defer modelWarmMu.Unlock()
+	modelWarm[name] = warm
+}
+
+// WarmupRequest optionally overrides how long Ollama keeps each model
+// resident after warmupModels loads it; an empty KeepAlive falls back to
+// KeepAliveTime, the same duration chatWithModel/chatWithModelStream use.
+type WarmupRequest struct {
+	KeepAlive string `json:"keep_alive"`
+}
+
+// warmupModels pre-loads the generation, reranking, and embeddings models
+// into Ollama's memory so the first real prompt doesn't pay model-load
+// latency, recording success per model via setModelWarm for /health to
+// report.
+func (a *API) warmupModels(w http.ResponseWriter, r *http.Request) {
+	var req WarmupRequest
+	if r.Body != nil {
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "Failed to decode request", Err: err})
+			return
+		}
+	}
+	keepAlive := req.KeepAlive
+	if keepAlive == "" {
+		keepAlive = KeepAliveTime
+	}
+
+	results := map[string]string{}
+	for _, model := range []string{generationModelName, rerankModelName} {
+		if err := warmupChatModel(r.Context(), model, keepAlive); err != nil {
+			setModelWarm(model, false)
+			results[model] = err.Error()
+			continue
+		}
+		setModelWarm(model, true)
+		results[model] = "warm"
+	}
+	if err := warmupEmbedModel(r.Context(), embeddingsModelName, keepAlive); err != nil {
+		setModelWarm(embeddingsModelName, false)
+		results[embeddingsModelName] = err.Error()
+	} else {
+		setModelWarm(embeddingsModelName, true)
+		results[embeddingsModelName] = "warm"
+	}
+
+	json.NewEncoder(w).Encode(results)
+}
+
+// modelPullProgressStream streams GET /models/pull?name=... as Server-Sent
+// Events, forwarding the named model's pull progress (as tracked by
+// setModelPullProgress) until it's done or the client disconnects. Unlike
+// connectorProgress, which reads from a per-connector progress channel,
+// pullModel only maintains a polled snapshot, so this polls it instead.
+func (a *API) modelPullProgressStream(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "No model name provided"})
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Streaming not supported"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ctx := r.Context()
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	var last ModelPullProgress
+	sent := false
+	for {
+		progress := getModelPullProgress(name)
+		if !sent || progress != last {
+			data, err := json.Marshal(progress)
+			if err != nil {
+				apiLog.WithContext(ctx).Errorf("Failed to marshal model pull progress: %s", err)
+				return
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+			last = progress
+			sent = true
+		}
+		if progress.Done {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// cancelModelPullHandler aborts name's in-flight pull, if any, via
+// modelPullCancels. This mirrors cancelPromptStream for the pull-progress
+// SSE endpoint above.
+func (a *API) cancelModelPullHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "No model name provided"})
+		return
+	}
+
+	if !cancelModelPull(name) {
+		writeError(w, r, apiError{Code: errCodeNotFound, Status: http.StatusNotFound, Message: "No in-flight pull for this model"})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // Struct to define the request payload
 type RequestPayload struct {
 	Model     string              `json:"model"`
@@ -536,13 +1733,19 @@ type StreamResponseHeader struct {
 }
 
 func (a *API) handlePrompt(w http.ResponseWriter, r *http.Request) {
-	log.Printf("Start of handlePrompt")
+	apiLog.WithContext(r.Context()).Infof("Start of handlePrompt")
 	startTime := time.Now()
 
+	// Register a cancellable context for the generation calls below, so a
+	// shutdown during BootStateGen can cut them short via CancelGenRequests
+	// instead of waiting for them to finish.
+	genCtx, done := registerGenRequest(r.Context())
+	defer done()
+
 	vars := mux.Vars(r)
 	conversationID, ok := vars["conversation_id"]
 	if !ok {
-		http.Error(w, "No conversation ID provided", http.StatusBadRequest)
+		writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "No conversation ID provided"})
 		return
 	}
 
@@ -551,38 +1754,50 @@ func (a *API) handlePrompt(w http.ResponseWriter, r *http.Request) {
 	err := json.NewDecoder(r.Body).Decode(&promptReq)
 	if err != nil {
 		// return HTTP 400 bad request
-		http.Error(w, "Failed to decode request", http.StatusBadRequest)
+		writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "Failed to decode request", Err: err})
 	}
 
 	conversation, err := a.store.GetConversation(r.Context(), conversationID)
 	if err != nil {
-		log.Printf("Failed to get conversation: %s", err)
-		http.Error(w, "Failed to get conversation: "+err.Error(), http.StatusInternalServerError)
+		if store.IsErrConversationNotFound(err) {
+			writeError(w, r, apiError{Code: errCodeNotFound, Status: http.StatusNotFound, Message: "Conversation not found", Err: err})
+			return
+		}
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to get conversation", Err: err})
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 
-	// Call Ollama embeddings model to get embeddings for the prompt
-	resp, err := EmbedFromModel(promptReq.Prompt)
+	topK := DefaultRetrievalTopK
+	topN := DefaultRerankTopN
+	reranker := a.Reranker
+	if cfg, cfgErr := a.store.GetConfig(r.Context()); cfgErr == nil && cfg != nil {
+		if cfg.RetrievalTopK > 0 {
+			topK = cfg.RetrievalTopK
+		}
+		if cfg.RerankTopN > 0 {
+			topN = cfg.RerankTopN
+		}
+		if cfg.RerankStrategy == rerankStrategyRRF {
+			reranker = rrfReranker{}
+		}
+	}
+
+	// Call the configured LLM provider's embeddings model to embed the prompt
+	embeddings, err := a.embed(r.Context(), promptReq.Prompt)
 	if err != nil {
-		log.Printf("Failed to get embeddings: %s", err)
-		http.Error(w, "Failed to get embeddings "+err.Error(), http.StatusInternalServerError)
+		writeError(w, r, apiError{Code: errCodeLLMFailure, Status: http.StatusInternalServerError, Message: "Failed to get embeddings", Err: err})
 		return
 	}
 	embedTime := time.Now()
 
-	embeddings := resp.Embedding
-	log.Printf("Performing vector search")
+	apiLog.WithContext(r.Context()).Infof("Performing vector search")
 
 	// Perform vector similarity search and get list of most relevant results
-	searchResults, err := a.store.HybridSearch(
-		r.Context(),
-		promptReq.Prompt,
-		embeddings,
-	)
+	searchResults, err := a.Retriever.Retrieve(r.Context(), promptReq.Prompt, embeddings, topK)
 	if err != nil {
-		http.Error(w, "Failed to search for vectors", http.StatusInternalServerError)
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to search for vectors", Err: err})
 		return
 	}
 	searchTime := time.Now()
@@ -591,9 +1806,15 @@ func (a *API) handlePrompt(w http.ResponseWriter, r *http.Request) {
 	for _, chunkHash := range conversation.ChunkHashes {
 		chunk, err := a.store.GetChunkByHash(r.Context(), chunkHash)
 		if err != nil {
-			log.Printf("Failed to get chunk by hash: %s", err)
-			http.Error(w, "Failed to get chunk by hash", http.StatusInternalServerError)
-			return
+			if store.IsErrChunkNotFound(err) {
+				// The chunk behind this historical turn was since purged
+				// (e.g. its document was tombstoned and GC'd). Keep the
+				// conversation usable rather than failing the whole prompt.
+				chunk = &types.Chunk{Hash: chunkHash, Document: types.Document{Name: "[deleted]"}}
+			} else {
+				writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to get chunk by hash", Err: err})
+				return
+			}
 		}
 		searchResults = append(searchResults, chunk)
 	}
@@ -601,21 +1822,20 @@ func (a *API) handlePrompt(w http.ResponseWriter, r *http.Request) {
 	hashes := map[string]bool{}
 	for _, chunk := range searchResults {
 		if chunk.Hash == "" {
-			log.Printf("Pre-rerank Chunk has no hash")
+			apiLog.WithContext(r.Context()).Infof("Pre-rerank Chunk has no hash")
 		}
 		_, ok := hashes[chunk.Hash]
 		if ok {
-			log.Printf("Pre-rerank duplicate hash " + chunk.Hash)
+			apiLog.WithContext(r.Context()).Infof("Pre-rerank duplicate hash " + chunk.Hash)
 		}
 
 		hashes[chunk.Hash] = true
 	}
 
 	// Rerank the results
-	rerankedChunks, err := Rerank(r.Context(), searchResults, promptReq.Prompt)
+	rerankedChunks, err := reranker.Rerank(promptCtx, searchResults, promptReq.Prompt, topN)
 	if err != nil {
-		log.Printf("Failed to rerank search results: %s", err)
-		http.Error(w, "Failed to rerank search results", http.StatusInternalServerError)
+		writeError(w, r, apiError{Code: errCodeLLMFailure, Status: http.StatusInternalServerError, Message: "Failed to rerank search results", Err: err})
 		return
 	}
 	rerankTime := time.Now()
@@ -623,52 +1843,48 @@ func (a *API) handlePrompt(w http.ResponseWriter, r *http.Request) {
 	hashes = map[string]bool{}
 	for _, chunk := range rerankedChunks {
 		if chunk.Hash == "" {
-			log.Printf("Post-rerank Chunk has no hash")
+			apiLog.WithContext(r.Context()).Infof("Post-rerank Chunk has no hash")
 		}
 		_, ok := hashes[chunk.Hash]
 		if ok {
-			log.Printf("Post-rerank duplicate hash " + chunk.Hash)
+			apiLog.WithContext(r.Context()).Infof("Post-rerank duplicate hash " + chunk.Hash)
 		}
 
 		hashes[chunk.Hash] = true
 	}
 
-	llmPrompt := MakePrompt(rerankedChunks, promptReq.Prompt)
-	log.Printf("LLM Prompt: %s", llmPrompt)
+	llmPrompt := a.PromptTemplate.Build(rerankedChunks, promptReq.Prompt)
+	apiLog.WithContext(r.Context()).Infof("LLM Prompt: %s", llmPrompt)
 	err = WritePromptLog(llmPrompt)
 	if err != nil {
-		log.Printf("Failed to write prompt to log: %s", err)
-		http.Error(w, "Failed to write prompt to log", http.StatusInternalServerError)
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to write prompt to log", Err: err})
 		return
 	}
 
 	streamChan := make(chan StreamResponse)
-	err = chatWithModelStream(r.Context(), llmPrompt, generationModelName, conversation.History, streamChan)
+	err = a.chatStream(promptCtx, llmPrompt, generationModelName, conversation.History, streamChan)
 	if err != nil {
-		log.Printf("Failed to generate response: %s", err)
-		http.Error(w, "Failed to generate response", http.StatusInternalServerError)
+		writeError(w, r, apiError{Code: errCodeLLMFailure, Status: http.StatusInternalServerError, Message: "Failed to generate response", Err: err})
 		return
 	}
 
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		// TODO: if we run into this, fall back to non-streaming
-		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Streaming not supported"})
 		return
 	}
 
 	sourcesObj := sourcesFromChunks(rerankedChunks)
 	sourcesObjJSON, marshalSourcesErr := json.Marshal(sourcesObj)
 	if marshalSourcesErr != nil {
-		log.Printf("Failed to marshal sources: %s", marshalSourcesErr)
-		http.Error(w, "Failed to marshal sources", http.StatusInternalServerError)
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to marshal sources", Err: marshalSourcesErr})
 		return
 	}
 	var sources []map[string]string
 	unmarshalSourcesErr := json.Unmarshal(sourcesObjJSON, &sources)
 	if unmarshalSourcesErr != nil {
-		log.Printf("Failed to unmarshal sources: %s", unmarshalSourcesErr)
-		http.Error(w, "Failed to unmarshal sources", http.StatusInternalServerError)
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to unmarshal sources", Err: unmarshalSourcesErr})
 		return
 	}
 
@@ -697,6 +1913,13 @@ func (a *API) handlePrompt(w http.ResponseWriter, r *http.Request) {
 		}
 		streamCount++
 		responseAcc += item.Message.Content
+		if item.Done {
+			// Carry the citation list on the terminating frame too, not
+			// just the header, so a client that only reads the last frame
+			// (e.g. one written to join a delayed connection) still gets
+			// the sources for this response.
+			item.Message.Sources = sourcesObj
+		}
 		json.NewEncoder(w).Encode(item)
 		_, err = w.Write([]byte("\n"))
 		if err != nil {
@@ -708,7 +1931,7 @@ func (a *API) handlePrompt(w http.ResponseWriter, r *http.Request) {
 
 	err = WritePromptLog(responseAcc)
 	if err != nil {
-		log.Printf("Failed to write prompt to log: %s", err)
+		apiLog.WithContext(r.Context()).Errorf("Failed to write prompt to log: %s", err)
 		http.Error(w, "Failed to write prompt to log", http.StatusInternalServerError)
 		return
 	}
@@ -742,36 +1965,314 @@ func (a *API) handlePrompt(w http.ResponseWriter, r *http.Request) {
 		},
 	}, newChunks)
 	if err != nil {
-		log.Printf("Failed to append to conversation: %s", err)
+		apiLog.WithContext(r.Context()).Errorf("Failed to append to conversation: %s", err)
 		http.Error(w, "Failed to append to conversation", http.StatusInternalServerError)
 		return
 	}
 
-	if a.Posthog == nil {
+	metrics.PromptStageDuration.WithLabelValues("embed").Observe(embedTime.Sub(startTime).Seconds())
+	metrics.PromptStageDuration.WithLabelValues("search").Observe(searchTime.Sub(embedTime).Seconds())
+	metrics.PromptStageDuration.WithLabelValues("rerank").Observe(rerankTime.Sub(searchTime).Seconds())
+	metrics.PromptStageDuration.WithLabelValues("ttft").Observe(timeToFirstToken.Sub(rerankTime).Seconds())
+	metrics.PromptStageDuration.WithLabelValues("gen").Observe(doneTime.Sub(timeToFirstToken).Seconds())
+	metrics.PromptResultCount.WithLabelValues("search").Observe(float64(len(searchResults)))
+	metrics.PromptResultCount.WithLabelValues("reranked").Observe(float64(len(rerankedChunks)))
+	metrics.PromptStreamEvents.Observe(float64(streamCount))
+
+	err = a.Analytics.Capture(a.PosthogDistinctID, "Prompt", map[string]interface{}{
+		"total_duration":        doneTime.Sub(startTime).String(),
+		"1.search_duration":     searchTime.Sub(embedTime).String(),
+		"2.embed_duration":      embedTime.Sub(startTime).String(),
+		"3.rerank_duration":     rerankTime.Sub(searchTime).String(),
+		"4.gen_ttft_duration":   timeToFirstToken.Sub(rerankTime).String(),
+		"5.gen_stream_duration": doneTime.Sub(timeToFirstToken).String(),
+		"ttft_duration":         timeToFirstToken.Sub(startTime).String(),
+		"gen_sum_duration":      doneTime.Sub(rerankTime).String(),
+		"num_search_results":    len(searchResults),
+		"num_reranked_results":  len(rerankedChunks),
+		"num_streamed_events":   streamCount,
+		"version":               a.Version,
+	})
+	if err != nil {
+		apiLog.WithContext(r.Context()).Errorf("Failed to enqueue event: %s\n", err)
+		http.Error(w, "Failed to enqueue event", http.StatusInternalServerError)
 		return
 	}
+	apiLog.WithContext(r.Context()).Infof("End of handlePrompt")
+}
 
-	err = a.Posthog.Enqueue(posthog.Capture{
-		DistinctId: a.PosthogDistinctID,
-		Event:      "Prompt",
-		Properties: posthog.NewProperties().
-			Set("total_duration", doneTime.Sub(startTime).String()).
-			Set("1.search_duration", searchTime.Sub(embedTime).String()).
-			Set("2.embed_duration", embedTime.Sub(startTime).String()).
-			Set("3.rerank_duration", rerankTime.Sub(searchTime).String()).
-			Set("4.gen_ttft_duration", timeToFirstToken.Sub(rerankTime).String()).
-			Set("5.gen_stream_duration", doneTime.Sub(timeToFirstToken).String()).
-			Set("ttft_duration", timeToFirstToken.Sub(startTime).String()).
-			Set("gen_sum_duration", doneTime.Sub(rerankTime).String()).
-			Set("num_search_results", len(searchResults)).
-			Set("num_reranked_results", len(rerankedChunks)).
-			Set("num_streamed_events", streamCount).
-			Set("version", a.Version),
-	})
+// promptStreamRetrieval is the "retrieval" event payload for
+// handlePromptStream, reporting progress through the pre-generation
+// pipeline so a client can show something more useful than a blank
+// spinner while embedding/search/rerank run.
+type promptStreamRetrieval struct {
+	Stage string `json:"stage"` // "embedded_query", "searched", "reranked"
+}
+
+// promptStreamToken is the "token" event payload, one per generated chunk.
+type promptStreamToken struct {
+	Content string `json:"content"`
+}
+
+// promptStreamDone is the "done" event payload, closing the stream.
+type promptStreamDone struct {
+	Content string              `json:"content"` // Full accumulated response
+	Sources []map[string]string `json:"sources"`
+}
+
+// promptStreamMetrics is the "metrics" event payload, sent right after
+// "done" so a client can show timing/result-count info without waiting on
+// a separate request.
+type promptStreamMetrics struct {
+	TotalDurationMs    int64 `json:"total_duration_ms"`
+	NumSearchResults   int   `json:"num_search_results"`
+	NumRerankedResults int   `json:"num_reranked_results"`
+}
+
+// promptStreamPingInterval is how often handlePromptStream writes an SSE
+// comment line to keep the connection alive through proxies that time out
+// idle connections during a slow retrieval or generation.
+const promptStreamPingInterval = 15 * time.Second
+
+// writeSSEEvent writes a single SSE frame and flushes it immediately, so a
+// slow generation doesn't sit buffered behind net/http's default buffering.
+func writeSSEEvent(w http.ResponseWriter, flusher http.Flusher, event string, v interface{}) error {
+	data, err := json.Marshal(v)
 	if err != nil {
-		log.Printf("Failed to enqueue event: %s\n", err)
-		http.Error(w, "Failed to enqueue event", http.StatusInternalServerError)
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	flusher.Flush()
+	return nil
+}
+
+// cancelPromptStream aborts conversationID's in-flight streamed prompt, if
+// any, via promptCancels. This lets a client stop generation explicitly
+// (e.g. a "stop" button) rather than relying solely on dropping the
+// EventSource connection, which some browsers are slow to report as
+// context cancellation on the server side.
+func (a *API) cancelPromptStream(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	conversationID, ok := vars["conversation_id"]
+	if !ok {
+		writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "No conversation ID provided"})
+		return
+	}
+
+	if !cancelPrompt(conversationID) {
+		writeError(w, r, apiError{Code: errCodeNotFound, Status: http.StatusNotFound, Message: "No in-flight prompt for this conversation"})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePromptStream is the Server-Sent Events counterpart to handlePrompt:
+// instead of returning one newline-delimited JSON response, it streams
+// "retrieval" progress events through embedding/search/rerank, a
+// "sources" event once sources are known, a "token" event per generated
+// chunk, a "done" event with the accumulated response, and a closing
+// "metrics" event. A keep-alive comment is sent every pingInterval so
+// intermediate proxies don't time out the connection during a slow
+// retrieval or generation. The client disconnecting, or a
+// DELETE /conversations/{id}/prompt, cancels genCtx, which aborts the
+// in-flight Ollama request instead of letting it run to completion unread.
+func (a *API) handlePromptStream(w http.ResponseWriter, r *http.Request) {
+	apiLog.WithContext(r.Context()).Infof("Start of handlePromptStream")
+	startTime := time.Now()
+
+	genCtx, done := registerGenRequest(r.Context())
+	defer done()
+
+	vars := mux.Vars(r)
+	conversationID, ok := vars["conversation_id"]
+	if !ok {
+		writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "No conversation ID provided"})
+		return
+	}
+
+	promptCtx, promptCancel := context.WithCancel(genCtx)
+	defer promptCancel()
+	cancelDone := registerPromptCancel(conversationID, promptCancel)
+	defer cancelDone()
+
+	var promptReq PromptRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&promptReq); err != nil {
+		writeError(w, r, apiError{Code: errCodeBadRequest, Status: http.StatusBadRequest, Message: "Failed to decode request", Err: err})
+		return
+	}
+
+	conversation, err := a.store.GetConversation(r.Context(), conversationID)
+	if err != nil {
+		if store.IsErrConversationNotFound(err) {
+			writeError(w, r, apiError{Code: errCodeNotFound, Status: http.StatusNotFound, Message: "Conversation not found", Err: err})
+			return
+		}
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Failed to get conversation", Err: err})
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, r, apiError{Code: errCodeStoreFailure, Status: http.StatusInternalServerError, Message: "Streaming not supported"})
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	var sseMu sync.Mutex
+	pingStop := make(chan struct{})
+	defer close(pingStop)
+	go func() {
+		ticker := time.NewTicker(promptStreamPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				sseMu.Lock()
+				_, err := fmt.Fprint(w, ": ping\n\n")
+				if err == nil {
+					flusher.Flush()
+				}
+				sseMu.Unlock()
+			case <-pingStop:
+				return
+			}
+		}
+	}()
+
+	sendEvent := func(event string, v interface{}) error {
+		sseMu.Lock()
+		defer sseMu.Unlock()
+		return writeSSEEvent(w, flusher, event, v)
+	}
+
+	topK := DefaultRetrievalTopK
+	topN := DefaultRerankTopN
+	reranker := a.Reranker
+	if cfg, cfgErr := a.store.GetConfig(r.Context()); cfgErr == nil && cfg != nil {
+		if cfg.RetrievalTopK > 0 {
+			topK = cfg.RetrievalTopK
+		}
+		if cfg.RerankTopN > 0 {
+			topN = cfg.RerankTopN
+		}
+		if cfg.RerankStrategy == rerankStrategyRRF {
+			reranker = rrfReranker{}
+		}
+	}
+
+	embeddings, err := a.embed(promptCtx, promptReq.Prompt)
+	if err != nil {
+		apiLog.WithContext(r.Context()).Errorf("Failed to get embeddings: %s", err)
+		sendEvent("error", map[string]string{"error": "Failed to get embeddings"})
+		return
+	}
+	sendEvent("retrieval", promptStreamRetrieval{Stage: "embedded_query"})
+
+	searchResults, err := a.Retriever.Retrieve(promptCtx, promptReq.Prompt, embeddings, topK)
+	if err != nil {
+		apiLog.WithContext(r.Context()).Errorf("Failed to search for vectors: %s", err)
+		sendEvent("error", map[string]string{"error": "Failed to search for vectors"})
+		return
+	}
+	sendEvent("retrieval", promptStreamRetrieval{Stage: "searched"})
+
+	for _, chunkHash := range conversation.ChunkHashes {
+		chunk, err := a.store.GetChunkByHash(r.Context(), chunkHash)
+		if err != nil {
+			if store.IsErrChunkNotFound(err) {
+				chunk = &types.Chunk{Hash: chunkHash, Document: types.Document{Name: "[deleted]"}}
+			} else {
+				apiLog.WithContext(r.Context()).Errorf("Failed to get chunk by hash: %s", err)
+				sendEvent("error", map[string]string{"error": "Failed to get chunk by hash"})
+				return
+			}
+		}
+		searchResults = append(searchResults, chunk)
+	}
+
+	rerankedChunks, err := reranker.Rerank(promptCtx, searchResults, promptReq.Prompt, topN)
+	if err != nil {
+		apiLog.WithContext(r.Context()).Errorf("Failed to rerank search results: %s", err)
+		sendEvent("error", map[string]string{"error": "Failed to rerank search results"})
+		return
+	}
+	sendEvent("retrieval", promptStreamRetrieval{Stage: "reranked"})
+
+	sourcesObj := sourcesFromChunks(rerankedChunks)
+	sendEvent("sources", map[string]interface{}{"sources": sourcesObj})
+
+	llmPrompt := a.PromptTemplate.Build(rerankedChunks, promptReq.Prompt)
+	if err := WritePromptLog(llmPrompt); err != nil {
+		apiLog.WithContext(r.Context()).Errorf("Failed to write prompt to log: %s", err)
+	}
+
+	streamChan := make(chan StreamResponse)
+	if err := a.chatStream(promptCtx, llmPrompt, generationModelName, conversation.History, streamChan); err != nil {
+		apiLog.WithContext(r.Context()).Errorf("Failed to generate response: %s", err)
+		sendEvent("error", map[string]string{"error": "Failed to generate response"})
+		return
+	}
+
+	responseAcc := ""
+	for item := range streamChan {
+		responseAcc += item.Message.Content
+		if !item.Done {
+			if err := sendEvent("token", promptStreamToken{Content: item.Message.Content}); err != nil {
+				return
+			}
+		}
+	}
+
+	if err := WritePromptLog(responseAcc); err != nil {
+		apiLog.WithContext(r.Context()).Errorf("Failed to write prompt to log: %s", err)
+	}
+
+	newChunks := []*types.Chunk{}
+	for _, chunk := range rerankedChunks {
+		found := false
+		for _, chunkHash := range conversation.ChunkHashes {
+			if chunkHash == chunk.Hash {
+				found = true
+				break
+			}
+		}
+		if !found {
+			newChunks = append(newChunks, chunk)
+		}
+	}
+
+	err = a.store.ConversationAppend(r.Context(), conversationID, []types.HistoryItem{
+		{Role: "user", Content: promptReq.Prompt},
+		{Role: "assistant", Content: responseAcc, Sources: sourcesObj},
+	}, newChunks)
+	if err != nil {
+		apiLog.WithContext(r.Context()).Errorf("Failed to append to conversation: %s", err)
+		sendEvent("error", map[string]string{"error": "Failed to append to conversation"})
 		return
 	}
-	log.Printf("End of handlePrompt")
+
+	sendEvent("done", promptStreamDone{Content: responseAcc, Sources: sourcesObj})
+	sendEvent("metrics", promptStreamMetrics{
+		TotalDurationMs:    time.Since(startTime).Milliseconds(),
+		NumSearchResults:   len(searchResults),
+		NumRerankedResults: len(rerankedChunks),
+	})
+
+	if err := a.Analytics.Capture(a.PosthogDistinctID, "PromptStream", map[string]interface{}{
+		"total_duration":       time.Since(startTime).String(),
+		"num_search_results":   len(searchResults),
+		"num_reranked_results": len(rerankedChunks),
+		"version":              a.Version,
+	}); err != nil {
+		apiLog.WithContext(r.Context()).Errorf("Failed to enqueue event: %s\n", err)
+	}
+	apiLog.WithContext(r.Context()).Infof("End of handlePromptStream")
 }
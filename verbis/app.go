@@ -1,11 +1,17 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"log"
 	"strings"
 
+	vlog "github.com/verbis-ai/verbis/verbis/log"
 	"github.com/verbis-ai/verbis/verbis/types"
+
+	// Blank-imported for its RegisterConnector side effect, the same way
+	// an out-of-tree connector package would be linked in; it's a no-op
+	// at runtime unless VERBIS_FS_WATCH_ROOT is set.
+	_ "github.com/verbis-ai/verbis/verbis/connectors/filesystem"
 )
 
 var (
@@ -16,53 +22,63 @@ var (
 	KeepAliveTime       = "20m"
 
 	// Will be populated by linker from .builder.env
-	PosthogAPIKey     = "n/a"
-	AzureSecretID     = "n/a"
-	AzureSecretValue  = "n/a"
-	SlackClientID     = "n/a"
-	SlackClientSecret = "n/a"
-	Version           = "0.0.0"
-	Tag               = "n/a"
+	PosthogAPIKey       = "n/a"
+	AzureSecretID       = "n/a"
+	AzureSecretValue    = "n/a"
+	SlackClientID       = "n/a"
+	SlackClientSecret   = "n/a"
+	DropboxClientID     = "n/a"
+	DropboxClientSecret = "n/a"
+	Version             = "0.0.0"
+	Tag                 = "n/a"
+
+	appLog = vlog.New("app")
 )
 
 func main() {
+	logFormat := flag.String("log-format", "text", "log output format: text or json")
+	flag.Parse()
+	vlog.SetFormat(*logFormat)
+
 	creds := types.BuildCredentials{
-		PosthogAPIKey:     PosthogAPIKey,
-		AzureSecretID:     AzureSecretID,
-		AzureSecretValue:  AzureSecretValue,
-		SlackClientID:     SlackClientID,
-		SlackClientSecret: SlackClientSecret,
+		PosthogAPIKey:       PosthogAPIKey,
+		AzureSecretID:       AzureSecretID,
+		AzureSecretValue:    AzureSecretValue,
+		SlackClientID:       SlackClientID,
+		SlackClientSecret:   SlackClientSecret,
+		DropboxClientID:     DropboxClientID,
+		DropboxClientSecret: DropboxClientSecret,
 	}
 	// Start everything needed to let the user onboard connectors
 	bootCtx, err := BootOnboard(creds, getVersionString())
 	if err != nil {
-		log.Fatalf("Failed to boot until onboarding: %s\n", err)
+		appLog.Fatalf("Failed to boot until onboarding: %s", err)
 	}
-	log.Printf("Boot: Ready to onboard connectors")
+	appLog.Infof("Boot: Ready to onboard connectors")
 	defer bootCtx.Logfile.Close()
 
 	// Start everything needed for syncing
 	// Pulls embeddings model
 	err = BootSyncing(bootCtx)
 	if err != nil {
-		log.Fatalf("Failed to boot until syncing: %s\n", err)
+		appLog.Fatalf("Failed to boot until syncing: %s", err)
 	}
-	log.Printf("Boot: Ready to sync")
+	appLog.Infof("Boot: Ready to sync")
 
 	// Start everything needed for generation
 	// Pulls generation and reranking models
 	err = BootGen(bootCtx)
 	if err != nil {
-		log.Fatalf("Failed to boot until generation: %s\n", err)
+		appLog.Fatalf("Failed to boot until generation: %s", err)
 	}
-	log.Printf("Boot: Ready to generate")
+	appLog.Infof("Boot: Ready to generate")
 
 	<-bootCtx.Done() // Block until the app terminates
 }
 
 func getVersionString() string {
 	if Tag == "n/a/" {
-		log.Fatalf("Tag is not set, application built with missing linker flags")
+		appLog.Fatalf("Tag is not set, application built with missing linker flags")
 	}
 
 	if strings.HasSuffix(Tag, "-dirty") {
@@ -2,37 +2,111 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"log"
-	"math"
 	"net/http"
 	"os"
-	"os/exec"
 	"os/signal"
 	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/gorilla/handlers"
-	"github.com/posthog/posthog-go"
 
+	"github.com/verbis-ai/verbis/verbis/analytics"
+	"github.com/verbis-ai/verbis/verbis/connectors"
+	"github.com/verbis-ai/verbis/verbis/llm"
+	vlog "github.com/verbis-ai/verbis/verbis/log"
+	"github.com/verbis-ai/verbis/verbis/metrics"
+	"github.com/verbis-ai/verbis/verbis/platform"
 	"github.com/verbis-ai/verbis/verbis/store"
+	"github.com/verbis-ai/verbis/verbis/supervisor"
 	"github.com/verbis-ai/verbis/verbis/types"
 	"github.com/verbis-ai/verbis/verbis/util"
 )
 
+var (
+	bootLog     = vlog.New("boot")
+	ollamaLog   = vlog.New("ollama")
+	weaviateLog = vlog.New("weaviate")
+)
+
 const (
-	masterLogPath      = ".verbis/logs/full.log"
-	WeaviatePersistDir = ".verbis/synced_data"
-	OllamaModelsDir    = ".verbis/ollama/models"
-	OllamaRunnersDir   = ".verbis/ollama/runners"
-	OllamaTmpDir       = ".verbis/ollama/tmp"
+	ollamaSupervisorName   = "ollama"
+	weaviateSupervisorName = "weaviate"
+)
 
-	miscModelsPath    = ".verbis/models"
+// maybeServeMetricsOnDedicatedAddr binds /metrics on its own loopback
+// listener when VERBIS_METRICS_ADDR is set, instead of exposing it on the
+// public 8081/8082 router.
+func maybeServeMetricsOnDedicatedAddr() {
+	addr := os.Getenv("VERBIS_METRICS_ADDR")
+	if addr == "" {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	go func() {
+		bootLog.Infof("Starting dedicated metrics server on %s", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			bootLog.Errorf("Metrics server exited: %s", err)
+		}
+	}()
+}
+
+// pollSupervisorGauges periodically mirrors supervisor state into the
+// verbis_subprocess_up and verbis_subprocess_restarts_total metrics.
+func pollSupervisorGauges(ctx context.Context, supervisors map[string]*supervisor.Supervisor) {
+	lastRestarts := map[string]int{}
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for name, sup := range supervisors {
+				status := sup.Status()
+				up := 0.0
+				if status.State == supervisor.StateRunning {
+					up = 1.0
+				}
+				metrics.SubprocessUp.WithLabelValues(name).Set(up)
+
+				if delta := status.RestartCount - lastRestarts[name]; delta > 0 {
+					metrics.SubprocessRestarts.WithLabelValues(name).Add(float64(delta))
+				}
+				lastRestarts[name] = status.RestartCount
+			}
+		}
+	}
+}
+
+const (
+	// appDataDirName is the app name passed to platform.Provider.UserDataDir,
+	// and the subdirectories below are resolved relative to it.
+	appDataDirName = "verbis"
+
+	masterLogPath      = "logs/full.log"
+	eventsLogPath      = "logs/events.jsonl"
+	authTokenPath      = "auth_token"
+	WeaviatePersistDir = "synced_data"
+	OllamaModelsDir    = "ollama/models"
+	OllamaRunnersDir   = "ollama/runners"
+	OllamaTmpDir       = "ollama/tmp"
+
+	miscModelsPath    = "models"
 	rerankerModelName = "ms-marco-MiniLM-L-12-v2"
+
+	// shutdownGracePeriod bounds how long Halt waits for in-flight work
+	// (HTTP requests, a syncing batch) to finish on its own before the
+	// boot context is cancelled out from under it.
+	shutdownGracePeriod = 30 * time.Second
 )
 
 type BootState string
@@ -50,9 +124,39 @@ type BootContext struct {
 	Credentials       types.BuildCredentials
 	State             BootState
 	PosthogDistinctID string
-	PosthogClient     posthog.Client
+	Analytics         analytics.Sink
 	Syncer            *Syncer
 	Logfile           *os.File
+	Platform          platform.Provider
+	Supervisors       map[string]*supervisor.Supervisor
+
+	// AuthToken is the per-install bearer token loaded (or generated, on
+	// first boot) by loadOrCreateAuthToken and handed to the API so
+	// bearerAuthMiddleware can check requests against it.
+	AuthToken string
+
+	// lastErr guards the error, if any, that stopped the current boot
+	// phase from progressing, surfaced by the /ready/* endpoints so a
+	// container orchestrator or front-end can show why boot is stuck
+	// rather than just that it hasn't finished yet.
+	errMu   sync.Mutex
+	lastErr error
+}
+
+// SetLastError records err as the reason the current boot phase hasn't
+// progressed, for LastError to report via the readiness endpoints.
+func (b *BootContext) SetLastError(err error) {
+	b.errMu.Lock()
+	defer b.errMu.Unlock()
+	b.lastErr = err
+}
+
+// LastError returns the error, if any, recorded by the most recent call to
+// SetLastError.
+func (b *BootContext) LastError() error {
+	b.errMu.Lock()
+	defer b.errMu.Unlock()
+	return b.lastErr
 }
 
 type Timers struct {
@@ -71,85 +175,116 @@ func NewBootContext(ctx context.Context) *BootContext {
 		},
 		State:             BootStateStarted,
 		PosthogDistinctID: uuid.New().String(),
+		Platform:          platform.New(),
 	}
 }
 
-func BootOnboard(creds types.BuildCredentials) (*BootContext, error) {
+func BootOnboard(creds types.BuildCredentials, version string) (*BootContext, error) {
+	plat := platform.New()
+
 	// Set up logging
-	path, err := GetMasterLogDir()
+	path, err := GetMasterLogDir(plat)
 	if err != nil {
-		log.Fatalf("Failed to get master log directory: %s", err)
+		bootLog.Fatalf("Failed to get master log directory: %s", err)
 	}
 
 	err = os.MkdirAll(filepath.Dir(path), 0755)
 	if err != nil && !os.IsExist(err) {
-		log.Fatalf("Failed to create log directory: %s", err)
+		bootLog.Fatalf("Failed to create log directory: %s", err)
 	}
 
 	logFile, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 	if err != nil {
-		log.Fatalf("Failed to open log file: %s", err)
+		bootLog.Fatalf("Failed to open log file: %s", err)
 	}
 
 	err = syscall.Dup2(int(logFile.Fd()), int(os.Stderr.Fd()))
 	if err != nil {
-		log.Fatalf("Failed to redirect stderr to file: %v", err)
+		bootLog.Fatalf("Failed to redirect stderr to file: %v", err)
 	}
 	os.Stderr = logFile
 	os.Stdout = logFile
 	log.SetOutput(logFile)
+	vlog.SetOutput(logFile)
 
-	ctx, cancel := context.WithCancel(context.Background())
-	log.Printf("Starting Verbis boot sequence")
+	ctx, cancel, sigChan := newGracefulContext()
+	bootLog.Infof("Starting Verbis boot sequence")
 
 	// Kill any previous stale processes
 	processesToKill := []string{"ollama", "weaviate"}
 	for _, process := range processesToKill {
-		if err := killProcessByName(process); err != nil {
-			log.Printf("Error killing process %s: %s\n", process, err)
+		if err := plat.KillProcessByName(process); err != nil {
+			bootLog.Infof("Error killing process %s: %s", process, err)
 		}
 	}
 
 	bootCtx := NewBootContext(ctx)
+	bootCtx.Platform = plat
 	bootCtx.Logfile = logFile
 	bootCtx.Credentials = creds
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-
-	postHogClient, err := posthog.NewWithConfig(
-		PosthogAPIKey,
-		posthog.Config{
-			PersonalApiKey:                     PosthogAPIKey,
-			Endpoint:                           "https://eu.i.posthog.com",
-			DefaultFeatureFlagsPollingInterval: math.MaxInt64,
-		},
-	)
+	dataDir, err := plat.UserDataDir(appDataDirName)
+	if err != nil {
+		bootLog.Fatalf("unable to get user data directory: %s", err)
+	}
+	eventsPath := filepath.Join(dataDir, eventsLogPath)
+
+	authToken, err := loadOrCreateAuthToken(dataDir)
 	if err != nil {
-		log.Fatalf("Failed to create PostHog client: %s\n", err)
+		bootLog.Fatalf("Failed to load or create auth token: %s", err)
 	}
+	bootCtx.AuthToken = authToken
 
-	bootCtx.PosthogClient = postHogClient
+	analyticsSink, err := analytics.New(analytics.ModeFromEnv(), PosthogAPIKey, eventsPath)
+	if err != nil {
+		bootLog.Fatalf("Failed to create analytics sink: %s", err)
+	}
+	bootCtx.Analytics = analyticsSink
 
-	syncer := NewSyncer(bootCtx.PosthogClient, bootCtx.PosthogDistinctID, bootCtx.Credentials)
-	if PosthogAPIKey == "n/a" {
-		log.Fatalf("Posthog API key not set\n")
+	apiStore, err := store.New(store.BackendFromEnv(), fmt.Sprintf("http://%s", OllamaHost), embeddingsModelName, dataDir)
+	if err != nil {
+		bootLog.Fatalf("Failed to create store: %s", err)
+	}
+	if ws, ok := apiStore.(*store.WeaviateStore); ok {
+		if err := ws.RebuildBloomFilters(ctx); err != nil {
+			bootLog.Errorf("Failed to rebuild bloom filters: %s", err)
+		}
+	}
+
+	syncer := NewSyncer(bootCtx.Analytics, bootCtx.PosthogDistinctID, bootCtx.Credentials, version, apiStore)
+	// VERBIS_LOCK_REDIS_ADDR opts into cross-process connector sync
+	// locking; left unset, Syncer falls back to relying solely on
+	// SetConnectorSyncing's CAS, which is fine for single-process
+	// deployments but not for multiple verbis replicas sharing a store.
+	if lockAddr := os.Getenv("VERBIS_LOCK_REDIS_ADDR"); lockAddr != "" {
+		locker, err := connectors.NewRedisLocker(ctx, lockAddr, connectors.DefaultLockTTL)
+		if err != nil {
+			bootLog.Errorf("Failed to create Redis locker, falling back to single-process sync locking: %s", err)
+		} else {
+			syncer.SetLocker(locker)
+		}
 	}
 	bootCtx.Syncer = syncer
+	// The LLM provider starts out as the Ollama default; a user who wants
+	// an OpenAI-compatible or llama.cpp endpoint instead switches via
+	// updateConfig, which rebuilds a.LLM from the submitted types.Config.
 	api := API{
 		Syncer:            syncer,
-		Posthog:           postHogClient,
+		Analytics:         analyticsSink,
 		PosthogDistinctID: bootCtx.PosthogDistinctID,
 		Context:           bootCtx,
+		Version:           version,
+		store:             apiStore,
+		AuthToken:         authToken,
+		LLM:               llm.NewFromConfig(nil, OllamaHost, embeddingsModelName),
+		Retriever:         &hybridSearchRetriever{store: apiStore},
+		Reranker:          bertReranker{},
+		PromptTemplate:    defaultPromptTemplate{},
 	}
 	router := api.SetupRouter()
+	maybeServeMetricsOnDedicatedAddr()
 
-	corsHeaders := handlers.CORS(
-		handlers.AllowedOrigins([]string{"http://localhost:3000"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-	)
-	handler := corsHeaders(router)
+	handler := router
 
 	server := http.Server{
 		Addr:    ":8081",
@@ -162,39 +297,28 @@ func BootOnboard(creds types.BuildCredentials) (*BootContext, error) {
 
 	go func() {
 		<-sigChan
-		log.Print("Received termination signal")
-		Halt(bootCtx, sigChan, cancel)
-		server.Close()
-		httpsServer.Close()
-	}()
-
-	go func() {
-		<-ctx.Done()
-		server.Close()
-		httpsServer.Close()
+		bootLog.Infof("Received termination signal")
+		Halt(bootCtx, sigChan, cancel, []*http.Server{&server, &httpsServer})
 	}()
 
 	path, err = util.GetDistPath()
 	if err != nil {
-		log.Fatalf("Failed to get dist path: %s\n", err)
+		bootLog.Fatalf("Failed to get dist path: %s", err)
 	}
 	ollamaPath := filepath.Join(path, util.OllamaFile)
 	weaviatePath := filepath.Join(path, util.WeaviateFile)
 
-	home, err := os.UserHomeDir()
-	if err != nil {
-		log.Fatalf("unable to get user home directory: %s", err)
-	}
-	weaviatePersistDir := filepath.Join(home, WeaviatePersistDir)
-	ollamaModelsPath := filepath.Join(home, OllamaModelsDir)
-	ollamaRunnersPath := filepath.Join(home, OllamaRunnersDir)
-	ollamaTmpDirPath := filepath.Join(home, OllamaTmpDir)
-
-	commands := []CmdSpec{
-		{
-			ollamaPath,
-			[]string{"serve"},
-			[]string{
+	weaviatePersistDir := filepath.Join(dataDir, WeaviatePersistDir)
+	ollamaModelsPath := filepath.Join(dataDir, OllamaModelsDir)
+	ollamaRunnersPath := filepath.Join(dataDir, OllamaRunnersDir)
+	ollamaTmpDirPath := filepath.Join(dataDir, OllamaTmpDir)
+
+	ollamaSupervisor := supervisor.New(supervisor.Config{
+		Name: ollamaSupervisorName,
+		Cmd: supervisor.CmdSpec{
+			Name: ollamaPath,
+			Args: []string{"serve"},
+			Env: []string{
 				"OLLAMA_HOST=" + OllamaHost,
 				"OLLAMA_KEEP_ALIVE=" + KeepAliveTime,
 				"OLLAMA_MAX_LOADED_MODELS=2",
@@ -204,48 +328,106 @@ func BootOnboard(creds types.BuildCredentials) (*BootContext, error) {
 				"OLLAMA_RUNNERS_DIR=" + ollamaRunnersPath,
 				"OLLAMA_TMPDIR=" + ollamaTmpDirPath,
 			},
+			SysProcAttr: plat.SysProcAttr(),
 		},
-		{
-			weaviatePath,
-			[]string{"--host", "0.0.0.0", "--port", "8088", "--scheme", "http"},
-			[]string{
+		HealthCheck:              checkOllamaHealth,
+		ReadinessTimeout:         60 * time.Second,
+		LivenessInterval:         15 * time.Second,
+		LivenessFailureThreshold: 3,
+		Stdout:                   vlog.NewPrefixWriter(ollamaLog),
+		Stderr:                   vlog.NewPrefixWriter(ollamaLog),
+	})
+
+	weaviateSupervisor := supervisor.New(supervisor.Config{
+		Name: weaviateSupervisorName,
+		Cmd: supervisor.CmdSpec{
+			Name: weaviatePath,
+			Args: []string{"--host", "0.0.0.0", "--port", "8088", "--scheme", "http"},
+			Env: []string{
 				"LIMIT_RESOURCES=true",
 				"PERSISTENCE_DATA_PATH=" + weaviatePersistDir,
 				"AUTHENTICATION_ANONYMOUS_ACCESS_ENABLED=true",
 				"ENABLE_MODULES=backup-filesystem",
 				"BACKUP_FILESYSTEM_PATH=" + weaviatePersistDir + "/backup",
 			},
+			SysProcAttr: plat.SysProcAttr(),
 		},
+		HealthCheck:              checkWeaviateHealth,
+		ReadinessTimeout:         60 * time.Second,
+		LivenessInterval:         15 * time.Second,
+		LivenessFailureThreshold: 3,
+		Stdout:                   vlog.NewPrefixWriter(weaviateLog),
+		Stderr:                   vlog.NewPrefixWriter(weaviateLog),
+	})
+
+	bootCtx.Supervisors = map[string]*supervisor.Supervisor{
+		ollamaSupervisorName:   ollamaSupervisor,
+		weaviateSupervisorName: weaviateSupervisor,
 	}
 
-	startSubprocesses(ctx, commands, logFile, logFile)
+	go ollamaSupervisor.Run(ctx)
+	go weaviateSupervisor.Run(ctx)
+	go pollSupervisorGauges(ctx, bootCtx.Supervisors)
+
+	// If either subprocess crash-loops before ever becoming healthy, treat
+	// it as a fatal boot error rather than restarting forever.
+	go func() {
+		select {
+		case err, ok := <-ollamaSupervisor.Fatal():
+			if ok {
+				bootLog.Fatalf("ollama supervisor failed: %s", err)
+			}
+		case err, ok := <-weaviateSupervisor.Fatal():
+			if ok {
+				bootLog.Fatalf("weaviate supervisor failed: %s", err)
+			}
+		case <-ctx.Done():
+		}
+	}()
 
 	err = waitForWeaviate(ctx)
 	if err != nil {
-		log.Fatalf("Failed to wait for Weaviate: %s\n", err)
+		bootLog.Fatalf("Failed to wait for Weaviate: %s", err)
 	}
 
-	weavClient := store.GetWeaviateClient()
-	store.CreateDocumentClass(ctx, weavClient, clean)
-	store.CreateConnectorStateClass(ctx, weavClient, clean)
-	store.CreateChunkClass(ctx, weavClient, clean)
-	store.CreateConversationClass(ctx, weavClient, clean)
+	// These are a no-op beyond the very first boot: the underlying stores
+	// don't treat "class/table already exists" as success, so on every
+	// later boot this logs and moves on rather than treating re-creation
+	// as fatal.
+	if err := apiStore.CreateDocumentClass(ctx, false); err != nil {
+		bootLog.Warnf("Failed to create document class (may already exist): %s", err)
+	}
+	if err := apiStore.CreateConnectorStateClass(ctx, false); err != nil {
+		bootLog.Warnf("Failed to create connector state class (may already exist): %s", err)
+	}
+	if err := apiStore.CreateChunkClass(ctx, false); err != nil {
+		bootLog.Warnf("Failed to create chunk class (may already exist): %s", err)
+	}
+	if err := apiStore.CreateConversationClass(ctx, false); err != nil {
+		bootLog.Warnf("Failed to create conversation class (may already exist): %s", err)
+	}
 
 	certPath := filepath.Join(path, "certs/localhost.pem")
 	keyPath := filepath.Join(path, "certs/localhost-key.pem")
 
 	go func() {
-		log.Print("Starting HTTP server on port 8081")
-		log.Fatal(server.ListenAndServe())
+		bootLog.Infof("Starting HTTP server on port 8081")
+		if err := server.ListenAndServe(); err != nil {
+			bootLog.Fatalf("%s", err)
+		}
 	}()
 
 	go func() {
-		log.Print("Starting HTTPS server on port 8082")
-		log.Fatal(httpsServer.ListenAndServeTLS(certPath, keyPath))
+		bootLog.Infof("Starting HTTPS server on port 8082")
+		if err := httpsServer.ListenAndServeTLS(certPath, keyPath); err != nil {
+			bootLog.Fatalf("%s", err)
+		}
 	}()
 
 	bootCtx.State = BootStateOnboard
+	bootLog.With(map[string]interface{}{"phase": string(bootCtx.State), "version": version}).Infof("Boot phase transition")
 	bootCtx.OnboardTime = time.Now()
+	metrics.BootPhaseDuration.WithLabelValues(BootStateOnboard).Observe(bootCtx.OnboardTime.Sub(bootCtx.StartTime).Seconds())
 	return bootCtx, nil
 }
 
@@ -256,15 +438,15 @@ func waitForOllama(ctx context.Context) error {
 	// Poll the ollama URL every 5 seconds until the context is cancelled
 	for {
 		resp, err := httpClient.Get(ollama_url)
-		log.Print(resp)
+		ollamaLog.Debugf("probe response: %v", resp)
 		if err == nil {
-			log.Printf("Ollama is up and running")
+			ollamaLog.Infof("Ollama is up and running")
 			resp.Body.Close()
 			return nil
 		}
 		select {
 		case <-time.After(5 * time.Second):
-			log.Printf("Waited 5 sec")
+			ollamaLog.Debugf("Waited 5 sec")
 			continue
 		case <-ctx.Done():
 			return fmt.Errorf("context cancelled during wait: %w", ctx.Err())
@@ -272,86 +454,50 @@ func waitForOllama(ctx context.Context) error {
 	}
 }
 
-type SystemStats struct {
-	Chipset string
-	MacOS   string
-	Memsize string
-}
-
-func getSystemStats() (*SystemStats, error) {
-	chipsetCmd := exec.Command("sysctl", "-n", "machdep.cpu.brand_string")
-	chipsetOut, err := chipsetCmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get chipset info: %v", err)
-	}
-	chipset := strings.TrimSpace(string(chipsetOut))
+// checkOllamaHealth performs a single readiness probe against Ollama, for
+// use as a supervisor.HealthCheck.
+func checkOllamaHealth(ctx context.Context) error {
+	start := time.Now()
+	defer func() {
+		metrics.ProbeLatency.WithLabelValues(ollamaSupervisorName).Observe(time.Since(start).Seconds())
+	}()
 
-	// Retrieve macOS version
-	versionCmd := exec.Command("sw_vers", "-productVersion")
-	versionOut, err := versionCmd.Output()
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("http://%s", OllamaHost), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get macOS version: %v", err)
+		return err
 	}
-	macos := strings.TrimSpace(string(versionOut))
-
-	// Retrieve system memory information
-	memCmd := exec.Command("sysctl", "-n", "hw.memsize")
-	memOut, err := memCmd.Output()
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get memory info: %v", err)
+		return err
 	}
-	memGB := strings.TrimSpace(string(memOut))
-
-	return &SystemStats{
-		Chipset: chipset,
-		MacOS:   macos,
-		Memsize: memGB,
-	}, nil
-}
-
-type CmdSpec struct {
-	Name string
-	Args []string
-	Env  []string
+	defer resp.Body.Close()
+	return nil
 }
 
-func startSubprocesses(ctx context.Context, commands []CmdSpec, stdout *os.File, stderr *os.File) {
-	for _, cmdConfig := range commands {
-		go func(c CmdSpec) {
-			for {
-				cmd := exec.Command(c.Name, c.Args...)
-				cmd.Env = append(os.Environ(), c.Env...)
-				cmd.Stdout = stdout
-				cmd.Stderr = stderr
-
-				if err := cmd.Start(); err != nil {
-					log.Printf("Error starting command %s: %s\n", c.Name, err)
-					return
-				}
+// checkWeaviateHealth performs a single readiness probe against Weaviate's
+// well-known ready endpoint, for use as a supervisor.HealthCheck.
+func checkWeaviateHealth(ctx context.Context) error {
+	start := time.Now()
+	defer func() {
+		metrics.ProbeLatency.WithLabelValues(weaviateSupervisorName).Observe(time.Since(start).Seconds())
+	}()
 
-				done := make(chan error)
-				go func() { done <- cmd.Wait() }()
-
-				select {
-				case <-ctx.Done():
-					if err := cmd.Process.Kill(); err != nil {
-						log.Printf("Failed to kill process %s: %s\n", c.Name, err)
-					}
-					return
-				case err := <-done:
-					if err != nil {
-						log.Printf("Command %s finished with error: %s. Restarting...\n", c.Name, err)
-					} else {
-						log.Printf("Command %s finished successfully. Exiting restart loop.\n", c.Name)
-						return
-					}
-				}
-			}
-		}(cmdConfig)
+	req, err := http.NewRequestWithContext(ctx, "GET", "http://localhost:8088/v1/.well-known/ready", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("weaviate readiness check returned status %d", resp.StatusCode)
 	}
+	return nil
 }
 
-func initModels(models []string) error {
+func initModels(ctx context.Context, models []string) error {
 	for _, modelName := range models {
 		if IsCustomModel(modelName) {
 			err := createModel(modelName)
@@ -359,7 +505,7 @@ func initModels(models []string) error {
 				return fmt.Errorf("failed to create model %s: %v", modelName, err)
 			}
 		} else {
-			err := pullModel(modelName, false)
+			err := pullModelWithRetry(ctx, modelName, false)
 			if err != nil {
 				return fmt.Errorf("failed to pull model %s: %v", modelName, err)
 			}
@@ -371,40 +517,47 @@ func initModels(models []string) error {
 func BootSyncing(ctx *BootContext) error {
 	err := waitForOllama(ctx)
 	if err != nil {
-		log.Fatalf("Failed to wait for ollama: %s\n", err)
+		ctx.SetLastError(err)
+		bootLog.Fatalf("Failed to wait for ollama: %s", err)
 	}
 
-	err = initModels([]string{embeddingsModelName})
+	err = initModels(ctx, []string{embeddingsModelName})
 	if err != nil {
-		log.Fatalf("Failed to initialize models: %s\n", err)
+		ctx.SetLastError(err)
+		bootLog.Fatalf("Failed to initialize models: %s", err)
 	}
 
 	err = ctx.Syncer.Init(ctx)
 	if err != nil {
-		log.Fatalf("Failed to initialize syncer: %s\n", err)
+		ctx.SetLastError(err)
+		bootLog.Fatalf("Failed to initialize syncer: %s", err)
 	}
 	go ctx.Syncer.Run(ctx)
 
 	ctx.State = BootStateSyncing
+	bootLog.With(map[string]interface{}{"phase": string(ctx.State)}).Infof("Boot phase transition")
 	ctx.SyncingTime = time.Now()
+	metrics.BootPhaseDuration.WithLabelValues(BootStateSyncing).Observe(ctx.SyncingTime.Sub(ctx.OnboardTime).Seconds())
 	return nil
 }
 
 func BootGen(ctx *BootContext) error {
-	err := copyRerankerModel()
+	err := copyRerankerModel(ctx.Platform)
 	if err != nil {
-		log.Fatalf("Failed to copy reranker model: %s\n", err)
+		ctx.SetLastError(err)
+		bootLog.Fatalf("Failed to copy reranker model: %s", err)
 	}
 
-	err = initModels([]string{generationModelName})
+	err = initModels(ctx, []string{generationModelName})
 	if err != nil {
-		log.Fatalf("Failed to initialize models: %s\n", err)
+		ctx.SetLastError(err)
+		bootLog.Fatalf("Failed to initialize models: %s", err)
 	}
 
 	retries := 0
 	maxRetries := 5
 	for {
-		resp, err := chatWithModel("What is the capital of France? Respond in one word only", generationModelName, []types.HistoryItem{})
+		resp, err := chatWithModel(ctx, "What is the capital of France? Respond in one word only", generationModelName, []types.HistoryItem{})
 
 		if err != nil {
 			if retries < maxRetries && strings.Contains(err.Error(), "try pulling it first") {
@@ -412,13 +565,13 @@ func BootGen(ctx *BootContext) error {
 				retries += 1
 				continue
 			}
-			log.Fatalf("Failed to generate response: %s\n", err)
+			bootLog.Fatalf("Failed to generate response: %s", err)
 		}
 		if !resp.Done {
-			log.Fatalf("Response not done: %v\n", resp)
+			bootLog.Fatalf("Response not done: %v", resp)
 		}
 		if !strings.Contains(resp.Message.Content, "Paris") {
-			log.Fatalf("Response does not contain Paris: %v\n", resp.Message.Content)
+			bootLog.Fatalf("Response does not contain Paris: %v", resp.Message.Content)
 		}
 		break
 	}
@@ -426,44 +579,41 @@ func BootGen(ctx *BootContext) error {
 	// Perform a test rerank to download the model
 	rerankOutput, err := RunRerankModel(ctx, []byte{})
 	if err != nil {
-		log.Fatalf("Failed to run rerank model: %s\n", err)
+		bootLog.Fatalf("Failed to run rerank model: %s", err)
 	}
-	log.Print(string(rerankOutput))
-	log.Print("Rerank model loaded successfully")
+	bootLog.Infof("%s", string(rerankOutput))
+	bootLog.Infof("Rerank model loaded successfully")
 
-	// Identify user to posthog
-	systemStats, err := getSystemStats()
+	// Identify user to the active analytics sink
+	systemStats, err := ctx.Platform.SystemStats(ctx)
 	if err != nil {
-		log.Fatalf("Failed to get system stats: %s\n", err)
-	}
-	err = ctx.PosthogClient.Enqueue(posthog.Identify{
-		DistinctId: ctx.PosthogDistinctID,
-		Properties: posthog.NewProperties().
-			Set("chipset", systemStats.Chipset).
-			Set("macos", systemStats.MacOS).
-			Set("memsize", systemStats.Memsize),
+		bootLog.Fatalf("Failed to get system stats: %s", err)
+	}
+	err = ctx.Analytics.Identify(ctx.PosthogDistinctID, map[string]interface{}{
+		"chipset": systemStats.Chipset,
+		"os":      systemStats.OS,
+		"memsize": systemStats.Memsize,
 		// TODO: version
 	})
 	if err != nil {
-		log.Fatalf("Failed to enqueue identify event: %s\n", err)
+		bootLog.Fatalf("Failed to enqueue identify event: %s", err)
 	}
 
 	ctx.GenTime = time.Now()
-	err = ctx.PosthogClient.Enqueue(posthog.Capture{
-		DistinctId: ctx.PosthogDistinctID,
-		Event:      "Started",
-		Properties: posthog.NewProperties().
-			// TODO: connector states
-			Set("boot_total_duration", ctx.GenTime.Sub(ctx.StartTime).String()).
-			Set("boot_onboard_duration", ctx.OnboardTime.Sub(ctx.StartTime).String()).
-			Set("boot_syncing_duration", ctx.SyncingTime.Sub(ctx.OnboardTime).String()).
-			Set("boot_gen_duration", ctx.GenTime.Sub(ctx.SyncingTime).String()),
+	metrics.BootPhaseDuration.WithLabelValues(BootStateGen).Observe(ctx.GenTime.Sub(ctx.SyncingTime).Seconds())
+	err = ctx.Analytics.Capture(ctx.PosthogDistinctID, "Started", map[string]interface{}{
+		// TODO: connector states
+		"boot_total_duration":   ctx.GenTime.Sub(ctx.StartTime).String(),
+		"boot_onboard_duration": ctx.OnboardTime.Sub(ctx.StartTime).String(),
+		"boot_syncing_duration": ctx.SyncingTime.Sub(ctx.OnboardTime).String(),
+		"boot_gen_duration":     ctx.GenTime.Sub(ctx.SyncingTime).String(),
 	})
 	if err != nil {
-		log.Fatalf("Failed to enqueue event: %s\n", err)
+		bootLog.Fatalf("Failed to enqueue event: %s", err)
 	}
 
 	ctx.State = BootStateGen
+	bootLog.With(map[string]interface{}{"phase": string(ctx.State)}).Infof("Boot phase transition")
 	return nil
 }
 
@@ -473,15 +623,15 @@ func waitForWeaviate(ctx context.Context) error {
 
 	for {
 		resp, err := httpClient.Get(weaviate_url)
-		log.Print(resp)
+		weaviateLog.Debugf("probe response: %v", resp)
 		if err == nil {
-			log.Printf("Weaviate is up and running")
+			weaviateLog.Infof("Weaviate is up and running")
 			resp.Body.Close()
 			return nil
 		}
 		select {
 		case <-time.After(5 * time.Second):
-			log.Printf("Waited 5 sec")
+			weaviateLog.Debugf("Waited 5 sec")
 			continue
 		case <-ctx.Done():
 			return fmt.Errorf("context cancelled during wait: %w", ctx.Err())
@@ -489,49 +639,106 @@ func waitForWeaviate(ctx context.Context) error {
 	}
 }
 
-func Halt(bootCtx *BootContext, sigChan chan os.Signal, cancel context.CancelFunc) {
+// newGracefulContext returns a context cancelled by cancel, along with the
+// signal channel that SIGINT, SIGTERM, or SIGHUP delivers to, so a caller
+// can select on bootCtx.Done() to know when a termination signal has
+// arrived without owning the plumbing itself. The channel is buffered to 1
+// per signal.Notify's requirement that the caller never block a delivery.
+func newGracefulContext() (context.Context, context.CancelFunc, chan os.Signal) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	return ctx, cancel, sigChan
+}
+
+// Halt performs a phase-aware graceful shutdown: it quiesces whatever the
+// boot phase was doing, gives the HTTP servers a grace period to drain
+// in-flight requests via Shutdown, and only then tears down the boot
+// context and its resources. Subprocess teardown (supervisor.Supervisor)
+// reacts to the boot context cancellation with its own SIGTERM-then-SIGKILL
+// escalation.
+func Halt(bootCtx *BootContext, sigChan chan os.Signal, cancel context.CancelFunc, servers []*http.Server) {
 	signal.Stop(sigChan)
-	cancel()
 	close(sigChan)
-	defer bootCtx.PosthogClient.Close()
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownGracePeriod)
+	defer shutdownCancel()
+
+	switch bootCtx.State {
+	case BootStateSyncing:
+		if bootCtx.Syncer != nil {
+			if err := bootCtx.Syncer.Drain(shutdownCtx); err != nil {
+				bootLog.Warnf("Syncer did not drain cleanly, proceeding with shutdown: %s", err)
+			}
+		}
+	case BootStateGen:
+		CancelGenRequests()
+	}
+
+	for _, server := range servers {
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			bootLog.Errorf("Failed to gracefully shut down server %s: %s", server.Addr, err)
+		}
+	}
+
+	// Now that in-flight work has either drained or been given its grace
+	// period, cancel the boot context so the supervisors and syncer loop
+	// tear down.
+	cancel()
+
+	defer bootCtx.Analytics.Close()
 	if err := bootCtx.Logfile.Close(); err != nil {
-		log.Printf("Failed to close log file: %s\n", err)
+		bootLog.Errorf("Failed to close log file: %s", err)
 	}
 }
 
-func killProcessByName(name string) error {
-	cmd := exec.Command("pkill", "-f", name)
-	err := cmd.Run()
+func GetMasterLogDir(plat platform.Provider) (string, error) {
+	dataDir, err := plat.UserDataDir(appDataDirName)
 	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok && exitError.ExitCode() == 1 {
-			// pkill returns 1 if no processes were matched, which is not an error in this context
-			return nil
-		}
-		return fmt.Errorf("failed to kill process %s: %v", name, err)
+		return "", fmt.Errorf("unable to get user data directory: %w", err)
 	}
-	return nil
+	return filepath.Join(dataDir, masterLogPath), nil
 }
 
-func GetMasterLogDir() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("unable to get user home directory: %w", err)
+// loadOrCreateAuthToken returns the per-install bearer token stored under
+// dataDir, generating and persisting a new random one on first boot. The
+// desktop app reads the same file to learn the token it must send back as
+// "Authorization: Bearer <token>" once VERBIS_REQUIRE_AUTH is set.
+func loadOrCreateAuthToken(dataDir string) (string, error) {
+	path := filepath.Join(dataDir, authTokenPath)
+
+	existing, err := os.ReadFile(path)
+	if err == nil {
+		return strings.TrimSpace(string(existing)), nil
+	}
+	if !os.IsNotExist(err) {
+		return "", fmt.Errorf("unable to read auth token file: %w", err)
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("unable to generate auth token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	if err := os.WriteFile(path, []byte(token), 0600); err != nil {
+		return "", fmt.Errorf("unable to persist auth token: %w", err)
 	}
-	return filepath.Join(home, masterLogPath), nil
+	return token, nil
 }
 
-func copyRerankerModel() error {
+func copyRerankerModel(plat platform.Provider) error {
 	distPath, err := util.GetDistPath()
 	if err != nil {
 		return fmt.Errorf("failed to get dist path: %w", err)
 	}
 	rerankerDirPath := filepath.Join(distPath, rerankerModelName)
 
-	home, err := os.UserHomeDir()
+	dataDir, err := plat.UserDataDir(appDataDirName)
 	if err != nil {
-		return fmt.Errorf("unable to get user home directory: %w", err)
+		return fmt.Errorf("unable to get user data directory: %w", err)
 	}
-	targetModelDir := filepath.Join(home, miscModelsPath, rerankerModelName)
+	targetModelDir := filepath.Join(dataDir, miscModelsPath, rerankerModelName)
 
 	err = os.MkdirAll(targetModelDir, 0755)
 	if err != nil && !os.IsExist(err) {
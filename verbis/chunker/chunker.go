@@ -0,0 +1,214 @@
+// Package chunker splits document text into overlapping chunks along
+// semantic boundaries (paragraphs, then lines, then sentences, then
+// words) instead of slicing fixed-size character windows, which tends to
+// cut mid-sentence and hurts both embedding quality and rerank passage
+// scoring.
+package chunker
+
+import (
+	"strings"
+)
+
+// TokenCounter estimates how many tokens s will consume once tokenized
+// by the target embedding model. The zero value of Options falls back to
+// a whitespace-word approximation; callers that need chunks to fit a
+// model's context window exactly should pass a counter backed by that
+// model's actual tokenizer (e.g. a BPE vocabulary wrapper).
+type TokenCounter func(s string) int
+
+// Options configures a Chunker. TargetTokens and OverlapTokens are
+// measured using TokenCounter, which defaults to a whitespace-word
+// approximation - good enough for sizing chunks relative to an embedding
+// model's context window, but callers with a real tokenizer available
+// can plug it in for an exact fit.
+type Options struct {
+	TargetTokens  int
+	OverlapTokens int
+	TokenCounter  TokenCounter
+}
+
+// Chunk is a single piece of split text, in document order.
+type Chunk struct {
+	Text string
+}
+
+// Chunker splits text into overlapping Chunks per Options.
+type Chunker interface {
+	Chunk(text string, opts Options) []Chunk
+}
+
+const (
+	defaultTargetTokens  = 200
+	defaultOverlapTokens = 40
+)
+
+// RecursiveChunker splits text by trying progressively finer-grained
+// separators (paragraph breaks, then line breaks, then sentence
+// boundaries, then words), only falling back to a hard character cut when
+// a single atom (e.g. one very long word) still exceeds TargetTokens.
+type RecursiveChunker struct{}
+
+func NewRecursiveChunker() *RecursiveChunker {
+	return &RecursiveChunker{}
+}
+
+func (c *RecursiveChunker) Chunk(text string, opts Options) []Chunk {
+	target := opts.TargetTokens
+	if target <= 0 {
+		target = defaultTargetTokens
+	}
+	overlap := opts.OverlapTokens
+	if overlap < 0 || overlap >= target {
+		overlap = defaultOverlapTokens
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+
+	counter := opts.TokenCounter
+	if counter == nil {
+		counter = countTokens
+	}
+
+	atoms := splitRecursive(text, target, counter)
+	return packAtoms(atoms, target, overlap, counter)
+}
+
+// countTokens approximates token count by whitespace-delimited words. It
+// is the default TokenCounter; callers that need chunks to fit a
+// specific embedding model's context window exactly can supply their own
+// via Options.TokenCounter instead.
+func countTokens(s string) int {
+	return len(strings.Fields(s))
+}
+
+// splitRecursive breaks text into atoms no larger than target tokens
+// apiece (except for a single oversized atom with no further separator to
+// split on), trying "\n\n", then "\n", then sentences, then words, and
+// finally a hard character cut.
+func splitRecursive(text string, target int, counter TokenCounter) []string {
+	if counter(text) <= target {
+		return []string{text}
+	}
+
+	for _, sep := range []string{"\n\n", "\n"} {
+		if strings.Contains(text, sep) {
+			parts := strings.Split(text, sep)
+			if len(parts) > 1 {
+				return splitParts(parts, target, counter)
+			}
+		}
+	}
+
+	if sentences := splitSentences(text); len(sentences) > 1 {
+		return splitParts(sentences, target, counter)
+	}
+
+	if words := strings.Fields(text); len(words) > 1 {
+		return splitParts(words, target, counter)
+	}
+
+	return hardCut(text, target)
+}
+
+// splitParts recursively splits any part that's still too big, and drops
+// empty parts left over from the separator split.
+func splitParts(parts []string, target int, counter TokenCounter) []string {
+	var atoms []string
+	for _, p := range parts {
+		if strings.TrimSpace(p) == "" {
+			continue
+		}
+		if counter(p) > target {
+			atoms = append(atoms, splitRecursive(p, target, counter)...)
+		} else {
+			atoms = append(atoms, p)
+		}
+	}
+	return atoms
+}
+
+// hardCut splits a single oversized atom (e.g. one absurdly long word) on
+// a fixed character window, as a last resort. It always uses a
+// characters-per-token estimate rather than the configured TokenCounter,
+// since there's no separator left to probe for sizing. It cuts on rune
+// boundaries, not bytes, so a run of multi-byte characters (CJK text, for
+// instance, has no whitespace for splitRecursive's word split to find)
+// doesn't get split in the middle of a character and come out as invalid
+// UTF-8.
+func hardCut(text string, target int) []string {
+	// Rough chars-per-token estimate, since we have no separators left to
+	// split on and can't count "words" in something with no whitespace.
+	const charsPerToken = 6
+	runes := []rune(text)
+	maxChars := target * charsPerToken
+	if maxChars <= 0 {
+		maxChars = len(runes)
+	}
+
+	var atoms []string
+	for i := 0; i < len(runes); i += maxChars {
+		end := i + maxChars
+		if end > len(runes) {
+			end = len(runes)
+		}
+		atoms = append(atoms, string(runes[i:end]))
+	}
+	return atoms
+}
+
+// packAtoms greedily packs atoms into chunks of at most target tokens,
+// carrying the trailing overlap tokens of one chunk into the start of the
+// next so context bleeds across the boundary.
+func packAtoms(atoms []string, target, overlap int, counter TokenCounter) []Chunk {
+	var chunks []Chunk
+	var cur []string
+	curTokens := 0
+
+	flush := func() {
+		if len(cur) == 0 {
+			return
+		}
+		chunks = append(chunks, Chunk{Text: strings.Join(cur, "\n")})
+	}
+
+	for _, atom := range atoms {
+		atomTokens := counter(atom)
+		if curTokens > 0 && curTokens+atomTokens > target {
+			flush()
+			cur = overlapTail(cur, overlap, counter)
+			curTokens = 0
+			for _, a := range cur {
+				curTokens += counter(a)
+			}
+		}
+		cur = append(cur, atom)
+		curTokens += atomTokens
+	}
+	flush()
+
+	return chunks
+}
+
+// overlapTail returns the trailing atoms of a flushed chunk whose
+// combined token count is closest to (without exceeding) overlap tokens,
+// to seed the next chunk.
+func overlapTail(atoms []string, overlap int, counter TokenCounter) []string {
+	if overlap <= 0 {
+		return nil
+	}
+
+	tokens := 0
+	start := len(atoms)
+	for start > 0 {
+		next := counter(atoms[start-1])
+		if tokens+next > overlap {
+			break
+		}
+		tokens += next
+		start--
+	}
+	return append([]string{}, atoms[start:]...)
+}
@@ -0,0 +1,117 @@
+package chunker
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func assertValidChunks(t *testing.T, text string, chunks []Chunk) {
+	t.Helper()
+	if len(chunks) == 0 {
+		t.Fatalf("got 0 chunks for non-empty input %q", text)
+	}
+	for i, c := range chunks {
+		if c.Text == "" {
+			t.Fatalf("chunk %d is empty", i)
+		}
+		if !utf8.ValidString(c.Text) {
+			t.Fatalf("chunk %d is not valid UTF-8: %q", i, c.Text)
+		}
+	}
+}
+
+// TestRecursiveChunker_CJK exercises text with no ASCII whitespace for
+// splitRecursive's word split to find, so it falls all the way through to
+// hardCut. hardCut cuts on a fixed character window with no regard for
+// word boundaries in CJK, so the only thing worth asserting is that it
+// never cuts a multi-byte rune in half.
+func TestRecursiveChunker_CJK(t *testing.T) {
+	c := NewRecursiveChunker()
+	// A run of Chinese characters with no whitespace at all, long enough
+	// to force multiple hardCut windows at a small TargetTokens.
+	text := strings.Repeat("测试分块器对中日韩文本的处理方式是否正确无误", 20)
+
+	chunks := c.Chunk(text, Options{TargetTokens: 10, OverlapTokens: 0})
+	assertValidChunks(t, text, chunks)
+
+	var rebuilt strings.Builder
+	for _, c := range chunks {
+		rebuilt.WriteString(c.Text)
+	}
+	if got := rebuilt.String(); got != text {
+		t.Fatalf("chunks with no overlap don't reassemble to the original text: got %d runes, want %d", utf8.RuneCountInString(got), utf8.RuneCountInString(text))
+	}
+}
+
+// TestRecursiveChunker_CodeBlock exercises text that's mostly line-oriented
+// (splittable on "\n") but contains a couple of very long, unbroken lines
+// (e.g. a minified/long literal), mixing the "\n" split path with a nested
+// word or hard-cut fallback for the oversized lines.
+func TestRecursiveChunker_CodeBlock(t *testing.T) {
+	c := NewRecursiveChunker()
+	longLine := "x := \"" + strings.Repeat("a", 1500) + "\""
+	text := strings.Join([]string{
+		"func process(items []Item) error {",
+		"\tfor _, item := range items {",
+		longLine,
+		"\t\tif err := handle(item); err != nil {",
+		"\t\t\treturn fmt.Errorf(\"failed to handle item: %w\", err)",
+		"\t\t}",
+		"\t}",
+		"\treturn nil",
+		"}",
+	}, "\n")
+
+	chunks := c.Chunk(text, Options{TargetTokens: 20, OverlapTokens: 5})
+	assertValidChunks(t, text, chunks)
+
+	var sawHandle, sawLongLine bool
+	for _, c := range chunks {
+		if strings.Contains(c.Text, "handle(item)") {
+			sawHandle = true
+		}
+		if strings.Contains(c.Text, strings.Repeat("a", 1500)) {
+			sawLongLine = true
+		}
+	}
+	if !sawHandle {
+		t.Fatalf("no chunk contains the handle(item) line: %v", chunks)
+	}
+	if !sawLongLine {
+		t.Fatalf("the long literal line wasn't preserved whole in any chunk: %v", chunks)
+	}
+}
+
+// TestRecursiveChunker_LongUnbrokenString exercises a single "word" (no
+// whitespace, no line breaks, no sentence punctuation) far larger than
+// TargetTokens, the case splitRecursive has no separator left for and must
+// hand off to hardCut.
+func TestRecursiveChunker_LongUnbrokenString(t *testing.T) {
+	c := NewRecursiveChunker()
+	text := strings.Repeat("a", 10000)
+
+	chunks := c.Chunk(text, Options{TargetTokens: 50, OverlapTokens: 0})
+	assertValidChunks(t, text, chunks)
+
+	if len(chunks) < 2 {
+		t.Fatalf("expected a 10000-char unbroken string at TargetTokens=50 to split into multiple chunks, got %d", len(chunks))
+	}
+
+	var rebuilt strings.Builder
+	for _, c := range chunks {
+		rebuilt.WriteString(c.Text)
+	}
+	if got := rebuilt.String(); got != text {
+		t.Fatalf("chunks with no overlap don't reassemble to the original text: got %d chars, want %d", len(got), len(text))
+	}
+}
+
+// TestRecursiveChunker_Empty confirms the empty/whitespace-only input
+// short-circuit documented on Chunk doesn't regress.
+func TestRecursiveChunker_Empty(t *testing.T) {
+	c := NewRecursiveChunker()
+	if chunks := c.Chunk("   \n\t  ", Options{}); chunks != nil {
+		t.Fatalf("expected nil chunks for whitespace-only input, got %v", chunks)
+	}
+}
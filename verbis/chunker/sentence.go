@@ -0,0 +1,66 @@
+package chunker
+
+import (
+	"strings"
+	"unicode"
+)
+
+// commonAbbreviations are words that precede a period without ending a
+// sentence. Go's regexp has no lookaround, so sentence splitting is done
+// with a small scanner instead of a single pattern.
+var commonAbbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true,
+	"sr": true, "jr": true, "st": true, "vs": true, "etc": true,
+	"e.g": true, "i.e": true, "inc": true, "ltd": true, "co": true,
+}
+
+// splitSentences splits text on ".", "?" and "!" that are followed by
+// whitespace (or end-of-text), skipping boundaries that are actually a
+// decimal number (e.g. "3.14") or a common abbreviation (e.g. "Dr.").
+func splitSentences(text string) []string {
+	runes := []rune(text)
+	var sentences []string
+	var cur strings.Builder
+
+	for i, r := range runes {
+		cur.WriteRune(r)
+
+		if r != '.' && r != '?' && r != '!' {
+			continue
+		}
+
+		nextIsBoundary := i+1 >= len(runes) || unicode.IsSpace(runes[i+1])
+		if !nextIsBoundary {
+			continue
+		}
+
+		if r == '.' && isDecimalPoint(runes, i) {
+			continue
+		}
+		if r == '.' && isAbbreviation(cur.String()) {
+			continue
+		}
+
+		sentences = append(sentences, strings.TrimSpace(cur.String()))
+		cur.Reset()
+	}
+
+	if strings.TrimSpace(cur.String()) != "" {
+		sentences = append(sentences, strings.TrimSpace(cur.String()))
+	}
+
+	return sentences
+}
+
+func isDecimalPoint(runes []rune, i int) bool {
+	return i > 0 && i+1 < len(runes) && unicode.IsDigit(runes[i-1]) && unicode.IsDigit(runes[i+1])
+}
+
+func isAbbreviation(soFar string) bool {
+	words := strings.Fields(soFar)
+	if len(words) == 0 {
+		return false
+	}
+	last := strings.ToLower(strings.TrimSuffix(words[len(words)-1], "."))
+	return commonAbbreviations[last]
+}
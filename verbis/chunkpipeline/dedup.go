@@ -0,0 +1,50 @@
+package chunkpipeline
+
+import (
+	"context"
+	"sync"
+
+	"github.com/verbis-ai/verbis/verbis/synclog"
+	"github.com/verbis-ai/verbis/verbis/types"
+	"github.com/verbis-ai/verbis/verbis/util"
+)
+
+// seenHashes tracks content hashes across every connector's pipeline run
+// in this process's lifetime, so Deduplicate catches a duplicate the
+// moment two connectors both emit it, not just the second time the same
+// connector resyncs it. It's process-wide rather than per-run because a
+// fresh Stats (and thus a fresh set) per sync would only catch duplicates
+// within a single connector's own sync.
+var (
+	seenMu     sync.Mutex
+	seenHashes = map[string]struct{}{}
+)
+
+// Deduplicate returns a middleware that drops any chunk whose content
+// hash has already been seen, from this or any other connector, since the
+// process started. It complements rather than replaces chunkAdder's own
+// st.ChunkHashExists check: this short-circuits before the embedding call
+// is even made, while the store check also catches duplicates against
+// chunks persisted in a previous process lifetime.
+func Deduplicate() Factory {
+	return func(stats *Stats, recLog *synclog.Sink, connectorID, connectorType string) ChunkMiddleware {
+		return func(next ChunkHandler) ChunkHandler {
+			return func(ctx context.Context, res types.ChunkSyncResult) error {
+				h := util.HashText(res.Chunk.Text)
+
+				seenMu.Lock()
+				_, dup := seenHashes[h]
+				if !dup {
+					seenHashes[h] = struct{}{}
+				}
+				seenMu.Unlock()
+
+				if dup {
+					stats.AddDeduped()
+					return nil
+				}
+				return next(ctx, res)
+			}
+		}
+	}
+}
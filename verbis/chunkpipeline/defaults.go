@@ -0,0 +1,29 @@
+package chunkpipeline
+
+import "github.com/verbis-ai/verbis/verbis/types"
+
+// RegisterDefaults installs the built-in middleware chain for every known
+// connector type. Called once from boot, before the syncer starts
+// processing any connector. A deployment that wants a different stack for
+// a given type (e.g. enabling DetectLanguage's skip list, or adding
+// PolicyVerifier rules) calls Register again afterward to override it;
+// the defaults here only cover what's safe to apply universally.
+func RegisterDefaults() {
+	defaultChain := []Factory{
+		Deduplicate(),
+		RedactPII(false),
+		RespectCodeFences(),
+	}
+
+	for _, t := range []types.ConnectorType{
+		types.ConnectorTypeGoogleDrive,
+		types.ConnectorTypeGmail,
+		types.ConnectorTypeOutlook,
+		types.ConnectorTypeDropbox,
+		types.ConnectorTypeOneDrive,
+		types.ConnectorTypeIMAP,
+		types.ConnectorTypeSlack,
+	} {
+		Register(t, defaultChain...)
+	}
+}
@@ -0,0 +1,42 @@
+package chunkpipeline
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/verbis-ai/verbis/verbis/synclog"
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+// fenceMarker is a Markdown code fence delimiter. A chunk containing an
+// odd number of them has had a code block split across a chunk boundary,
+// the main failure mode of EmitChunks' word-count split on Markdown/code
+// content.
+const fenceMarker = "```"
+
+// RespectCodeFences returns a middleware that flags chunks whose text
+// contains an unterminated code fence, so the split is visible in the
+// sync log instead of silently embedding half a code block. It doesn't
+// attempt to merge the two halves back together: that would need
+// EmitChunks itself to split on fence boundaries rather than word count,
+// which is a connector-side change out of scope for a pipeline stage that
+// only sees chunks after they've already been split.
+func RespectCodeFences() Factory {
+	return func(stats *Stats, recLog *synclog.Sink, connectorID, connectorType string) ChunkMiddleware {
+		return func(next ChunkHandler) ChunkHandler {
+			return func(ctx context.Context, res types.ChunkSyncResult) error {
+				if strings.Count(res.Chunk.Text, fenceMarker)%2 != 0 {
+					recLog.Write(synclog.Record{
+						"Event":          "chunk-split-fence",
+						"Connector-ID":   connectorID,
+						"Connector-Type": connectorType,
+						"Parent-Hash":    res.Chunk.ParentHash,
+						"Chunk-Index":    strconv.Itoa(res.Chunk.ChunkIndex),
+					})
+				}
+				return next(ctx, res)
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package chunkpipeline
+
+import (
+	"context"
+	"strings"
+
+	"github.com/verbis-ai/verbis/verbis/synclog"
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+// languageStopwords is a tiny per-language stopword set used to guess a
+// chunk's dominant language without vendoring a full language-detection
+// library. It's accurate enough to separate a handful of configured
+// languages, not a general-purpose detector.
+var languageStopwords = map[string][]string{
+	"en": {"the", "and", "is", "of", "to", "in", "that", "it", "for", "was"},
+	"es": {"el", "la", "de", "que", "y", "en", "los", "se", "por", "un"},
+	"fr": {"le", "la", "de", "et", "les", "des", "est", "que", "pour", "un"},
+	"de": {"der", "die", "und", "das", "ist", "zu", "den", "mit", "nicht", "ein"},
+}
+
+// minStopwordHits is how many stopword matches a chunk needs before its
+// guessed language is trusted. Below this, a short or jargon-heavy chunk
+// is left unclassified rather than risk misclassifying it off one match.
+const minStopwordHits = 3
+
+// DetectLanguage returns a middleware that guesses each chunk's language
+// by stopword frequency and drops (counted as rejected) any chunk whose
+// guessed language is in skip. A chunk whose language can't be confidently
+// guessed is always kept, since a false-positive skip silently loses
+// content with no way to recover it downstream.
+func DetectLanguage(skip map[string]bool) Factory {
+	return func(stats *Stats, recLog *synclog.Sink, connectorID, connectorType string) ChunkMiddleware {
+		return func(next ChunkHandler) ChunkHandler {
+			return func(ctx context.Context, res types.ChunkSyncResult) error {
+				if lang, ok := detectLanguage(res.Chunk.Text); ok && skip[lang] {
+					stats.AddRejected()
+					return nil
+				}
+				return next(ctx, res)
+			}
+		}
+	}
+}
+
+func detectLanguage(text string) (string, bool) {
+	words := strings.Fields(strings.ToLower(text))
+	if len(words) == 0 {
+		return "", false
+	}
+
+	counts := make(map[string]int, len(languageStopwords))
+	for _, w := range words {
+		for lang, stopwords := range languageStopwords {
+			for _, sw := range stopwords {
+				if w == sw {
+					counts[lang]++
+					break
+				}
+			}
+		}
+	}
+
+	best, bestCount := "", 0
+	for lang, c := range counts {
+		if c > bestCount {
+			best, bestCount = lang, c
+		}
+	}
+	if bestCount < minStopwordHits {
+		return "", false
+	}
+	return best, true
+}
@@ -0,0 +1,81 @@
+// Package chunkpipeline implements a composable middleware chain that sits
+// between a connector's chunk producer and the embedder, so cross-cutting
+// concerns like PII redaction or dedup don't have to be reimplemented in
+// every connector. It mirrors the shape of an HTTP middleware stack:
+// Middlewares wrap a ChunkHandler and decide whether (and in what form) a
+// chunk continues toward the next one in the chain.
+package chunkpipeline
+
+import (
+	"context"
+
+	"github.com/verbis-ai/verbis/verbis/synclog"
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+// ChunkHandler processes a single chunk result and decides whether it
+// continues toward the embedder. A handler that wants to drop a chunk
+// (a policy rejection, a duplicate) simply returns nil without calling
+// the next handler in the chain; a returned error is reserved for actual
+// pipeline faults, not ordinary reject decisions.
+type ChunkHandler func(ctx context.Context, res types.ChunkSyncResult) error
+
+// ChunkMiddleware wraps a ChunkHandler with additional behavior before
+// calling (or not calling) next.
+type ChunkMiddleware func(next ChunkHandler) ChunkHandler
+
+// Chain composes mws around final, in the order given, so mws[0] sees a
+// chunk first and decides last whether it was actually forwarded.
+func Chain(final ChunkHandler, mws ...ChunkMiddleware) ChunkHandler {
+	h := final
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// Factory builds a ChunkMiddleware bound to the run it's part of: stats
+// so counts accumulate into that run rather than some other connector's,
+// and recLog/connectorID/connectorType so a middleware can audit what it
+// did without needing its own plumbing to get there. Registered per
+// connector type via Register.
+type Factory func(stats *Stats, recLog *synclog.Sink, connectorID, connectorType string) ChunkMiddleware
+
+// Run reads chunk results from in, passes every well-formed chunk add
+// through the middleware chain built from factories, and forwards
+// whatever the chain still wants embedded onto out. Errors and
+// delete/live-edit signals bypass the chain entirely, since those aren't
+// content a middleware should redact, dedup, or reject. Run closes out
+// once in is exhausted, so chunkAdder (which ranges over out) terminates
+// normally.
+func Run(ctx context.Context, in <-chan types.ChunkSyncResult, out chan<- types.ChunkSyncResult, factories []Factory, stats *Stats, connectorID, connectorType string, recLog *synclog.Sink) {
+	defer close(out)
+
+	mws := make([]ChunkMiddleware, len(factories))
+	for i, f := range factories {
+		mws[i] = f(stats, recLog, connectorID, connectorType)
+	}
+
+	terminal := func(ctx context.Context, res types.ChunkSyncResult) error {
+		out <- res
+		return nil
+	}
+	handler := Chain(terminal, mws...)
+
+	for res := range in {
+		if res.Err != nil || res.DeleteHash != "" {
+			out <- res
+			continue
+		}
+
+		if err := handler(ctx, res); err != nil {
+			recLog.Write(synclog.Record{
+				"Event":          "chunk-pipeline-error",
+				"Connector-ID":   connectorID,
+				"Connector-Type": connectorType,
+				"Error":          err.Error(),
+			})
+			out <- types.ChunkSyncResult{Err: err}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package chunkpipeline
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/verbis-ai/verbis/verbis/synclog"
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+// PolicyAction is what a matching PolicyRule does to a chunk.
+type PolicyAction int
+
+const (
+	// PolicyReject drops the chunk entirely.
+	PolicyReject PolicyAction = iota
+	// PolicyTag lets the chunk through but records that it matched, so an
+	// operator can audit which rules are firing before switching one to
+	// PolicyReject.
+	PolicyTag
+)
+
+// PolicyRule matches chunk text against Pattern and either rejects the
+// chunk or tags it, depending on Action. Rules are user-supplied, e.g.
+// "drop anything matching our internal project codename regex."
+type PolicyRule struct {
+	Pattern *regexp.Regexp
+	Action  PolicyAction
+	// Tag identifies the rule in the sync log for a PolicyTag match; unused
+	// for PolicyReject.
+	Tag string
+}
+
+// PolicyVerifier returns a middleware that evaluates rules against every
+// chunk in order and stops at the first match: PolicyReject drops the
+// chunk (counted as rejected), PolicyTag logs the match and continues
+// evaluating the remaining rules.
+func PolicyVerifier(rules []PolicyRule) Factory {
+	return func(stats *Stats, recLog *synclog.Sink, connectorID, connectorType string) ChunkMiddleware {
+		return func(next ChunkHandler) ChunkHandler {
+			return func(ctx context.Context, res types.ChunkSyncResult) error {
+				for _, rule := range rules {
+					if !rule.Pattern.MatchString(res.Chunk.Text) {
+						continue
+					}
+
+					switch rule.Action {
+					case PolicyReject:
+						stats.AddRejected()
+						recLog.Write(synclog.Record{
+							"Event":          "chunk-policy-reject",
+							"Connector-ID":   connectorID,
+							"Connector-Type": connectorType,
+							"Parent-Hash":    res.Chunk.ParentHash,
+						})
+						return nil
+					case PolicyTag:
+						recLog.Write(synclog.Record{
+							"Event":          "chunk-policy-tag",
+							"Connector-ID":   connectorID,
+							"Connector-Type": connectorType,
+							"Parent-Hash":    res.Chunk.ParentHash,
+							"Tag":            rule.Tag,
+						})
+					}
+				}
+				return next(ctx, res)
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package chunkpipeline
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/verbis-ai/verbis/verbis/synclog"
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+var (
+	emailPattern      = regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`)
+	phonePattern      = regexp.MustCompile(`\b(?:\+?\d{1,2}[\s.\-]?)?\(?\d{3}\)?[\s.\-]?\d{3}[\s.\-]?\d{4}\b`)
+	ssnPattern        = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)
+	creditCardPattern = regexp.MustCompile(`\b(?:\d[ \-]?){13,16}\b`)
+	personNamePattern = regexp.MustCompile(`\b[A-Z][a-z]+ [A-Z][a-z]+\b`)
+
+	piiPatterns = []*regexp.Regexp{emailPattern, phonePattern, ssnPattern, creditCardPattern}
+)
+
+// RedactPII returns a middleware that masks emails, phone numbers, SSNs,
+// and credit-card-like digit runs in a chunk's text before it reaches the
+// embedder, so raw PII never gets embedded or stored. withNames
+// additionally masks two-word Title Case sequences as a cheap stand-in
+// for a real NER model, which this repo doesn't vendor; it catches plain
+// English names at the cost of occasional false positives (e.g. "New
+// York"), so it's opt-in rather than part of the default chain.
+func RedactPII(withNames bool) Factory {
+	return func(stats *Stats, recLog *synclog.Sink, connectorID, connectorType string) ChunkMiddleware {
+		return func(next ChunkHandler) ChunkHandler {
+			return func(ctx context.Context, res types.ChunkSyncResult) error {
+				text := res.Chunk.Text
+				redacted := false
+
+				for _, p := range piiPatterns {
+					if p.MatchString(text) {
+						text = p.ReplaceAllString(text, "[redacted]")
+						redacted = true
+					}
+				}
+				if withNames && personNamePattern.MatchString(text) {
+					text = personNamePattern.ReplaceAllString(text, "[redacted]")
+					redacted = true
+				}
+
+				if redacted {
+					stats.AddRedacted()
+					res.Chunk.Text = text
+				}
+				return next(ctx, res)
+			}
+		}
+	}
+}
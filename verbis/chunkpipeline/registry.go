@@ -0,0 +1,33 @@
+package chunkpipeline
+
+import (
+	"sync"
+
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+// registry is the process-wide per-connector-type middleware registry,
+// mirroring types.RegisterConnector's connectorFactories map: each
+// connector type gets its own chain, set once at startup (see
+// RegisterDefaults) rather than threaded through every call site that
+// builds a pipeline.
+var (
+	registryMu sync.Mutex
+	registry   = map[types.ConnectorType][]Factory{}
+)
+
+// Register installs factories as the middleware chain for connector type
+// t, replacing any chain previously registered for it.
+func Register(t types.ConnectorType, factories ...Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[t] = factories
+}
+
+// For returns the middleware chain registered for t, or nil if none was
+// registered.
+func For(t types.ConnectorType) []Factory {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return registry[t]
+}
@@ -0,0 +1,41 @@
+package chunkpipeline
+
+import "sync"
+
+// Stats accumulates the aggregate outcomes of one connectorSync's pass
+// through the middleware chain, so the Syncer can fold them into
+// ConnectorState's Chunks{Rejected,Redacted,Deduped} counters once the
+// run finishes. Built fresh per sync, mirroring how ioStatsRegistry's
+// per-flush counters are scoped to the Syncer rather than to the pipeline
+// itself.
+type Stats struct {
+	mu       sync.Mutex
+	rejected int64
+	redacted int64
+	deduped  int64
+}
+
+func (s *Stats) AddRejected() {
+	s.mu.Lock()
+	s.rejected++
+	s.mu.Unlock()
+}
+
+func (s *Stats) AddRedacted() {
+	s.mu.Lock()
+	s.redacted++
+	s.mu.Unlock()
+}
+
+func (s *Stats) AddDeduped() {
+	s.mu.Lock()
+	s.deduped++
+	s.mu.Unlock()
+}
+
+// Snapshot returns the current counts.
+func (s *Stats) Snapshot() (rejected, redacted, deduped int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rejected, s.redacted, s.deduped
+}
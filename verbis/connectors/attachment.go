@@ -0,0 +1,234 @@
+package connectors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/emersion/go-message/mail"
+
+	"github.com/verbis-ai/verbis/verbis/connectors/mailbody"
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+// AttachmentExtraction is what an AttachmentExtractor pulls out of a
+// single email attachment: the text to chunk, plus enough structural
+// metadata for a citation to point at more than just "this email".
+type AttachmentExtraction struct {
+	Text string
+
+	// PageCount is the number of pages the source format reports (PDF,
+	// DOCX via page breaks), or 0 if the format doesn't paginate.
+	PageCount int
+
+	// SheetNames lists a spreadsheet's sheets/tabs in source order. It's
+	// left nil for every attachment today: extract.ExtractedBlock (which
+	// binaryAttachmentExtractor builds on) doesn't yet carry a sheet
+	// name, so there's nothing real to report here until it does.
+	SheetNames []string
+}
+
+// AttachmentExtractor turns a single email attachment into text plus
+// metadata. mimeType and filename are both passed through since some
+// extractors branch on one, some on the other (an .eml attachment, say,
+// is identified by MIME type regardless of its filename).
+type AttachmentExtractor interface {
+	Extract(ctx context.Context, mimeType, filename string, r io.Reader) (AttachmentExtraction, error)
+}
+
+// attachmentExtractorsByMime is the registry ExtractAttachment looks up
+// by exact MIME type before falling back to prefix-based rules in
+// attachmentExtractorFor.
+var attachmentExtractorsByMime = buildAttachmentExtractors()
+
+func buildAttachmentExtractors() map[string]AttachmentExtractor {
+	m := map[string]AttachmentExtractor{}
+	for mime := range SupportedMimeTypes {
+		// PDF, DOCX, PPTX, XLSX, and images all already go through
+		// extractBlocks' unstructured/pure-Go-PDF/OCR pipeline.
+		m[mime] = binaryAttachmentExtractor{}
+	}
+	m["message/rfc822"] = emlAttachmentExtractor{}
+	return m
+}
+
+// attachmentExtractorFor returns the extractor registered for mime, or a
+// plain passthrough for text-ish formats (plain text, CSV, Markdown) that
+// don't need any real extraction work, or nil if mime isn't supported at
+// all.
+func attachmentExtractorFor(mime string) AttachmentExtractor {
+	if e, ok := attachmentExtractorsByMime[mime]; ok {
+		return e
+	}
+	switch mime {
+	case "text/csv", "application/csv", "text/markdown":
+		return plainTextAttachmentExtractor{}
+	}
+	if strings.HasPrefix(mime, "text/") {
+		return plainTextAttachmentExtractor{}
+	}
+	return nil
+}
+
+// ExtractAttachment runs an email attachment through the extractor
+// registered for mime. It returns (nil, nil) when mime isn't supported,
+// so callers can skip the attachment without treating it as an error.
+func ExtractAttachment(ctx context.Context, mime, filename string, r io.Reader) (*AttachmentExtraction, error) {
+	extractor := attachmentExtractorFor(mime)
+	if extractor == nil {
+		return nil, nil
+	}
+	result, err := extractor.Extract(ctx, mime, filename, r)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AttachmentDocument builds the child Document used to chunk an
+// attachment's extracted text. Its UniqueID is derived from the parent
+// email's so DeleteDocumentChunks on the parent and on the attachment
+// never collide, while a citation against it can still be traced back to
+// "this email, this attachment" rather than just "this email".
+func AttachmentDocument(parent types.Document, filename string) types.Document {
+	child := parent
+	child.UniqueID = fmt.Sprintf("%s#attachment:%s", parent.UniqueID, filename)
+	child.Name = fmt.Sprintf("%s (%s)", parent.Name, filename)
+	return child
+}
+
+// plainTextAttachmentExtractor handles attachments that need no real
+// extraction: plain text, CSV, and Markdown are already chunkable text.
+type plainTextAttachmentExtractor struct{}
+
+func (plainTextAttachmentExtractor) Extract(ctx context.Context, mimeType, filename string, r io.Reader) (AttachmentExtraction, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return AttachmentExtraction{}, fmt.Errorf("unable to read attachment %s: %v", filename, err)
+	}
+	return AttachmentExtraction{Text: string(data)}, nil
+}
+
+// binaryAttachmentExtractor covers every MIME type extractBlocks already
+// understands (PDF, DOCX, PPTX, XLSX, images). extractBlocks needs a file
+// path to open rather than a reader, so the attachment is written to a
+// temp file first and removed again once extraction finishes.
+type binaryAttachmentExtractor struct{}
+
+func (binaryAttachmentExtractor) Extract(ctx context.Context, mimeType, filename string, r io.Reader) (AttachmentExtraction, error) {
+	path, err := writeTempAttachment(filename, r)
+	if err != nil {
+		return AttachmentExtraction{}, err
+	}
+	defer os.Remove(path)
+
+	blocks, err := extractBlocks(ctx, mimeType, path)
+	if err != nil {
+		return AttachmentExtraction{}, err
+	}
+
+	var sb strings.Builder
+	pageCount := 0
+	for _, b := range blocks {
+		sb.WriteString(b.Text)
+		sb.WriteString("\n")
+		if b.Page > pageCount {
+			pageCount = b.Page
+		}
+	}
+	return AttachmentExtraction{Text: sb.String(), PageCount: pageCount}, nil
+}
+
+// writeTempAttachment copies r to a file under ~/.verbis/tmp named after
+// filename (truncated to a safe length), returning its path for callers
+// that need to hand a path rather than a reader to a downstream library.
+func writeTempAttachment(filename string, r io.Reader) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %v", err)
+	}
+	tempDir := filepath.Join(homeDir, ".verbis", "tmp")
+	if err := os.MkdirAll(tempDir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+
+	const maxFileNameLength = 255
+	name := filename
+	if name == "" {
+		name = "attachment"
+	}
+	if len(name) > maxFileNameLength {
+		name = name[:maxFileNameLength]
+	}
+	tempFilePath := filepath.Join(tempDir, name)
+
+	f, err := os.Create(tempFilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	_, copyErr := io.Copy(f, r)
+	f.Close()
+	if copyErr != nil {
+		os.Remove(tempFilePath)
+		return "", fmt.Errorf("failed to write attachment to disk: %v", copyErr)
+	}
+	return tempFilePath, nil
+}
+
+// emlAttachmentExtractor recursively parses a forwarded message/rfc822
+// attachment (a ".eml" file) the same way IMAPConnector parses a
+// top-level message: text/plain and text/html inline parts through
+// mailbody.Clean, and the nested message's own attachments through this
+// same registry, so a forwarded email contributes its real content
+// instead of being skipped as an opaque binary blob.
+type emlAttachmentExtractor struct{}
+
+func (emlAttachmentExtractor) Extract(ctx context.Context, mimeType, filename string, r io.Reader) (AttachmentExtraction, error) {
+	mr, err := mail.CreateReader(r)
+	if err != nil {
+		return AttachmentExtraction{}, fmt.Errorf("unable to parse nested message %s: %v", filename, err)
+	}
+
+	var content strings.Builder
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return AttachmentExtraction{}, fmt.Errorf("unable to read part of nested message %s: %v", filename, err)
+		}
+
+		switch h := part.Header.(type) {
+		case *mail.InlineHeader:
+			partMime, _, _ := h.ContentType()
+			if partMime != "text/plain" && partMime != "text/html" {
+				continue
+			}
+			data, err := io.ReadAll(part.Body)
+			if err != nil {
+				continue
+			}
+			content.WriteString(mailbody.Clean(string(data), partMime))
+		case *mail.AttachmentHeader:
+			partMime, _, _ := h.ContentType()
+			// A .eml nested inside a .eml is vanishingly rare and would
+			// otherwise recurse without a depth bound; it's skipped
+			// rather than extracted.
+			if partMime == "message/rfc822" {
+				continue
+			}
+			nestedFilename, _ := h.Filename()
+			nested, err := ExtractAttachment(ctx, partMime, nestedFilename, part.Body)
+			if err != nil || nested == nil {
+				continue
+			}
+			content.WriteString(nested.Text)
+		}
+	}
+
+	return AttachmentExtraction{Text: content.String()}, nil
+}
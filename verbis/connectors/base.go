@@ -4,35 +4,32 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+
+	"github.com/verbis-ai/verbis/verbis/chunker"
 	"github.com/verbis-ai/verbis/verbis/keychain"
+	"github.com/verbis-ai/verbis/verbis/metrics"
 	"github.com/verbis-ai/verbis/verbis/store"
 	"github.com/verbis-ai/verbis/verbis/types"
 	"github.com/verbis-ai/verbis/verbis/util"
 )
 
-var AllConnectors = map[string]types.ConnectorConstructor{
-	string(types.ConnectorTypeGoogleDrive): NewGoogleDriveConnector,
-	string(types.ConnectorTypeGmail):       NewGmailConnector,
-	string(types.ConnectorTypeOutlook):     NewOutlookConnector,
-	string(types.ConnectorTypeSlack):       NewSlackConnector,
-}
-
 const (
 	// The following parameters are only used in connectors that use
 	// content chunking of larger documents.
 
 	// MaxChunkSize in number of words in a chunk
 	MaxChunkSize = 200
-	// ChunkOverlap is the number of words that overlap between chunks
+	// ChunkOverlap is the fraction of MaxChunkSize that overlaps between
+	// chunks
 	ChunkOverlap = 0.2
 )
 
-func IsConnectorType(s string) bool {
-	_, ok := AllConnectors[s]
-	return ok
-}
+var recursiveChunker = chunker.NewRecursiveChunker()
 
 // BaseConnector contains methods and fields common to all connector
 // implementations. Most connectors are expected to embed BaseConnector.
@@ -43,8 +40,38 @@ type BaseConnector struct {
 	context       context.Context
 	cancel        context.CancelFunc
 	store         types.Store
+
+	progressMu   sync.Mutex
+	progress     types.SyncProgress
+	progressChan chan types.SyncProgress
+	// progressRate is an EWMA of processed items/sec, used to derive
+	// SyncProgress.EstimatedRemaining. lastAdvance is the timestamp of the
+	// previous advanceProgress call, used to compute the instantaneous
+	// rate that feeds the EWMA.
+	progressRate float64
+	lastAdvance  time.Time
 }
 
+// NewBaseConnector builds a BaseConnector of the given type backed by st,
+// for use by connector implementations outside this package that can't
+// set BaseConnector's unexported fields directly via a struct literal.
+// In-tree connectors still construct BaseConnector inline for consistency
+// with the rest of this file.
+func NewBaseConnector(t types.ConnectorType, st types.Store) BaseConnector {
+	return BaseConnector{
+		connectorType: t,
+		store:         st,
+	}
+}
+
+// minItemsForEstimate is how many items a sync needs to have processed
+// before EstimatedRemaining is considered reliable enough to report.
+const minItemsForEstimate = 20
+
+// progressRateSmoothing is the EWMA smoothing factor applied to each new
+// instantaneous items/sec sample.
+const progressRateSmoothing = 0.3
+
 func (s *BaseConnector) ID() string {
 	return s.id
 }
@@ -56,6 +83,14 @@ func (s *BaseConnector) Type() types.ConnectorType {
 	return s.connectorType
 }
 
+// Context returns the connector's background context, set up by Init and
+// cancelled by Cancel, for connector implementations (in this package or
+// registered from elsewhere via RegisterConnector) that run a long-lived
+// goroutine alongside periodic Sync calls, such as a live event listener.
+func (s *BaseConnector) Context() context.Context {
+	return s.context
+}
+
 func (s *BaseConnector) Status(ctx context.Context) (*types.ConnectorState, error) {
 	state, err := s.store.GetConnectorState(ctx, s.ID())
 	if err != nil {
@@ -69,8 +104,116 @@ func (s *BaseConnector) Status(ctx context.Context) (*types.ConnectorState, erro
 	return state, nil
 }
 
-func (s *BaseConnector) Cancel() {
+// Progress returns the connector's progress channel. See the
+// types.Connector doc comment for delivery semantics.
+func (s *BaseConnector) Progress() <-chan types.SyncProgress {
+	return s.progressChan
+}
+
+// StartProgress is startProgress's exported form, for connector
+// implementations outside this package that can't call it directly.
+func (s *BaseConnector) StartProgress(totalItems int) {
+	s.startProgress(totalItems)
+}
+
+// startProgress resets the connector's progress snapshot at the start of
+// a sync. totalItems may be 0 when the source API doesn't expose a count
+// up front (e.g. a paged message listing); ProcessedItems then just
+// counts up without a known denominator.
+func (s *BaseConnector) startProgress(totalItems int) {
+	s.progressMu.Lock()
+	s.progress = types.SyncProgress{
+		TotalItems: totalItems,
+		StartedAt:  time.Now(),
+	}
+	s.progressRate = 0
+	s.lastAdvance = time.Time{}
+	s.progressMu.Unlock()
+	s.publishProgress()
+}
+
+// advanceProgress records that fileName has been processed, optionally
+// with the number of bytes downloaded for it, and publishes the updated
+// snapshot.
+func (s *BaseConnector) advanceProgress(fileName string, bytesDownloaded int64) {
+	s.progressMu.Lock()
+	now := time.Now()
+	if !s.lastAdvance.IsZero() {
+		if dt := now.Sub(s.lastAdvance).Seconds(); dt > 0 {
+			instRate := 1.0 / dt
+			if s.progressRate == 0 {
+				s.progressRate = instRate
+			} else {
+				s.progressRate = progressRateSmoothing*instRate + (1-progressRateSmoothing)*s.progressRate
+			}
+		}
+	}
+	s.lastAdvance = now
+
+	s.progress.ProcessedItems++
+	s.progress.CurrentFile = fileName
+	s.progress.BytesDownloaded += bytesDownloaded
+	metrics.ConnectorBytesRead.WithLabelValues(s.id).Add(float64(bytesDownloaded))
+
+	remaining := s.progress.TotalItems - s.progress.ProcessedItems
+	if s.progress.ProcessedItems >= minItemsForEstimate && s.progress.TotalItems > 0 && s.progressRate > 0 && remaining > 0 {
+		s.progress.EstimatedRemaining = time.Duration(float64(remaining)/s.progressRate*1000) * time.Millisecond
+	} else {
+		s.progress.EstimatedRemaining = 0
+	}
+	s.progressMu.Unlock()
+	s.publishProgress()
+}
+
+// recordProgressError increments the error count on the current progress
+// snapshot and publishes it, without advancing ProcessedItems.
+func (s *BaseConnector) recordProgressError() {
+	s.progressMu.Lock()
+	s.progress.Errors++
+	s.progressMu.Unlock()
+	s.publishProgress()
+}
+
+// publishProgress sends a copy of the current snapshot on progressChan,
+// discarding any previous unread snapshot first so the producer never
+// blocks on a slow or absent subscriber.
+func (s *BaseConnector) publishProgress() {
+	s.progressMu.Lock()
+	snapshot := s.progress
+	s.progressMu.Unlock()
+
+	select {
+	case <-s.progressChan:
+	default:
+	}
+	s.progressChan <- snapshot
+}
+
+// Cancel stops the connector's background context, marks it as no longer
+// syncing, and flushes its last known progress snapshot into persisted
+// state, so a cancelled sync leaves behind an accurate partial record
+// instead of being stuck "Syncing" forever.
+func (s *BaseConnector) Cancel(ctx context.Context) {
 	s.cancel()
+
+	state, err := s.store.SetConnectorSyncing(ctx, s.id, false)
+	if err != nil && !store.IsSyncingAlreadyExpected(err) {
+		log.Printf("failed to mark connector %s not syncing on cancel: %v", s.id, err)
+		return
+	}
+	if state == nil {
+		return
+	}
+
+	err = store.MutateConnectorState(ctx, s.store, s.id, func(st *types.ConnectorState) error {
+		s.progressMu.Lock()
+		st.Progress = s.progress
+		s.progressMu.Unlock()
+		return nil
+	})
+	if err != nil {
+		log.Printf("failed to flush progress for connector %s on cancel: %v", s.id, err)
+	}
 }
 
 func (c *BaseConnector) Init(ctx context.Context, connectorID string) error {
@@ -85,24 +228,22 @@ func (c *BaseConnector) Init(ctx context.Context, connectorID string) error {
 
 	// Set up a new context for the connector
 	c.context, c.cancel = context.WithCancel(ctx)
+	c.progressChan = make(chan types.SyncProgress, 1)
 
-	state, err := c.store.GetConnectorState(ctx, c.ID())
-	if err != nil && !store.IsStateNotFound(err) {
-		return fmt.Errorf("failed to get connector state: %v", err)
-	}
-
-	if state == nil {
-		state = &types.ConnectorState{}
-	}
-
-	state.ConnectorID = c.ID()
-	state.Syncing = false
-	// state.User is unknown until auth is complete
-	state.ConnectorType = string(c.Type())
-	token, err := keychain.TokenFromKeychain(c.ID(), c.Type())
-	state.AuthValid = (err == nil && token != nil) // TODO: check for expiry of refresh token
-
-	err = c.store.UpdateConnectorState(ctx, state)
+	err := store.MutateConnectorState(ctx, c.store, c.ID(), func(state *types.ConnectorState) error {
+		state.ConnectorID = c.ID()
+		state.Syncing = false
+		// state.User is unknown until auth is complete
+		state.ConnectorType = string(c.Type())
+		token, err := keychain.TokenFromKeychain(c.ID(), c.Type())
+		authState := keychain.TokenStateInvalid
+		if err == nil {
+			authState = keychain.StateForToken(token)
+		}
+		state.AuthState = string(authState)
+		state.AuthValid = authState != keychain.TokenStateInvalid
+		return nil
+	})
 	if err != nil {
 		return fmt.Errorf("failed to set connector state: %v", err)
 	}
@@ -113,18 +254,44 @@ func (s *BaseConnector) UpdateConnectorState(ctx context.Context, state *types.C
 	return s.store.UpdateConnectorState(ctx, state)
 }
 
-func emitChunks(fileName string, content string, document types.Document, chunkChan chan types.ChunkSyncResult) {
-	numChunks := 0
+// MutateState is store.MutateConnectorState scoped to this connector's own
+// ID, exported so connector implementations (in this package directly, or
+// outside it via the embedded BaseConnector) can read-modify-write their
+// ConnectorState, e.g. from AuthCallback to set User/UserKey once identity
+// is resolved, without a bare Status/UpdateConnectorState pair racing a
+// concurrent Sync or Cancel update.
+func (s *BaseConnector) MutateState(ctx context.Context, mutate func(*types.ConnectorState) error) error {
+	return store.MutateConnectorState(ctx, s.store, s.ID(), mutate)
+}
+
+// TokenSource returns the shared, proactively-refreshed oauth2.TokenSource
+// for this connector, backed by keychain.DefaultManager so every HTTP call
+// a long-running sync makes pulls a fresh token, and concurrent Sync
+// goroutines for the same connector share one refresh-in-flight instead of
+// each racing an independent RefreshingTokenSource.
+func (s *BaseConnector) TokenSource(config *oauth2.Config) (oauth2.TokenSource, error) {
+	return keychain.DefaultManager().GetOrCreate(s.id, s.connectorType, config)
+}
+
+func EmitChunks(bc *BaseConnector, fileName string, content string, document types.Document, chunkChan chan types.ChunkSyncResult) {
 	content = util.CleanChunk(content)
-	chunkTexts := util.ChunkText(content, MaxChunkSize, ChunkOverlap)
-	for _, text := range chunkTexts {
-		numChunks++
-		log.Printf("Processing chunk %d of document %s", numChunks, fileName)
+	parentHash := util.HashText(content)
+
+	chunks := recursiveChunker.Chunk(content, chunker.Options{
+		TargetTokens:  MaxChunkSize,
+		OverlapTokens: int(float64(MaxChunkSize) * ChunkOverlap),
+	})
+
+	for i, c := range chunks {
+		log.Printf("Processing chunk %d of document %s", i+1, fileName)
 		chunkChan <- types.ChunkSyncResult{
 			Chunk: types.Chunk{
-				Text:     text,
-				Document: document,
+				Text:       c.Text,
+				Document:   document,
+				ChunkIndex: i,
+				ParentHash: parentHash,
 			},
 		}
 	}
+	bc.advanceProgress(fileName, int64(len(content)))
 }
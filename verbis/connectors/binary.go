@@ -3,53 +3,97 @@ package connectors
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
-	"os/exec"
-	"path/filepath"
+	"os"
+	"strings"
 
-	"github.com/verbis-ai/verbis/verbis/util"
-)
-
-const (
-	pdfToTextPath = "pdftotext/pdftoText"
+	"github.com/verbis-ai/verbis/verbis/extract"
 )
 
 var (
 	SupportedMimeTypes = map[string]bool{
 		"application/pdf": true,
-		//		"image/jpeg":      true,
-		//		"image/png":       true,
-		//		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         true,
-		//		"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   true,
-		//		"application/vnd.openxmlformats-officedocument.presentationml.presentation": true,
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   true,
+		"application/vnd.openxmlformats-officedocument.presentationml.presentation": true,
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         true,
+		"image/jpeg": true,
+		"image/png":  true,
 	}
+
+	// minExtractedChars is the threshold below which a PDF extraction is
+	// treated as having failed to find a text layer at all (e.g. a
+	// scanned document), triggering the next fallback in extractBlocks.
+	minExtractedChars = 20
 )
 
-type ParseRequest struct {
-	Type string `json:"type"`
-	Path string `json:"path"`
+// isSupportedMimeType reports whether extractBlocks knows how to handle
+// mime. Plain text files are always supported since they need no
+// extraction at all.
+func isSupportedMimeType(mime string) bool {
+	if strings.HasPrefix(mime, "text/") {
+		return true
+	}
+	return SupportedMimeTypes[mime]
 }
 
-type UnstructuredChunk struct {
-	Index   int    `json:"index"`
-	Content string `json:"string"`
-}
+// extractBlocks turns a downloaded binary file into structural blocks.
+// Images go straight to OCR. Everything else prefers the
+// unstructured-backed extractor (which understands DOCX, PPTX and XLSX as
+// well as PDF); for PDFs that come back with suspiciously little text -
+// whether because unstructured isn't available, failed, or the PDF is a
+// scan with no text layer - it falls through to the pure-Go PDF extractor
+// and finally to OCR as a last resort.
+func extractBlocks(ctx context.Context, mime string, path string) ([]extract.ExtractedBlock, error) {
+	if strings.HasPrefix(mime, "image/") {
+		return extractWith(ctx, extract.NewOCRExtractor(), mime, path)
+	}
 
-func ParseBinaryFile(ctx context.Context, request *ParseRequest) (string, error) {
-	// Execute the Python script and pass JSON data to stdin
-	distPath, err := util.GetDistPath()
+	blocks, err := extractWith(ctx, extract.NewUnstructuredExtractor(), mime, path)
+	if err == nil && blockTextLen(blocks) >= minExtractedChars {
+		return blocks, nil
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to get dist path: %v", err)
+		log.Printf("unstructured extraction failed for %s, falling back: %v", path, err)
+	} else {
+		log.Printf("unstructured extraction returned only %d chars for %s, falling back", blockTextLen(blocks), path)
+	}
+
+	if mime != "application/pdf" {
+		if err != nil {
+			return nil, err
+		}
+		return blocks, nil
 	}
 
-	path := filepath.Join(distPath, pdfToTextPath)
-	cmd := exec.CommandContext(ctx, path, "-layout", request.Path, "-")
-	output, err := cmd.CombinedOutput()
-	log.Print(string(output))
+	pdfBlocks, pdfErr := extractWith(ctx, extract.NewPDFExtractor(), mime, path)
+	if pdfErr == nil && blockTextLen(pdfBlocks) >= minExtractedChars {
+		return pdfBlocks, nil
+	}
+	if pdfErr != nil {
+		log.Printf("pure-Go PDF extraction failed for %s, falling back to OCR: %v", path, pdfErr)
+	} else {
+		log.Printf("pure-Go PDF extraction returned only %d chars for %s, falling back to OCR", blockTextLen(pdfBlocks), path)
+	}
+
+	// Likely a scanned PDF with no text layer: OCR is the last resort.
+	return extractWith(ctx, extract.NewOCRExtractor(), mime, path)
+}
+
+func blockTextLen(blocks []extract.ExtractedBlock) int {
+	n := 0
+	for _, b := range blocks {
+		n += len(b.Text)
+	}
+	return n
+}
+
+func extractWith(ctx context.Context, extractor extract.Extractor, mime string, path string) ([]extract.ExtractedBlock, error) {
+	f, err := os.Open(path)
 	if err != nil {
-		log.Print(string(output))
-		return "", fmt.Errorf("error executing script: %v", err)
+		return nil, fmt.Errorf("failed to open %s: %v", path, err)
 	}
+	defer f.Close()
 
-	return util.CleanWhitespace(string(output)), nil
+	return extractor.Extract(ctx, mime, io.Reader(f))
 }
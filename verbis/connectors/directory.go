@@ -0,0 +1,123 @@
+package connectors
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/option"
+)
+
+// directoryGroupsTTL bounds how long a DirectoryGroups result is cached
+// per user, so a large sync that processes thousands of documents for the
+// same signed-in account doesn't burn a Directory API quota unit per
+// document.
+const directoryGroupsTTL = 15 * time.Minute
+
+type directoryGroupsEntry struct {
+	groups  []string
+	expires time.Time
+}
+
+var (
+	directoryGroupsMu    sync.Mutex
+	directoryGroupsCache = map[string]directoryGroupsEntry{}
+)
+
+// DirectoryGroups returns the email addresses of every Workspace group
+// userEmail belongs to, via the Admin SDK Directory API, caching the
+// result for directoryGroupsTTL. Personal Google accounts, and Workspace
+// accounts without admin.directory.group.readonly consent, don't have a
+// directory to query: the Directory API returns an error in both cases,
+// which is treated as "no groups" rather than a sync failure, so callers
+// get back a nil slice and the document still gets an ACL of just
+// userEmail instead of the sync aborting.
+func DirectoryGroups(ctx context.Context, tokenSource oauth2.TokenSource, userEmail string) []string {
+	directoryGroupsMu.Lock()
+	if e, ok := directoryGroupsCache[userEmail]; ok && time.Now().Before(e.expires) {
+		directoryGroupsMu.Unlock()
+		return e.groups
+	}
+	directoryGroupsMu.Unlock()
+
+	groups, err := fetchDirectoryGroups(ctx, tokenSource, userEmail)
+	if err != nil {
+		log.Printf("No directory available for %s, falling back to a single-user ACL: %v", userEmail, err)
+		groups = nil
+	}
+
+	directoryGroupsMu.Lock()
+	directoryGroupsCache[userEmail] = directoryGroupsEntry{groups: groups, expires: time.Now().Add(directoryGroupsTTL)}
+	directoryGroupsMu.Unlock()
+
+	return groups
+}
+
+func fetchDirectoryGroups(ctx context.Context, tokenSource oauth2.TokenSource, userEmail string) ([]string, error) {
+	srv, err := admin.NewService(ctx, option.WithTokenSource(tokenSource))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create directory client: %v", err)
+	}
+
+	var groups []string
+	call := srv.Groups.List().UserKey(userEmail).Context(ctx)
+	err = call.Pages(ctx, func(page *admin.Groups) error {
+		for _, grp := range page.Groups {
+			groups = append(groups, strings.ToLower(grp.Email))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list groups for %s: %v", userEmail, err)
+	}
+
+	return groups, nil
+}
+
+// resolveDocumentACL builds the ACL recorded on a synced document from the
+// raw identities who can access it in the source system (Drive file
+// permissions, or Gmail To/Cc/Bcc addresses) plus the signed-in user's own
+// identity. selfGroups is intersected with grantees, not folded in
+// unconditionally: a group the user belongs to only grants access to a
+// document if the source system actually shared that document with the
+// group, otherwise every document the user can see would become visible
+// to every group they're a member of, whether or not that group was ever
+// granted access to it. The ACL may end up holding group addresses rather
+// than individual users; the query path resolves that the same way, by
+// checking a requester's own identity-plus-groups set against it.
+func resolveDocumentACL(selfEmail string, selfGroups []string, grantees []string) []string {
+	seen := map[string]struct{}{}
+	var acl []string
+	add := func(id string) {
+		id = strings.ToLower(strings.TrimSpace(id))
+		if id == "" {
+			return
+		}
+		if _, ok := seen[id]; ok {
+			return
+		}
+		seen[id] = struct{}{}
+		acl = append(acl, id)
+	}
+
+	granteeSet := map[string]struct{}{}
+	for _, g := range grantees {
+		granteeSet[strings.ToLower(strings.TrimSpace(g))] = struct{}{}
+	}
+
+	add(selfEmail)
+	for _, g := range selfGroups {
+		if _, ok := granteeSet[strings.ToLower(strings.TrimSpace(g))]; ok {
+			add(g)
+		}
+	}
+	for _, g := range grantees {
+		add(g)
+	}
+	return acl
+}
@@ -0,0 +1,479 @@
+package connectors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/verbis-ai/verbis/verbis/keychain"
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+const (
+	dropboxAPIURL     = "https://api.dropboxapi.com/2"
+	dropboxContentURL = "https://content.dropboxapi.com/2"
+
+	// dropboxPageLimit bounds how many entries list_folder/list_folder/continue
+	// return per page.
+	dropboxPageLimit = 200
+)
+
+func NewDropboxConnector(creds types.BuildCredentials, st types.Store) types.Connector {
+	return &DropboxConnector{
+		BaseConnector: BaseConnector{
+			connectorType: types.ConnectorTypeDropbox,
+			store:         st,
+		},
+		clientID:     creds.DropboxClientID,
+		clientSecret: creds.DropboxClientSecret,
+	}
+}
+
+func init() {
+	types.RegisterConnector(types.ConnectorTypeDropbox, NewDropboxConnector)
+}
+
+type DropboxConnector struct {
+	BaseConnector
+	clientID     string
+	clientSecret string
+}
+
+func (d *DropboxConnector) dropboxConfig() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     d.clientID,
+		ClientSecret: d.clientSecret,
+		RedirectURL:  fmt.Sprintf("http://127.0.0.1:8081/connectors/%s/callback", d.ID()),
+		Scopes: []string{
+			"files.metadata.read",
+			"files.content.read",
+			"account_info.read",
+		},
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  "https://www.dropbox.com/oauth2/authorize",
+			TokenURL: "https://api.dropboxapi.com/oauth2/token",
+		},
+	}
+}
+
+func (d *DropboxConnector) getClient(ctx context.Context) (*http.Client, error) {
+	tok, err := keychain.TokenFromKeychain(d.ID(), d.Type())
+	if err != nil {
+		return nil, err
+	}
+	return d.dropboxConfig().Client(ctx, tok), nil
+}
+
+func (d *DropboxConnector) requestOauthWeb(config *oauth2.Config) error {
+	log.Printf("Requesting token from web with redirectURL: %v", config.RedirectURL)
+	authURL := config.AuthCodeURL(
+		d.ID(),
+		oauth2.AccessTypeOffline,
+		oauth2.SetAuthURLParam("token_access_type", "offline"),
+	)
+	fmt.Printf("Your browser has been opened to visit:\n%v\n", authURL)
+
+	// Open URL in the default browser
+	return exec.Command("open", authURL).Start()
+}
+
+func (d *DropboxConnector) AuthSetup(ctx context.Context) error {
+	_, err := keychain.TokenFromKeychain(d.ID(), d.Type())
+	if err == nil {
+		// TODO: check for expiry of refresh token
+		log.Print("Token found in keychain.")
+		return nil
+	}
+	log.Print("No token found in keychain. Getting token from web.")
+	err = d.requestOauthWeb(d.dropboxConfig())
+	if err != nil {
+		log.Printf("Unable to request token from web: %v", err)
+	}
+	return nil
+}
+
+// TODO: handle token expiries
+func (d *DropboxConnector) AuthCallback(ctx context.Context, authCode string) error {
+	config := d.dropboxConfig()
+	tok, err := config.Exchange(ctx, authCode)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve token from web: %v", err)
+	}
+
+	err = keychain.SaveTokenToKeychain(tok, d.ID(), d.Type())
+	if err != nil {
+		return fmt.Errorf("unable to save token to keychain: %v", err)
+	}
+
+	client := config.Client(ctx, tok)
+	email, err := getDropboxUserEmail(client)
+	if err != nil {
+		return fmt.Errorf("unable to get user email: %v", err)
+	}
+	log.Printf("User email: %s", email)
+	d.user = email
+
+	return d.MutateState(ctx, func(state *types.ConnectorState) error {
+		state.User = d.User()
+		return nil
+	})
+}
+
+func getDropboxUserEmail(client *http.Client) (string, error) {
+	resp, err := client.Post(dropboxAPIURL+"/users/get_current_account", "application/json", nil)
+	if err != nil {
+		return "", fmt.Errorf("unable to get current account: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("failed to get current account: status %s: %s", resp.Status, string(body))
+	}
+
+	var account struct {
+		Email string `json:"email"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&account)
+	if err != nil {
+		return "", fmt.Errorf("unable to decode account info: %v", err)
+	}
+
+	return account.Email, nil
+}
+
+// dropboxMetadata is the subset of Dropbox's file/folder metadata we care
+// about, shared by the list_folder and list_folder/continue responses.
+type dropboxMetadata struct {
+	Tag            string `json:".tag"`
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	PathLower      string `json:"path_lower"`
+	PathDisplay    string `json:"path_display"`
+	ServerModified string `json:"server_modified"`
+}
+
+type dropboxListFolderResult struct {
+	Entries []dropboxMetadata `json:"entries"`
+	Cursor  string            `json:"cursor"`
+	HasMore bool              `json:"has_more"`
+}
+
+func (d *DropboxConnector) Sync(ctx context.Context, lastSync time.Time, chunkChan chan types.ChunkSyncResult, errChan chan error) {
+	defer close(chunkChan)
+	if err := ctx.Err(); err != nil {
+		errChan <- fmt.Errorf("context error: %s", err)
+		return
+	}
+
+	// TotalItems is unknown up front: list_folder is paged and doesn't
+	// report a total count, so ProcessedItems counts up without a known
+	// denominator until the sync completes.
+	d.startProgress(0)
+
+	log.Printf("Starting dropbox sync")
+	client, err := d.getClient(ctx)
+	if err != nil {
+		errChan <- fmt.Errorf("unable to get client: %v", err)
+		return
+	}
+
+	state, err := d.Status(ctx)
+	if err != nil {
+		errChan <- fmt.Errorf("unable to get connector state: %v", err)
+		return
+	}
+
+	err = d.listFolder(ctx, client, state, chunkChan)
+	if err != nil {
+		errChan <- fmt.Errorf("unable to list folder: %v", err)
+		return
+	}
+}
+
+// listFolder walks the full Dropbox file tree on first sync, and from then
+// on resumes from the cursor persisted on the connector state via
+// list_folder/continue, rather than filtering on a modifiedTime-style
+// timestamp.
+func (d *DropboxConnector) listFolder(ctx context.Context, client *http.Client, state *types.ConnectorState, chunkChan chan types.ChunkSyncResult) error {
+	cursor := state.Cursor
+
+	for {
+		var result dropboxListFolderResult
+		var err error
+		if cursor == "" {
+			result, err = d.listFolderStart(ctx, client)
+		} else {
+			result, err = d.listFolderContinue(ctx, client, cursor)
+		}
+		if err != nil {
+			return err
+		}
+
+		var wg sync.WaitGroup
+		for _, entry := range result.Entries {
+			if entry.Tag == "deleted" {
+				// list_folder/continue reports deletions as tombstone
+				// entries with no content; purge any vectors synced for
+				// them rather than silently leaving them behind, the same
+				// way googledrive.go's processChange handles a Removed
+				// change.
+				if entry.ID != "" {
+					if err := d.store.DeleteDocumentChunks(ctx, entry.ID, d.ID()); err != nil {
+						log.Printf("Unable to delete chunks for removed dropbox file %s: %v", entry.PathDisplay, err)
+					}
+				}
+				continue
+			}
+			if entry.Tag != "file" {
+				// Folders don't carry content to chunk.
+				continue
+			}
+			wg.Add(1)
+			go func(e dropboxMetadata) {
+				defer wg.Done()
+				d.processFile(ctx, client, e, chunkChan)
+			}(entry)
+		}
+		wg.Wait()
+
+		cursor = result.Cursor
+		state.Cursor = cursor
+		if err := d.UpdateConnectorState(ctx, state); err != nil {
+			log.Printf("Unable to persist dropbox cursor: %v", err)
+		}
+
+		if !result.HasMore {
+			break
+		}
+	}
+	return nil
+}
+
+func (d *DropboxConnector) listFolderStart(ctx context.Context, client *http.Client) (dropboxListFolderResult, error) {
+	var result dropboxListFolderResult
+	body := map[string]interface{}{
+		"path":      "",
+		"recursive": true,
+		"limit":     dropboxPageLimit,
+	}
+	err := dropboxAPICall(ctx, client, "/files/list_folder", body, &result)
+	return result, err
+}
+
+func (d *DropboxConnector) listFolderContinue(ctx context.Context, client *http.Client, cursor string) (dropboxListFolderResult, error) {
+	var result dropboxListFolderResult
+	body := map[string]interface{}{
+		"cursor": cursor,
+	}
+	err := dropboxAPICall(ctx, client, "/files/list_folder/continue", body, &result)
+	return result, err
+}
+
+func dropboxAPICall(ctx context.Context, client *http.Client, endpoint string, reqBody interface{}, out interface{}) error {
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return fmt.Errorf("unable to marshal request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dropboxAPIURL+endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("unable to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to call %s: %v", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("call to %s failed: status %s: %s", endpoint, resp.Status, string(body))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (d *DropboxConnector) processFile(ctx context.Context, client *http.Client, entry dropboxMetadata, chunkChan chan types.ChunkSyncResult) {
+	var content string
+	var err error
+	if strings.HasSuffix(strings.ToLower(entry.Name), ".paper") {
+		content, err = dropboxExport(ctx, client, entry.PathLower)
+	} else {
+		content, err = downloadAndParseDropboxFile(ctx, client, entry)
+	}
+	if err != nil {
+		d.recordProgressError()
+		chunkChan <- types.ChunkSyncResult{
+			Err: fmt.Errorf("unable to process dropbox file %s: %v", entry.Name, err),
+		}
+		return
+	}
+
+	modifiedAt, err := time.Parse(time.RFC3339, entry.ServerModified)
+	if err != nil {
+		log.Printf("Error parsing server_modified %s: %v", entry.ServerModified, err)
+		modifiedAt = time.Now()
+	}
+
+	document := types.Document{
+		UniqueID:      entry.ID,
+		Name:          entry.Name,
+		SourceURL:     fmt.Sprintf("https://www.dropbox.com/home%s", entry.PathDisplay),
+		ConnectorID:   d.ID(),
+		ConnectorType: string(d.Type()),
+		CreatedAt:     modifiedAt,
+		UpdatedAt:     modifiedAt,
+	}
+
+	err = d.store.DeleteDocumentChunks(ctx, document.UniqueID, d.ID())
+	if err != nil {
+		// Not a fatal error, just log it and leave the old chunks behind
+		log.Printf("Unable to delete chunks for document %s: %v", document.UniqueID, err)
+	}
+
+	EmitChunks(&d.BaseConnector, entry.Name, content, document, chunkChan)
+}
+
+// dropboxExport downloads a Dropbox Paper doc through the export endpoint,
+// the same way Google Docs-native files are exported to plain text.
+func dropboxExport(ctx context.Context, client *http.Client, path string) (string, error) {
+	arg, err := json.Marshal(map[string]string{
+		"path":          path,
+		"export_format": "markdown",
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal export arg: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dropboxContentURL+"/files/export", nil)
+	if err != nil {
+		return "", fmt.Errorf("unable to build request: %v", err)
+	}
+	req.Header.Set("Dropbox-API-Arg", string(arg))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("unable to export file: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("failed to export file: status %s: %s", resp.Status, string(body))
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("unable to read exported content: %v", err)
+	}
+	return string(data), nil
+}
+
+func downloadDropboxFile(ctx context.Context, client *http.Client, path string) (string, error) {
+	arg, err := json.Marshal(map[string]string{"path": path})
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal download arg: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dropboxContentURL+"/files/download", nil)
+	if err != nil {
+		return "", fmt.Errorf("unable to build request: %v", err)
+	}
+	req.Header.Set("Dropbox-API-Arg", string(arg))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download file: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("failed to download file: status %s: %s", resp.Status, string(body))
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %v", err)
+	}
+
+	tempDir := filepath.Join(homeDir, ".verbis", "tmp")
+	err = os.MkdirAll(tempDir, os.ModePerm)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+
+	tempFilePath := filepath.Join(tempDir, filepath.Base(path))
+	outFile, err := os.Create(tempFilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	defer outFile.Close()
+
+	_, err = io.Copy(outFile, resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to write file to disk: %v", err)
+	}
+
+	return tempFilePath, nil
+}
+
+// dropboxExtMimeTypes maps file extensions to the MIME types extractBlocks
+// expects, since Dropbox's file metadata doesn't carry a MIME type.
+var dropboxExtMimeTypes = map[string]string{
+	".pdf":  "application/pdf",
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+}
+
+func downloadAndParseDropboxFile(ctx context.Context, client *http.Client, entry dropboxMetadata) (string, error) {
+	mimeType, ok := dropboxExtMimeTypes[strings.ToLower(filepath.Ext(entry.Name))]
+	if !ok {
+		log.Printf("Unsupported dropbox file type: %s", entry.Name)
+		return "", nil
+	}
+	_, ok = SupportedMimeTypes[mimeType]
+	if !ok {
+		log.Printf("Unsupported MIME type: %s", mimeType)
+		return "", nil
+	}
+	log.Printf("Processing dropbox file: %s", entry.Name)
+
+	tempFilePath, err := downloadDropboxFile(ctx, client, entry.PathLower)
+	if err != nil {
+		return "", fmt.Errorf("failed to download file: %v", err)
+	}
+	log.Printf("Finished downloading dropbox file: %s", entry.Name)
+
+	blocks, err1 := extractBlocks(ctx, mimeType, tempFilePath)
+	err2 := os.Remove(tempFilePath) // Delete the file after processing
+	log.Printf("Finished parsing dropbox file %s", entry.Name)
+
+	if err1 != nil {
+		return "", fmt.Errorf("failed to parse binary file: %v", err1)
+	}
+	if err2 != nil {
+		log.Printf("Error deleting file %s: %v", tempFilePath, err2)
+	}
+
+	texts := make([]string, len(blocks))
+	for i, b := range blocks {
+		texts[i] = b.Text
+	}
+
+	return strings.Join(texts, "\n"), nil
+}
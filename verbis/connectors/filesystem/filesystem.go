@@ -0,0 +1,242 @@
+// Package filesystem is a sample connector that lives outside the
+// connectors package, to demonstrate the types.RegisterConnector extension
+// point end-to-end: it registers itself from an init() exactly like an
+// out-of-tree driver would, and main only needs to add a blank import
+//
+//	import _ "github.com/verbis-ai/verbis/verbis/connectors/filesystem"
+//
+// to link it in, the same way database/sql drivers are wired in without
+// the database/sql package itself knowing about them.
+//
+// It watches a local directory named by the VERBIS_FS_WATCH_ROOT
+// environment variable, indexing plain-text files under it and using
+// fsnotify to pick up new/changed files as a live feed between periodic
+// syncs. It's intentionally minimal (no subdirectory watch, no ignore
+// rules, no binary format support) since its purpose is to prove out the
+// registry, not to be a production-grade local file indexer.
+package filesystem
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/verbis-ai/verbis/verbis/connectors"
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+// ConnectorType identifies this connector in ConnectorState.ConnectorType
+// and in the registry RegisterConnector adds it to.
+const ConnectorType types.ConnectorType = "filesystem"
+
+// watchRootEnvVar names the directory this connector watches. There's no
+// per-account auth to configure, so AuthSetup/AuthCallback just reflect
+// whether it's set to a readable directory.
+const watchRootEnvVar = "VERBIS_FS_WATCH_ROOT"
+
+// liveUpdatesBufferSize mirrors connectors.SlackConnector's live channel
+// sizing: generous enough that a burst of file writes doesn't block the
+// fsnotify watch loop on a slow consumer.
+const liveUpdatesBufferSize = 100
+
+func init() {
+	types.RegisterConnector(ConnectorType, NewFilesystemConnector)
+}
+
+// NewFilesystemConnector is the types.ConnectorConstructor this package
+// registers. creds is unused: the watched directory comes from
+// watchRootEnvVar rather than per-account credentials.
+func NewFilesystemConnector(creds types.BuildCredentials, st types.Store) types.Connector {
+	return &FilesystemConnector{
+		BaseConnector: connectors.NewBaseConnector(ConnectorType, st),
+	}
+}
+
+// FilesystemConnector embeds connectors.BaseConnector via the exported
+// NewBaseConnector/Context extension points, the only ones available to a
+// connector implementation outside the connectors package.
+type FilesystemConnector struct {
+	connectors.BaseConnector
+
+	liveOnce sync.Once
+	liveChan chan types.ChunkSyncResult
+}
+
+// Init mirrors BaseConnector.Init, except auth validity is determined by
+// whether watchRootEnvVar points at a readable directory rather than a
+// keychain token.
+func (f *FilesystemConnector) Init(ctx context.Context, connectorID string) error {
+	if err := f.BaseConnector.Init(ctx, connectorID); err != nil {
+		return err
+	}
+
+	root, authValid := watchRoot()
+	if authValid {
+		f.startWatch(root)
+	}
+
+	return f.MutateState(ctx, func(state *types.ConnectorState) error {
+		state.AuthValid = authValid
+		if authValid {
+			state.AuthState = "valid"
+			state.User = root
+		} else {
+			state.AuthState = "invalid"
+		}
+		return nil
+	})
+}
+
+// watchRoot returns the configured watch directory and whether it exists
+// and is readable.
+func watchRoot() (string, bool) {
+	root := os.Getenv(watchRootEnvVar)
+	if root == "" {
+		return "", false
+	}
+	info, err := os.Stat(root)
+	if err != nil || !info.IsDir() {
+		return root, false
+	}
+	return root, true
+}
+
+// AuthSetup has nothing to open a browser to: readiness is just whether
+// watchRootEnvVar is set to a valid directory.
+func (f *FilesystemConnector) AuthSetup(ctx context.Context) error {
+	if _, ok := watchRoot(); !ok {
+		log.Printf("%s is not set to a readable directory; filesystem connector will stay unauthenticated", watchRootEnvVar)
+	}
+	return nil
+}
+
+// AuthCallback has no code to exchange; watchRootEnvVar is read once at
+// Init, so there's nothing further to do here.
+func (f *FilesystemConnector) AuthCallback(ctx context.Context, code string) error {
+	return nil
+}
+
+// Sync walks the watched directory, emitting a chunked ChunkSyncResult for
+// every regular file modified since lastSync.
+func (f *FilesystemConnector) Sync(ctx context.Context, lastSync time.Time, chunkChan chan types.ChunkSyncResult, errChan chan error) {
+	defer close(chunkChan)
+
+	root, ok := watchRoot()
+	if !ok {
+		errChan <- fmt.Errorf("%s is not set to a readable directory", watchRootEnvVar)
+		return
+	}
+
+	var paths []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() || info.ModTime().Before(lastSync) {
+			return nil
+		}
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		errChan <- fmt.Errorf("unable to walk %s: %v", root, err)
+		return
+	}
+
+	f.StartProgress(len(paths))
+	for _, path := range paths {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := f.syncFile(path, chunkChan); err != nil {
+			log.Printf("Unable to sync %s: %v", path, err)
+			continue
+		}
+	}
+}
+
+func (f *FilesystemConnector) syncFile(path string, chunkChan chan types.ChunkSyncResult) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read file: %v", err)
+	}
+
+	document := types.Document{
+		UniqueID:      path,
+		Name:          filepath.Base(path),
+		SourceURL:     "file://" + path,
+		ConnectorID:   f.ID(),
+		ConnectorType: string(f.Type()),
+	}
+	connectors.EmitChunks(&f.BaseConnector, document.Name, string(content), document, chunkChan)
+	return nil
+}
+
+// LiveUpdates implements types.LiveConnector, so new/changed files land in
+// the index as soon as fsnotify sees them rather than waiting for the next
+// periodic Sync.
+func (f *FilesystemConnector) LiveUpdates() <-chan types.ChunkSyncResult {
+	return f.liveChan
+}
+
+// startWatch launches the fsnotify watch loop exactly once per connector
+// instance; liveOnce guards against starting it twice since Init can run
+// more than once (fresh create vs. restore from state).
+func (f *FilesystemConnector) startWatch(root string) {
+	f.liveOnce.Do(func() {
+		f.liveChan = make(chan types.ChunkSyncResult, liveUpdatesBufferSize)
+		go f.watchLoop(f.Context(), root)
+	})
+}
+
+// watchLoop keeps an fsnotify watch open on root for the life of ctx,
+// re-chunking a file and pushing it onto liveChan whenever fsnotify
+// reports it was created or written. Only root itself is watched, not
+// subdirectories created after the watch starts.
+func (f *FilesystemConnector) watchLoop(ctx context.Context, root string) {
+	defer close(f.liveChan)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Printf("Unable to start filesystem watcher: %v", err)
+		return
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(root); err != nil {
+		log.Printf("Unable to watch %s: %v", root, err)
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("Filesystem watcher error: %v", err)
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			info, err := os.Stat(event.Name)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			if err := f.syncFile(event.Name, f.liveChan); err != nil {
+				log.Printf("Unable to sync live update for %s: %v", event.Name, err)
+			}
+		}
+	}
+}
@@ -1,22 +1,25 @@
 package connectors
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"fmt"
 	"log"
 	"net/http"
-	"os"
 	"os/exec"
-	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
+	admin "google.golang.org/api/admin/directory/v1"
 	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 
+	"github.com/verbis-ai/verbis/verbis/connectors/mailbody"
 	"github.com/verbis-ai/verbis/verbis/keychain"
 	"github.com/verbis-ai/verbis/verbis/types"
 )
@@ -31,19 +34,21 @@ func NewGmailConnector(creds types.BuildCredentials, st types.Store) types.Conne
 	}
 }
 
+func init() {
+	types.RegisterConnector(types.ConnectorTypeGmail, NewGmailConnector)
+}
+
 type GmailConnector struct {
 	BaseConnector
 	GoogleJSONCreds string
 }
 
 func (g *GmailConnector) getClient(ctx context.Context, config *oauth2.Config) (*http.Client, error) {
-	// Token from Keychain
-	tok, err := keychain.TokenFromKeychain(g.ID(), g.Type())
+	tokenSource, err := g.TokenSource(config)
 	if err != nil {
 		return nil, err
 	}
-
-	return config.Client(ctx, tok), nil
+	return oauth2.NewClient(ctx, tokenSource), nil
 }
 
 func (g *GmailConnector) requestOauthWeb(config *oauth2.Config) error {
@@ -56,9 +61,22 @@ func (g *GmailConnector) requestOauthWeb(config *oauth2.Config) error {
 	return exec.Command("open", authURL).Start()
 }
 
+// gmailOIDCDiscoveryURL is Google's well-known OpenID Connect discovery
+// document, used by FetchOIDCIdentity to resolve the authenticated
+// account's email and stable sub claim.
+const gmailOIDCDiscoveryURL = "https://accounts.google.com/.well-known/openid-configuration"
+
 var gmailScopes []string = []string{
 	gmail.GmailReadonlyScope,
+	"openid",
 	"https://www.googleapis.com/auth/userinfo.email",
+	// admin.directory.group.readonly lets DirectoryGroups resolve the
+	// signed-in user's Workspace group memberships for document ACLs.
+	// Requesting it is harmless for personal accounts and non-admin
+	// Workspace users too: the consent screen just won't grant it, and
+	// DirectoryGroups falls back gracefully when the resulting API calls
+	// fail.
+	admin.AdminDirectoryGroupReadonlyScope,
 }
 
 func (g *GmailConnector) AuthSetup(ctx context.Context) error {
@@ -85,7 +103,6 @@ func gmailConfigFromJSON(credsBlob string) (*oauth2.Config, error) {
 	return google.ConfigFromJSON([]byte(credsBlob), gmailScopes...)
 }
 
-// TODO: handle token expiries
 func (g *GmailConnector) AuthCallback(ctx context.Context, authCode string) error {
 	config, err := gmailConfigFromJSON(g.GoogleJSONCreds)
 	if err != nil {
@@ -104,26 +121,27 @@ func (g *GmailConnector) AuthCallback(ctx context.Context, authCode string) erro
 		return fmt.Errorf("unable to save token to keychain: %v", err)
 	}
 
-	client := config.Client(ctx, tok)
-	email, err := getUserEmail(client)
-	if err != nil {
-		return fmt.Errorf("unable to get user email: %v", err)
-	}
-	log.Printf("User email: %s", email)
-	g.user = email
-
-	state, err := g.Status(ctx)
+	identity, err := FetchOIDCIdentity(ctx, config.TokenSource(ctx, tok), gmailOIDCDiscoveryURL)
 	if err != nil {
-		return fmt.Errorf("unable to get connector state: %v", err)
+		return fmt.Errorf("unable to resolve user identity: %v", err)
 	}
+	log.Printf("User email: %s", identity.Email)
+	g.user = identity.Email
 
-	state.User = g.User()
-	return g.UpdateConnectorState(ctx, state)
+	return g.MutateState(ctx, func(state *types.ConnectorState) error {
+		state.User = g.User()
+		state.UserKey = identity.Sub
+		return nil
+	})
 }
 
-func (g *GmailConnector) Sync(lastSync time.Time, chunkChan chan types.ChunkSyncResult, errChan chan error) {
+func (g *GmailConnector) Sync(ctx context.Context, lastSync time.Time, chunkChan chan types.ChunkSyncResult, errChan chan error) {
 	defer close(chunkChan)
 
+	// TotalItems is unknown up front: the messages.list API is paged and
+	// doesn't report a total count.
+	g.startProgress(0)
+
 	log.Printf("Starting gmail sync")
 	config, err := gmailConfigFromJSON(g.GoogleJSONCreds)
 	if err != nil {
@@ -131,29 +149,146 @@ func (g *GmailConnector) Sync(lastSync time.Time, chunkChan chan types.ChunkSync
 		return
 	}
 
-	client, err := g.getClient(g.context, config)
+	client, err := g.getClient(ctx, config)
 	if err != nil {
 		errChan <- fmt.Errorf("unable to get client: %v", err)
 		return
 	}
 
-	srv, err := gmail.NewService(g.context, option.WithHTTPClient(client))
+	// A second TokenSource alongside getClient's is cheap: both resolve to
+	// the same shared, cached keychain.DefaultManager entry for this
+	// connector. This one feeds DirectoryGroups for document ACLs.
+	tokenSource, err := g.TokenSource(config)
+	if err != nil {
+		errChan <- fmt.Errorf("unable to get token source: %v", err)
+		return
+	}
+
+	srv, err := gmail.NewService(ctx, option.WithHTTPClient(client))
 	if err != nil {
 		errChan <- fmt.Errorf("unable to retrieve Gmail client: %v", err)
 		return
 	}
 
-	err = g.listEmails(g.context, srv, lastSync, chunkChan)
+	state, err := g.Status(ctx)
 	if err != nil {
-		errChan <- fmt.Errorf("unable to list emails: %v", err)
+		errChan <- fmt.Errorf("unable to get connector state: %v", err)
+		return
+	}
+
+	// Cursor holds the mailbox's Gmail historyId as of the last sync. On
+	// first sync there's nothing to diff against, so fall back to a full
+	// listing and seed the cursor for the next tick.
+	if state.Cursor == "" {
+		err = g.listEmails(ctx, srv, tokenSource, lastSync, chunkChan)
+		if err != nil {
+			errChan <- fmt.Errorf("unable to list emails: %v", err)
+			return
+		}
+		if err := g.seedHistoryCursor(ctx, srv, state); err != nil {
+			errChan <- fmt.Errorf("unable to seed gmail history cursor: %v", err)
+			return
+		}
 		return
 	}
+
+	if err := g.syncHistory(ctx, srv, tokenSource, state, chunkChan); err != nil {
+		errChan <- fmt.Errorf("unable to sync gmail history: %v", err)
+		return
+	}
+}
+
+// seedHistoryCursor stores the mailbox's current historyId on first sync,
+// so the next sync can resume incrementally via syncHistory instead of
+// re-listing the whole mailbox. messages.list doesn't return a historyId,
+// so this requires a separate profile lookup.
+func (g *GmailConnector) seedHistoryCursor(ctx context.Context, srv *gmail.Service, state *types.ConnectorState) error {
+	profile, err := srv.Users.GetProfile("me").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("unable to get gmail profile: %v", err)
+	}
+	state.Cursor = fmt.Sprintf("%d", profile.HistoryId)
+	return g.UpdateConnectorState(ctx, state)
+}
+
+// syncHistory pulls only the messages added since state.Cursor (a Gmail
+// historyId) via users.history.list, instead of re-scanning the mailbox
+// on every sync tick. Gmail expires history records after about a week;
+// if the stored historyId has aged out, Google returns a 404 and we clear
+// the cursor so the next sync falls back to a full listEmails backfill.
+func (g *GmailConnector) syncHistory(ctx context.Context, srv *gmail.Service, tokenSource oauth2.TokenSource, state *types.ConnectorState, chunkChan chan types.ChunkSyncResult) error {
+	historyID := state.Cursor
+	seen := map[string]bool{}
+
+	req := srv.Users.History.List("me").StartHistoryId(historyID).HistoryTypes("messageAdded")
+	err := req.Pages(ctx, func(page *gmail.ListHistoryResponse) error {
+		var wg sync.WaitGroup
+		for _, h := range page.History {
+			for _, added := range h.MessagesAdded {
+				messageID := added.Message.Id
+				if seen[messageID] {
+					continue
+				}
+				seen[messageID] = true
+				wg.Add(1)
+				go func(messageID string) {
+					defer wg.Done()
+					email, err := srv.Users.Messages.Get("me", messageID).Format("full").Do()
+					if err != nil {
+						log.Printf("Unable to retrieve message %s: %v", messageID, err)
+						g.recordProgressError()
+						return
+					}
+					g.processEmail(ctx, srv, tokenSource, email, chunkChan)
+				}(messageID)
+			}
+		}
+		wg.Wait()
+		if page.HistoryId != 0 {
+			historyID = fmt.Sprintf("%d", page.HistoryId)
+		}
+		return nil
+	})
+	if err != nil {
+		if isGmailHistoryExpired(err) {
+			log.Printf("Gmail history cursor expired, clearing it for a full re-sync next tick")
+			state.Cursor = ""
+			return g.UpdateConnectorState(ctx, state)
+		}
+		return fmt.Errorf("unable to list history: %v", err)
+	}
+
+	state.Cursor = historyID
+	return g.UpdateConnectorState(ctx, state)
+}
+
+// isGmailHistoryExpired reports whether err is the 404 Gmail returns when
+// a historyId is older than the mailbox's retention window.
+func isGmailHistoryExpired(err error) bool {
+	gerr, ok := err.(*googleapi.Error)
+	return ok && gerr.Code == http.StatusNotFound
 }
 
-func (g *GmailConnector) processEmail(ctx context.Context, srv *gmail.Service, email *gmail.Message, chunkChan chan types.ChunkSyncResult) {
+// processEmail chunks the email body as its own Document and, for each
+// attachment the AttachmentExtractor registry supports, a separate
+// Document linked to it via AttachmentDocument, so a citation can point
+// at the specific attachment rather than just the email.
+func (g *GmailConnector) processEmail(ctx context.Context, srv *gmail.Service, tokenSource oauth2.TokenSource, email *gmail.Message, chunkChan chan types.ChunkSyncResult) {
 	var content string
+	var htmlContent string
+	haveText := false
+	type attachment struct {
+		filename string
+		text     string
+	}
+	var attachments []attachment
+
 	for _, part := range email.Payload.Parts {
-		if part.MimeType == "text/plain" {
+		// text/plain and text/html are alternative representations of the
+		// same body, not additional content, so only one is kept: plain
+		// text is preferred, and the HTML part is used as a fallback for
+		// HTML-only messages.
+		if part.MimeType == "text/plain" || part.MimeType == "text/html" {
 			data, err := decodeBase64(part.Body.Data)
 			if err != nil {
 				chunkChan <- types.ChunkSyncResult{
@@ -161,25 +296,49 @@ func (g *GmailConnector) processEmail(ctx context.Context, srv *gmail.Service, e
 				}
 				continue
 			}
-			content += data
+			cleaned := mailbody.Clean(data, part.MimeType)
+			if part.MimeType == "text/plain" {
+				content += cleaned
+				haveText = true
+			} else {
+				htmlContent += cleaned
+			}
+			continue
 		}
-		// Process attachments
-		if part.Filename != "" && part.MimeType == "application/pdf" {
-			data, err := downloadAttachment(ctx, srv, g.user, email.Id, part.Body.AttachmentId)
-			if err != nil {
-				chunkChan <- types.ChunkSyncResult{
-					Err: fmt.Errorf("unable to download attachment for file %s: %s", part.Filename, err),
-				}
-				continue
+
+		if part.Filename == "" {
+			continue
+		}
+		data, err := downloadAttachment(ctx, srv, g.user, email.Id, part.Body.AttachmentId)
+		if err != nil {
+			chunkChan <- types.ChunkSyncResult{
+				Err: fmt.Errorf("unable to download attachment for file %s: %s", part.Filename, err),
 			}
-			content += data
+			continue
+		}
+		extraction, err := ExtractAttachment(ctx, part.MimeType, part.Filename, bytes.NewReader(data))
+		if err != nil {
+			chunkChan <- types.ChunkSyncResult{
+				Err: fmt.Errorf("unable to extract attachment %s: %s", part.Filename, err),
+			}
+			continue
+		}
+		if extraction == nil {
+			continue
 		}
+		attachments = append(attachments, attachment{filename: part.Filename, text: extraction.Text})
+	}
+	if !haveText {
+		content += htmlContent
 	}
 
 	receivedAt := time.Unix(email.InternalDate/1000, 0)
 	emailURL := fmt.Sprintf("https://mail.google.com/mail/u/0/#inbox/%s", email.Id)
 	subject := getEmailSubject(email.Payload.Headers)
 
+	groups := DirectoryGroups(ctx, tokenSource, g.User())
+	recipients := emailRecipients(email.Payload.Headers)
+
 	document := types.Document{
 		UniqueID:      email.Id,
 		Name:          subject,
@@ -188,6 +347,7 @@ func (g *GmailConnector) processEmail(ctx context.Context, srv *gmail.Service, e
 		ConnectorType: string(g.Type()),
 		CreatedAt:     receivedAt,
 		UpdatedAt:     receivedAt,
+		ACL:           resolveDocumentACL(g.User(), groups, recipients),
 	}
 
 	err := g.store.DeleteDocumentChunks(ctx, document.UniqueID, g.ID())
@@ -195,10 +355,18 @@ func (g *GmailConnector) processEmail(ctx context.Context, srv *gmail.Service, e
 		log.Printf("Unable to delete chunks for document %s: %v", document.UniqueID, err)
 	}
 
-	emitChunks(subject, content, document, chunkChan)
+	EmitChunks(&g.BaseConnector, subject, content, document, chunkChan)
+
+	for _, a := range attachments {
+		attachmentDoc := AttachmentDocument(document, a.filename)
+		if err := g.store.DeleteDocumentChunks(ctx, attachmentDoc.UniqueID, g.ID()); err != nil {
+			log.Printf("Unable to delete chunks for attachment document %s: %v", attachmentDoc.UniqueID, err)
+		}
+		EmitChunks(&g.BaseConnector, a.filename, a.text, attachmentDoc, chunkChan)
+	}
 }
 
-func (g *GmailConnector) listEmails(ctx context.Context, srv *gmail.Service, lastSync time.Time, chunkChan chan types.ChunkSyncResult) error {
+func (g *GmailConnector) listEmails(ctx context.Context, srv *gmail.Service, tokenSource oauth2.TokenSource, lastSync time.Time, chunkChan chan types.ChunkSyncResult) error {
 	user := "me"
 	query := "in:inbox -category:spam"
 	if !lastSync.IsZero() {
@@ -218,7 +386,7 @@ func (g *GmailConnector) listEmails(ctx context.Context, srv *gmail.Service, las
 					log.Printf("Unable to retrieve message %s: %v", messageID, err)
 					return
 				}
-				g.processEmail(ctx, srv, email, chunkChan)
+				g.processEmail(ctx, srv, tokenSource, email, chunkChan)
 			}(m.Id)
 		}
 		wg.Wait()
@@ -240,6 +408,36 @@ func getEmailSubject(headers []*gmail.MessagePartHeader) string {
 	return "(no subject)"
 }
 
+// emailRecipients pulls every address out of an email's To, Cc, and Bcc
+// headers, for resolveDocumentACL. Headers are comma-separated and may
+// include a display name ("Jane Doe <jane@example.com>"), so each entry
+// is reduced to the bracketed or bare address.
+func emailRecipients(headers []*gmail.MessagePartHeader) []string {
+	var recipients []string
+	for _, h := range headers {
+		if h.Name != "To" && h.Name != "Cc" && h.Name != "Bcc" {
+			continue
+		}
+		for _, addr := range strings.Split(h.Value, ",") {
+			recipients = append(recipients, extractEmailAddress(addr))
+		}
+	}
+	return recipients
+}
+
+// extractEmailAddress strips an optional display name and angle brackets
+// from a single address, e.g. "Jane Doe <jane@example.com>" ->
+// "jane@example.com".
+func extractEmailAddress(addr string) string {
+	addr = strings.TrimSpace(addr)
+	if start := strings.LastIndex(addr, "<"); start != -1 {
+		if end := strings.Index(addr[start:], ">"); end != -1 {
+			return strings.TrimSpace(addr[start+1 : start+end])
+		}
+	}
+	return addr
+}
+
 func decodeBase64(encoded string) (string, error) {
 	decoded, err := base64.URLEncoding.DecodeString(encoded)
 	if err != nil {
@@ -248,44 +446,13 @@ func decodeBase64(encoded string) (string, error) {
 	return string(decoded), nil
 }
 
-func downloadAttachment(ctx context.Context, srv *gmail.Service, userID, messageID, attachmentID string) (string, error) {
+// downloadAttachment fetches and base64-decodes an attachment's raw bytes.
+// Writing it to a temp file, if its extractor needs one, is
+// ExtractAttachment's job, not this function's.
+func downloadAttachment(ctx context.Context, srv *gmail.Service, userID, messageID, attachmentID string) ([]byte, error) {
 	att, err := srv.Users.Messages.Attachments.Get(userID, messageID, attachmentID).Context(ctx).Do()
 	if err != nil {
-		return "", err
-	}
-	data, err := base64.URLEncoding.DecodeString(att.Data)
-	if err != nil {
-		return "", err
-	}
-
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get user home directory: %v", err)
-	}
-
-	tempDir := filepath.Join(homeDir, ".verbis", "tmp")
-	err = os.MkdirAll(tempDir, os.ModePerm)
-	if err != nil {
-		return "", fmt.Errorf("failed to create temporary directory: %v", err)
-	}
-
-	const maxFileNameLength = 255
-	fileName := attachmentID
-	if len(fileName) > maxFileNameLength {
-		fileName = fileName[:maxFileNameLength]
-	}
-
-	tempFilePath := filepath.Join(tempDir, fileName)
-	outFile, err := os.Create(tempFilePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to create temporary file: %v", err)
-	}
-	defer outFile.Close()
-
-	_, err = outFile.Write(data)
-	if err != nil {
-		return "", fmt.Errorf("failed to write file to disk: %v", err)
+		return nil, err
 	}
-
-	return tempFilePath, nil
+	return base64.URLEncoding.DecodeString(att.Data)
 }
@@ -3,6 +3,7 @@ package connectors
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -13,14 +14,16 @@ import (
 	"sync"
 	"time"
 
-	"github.com/google/uuid"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
+	admin "google.golang.org/api/admin/directory/v1"
 	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 
+	"github.com/verbis-ai/verbis/verbis/chunker"
+	"github.com/verbis-ai/verbis/verbis/extract"
 	"github.com/verbis-ai/verbis/verbis/keychain"
-	"github.com/verbis-ai/verbis/verbis/store"
 	"github.com/verbis-ai/verbis/verbis/types"
 	"github.com/verbis-ai/verbis/verbis/util"
 )
@@ -29,50 +32,43 @@ const (
 	googleCredentialFile = "credentials.json"
 )
 
-func NewGoogleDriveConnector() types.Connector {
+func NewGoogleDriveConnector(creds types.BuildCredentials, st types.Store) types.Connector {
 	return &GoogleDriveConnector{
-		id:   "",
-		user: "",
+		BaseConnector: BaseConnector{
+			connectorType: types.ConnectorTypeGoogleDrive,
+			store:         st,
+		},
 	}
 }
 
-type GoogleDriveConnector struct {
-	id   string
-	user string
-}
-
-func (g *GoogleDriveConnector) ID() string {
-	return g.id
+func init() {
+	types.RegisterConnector(types.ConnectorTypeGoogleDrive, NewGoogleDriveConnector)
 }
 
-func (g *GoogleDriveConnector) User() string {
-	return g.user
-}
-
-func (g *GoogleDriveConnector) Type() types.ConnectorType {
-	return types.ConnectorTypeGoogleDrive
-}
-
-func (g *GoogleDriveConnector) Status(ctx context.Context) (*types.ConnectorState, error) {
-	state, err := store.GetConnectorState(ctx, store.GetWeaviateClient(), g.ID())
-	if err != nil {
-		return nil, fmt.Errorf("failed to get connector state: %v", err)
-	}
-
-	if state == nil {
-		// No stored state, only happens if sync() is called before init()
-		return nil, fmt.Errorf("connector state not found")
-	}
-	return state, nil
+type GoogleDriveConnector struct {
+	BaseConnector
+
+	// progressFunc, if set, is called after each downloaded chunk of a
+	// large binary file so callers can render per-file download progress.
+	progressFunc types.DownloadProgressFunc
+
+	// completedRangesMu guards completedRanges, which tracks which byte
+	// ranges of an in-progress file download have already landed on disk,
+	// keyed by file ID then by range start. A sync cancelled mid-download
+	// (e.g. ctx cancellation in connectorSync) leaves the partial temp
+	// file and this map behind, so the next SyncNow's downloadFile call
+	// resumes by skipping ranges already marked complete instead of
+	// restarting the file from scratch.
+	completedRangesMu sync.Mutex
+	completedRanges   map[string]map[int64]bool
 }
 
 func (g *GoogleDriveConnector) getClient(ctx context.Context, config *oauth2.Config) (*http.Client, error) {
-	// Token from Keychain
-	tok, err := keychain.TokenFromKeychain(g.ID(), g.Type())
+	source, err := g.TokenSource(config)
 	if err != nil {
 		return nil, err
 	}
-	return config.Client(ctx, tok), nil
+	return oauth2.NewClient(ctx, source), nil
 }
 
 func (g *GoogleDriveConnector) requestOauthWeb(config *oauth2.Config) error {
@@ -89,46 +85,13 @@ var driveScopes []string = []string{
 	drive.DriveMetadataReadonlyScope,
 	drive.DriveReadonlyScope,
 	"https://www.googleapis.com/auth/userinfo.email",
-}
-
-func (g *GoogleDriveConnector) Init(ctx context.Context, connectorID string) error {
-	if connectorID != "" {
-		// connectorID is passed only when Init is called to re-create the
-		// connector from a state object during initial load
-		g.id = connectorID
-	}
-	if g.id == "" {
-		g.id = uuid.New().String()
-	}
-
-	log.Printf("Initializing connector type: %s id: %s", g.Type(), g.ID())
-	state, err := store.GetConnectorState(ctx, store.GetWeaviateClient(), g.ID())
-	if err != nil {
-		return fmt.Errorf("failed to get connector state: %v", err)
-	}
-
-	if state == nil {
-		state = &types.ConnectorState{}
-	}
-
-	state.ConnectorID = g.ID()
-	state.Syncing = false
-	// state.User is unknown until auth is complete
-	state.ConnectorType = string(g.Type())
-	token, err := keychain.TokenFromKeychain(g.ID(), g.Type())
-	state.AuthValid = (err == nil && token != nil) // TODO: check for expiry of refresh token
-	log.Printf("AuthValid: %v", state.AuthValid)
-
-	err = store.UpdateConnectorState(ctx, store.GetWeaviateClient(), state)
-	if err != nil {
-		return fmt.Errorf("failed to set connector state: %v", err)
-	}
-	log.Printf("Initialized connector type %s: %s", g.Type(), g.ID())
-	return nil
-}
-
-func (g *GoogleDriveConnector) UpdateConnectorState(ctx context.Context, state *types.ConnectorState) error {
-	return store.UpdateConnectorState(ctx, store.GetWeaviateClient(), state)
+	// admin.directory.group.readonly lets DirectoryGroups resolve the
+	// signed-in user's Workspace group memberships for document ACLs.
+	// Requesting it is harmless for personal accounts and non-admin
+	// Workspace users too: the consent screen just won't grant it, and
+	// DirectoryGroups falls back gracefully when the resulting API calls
+	// fail.
+	admin.AdminDirectoryGroupReadonlyScope,
 }
 
 func driveConfigFromJSON() (*oauth2.Config, error) {
@@ -148,13 +111,12 @@ func (g *GoogleDriveConnector) AuthSetup(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("unable to get google config: %s", err)
 	}
-	_, err = keychain.TokenFromKeychain(g.ID(), g.Type())
-	if err == nil {
-		// TODO: check for expiry of refresh token
-		log.Print("Token found in keychain.")
+	tok, err := keychain.TokenFromKeychain(g.ID(), g.Type())
+	if err == nil && keychain.StateForToken(tok) != keychain.TokenStateInvalid {
+		log.Print("Valid token found in keychain.")
 		return nil
 	}
-	log.Print("No token found in keychain. Getting token from web.")
+	log.Print("No valid token found in keychain. Getting token from web.")
 	err = g.requestOauthWeb(config)
 	if err != nil {
 		log.Printf("Unable to request token from web: %v", err)
@@ -189,13 +151,32 @@ func (g *GoogleDriveConnector) AuthCallback(ctx context.Context, authCode string
 	log.Printf("User email: %s", email)
 	g.user = email
 
-	state, err := g.Status(ctx)
+	return g.MutateState(ctx, func(state *types.ConnectorState) error {
+		state.User = g.User()
+		return nil
+	})
+}
+
+// Status overrides BaseConnector.Status to refresh AuthValid/AuthState
+// from the token's actual remaining lifetime, rather than whatever was
+// last written to the store at Init or AuthCallback time, so the UI can
+// prompt for re-auth before an expiring token causes a sync to fail.
+func (g *GoogleDriveConnector) Status(ctx context.Context) (*types.ConnectorState, error) {
+	state, err := g.BaseConnector.Status(ctx)
 	if err != nil {
-		return fmt.Errorf("unable to get connector state: %v", err)
+		return nil, err
 	}
 
-	state.User = g.User()
-	return g.UpdateConnectorState(ctx, state)
+	tok, tokErr := keychain.TokenFromKeychain(g.ID(), g.Type())
+	authState := keychain.TokenStateInvalid
+	if tokErr == nil {
+		authState = keychain.StateForToken(tok)
+	}
+
+	state.AuthState = string(authState)
+	state.AuthValid = authState != keychain.TokenStateInvalid
+
+	return state, nil
 }
 
 func getUserEmail(client *http.Client) (string, error) {
@@ -225,6 +206,10 @@ func (g *GoogleDriveConnector) Sync(ctx context.Context, lastSync time.Time, chu
 	defer close(errChan)
 	defer close(chunkChan)
 
+	// TotalItems is unknown up front: the Changes API is paged and
+	// doesn't report a total count.
+	g.startProgress(0)
+
 	config, err := driveConfigFromJSON()
 	if err != nil {
 		errChan <- fmt.Errorf("unable to get google config: %s", err)
@@ -237,36 +222,60 @@ func (g *GoogleDriveConnector) Sync(ctx context.Context, lastSync time.Time, chu
 		return
 	}
 
+	// A second TokenSource alongside getClient's is cheap: both resolve to
+	// the same shared, cached keychain.DefaultManager entry for this
+	// connector. This one feeds DirectoryGroups for document ACLs.
+	tokenSource, err := g.TokenSource(config)
+	if err != nil {
+		errChan <- fmt.Errorf("unable to get token source: %v", err)
+		return
+	}
+
 	srv, err := drive.NewService(ctx, option.WithHTTPClient(client))
 	if err != nil {
 		errChan <- fmt.Errorf("unable to retrieve Drive client: %v", err)
 		return
 	}
 
-	err = g.listFiles(ctx, srv, lastSync, chunkChan, errChunkChan)
+	err = g.syncChanges(ctx, srv, tokenSource, lastSync, chunkChan, errChunkChan)
 	if err != nil {
-		errChan <- fmt.Errorf("unable to list files: %v", err)
+		errChan <- fmt.Errorf("unable to sync changes: %v", err)
 		return
 	}
 }
 
-func (g *GoogleDriveConnector) processFile(ctx context.Context, service *drive.Service, file *drive.File, chunkChan chan types.Chunk, errChunkChan chan error) {
-	var content string
-	var err error
+func (g *GoogleDriveConnector) processFile(ctx context.Context, service *drive.Service, tokenSource oauth2.TokenSource, file *drive.File, chunkChan chan types.Chunk, errChunkChan chan error) {
+	var chunkTexts []string
+	var contentLen int
 	if file.MimeType == "application/vnd.google-apps.document" {
-		content, err = exportFile(service, file.Id, "text/plain")
+		content, err := exportFile(service, file.Id, "text/plain")
+		if err != nil {
+			g.recordProgressError()
+			errChunkChan <- fmt.Errorf("unable to export file: %v", err)
+			return
+		}
+		contentLen = len(content)
+		chunkTexts = chunkPlainText(content)
 	} else if file.MimeType == "application/vnd.google-apps.spreadsheet" {
-		content, err = exportFile(service, file.Id, "application/csv")
+		content, err := exportFile(service, file.Id, "application/csv")
+		if err != nil {
+			g.recordProgressError()
+			errChunkChan <- fmt.Errorf("unable to export file: %v", err)
+			return
+		}
+		contentLen = len(content)
+		chunkTexts = chunkPlainText(content)
 	} else {
-		content, err = downloadAndParseBinaryFile(ctx, service, file)
+		blocks, err := downloadAndParseBinaryFile(ctx, g, service, file)
 		if err != nil {
+			g.recordProgressError()
 			errChunkChan <- fmt.Errorf("unable to process binary file %s: %v", file.Name, err)
 			return
 		}
-	}
-	if err != nil {
-		errChunkChan <- fmt.Errorf("unable to export file: %v", err)
-		return
+		for _, b := range blocks {
+			contentLen += len(b.Text)
+		}
+		chunkTexts = extract.ChunkBlocks(blocks, MaxChunkSize)
 	}
 
 	log.Printf("Document: %s, %s, %s", file.Name, file.CreatedTime, file.ModifiedTime)
@@ -282,7 +291,18 @@ func (g *GoogleDriveConnector) processFile(ctx context.Context, service *drive.S
 		updatedAt = time.Now()
 	}
 
-	numChunks := 0
+	groups := DirectoryGroups(ctx, tokenSource, g.User())
+	var grantees []string
+	for _, p := range file.Permissions {
+		if p.Type != "user" && p.Type != "group" {
+			// Skip "domain" and "anyone" grants: there's no single email
+			// address to record for either, and a domain-wide share is
+			// already covered by whatever groups the requester belongs to.
+			continue
+		}
+		grantees = append(grantees, p.EmailAddress)
+	}
+
 	document := types.Document{
 		UniqueID:    file.Id,
 		Name:        file.Name,
@@ -290,90 +310,232 @@ func (g *GoogleDriveConnector) processFile(ctx context.Context, service *drive.S
 		ConnectorID: g.ID(),
 		CreatedAt:   createdAt,
 		UpdatedAt:   updatedAt,
+		ACL:         resolveDocumentACL(g.User(), groups, grantees),
+	}
+
+	if unchanged, err := g.documentUnchanged(ctx, document.UniqueID, chunkTexts); err != nil {
+		log.Printf("Unable to diff existing chunks for document %s: %v", document.UniqueID, err)
+	} else if unchanged {
+		log.Printf("Document %s unchanged since last sync, skipping re-embedding", file.Name)
+		g.advanceProgress(file.Name, int64(contentLen))
+		return
 	}
 
 	// TODO: ideally this should live at the top level but we need to refactor the syncer first
-	err = store.DeleteDocumentChunks(ctx, store.GetWeaviateClient(), document.UniqueID, g.ID())
+	err = g.store.DeleteDocumentChunks(ctx, document.UniqueID, g.ID())
 	if err != nil {
 		// Not a fatal error, just log it and leave the old chunks behind
 		log.Printf("Unable to delete chunks for document %s: %v", document.UniqueID, err)
 	}
 
-	// Split contents into chunks of MaxChunkSize characters
-	for i := 0; i < len(content); i += MaxChunkSize {
-		end := i + MaxChunkSize
-		if end > len(content) {
-			end = len(content)
-		}
-
-		// TODO: add chunk overlaps
+	parentHash := util.HashText(file.Id)
+	for i, text := range chunkTexts {
 		chunk := types.Chunk{
-			Text:     content[i:end],
-			Document: document,
+			Text:       text,
+			Document:   document,
+			ChunkIndex: i,
+			ParentHash: parentHash,
 		}
-		numChunks += 1
-		log.Printf("Processing chunk %d of document %s", numChunks, file.Name)
+		log.Printf("Processing chunk %d of document %s", i+1, file.Name)
 		chunkChan <- chunk
 	}
+	g.advanceProgress(file.Name, int64(contentLen))
 }
 
-func (g *GoogleDriveConnector) listFiles(ctx context.Context, service *drive.Service, lastSync time.Time, chunkChan chan types.Chunk, errChunkChan chan error) error {
-	pageToken := ""
-	for {
-		q := service.Files.List().
-			PageSize(10).
-			Fields("nextPageToken, files(id, name, webViewLink, createdTime, modifiedTime, mimeType)").
-			OrderBy("modifiedTime desc").Context(ctx)
-		if !lastSync.IsZero() {
-			q = q.Q("modifiedTime > '" + lastSync.Format(time.RFC3339) + "'")
+// documentUnchanged reports whether uniqueID's currently stored chunks have
+// exactly the same content hashes as newTexts, in which case processFile
+// can skip its usual delete-then-re-embed cycle entirely: re-running sync
+// on a document whose content hasn't changed shouldn't cost an embedding
+// call or a chunk-store round trip, and the delete+reinsert churn it would
+// otherwise do duplicates no work since chunkAdder's own ChunkHashExists
+// check would immediately skip re-adding every one of those chunks anyway.
+func (g *GoogleDriveConnector) documentUnchanged(ctx context.Context, uniqueID string, newTexts []string) (bool, error) {
+	existingHashes, err := g.store.GetDocumentChunkHashes(ctx, uniqueID)
+	if err != nil {
+		return false, fmt.Errorf("unable to get existing chunk hashes: %v", err)
+	}
+	if len(existingHashes) != len(newTexts) {
+		return false, nil
+	}
+
+	existing := map[string]int{}
+	for _, h := range existingHashes {
+		existing[h]++
+	}
+	for _, text := range newTexts {
+		h := util.HashText(util.CleanChunk(text))
+		if existing[h] == 0 {
+			return false, nil
 		}
-		if pageToken != "" {
-			q = q.PageToken(pageToken)
+		existing[h]--
+	}
+	return true, nil
+}
+
+// chunkPlainText splits plain-text content (e.g. an exported Google Doc
+// or Sheet, which carries no block structure) along semantic boundaries.
+// Binary files go through extract.ChunkBlocks instead, which respects the
+// structural boundaries the extractor reports.
+func chunkPlainText(content string) []string {
+	chunks := recursiveChunker.Chunk(content, chunker.Options{
+		TargetTokens:  MaxChunkSize,
+		OverlapTokens: int(float64(MaxChunkSize) * ChunkOverlap),
+	})
+	texts := make([]string, len(chunks))
+	for i, c := range chunks {
+		texts[i] = c.Text
+	}
+	return texts
+}
+
+// driveChangeFields is shared between changes.list pages so we only ever
+// pull the fields processFile and processChange need.
+const driveChangeFields = "nextPageToken, newStartPageToken, changes(fileId, removed, file(id, name, webViewLink, createdTime, modifiedTime, mimeType, trashed, size, permissions(emailAddress,type)))"
+
+// backfillExisting indexes files already in Drive before the connector
+// starts following the Changes API, using lastSync (if non-zero) to only
+// pull files modified since the previous fixed-interval poll. This only
+// runs once, on the sync where the Changes page token is first seeded.
+func (g *GoogleDriveConnector) backfillExisting(ctx context.Context, service *drive.Service, tokenSource oauth2.TokenSource, lastSync time.Time, chunkChan chan types.Chunk, errChunkChan chan error) error {
+	q := service.Files.List().
+		Fields("nextPageToken, files(id, name, webViewLink, createdTime, modifiedTime, mimeType, trashed, size, permissions(emailAddress,type))").
+		OrderBy("modifiedTime desc").
+		Context(ctx)
+	if !lastSync.IsZero() {
+		q = q.Q("modifiedTime > '" + lastSync.Format(time.RFC3339) + "'")
+	}
+
+	return q.Pages(ctx, func(page *drive.FileList) error {
+		wg := sync.WaitGroup{}
+		for _, file := range page.Files {
+			if file.Trashed {
+				continue
+			}
+			wg.Add(1)
+			go func(f *drive.File) {
+				defer wg.Done()
+				g.processFile(ctx, service, tokenSource, f, chunkChan, errChunkChan)
+			}(file)
+		}
+		wg.Wait()
+		return nil
+	})
+}
+
+// syncChanges walks the Drive Changes API starting from the pageToken
+// persisted on the connector state, so that renames, deletions, re-parents
+// and permission changes are all reflected without re-scanning the entire
+// corpus. On first sync (empty Cursor) it backfills via backfillExisting
+// using lastSync as a fallback filter, then seeds the page token via
+// changes.getStartPageToken so later syncs only see changes from this
+// point on.
+func (g *GoogleDriveConnector) syncChanges(ctx context.Context, service *drive.Service, tokenSource oauth2.TokenSource, lastSync time.Time, chunkChan chan types.Chunk, errChunkChan chan error) error {
+	state, err := g.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get connector state: %v", err)
+	}
+
+	pageToken := state.Cursor
+	if pageToken == "" {
+		if err := g.backfillExisting(ctx, service, tokenSource, lastSync, chunkChan, errChunkChan); err != nil {
+			return fmt.Errorf("unable to backfill existing files: %v", err)
 		}
-		r, err := q.Do()
+
+		startToken, err := service.Changes.GetStartPageToken().Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("unable to get start page token: %v", err)
+		}
+		pageToken = startToken.StartPageToken
+	}
+
+	for {
+		r, err := service.Changes.List(pageToken).
+			IncludeRemoved(true).
+			Fields(driveChangeFields).
+			Context(ctx).
+			Do()
 		if err != nil {
-			return fmt.Errorf("unable to retrieve files: %v", err)
+			return fmt.Errorf("unable to list changes: %v", err)
 		}
 
-		// Max parallelism is number of files per page (10)
+		// Max parallelism is number of changes per page
 		wg := sync.WaitGroup{}
-		for _, file := range r.Files {
+		for _, change := range r.Changes {
 			wg.Add(1)
-			go func(f *drive.File) {
+			go func(c *drive.Change) {
 				defer wg.Done()
-				g.processFile(ctx, service, f, chunkChan, errChunkChan)
-			}(file)
+				g.processChange(ctx, service, tokenSource, c, chunkChan, errChunkChan)
+			}(change)
 		}
 		wg.Wait()
 
-		pageToken = r.NextPageToken
-		if pageToken == "" {
+		if r.NewStartPageToken != "" {
+			pageToken = r.NewStartPageToken
+		} else {
+			pageToken = r.NextPageToken
+		}
+		state.Cursor = pageToken
+		if err := g.UpdateConnectorState(ctx, state); err != nil {
+			log.Printf("Unable to persist drive change cursor: %v", err)
+		}
+
+		if r.NextPageToken == "" {
 			break
 		}
 	}
 	return nil
 }
 
+func (g *GoogleDriveConnector) processChange(ctx context.Context, service *drive.Service, tokenSource oauth2.TokenSource, change *drive.Change, chunkChan chan types.Chunk, errChunkChan chan error) {
+	if change.Removed || (change.File != nil && change.File.Trashed) {
+		err := g.store.DeleteDocumentChunks(ctx, change.FileId, g.ID())
+		if err != nil {
+			g.recordProgressError()
+			errChunkChan <- fmt.Errorf("unable to delete chunks for removed file %s: %v", change.FileId, err)
+			return
+		}
+		g.advanceProgress(change.FileId, 0)
+		return
+	}
+	if change.File == nil {
+		// Changes to things other than files, e.g. shared drives, with no
+		// file payload to index.
+		return
+	}
+	g.processFile(ctx, service, tokenSource, change.File, chunkChan, errChunkChan)
+}
+
 func exportFile(service *drive.Service, fileId string, mimeType string) (string, error) {
 	resp, err := service.Files.Export(fileId, mimeType).Download()
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
-	}
-	return string(data), nil
+	// Export has no Range-request support, so it can't be resumed like
+	// downloadFile's ranged chunks; readAllStreaming at least avoids
+	// pulling the whole response into memory in one shot.
+	return readAllStreaming(resp.Body)
 }
 
-func downloadFile(service *drive.Service, fileId string) (string, error) {
-	resp, err := service.Files.Get(fileId).Download()
-	if err != nil {
-		return "", fmt.Errorf("failed to download file: %v", err)
-	}
-	defer resp.Body.Close()
+const (
+	// downloadChunkSize is the size of each ranged GET issued against a
+	// Drive file, matching the GCS resumable-upload chunking convention of
+	// 20*256KiB.
+	downloadChunkSize = 20 * 256 * 1024
+	// downloadMaxConcurrentChunks bounds how many ranged GETs for a single
+	// file are in flight at once.
+	downloadMaxConcurrentChunks = 4
+	// downloadMaxTries is the number of attempts (including the first) made
+	// for each chunk before giving up.
+	downloadMaxTries = 5
+)
 
+// downloadFile fetches a Drive file into a temp file using ranged GET
+// requests issued concurrently in downloadChunkSize pieces, each retried
+// with exponential backoff on transient errors. This avoids buffering
+// multi-GB files in memory and lets callers track progress via
+// progressFunc, unlike a single Files.Get(...).Download() call.
+func (g *GoogleDriveConnector) downloadFile(ctx context.Context, service *drive.Service, file *drive.File) (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get user home directory: %v", err)
@@ -385,49 +547,208 @@ func downloadFile(service *drive.Service, fileId string) (string, error) {
 		return "", fmt.Errorf("failed to create temporary directory: %v", err)
 	}
 
-	tempFilePath := filepath.Join(tempDir, fileId)
-	outFile, err := os.Create(tempFilePath)
+	tempFilePath := filepath.Join(tempDir, file.Id)
+	// Deliberately not O_TRUNC: if a previous call for this same file was
+	// interrupted partway through, its bytes (and the matching entries in
+	// g.completedRanges) are still good and shouldn't be discarded.
+	outFile, err := os.OpenFile(tempFilePath, os.O_CREATE|os.O_RDWR, 0644)
 	if err != nil {
 		return "", fmt.Errorf("failed to create temporary file: %v", err)
 	}
 	defer outFile.Close()
 
-	_, err = io.Copy(outFile, resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to write file to disk: %v", err)
+	totalSize := file.Size
+	if totalSize <= 0 {
+		// Size unknown (e.g. some shortcuts): fall back to a plain
+		// single-shot download rather than guessing at ranges.
+		resp, err := service.Files.Get(file.Id).Context(ctx).Download()
+		if err != nil {
+			return "", fmt.Errorf("failed to download file: %v", err)
+		}
+		defer resp.Body.Close()
+		counting := util.NewCountingReader(resp.Body)
+		if _, err := io.Copy(outFile, counting); err != nil {
+			return "", fmt.Errorf("failed to write file to disk: %v", err)
+		}
+		written := counting.Count()
+		if g.progressFunc != nil {
+			g.progressFunc(file.Id, file.Name, written, written)
+		}
+		return tempFilePath, nil
+	}
+
+	numChunks := (totalSize + downloadChunkSize - 1) / downloadChunkSize
+	sem := make(chan struct{}, downloadMaxConcurrentChunks)
+	errs := make(chan error, numChunks)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var downloaded int64
+
+	for start := int64(0); start < totalSize; start += downloadChunkSize {
+		end := start + downloadChunkSize - 1
+		if end >= totalSize {
+			end = totalSize - 1
+		}
+
+		if g.rangeCompleted(file.Id, start) {
+			mu.Lock()
+			downloaded += end - start + 1
+			done := downloaded
+			mu.Unlock()
+			if g.progressFunc != nil {
+				g.progressFunc(file.Id, file.Name, done, totalSize)
+			}
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(start, end int64) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			data, err := downloadRangeWithRetry(ctx, service, file.Id, start, end)
+			if err != nil {
+				errs <- fmt.Errorf("failed to download bytes %d-%d: %v", start, end, err)
+				return
+			}
+			if _, err := outFile.WriteAt(data, start); err != nil {
+				errs <- fmt.Errorf("failed to write bytes %d-%d to disk: %v", start, end, err)
+				return
+			}
+			g.markRangeCompleted(file.Id, start)
+
+			mu.Lock()
+			downloaded += int64(len(data))
+			done := downloaded
+			mu.Unlock()
+			if g.progressFunc != nil {
+				g.progressFunc(file.Id, file.Name, done, totalSize)
+			}
+		}(start, end)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			return "", err
+		}
 	}
 
+	// Fully downloaded: forget the completed-ranges bookkeeping for this
+	// file so a future re-download (e.g. the file changed) starts clean.
+	g.clearCompletedRanges(file.Id)
+
 	return tempFilePath, nil
 }
 
-func downloadAndParseBinaryFile(ctx context.Context, service *drive.Service, file *drive.File) (string, error) {
-	_, ok := SupportedMimeTypes[file.MimeType]
-	if !ok {
+// rangeCompleted reports whether the byte range starting at start for
+// fileId was already downloaded by a previous, since-interrupted call to
+// downloadFile in this process.
+func (g *GoogleDriveConnector) rangeCompleted(fileId string, start int64) bool {
+	g.completedRangesMu.Lock()
+	defer g.completedRangesMu.Unlock()
+	return g.completedRanges[fileId][start]
+}
+
+func (g *GoogleDriveConnector) markRangeCompleted(fileId string, start int64) {
+	g.completedRangesMu.Lock()
+	defer g.completedRangesMu.Unlock()
+	if g.completedRanges == nil {
+		g.completedRanges = map[string]map[int64]bool{}
+	}
+	if g.completedRanges[fileId] == nil {
+		g.completedRanges[fileId] = map[int64]bool{}
+	}
+	g.completedRanges[fileId][start] = true
+}
+
+func (g *GoogleDriveConnector) clearCompletedRanges(fileId string) {
+	g.completedRangesMu.Lock()
+	defer g.completedRangesMu.Unlock()
+	delete(g.completedRanges, fileId)
+}
+
+// downloadRangeWithRetry downloads a single byte range, retrying with
+// exponential backoff on 5xx responses and transient network errors.
+func downloadRangeWithRetry(ctx context.Context, service *drive.Service, fileId string, start, end int64) ([]byte, error) {
+	var lastErr error
+	for attempt := 0; attempt < downloadMaxTries; attempt++ {
+		if attempt > 0 {
+			backoff := time.Duration(1<<uint(attempt-1)) * time.Second
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+			}
+			log.Printf("Retrying download of bytes %d-%d of file %s (attempt %d/%d)", start, end, fileId, attempt+1, downloadMaxTries)
+		}
+
+		data, err := downloadRange(ctx, service, fileId, start, end)
+		if err == nil {
+			return data, nil
+		}
+		lastErr = err
+		if !isRetryableDownloadError(err) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("exceeded %d retries: %v", downloadMaxTries, lastErr)
+}
+
+func downloadRange(ctx context.Context, service *drive.Service, fileId string, start, end int64) ([]byte, error) {
+	call := service.Files.Get(fileId).Context(ctx)
+	call.Header().Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	resp, err := call.Download()
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return nil, fmt.Errorf("server error: status %s", resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func isRetryableDownloadError(err error) bool {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code >= 500 && apiErr.Code < 600
+	}
+	// Network-level errors (timeouts, connection resets) aren't
+	// googleapi.Errors and are generally worth retrying.
+	return true
+}
+
+func downloadAndParseBinaryFile(ctx context.Context, g *GoogleDriveConnector, service *drive.Service, file *drive.File) ([]extract.ExtractedBlock, error) {
+	if !isSupportedMimeType(file.MimeType) {
 		log.Printf("Unsupported MIME type: %s", file.MimeType)
-		return "", nil
+		return nil, nil
 	}
 	log.Printf("Processing binary file: %s", file.Name)
 
-	tempFilePath, err := downloadFile(service, file.Id)
+	tempFilePath, err := g.downloadFile(ctx, service, file)
 	if err != nil {
-		return "", fmt.Errorf("failed to download file: %v", err)
+		return nil, fmt.Errorf("failed to download file: %v", err)
 	}
 	log.Printf("Finished downloading binary file: %s", file.Name)
 
-	request := &ParseRequest{
-		Type: file.MimeType,
-		Path: tempFilePath,
-	}
-	content, err1 := ParseBinaryFile(ctx, request)
+	blocks, err1 := extractBlocks(ctx, file.MimeType, tempFilePath)
 	err2 := os.Remove(tempFilePath) // Delete the file after processing
 	log.Printf("Finished parsing binary file %s", file.Name)
 
 	if err1 != nil {
-		return "", fmt.Errorf("failed to parse binary file: %v", err)
+		return nil, fmt.Errorf("failed to parse binary file: %v", err1)
 	}
 	if err2 != nil {
-		log.Printf("Error deleting file %s: %v", tempFilePath, err)
+		log.Printf("Error deleting file %s: %v", tempFilePath, err2)
 	}
 
-	return content, nil
+	return blocks, nil
 }
@@ -0,0 +1,325 @@
+package connectors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/client"
+	"github.com/emersion/go-message/mail"
+	"github.com/emersion/go-sasl"
+
+	"github.com/verbis-ai/verbis/verbis/connectors/mailbody"
+	"github.com/verbis-ai/verbis/verbis/keychain"
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+// imapMailbox is the mailbox IMAPConnector syncs. Every server it targets
+// exposes INBOX; a future request could make this configurable per
+// connector if subfolder sync is needed.
+const imapMailbox = "INBOX"
+
+func NewIMAPConnector(creds types.BuildCredentials, st types.Store) types.Connector {
+	return &IMAPConnector{
+		BaseConnector: BaseConnector{
+			connectorType: types.ConnectorTypeIMAP,
+			store:         st,
+		},
+	}
+}
+
+func init() {
+	types.RegisterConnector(types.ConnectorTypeIMAP, NewIMAPConnector)
+}
+
+// IMAPConnector syncs mail from any IMAP server (Fastmail, iCloud,
+// self-hosted, Proton Bridge, ...) rather than a vendor-specific API.
+// Unlike the OAuth connectors it has no app-level client ID/secret: its
+// per-mailbox host, port, and credentials live entirely in
+// keychain.IMAPCredentials.
+type IMAPConnector struct {
+	BaseConnector
+}
+
+// Init mirrors BaseConnector.Init, except auth validity is determined
+// from keychain.IMAPCredentials rather than an OAuth token, since IMAP
+// connectors don't use one.
+func (i *IMAPConnector) Init(ctx context.Context, connectorID string) error {
+	if err := i.BaseConnector.Init(ctx, connectorID); err != nil {
+		return err
+	}
+
+	state, err := i.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connector state: %v", err)
+	}
+
+	creds, err := keychain.IMAPCredentialsFromKeychain(i.ID())
+	authValid := err == nil && creds.Host != ""
+	state.AuthState = string(keychain.TokenStateInvalid)
+	state.AuthValid = authValid
+	if authValid {
+		state.AuthState = string(keychain.TokenStateValid)
+		state.User = creds.Username
+		i.user = creds.Username
+	}
+
+	return i.UpdateConnectorState(ctx, state)
+}
+
+// AuthSetup has nothing to open a browser to: there's no OAuth redirect
+// for a mailbox's own server, so it just reports whether credentials are
+// already in the keychain. The actual credentials arrive via AuthCallback.
+func (i *IMAPConnector) AuthSetup(ctx context.Context) error {
+	if _, err := keychain.IMAPCredentialsFromKeychain(i.ID()); err == nil {
+		log.Print("IMAP credentials found in keychain.")
+		return nil
+	}
+	log.Print("No IMAP credentials found in keychain; waiting for AuthCallback.")
+	return nil
+}
+
+// AuthCallback takes the place of an OAuth code exchange: credsJSON is a
+// JSON-encoded keychain.IMAPCredentials blob supplied directly by the
+// caller (there's no redirect to exchange a code through). The
+// credentials are verified with a real connection attempt before being
+// saved, so a typo'd password or host doesn't get persisted as "valid".
+func (i *IMAPConnector) AuthCallback(ctx context.Context, credsJSON string) error {
+	var creds keychain.IMAPCredentials
+	if err := json.Unmarshal([]byte(credsJSON), &creds); err != nil {
+		return fmt.Errorf("unable to parse IMAP credentials: %v", err)
+	}
+
+	c, err := dialIMAP(&creds)
+	if err != nil {
+		return fmt.Errorf("unable to verify IMAP credentials: %v", err)
+	}
+	c.Logout()
+
+	if err := keychain.SaveIMAPCredentialsToKeychain(&creds, i.ID()); err != nil {
+		return fmt.Errorf("unable to save IMAP credentials: %v", err)
+	}
+	i.user = creds.Username
+
+	return i.MutateState(ctx, func(state *types.ConnectorState) error {
+		state.User = i.User()
+		return nil
+	})
+}
+
+// dialIMAP connects to creds.Host/Port over TLS and authenticates using
+// the mechanism it specifies, falling back to plain username/password
+// login for servers that don't advertise SASL at all.
+func dialIMAP(creds *keychain.IMAPCredentials) (*client.Client, error) {
+	addr := fmt.Sprintf("%s:%d", creds.Host, creds.Port)
+	c, err := client.DialTLS(addr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to dial %s: %v", addr, err)
+	}
+
+	var authErr error
+	switch creds.Mechanism {
+	case "XOAUTH2":
+		authErr = c.Authenticate(sasl.NewXoauth2Client(creds.Username, creds.Secret))
+	case "OAUTHBEARER":
+		authErr = c.Authenticate(sasl.NewOAuthBearerClient(&sasl.OAuthBearerOptions{
+			Username: creds.Username,
+			Token:    creds.Secret,
+		}))
+	case "LOGIN":
+		authErr = c.Authenticate(sasl.NewLoginClient(creds.Username, creds.Secret))
+	default:
+		authErr = c.Login(creds.Username, creds.Secret)
+	}
+	if authErr != nil {
+		c.Logout()
+		return nil, fmt.Errorf("unable to authenticate: %v", authErr)
+	}
+
+	return c, nil
+}
+
+func (i *IMAPConnector) Sync(ctx context.Context, lastSync time.Time, chunkChan chan types.ChunkSyncResult, errChan chan error) {
+	defer close(chunkChan)
+
+	// TotalItems is unknown up front: it depends on the UID SEARCH result
+	// below, which hasn't run yet.
+	i.startProgress(0)
+
+	creds, err := keychain.IMAPCredentialsFromKeychain(i.ID())
+	if err != nil {
+		errChan <- fmt.Errorf("unable to get IMAP credentials: %v", err)
+		return
+	}
+
+	c, err := dialIMAP(creds)
+	if err != nil {
+		errChan <- fmt.Errorf("unable to connect to IMAP server: %v", err)
+		return
+	}
+	defer c.Logout()
+
+	if _, err := c.Select(imapMailbox, false); err != nil {
+		errChan <- fmt.Errorf("unable to select mailbox %s: %v", imapMailbox, err)
+		return
+	}
+
+	criteria := imap.NewSearchCriteria()
+	if !lastSync.IsZero() {
+		// UID SEARCH SINCE is date-only (no time-of-day), so this can
+		// re-fetch the last day's mail every sync; processMessage keys
+		// documents off the Message-ID, so re-processing is a harmless
+		// overwrite rather than a duplicate.
+		criteria.Since = lastSync
+	}
+	uids, err := c.UidSearch(criteria)
+	if err != nil {
+		errChan <- fmt.Errorf("unable to search mailbox %s: %v", imapMailbox, err)
+		return
+	}
+	if len(uids) == 0 {
+		return
+	}
+
+	seqset := new(imap.SeqSet)
+	seqset.AddNum(uids...)
+
+	messages := make(chan *imap.Message, 16)
+	fetchDone := make(chan error, 1)
+	go func() {
+		fetchDone <- c.UidFetch(seqset, []imap.FetchItem{imap.FetchUid, imap.FetchFlags, imap.FetchRFC822}, messages)
+	}()
+
+	for msg := range messages {
+		if hasFlag(msg.Flags, imap.DeletedFlag) {
+			continue
+		}
+		i.processMessage(msg, chunkChan)
+	}
+
+	if err := <-fetchDone; err != nil {
+		errChan <- fmt.Errorf("unable to fetch messages from %s: %v", imapMailbox, err)
+	}
+}
+
+func hasFlag(flags []string, flag string) bool {
+	for _, f := range flags {
+		if strings.EqualFold(f, flag) {
+			return true
+		}
+	}
+	return false
+}
+
+// processMessage parses a fetched message's RFC822 body via go-message/mail,
+// cleaning text/plain and text/html parts through mailbody.Clean and
+// running attachments through the shared AttachmentExtractor registry.
+// Each attachment is chunked as its own Document, linked to the parent
+// email via AttachmentDocument, so a citation can point at the specific
+// attachment rather than just the email.
+func (i *IMAPConnector) processMessage(msg *imap.Message, chunkChan chan types.ChunkSyncResult) {
+	var rawBody imap.Literal
+	for _, body := range msg.Body {
+		rawBody = body
+		break
+	}
+	if rawBody == nil {
+		i.recordProgressError()
+		return
+	}
+
+	mr, err := mail.CreateReader(rawBody)
+	if err != nil {
+		chunkChan <- types.ChunkSyncResult{Err: fmt.Errorf("unable to parse message UID %d: %v", msg.Uid, err)}
+		i.recordProgressError()
+		return
+	}
+
+	subject, _ := mr.Header.Subject()
+	if subject == "" {
+		subject = "(no subject)"
+	}
+	messageID, _ := mr.Header.MessageID()
+	if messageID == "" {
+		messageID = fmt.Sprintf("uid-%d", msg.Uid)
+	}
+	receivedAt, err := mr.Header.Date()
+	if err != nil || receivedAt.IsZero() {
+		receivedAt = time.Now()
+	}
+
+	var content strings.Builder
+	var attachments []attachmentContent
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			chunkChan <- types.ChunkSyncResult{Err: fmt.Errorf("unable to read part of message %s: %v", messageID, err)}
+			break
+		}
+
+		switch h := part.Header.(type) {
+		case *mail.InlineHeader:
+			mimeType, _, _ := h.ContentType()
+			data, err := io.ReadAll(part.Body)
+			if err != nil {
+				chunkChan <- types.ChunkSyncResult{Err: fmt.Errorf("unable to read body of message %s: %v", messageID, err)}
+				continue
+			}
+			switch mimeType {
+			case "text/plain", "text/html":
+				content.WriteString(mailbody.Clean(string(data), mimeType))
+			}
+		case *mail.AttachmentHeader:
+			mimeType, _, _ := h.ContentType()
+			filename, _ := h.Filename()
+			extraction, err := ExtractAttachment(i.context, mimeType, filename, part.Body)
+			if err != nil {
+				chunkChan <- types.ChunkSyncResult{Err: fmt.Errorf("unable to extract attachment %s of message %s: %v", filename, messageID, err)}
+				continue
+			}
+			if extraction == nil {
+				continue
+			}
+			attachments = append(attachments, attachmentContent{filename: filename, text: extraction.Text})
+		}
+	}
+
+	document := types.Document{
+		UniqueID:      messageID,
+		Name:          subject,
+		ConnectorID:   i.ID(),
+		ConnectorType: string(i.Type()),
+		CreatedAt:     receivedAt,
+		UpdatedAt:     receivedAt,
+	}
+
+	if err := i.store.DeleteDocumentChunks(i.context, document.UniqueID, i.ID()); err != nil {
+		log.Printf("Unable to delete chunks for document %s: %v", document.UniqueID, err)
+	}
+
+	EmitChunks(&i.BaseConnector, subject, content.String(), document, chunkChan)
+
+	for _, a := range attachments {
+		attachmentDoc := AttachmentDocument(document, a.filename)
+		if err := i.store.DeleteDocumentChunks(i.context, attachmentDoc.UniqueID, i.ID()); err != nil {
+			log.Printf("Unable to delete chunks for attachment document %s: %v", attachmentDoc.UniqueID, err)
+		}
+		EmitChunks(&i.BaseConnector, a.filename, a.text, attachmentDoc, chunkChan)
+	}
+}
+
+// attachmentContent holds one attachment's extracted text until the
+// parent email's own Document has been built, since AttachmentDocument
+// needs the parent Document to derive the child's UniqueID from.
+type attachmentContent struct {
+	filename string
+	text     string
+}
@@ -0,0 +1,205 @@
+package connectors
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Locker grants exclusive, cross-process access to a named resource (a
+// connector ID, scoped with a "connector:" prefix by callers). Unlike the
+// Syncer's in-memory mu, a Locker's exclusivity holds across every verbis
+// process pointed at the same backing store, so two replicas racing to
+// sync the same connector don't both win.
+type Locker interface {
+	// Lock blocks until it acquires key or ctx is cancelled, returning a
+	// fencing token that must be passed to Unlock. Losing ctx before
+	// acquiring returns ctx.Err().
+	Lock(ctx context.Context, key string) (token string, err error)
+	// Unlock releases key if and only if it's still held by token, so a
+	// caller whose lock already expired and was taken over by someone
+	// else can't accidentally release the new holder's lock.
+	Unlock(ctx context.Context, key, token string) error
+}
+
+// redisUnlockScript is Lua so the check-and-delete is atomic: without it,
+// a lock that expired between the GET and the DEL could have already been
+// re-acquired by another waiter, and Unlock would delete their lock
+// instead of a stale one of its own.
+const redisUnlockScript = `
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+else
+	return 0
+end
+`
+
+// RedisLocker implements Locker with Redis SET NX PX leases: acquiring a
+// key is a single atomic SET ... NX PX, and a holder's background
+// goroutine renews the PX TTL periodically so a lock doesn't expire out
+// from under a sync that's still running. A holder that crashes without
+// calling Unlock just lets the lease expire instead of wedging the
+// resource forever, the same crash-safety a polling-based lock gets for
+// free but this one gets without polling: a waiter blocks on
+// unlockChannel(key)'s pub/sub notification (falling back to a bounded
+// poll in case a release's Publish is missed, e.g. by a waiter that
+// subscribed a moment too late) rather than re-checking on a timer.
+type RedisLocker struct {
+	client *redis.Client
+	ttl    time.Duration
+
+	// retryInterval bounds how long Lock waits on a missed unlock
+	// notification before attempting to acquire the key again.
+	retryInterval time.Duration
+
+	mu       sync.Mutex
+	renewals map[string]chan struct{} // token -> stop channel for its renewal goroutine
+}
+
+// DefaultLockTTL is how long a RedisLocker lease lives before it must be
+// renewed, long enough that a renewal every DefaultLockRenewInterval has
+// several chances to land before the lease would expire.
+const DefaultLockTTL = 30 * time.Second
+
+// DefaultLockRenewInterval is how often a held lock's TTL is refreshed.
+const DefaultLockRenewInterval = 10 * time.Second
+
+// defaultLockRetryInterval bounds how long Lock waits on a missed unlock
+// notification before re-attempting acquisition.
+const defaultLockRetryInterval = 5 * time.Second
+
+// NewRedisLocker connects to the Redis instance at addr. Locks it grants
+// use ttl as their lease length; the caller is expected to have a
+// renewal goroutine (started automatically by Lock) outlive any single
+// connectorSync call.
+func NewRedisLocker(ctx context.Context, addr string, ttl time.Duration) (*RedisLocker, error) {
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis at %s: %v", addr, err)
+	}
+	if ttl <= 0 {
+		ttl = DefaultLockTTL
+	}
+	return &RedisLocker{client: client, ttl: ttl, retryInterval: defaultLockRetryInterval, renewals: map[string]chan struct{}{}}, nil
+}
+
+func lockKey(key string) string       { return "verbis:lock:" + key }
+func unlockChannel(key string) string { return "connector:" + key + ":unlock" }
+
+// newFencingToken returns a random token unique enough that two
+// concurrent Lock callers can never collide on it, so Unlock's
+// compare-and-delete can tell "I still hold this lock" from "someone else
+// acquired it after mine expired".
+func newFencingToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate fencing token: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Lock blocks until it acquires key, retrying on every unlockChannel(key)
+// pub/sub notification instead of polling. It also retries every
+// retryInterval regardless, in case the holder released the lock between
+// our last failed SET NX and our Subscribe call, which would otherwise
+// mean we wait for a notification that already fired.
+func (l *RedisLocker) Lock(ctx context.Context, key string) (string, error) {
+	redisKey := lockKey(key)
+
+	for {
+		token, err := newFencingToken()
+		if err != nil {
+			return "", err
+		}
+		ok, err := l.client.SetNX(ctx, redisKey, token, l.ttl).Result()
+		if err != nil {
+			return "", fmt.Errorf("failed to acquire lock %s: %v", key, err)
+		}
+		if ok {
+			l.startRenewal(redisKey, token)
+			return token, nil
+		}
+
+		if err := l.waitForUnlock(ctx, key); err != nil {
+			return "", err
+		}
+	}
+}
+
+// waitForUnlock blocks until key's unlock channel publishes, retryInterval
+// elapses, or ctx is cancelled, whichever comes first.
+func (l *RedisLocker) waitForUnlock(ctx context.Context, key string) error {
+	sub := l.client.Subscribe(ctx, unlockChannel(key))
+	defer sub.Close()
+
+	timer := time.NewTimer(l.retryInterval)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-sub.Channel():
+		return nil
+	case <-timer.C:
+		return nil
+	}
+}
+
+// startRenewal runs a background goroutine that refreshes redisKey's TTL
+// every DefaultLockRenewInterval for as long as token still holds it, so a
+// sync that runs longer than the lease doesn't lose the lock mid-flight.
+// Unlock (or the renewal failing to find its own token, meaning someone
+// else already took over the key) stops it.
+func (l *RedisLocker) startRenewal(redisKey, token string) {
+	stop := make(chan struct{})
+	l.mu.Lock()
+	l.renewals[token] = stop
+	l.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(DefaultLockRenewInterval)
+		defer ticker.Stop()
+		ctx := context.Background()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				current, err := l.client.Get(ctx, redisKey).Result()
+				if err != nil || current != token {
+					// Lease already expired and either went unclaimed or
+					// was taken over by someone else; nothing left to renew.
+					return
+				}
+				if err := l.client.Expire(ctx, redisKey, l.ttl).Err(); err != nil {
+					log.Printf("failed to renew lock %s: %v", redisKey, err)
+				}
+			}
+		}
+	}()
+}
+
+// Unlock releases key via the Lua check-and-delete script, then
+// publishes to its unlock channel so any blocked Lock callers retry
+// immediately instead of waiting out their retryInterval.
+func (l *RedisLocker) Unlock(ctx context.Context, key, token string) error {
+	redisKey := lockKey(key)
+
+	l.mu.Lock()
+	if stop, ok := l.renewals[token]; ok {
+		close(stop)
+		delete(l.renewals, token)
+	}
+	l.mu.Unlock()
+
+	if err := l.client.Eval(ctx, redisUnlockScript, []string{redisKey}, token).Err(); err != nil {
+		return fmt.Errorf("failed to release lock %s: %v", key, err)
+	}
+	return l.client.Publish(ctx, unlockChannel(key), "unlocked").Err()
+}
@@ -0,0 +1,120 @@
+// Package mailbody turns a raw email body part into clean text suitable
+// for chunking and embedding. HTML parts are reduced to their visible
+// text while preserving link targets and list structure, then quoted
+// reply chains, signature blocks, and common tracking/marketing
+// boilerplate are stripped out, so a thread's embedding isn't dominated
+// by the same quoted history repeated across every reply.
+package mailbody
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Clean reduces body to plain text appropriate for chunking. contentType
+// should be the part's MIME type ("text/plain" or "text/html"); anything
+// else is treated as plain text.
+func Clean(body string, contentType string) string {
+	text := body
+	if contentType == "text/html" {
+		text = htmlToText(text)
+	}
+	text = stripQuotedReplies(text)
+	text = stripSignature(text)
+	text = stripBoilerplate(text)
+	return strings.TrimSpace(text)
+}
+
+var (
+	// outlookQuoteDiv and gmailQuoteDiv wrap the quoted portion of a reply
+	// in a whole div rather than "> " prefixed lines, so they're cut out
+	// before generic tag stripping instead of being left behind as
+	// unmarked quoted text.
+	outlookQuoteDiv = regexp.MustCompile(`(?is)<div[^>]*\bid="appendonsend"[^>]*>.*`)
+	gmailQuoteDiv   = regexp.MustCompile(`(?is)<div[^>]*\bclass="[^"]*\bgmail_quote\b[^"]*"[^>]*>.*`)
+
+	linkTag  = regexp.MustCompile(`(?is)<a\s[^>]*href="([^"]*)"[^>]*>(.*?)</a>`)
+	listItem = regexp.MustCompile(`(?is)<li[^>]*>`)
+	breakTag = regexp.MustCompile(`(?is)<(br|/p|/div|/li|/tr)\s*/?>`)
+	anyTag   = regexp.MustCompile(`(?is)<[^>]*>`)
+)
+
+// htmlToText reduces an HTML email body to its visible text: links become
+// "label (url)" so a URL a user would have clicked survives, list items
+// become "- " prefixed lines, and block-level tags become line breaks
+// before remaining markup is stripped outright.
+func htmlToText(body string) string {
+	text := outlookQuoteDiv.ReplaceAllString(body, "")
+	text = gmailQuoteDiv.ReplaceAllString(text, "")
+
+	text = linkTag.ReplaceAllStringFunc(text, func(m string) string {
+		groups := linkTag.FindStringSubmatch(m)
+		href := groups[1]
+		label := strings.TrimSpace(anyTag.ReplaceAllString(groups[2], ""))
+		if label == "" || label == href {
+			return href
+		}
+		return fmt.Sprintf("%s (%s)", label, href)
+	})
+
+	text = listItem.ReplaceAllString(text, "\n- ")
+	text = breakTag.ReplaceAllString(text, "\n")
+	text = anyTag.ReplaceAllString(text, "")
+	return html.UnescapeString(text)
+}
+
+// onWroteHeader matches the "On <date>, <person> wrote:" line most mail
+// clients prepend to a quoted reply. Everything from that line on is the
+// previous message in the thread, already chunked as its own document, so
+// it's dropped here rather than duplicated into this one.
+var onWroteHeader = regexp.MustCompile(`(?im)^\s*On .{0,160} wrote:\s*$`)
+
+// stripQuotedReplies drops the "On ... wrote:" header and everything after
+// it, plus any remaining "> " prefixed quoted lines (the plain-text quoting
+// convention, and what's left after an HTML quote div was already cut).
+func stripQuotedReplies(text string) string {
+	if loc := onWroteHeader.FindStringIndex(text); loc != nil {
+		text = text[:loc[0]]
+	}
+
+	lines := strings.Split(text, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), ">") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// signatureDelimiter is the de facto standard "-- " marker (on its own
+// line) separating a message body from its signature block, honored by
+// essentially every mail client.
+var signatureDelimiter = regexp.MustCompile(`(?m)^--\s*$`)
+
+// stripSignature cuts the text at the first signature delimiter line,
+// dropping the signature block that follows.
+func stripSignature(text string) string {
+	if loc := signatureDelimiter.FindStringIndex(text); loc != nil {
+		text = text[:loc[0]]
+	}
+	return text
+}
+
+// footerLine matches common single-line marketing/tracking boilerplate
+// that survives into the body text: mobile client taglines and
+// unsubscribe/view-in-browser links.
+var footerLine = regexp.MustCompile(`(?im)^\s*(sent from my \w+.*|get outlook for (ios|android).*|unsubscribe.*|view (this|in) (email|browser).*)\s*$`)
+
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// stripBoilerplate removes footerLine matches and collapses the runs of
+// blank lines they (and the divs/tags already cut above) tend to leave
+// behind.
+func stripBoilerplate(text string) string {
+	text = footerLine.ReplaceAllString(text, "")
+	return blankLines.ReplaceAllString(text, "\n\n")
+}
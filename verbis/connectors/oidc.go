@@ -0,0 +1,115 @@
+package connectors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// oidcDiscoveryCacheTTL bounds how long a fetched discovery document is
+// reused before being re-fetched, so a provider rotating its
+// userinfo_endpoint is picked up without a restart, while normal identity
+// lookups (one per AuthCallback) don't each pay for a discovery round trip.
+const oidcDiscoveryCacheTTL = 1 * time.Hour
+
+// OIDCIdentity is the subset of an OpenID Connect userinfo response
+// connectors care about: email is what's shown to the user, sub is the
+// provider's stable per-account identifier that survives an email change.
+type OIDCIdentity struct {
+	Sub           string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+type oidcDiscoveryDoc struct {
+	UserinfoEndpoint string `json:"userinfo_endpoint"`
+}
+
+type oidcDiscoveryCacheEntry struct {
+	doc       oidcDiscoveryDoc
+	fetchedAt time.Time
+}
+
+// oidcDiscoveryCache memoizes discovery documents by their well-known URL,
+// shared across every connector instance since a provider's discovery
+// document doesn't depend on which account is authenticating.
+var oidcDiscoveryCache = struct {
+	mu      sync.Mutex
+	entries map[string]oidcDiscoveryCacheEntry
+}{entries: map[string]oidcDiscoveryCacheEntry{}}
+
+// FetchOIDCIdentity resolves the account identity behind ts by fetching
+// discoveryURL's `/.well-known/openid-configuration` document (cached for
+// oidcDiscoveryCacheTTL) and then GETing its userinfo_endpoint with ts's
+// bearer token. ts should come from the same oauth2.Config/token used to
+// authenticate the connector, so a refreshed access token is picked up
+// automatically rather than this call using a stale one.
+func FetchOIDCIdentity(ctx context.Context, ts oauth2.TokenSource, discoveryURL string) (*OIDCIdentity, error) {
+	doc, err := oidcDiscovery(ctx, discoveryURL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch OIDC discovery document: %v", err)
+	}
+	if doc.UserinfoEndpoint == "" {
+		return nil, fmt.Errorf("discovery document at %s has no userinfo_endpoint", discoveryURL)
+	}
+
+	client := oauth2.NewClient(ctx, ts)
+	resp, err := client.Get(doc.UserinfoEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch userinfo: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("userinfo request failed: status %s", resp.Status)
+	}
+
+	var identity OIDCIdentity
+	if err := json.NewDecoder(resp.Body).Decode(&identity); err != nil {
+		return nil, fmt.Errorf("unable to decode userinfo: %v", err)
+	}
+	if identity.Sub == "" {
+		return nil, fmt.Errorf("userinfo response has no sub claim")
+	}
+
+	return &identity, nil
+}
+
+func oidcDiscovery(ctx context.Context, discoveryURL string) (oidcDiscoveryDoc, error) {
+	oidcDiscoveryCache.mu.Lock()
+	entry, ok := oidcDiscoveryCache.entries[discoveryURL]
+	oidcDiscoveryCache.mu.Unlock()
+	if ok && time.Since(entry.fetchedAt) < oidcDiscoveryCacheTTL {
+		return entry.doc, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return oidcDiscoveryDoc{}, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return oidcDiscoveryDoc{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return oidcDiscoveryDoc{}, fmt.Errorf("discovery request to %s failed: status %s", discoveryURL, resp.Status)
+	}
+
+	var doc oidcDiscoveryDoc
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return oidcDiscoveryDoc{}, fmt.Errorf("unable to decode discovery document: %v", err)
+	}
+
+	oidcDiscoveryCache.mu.Lock()
+	oidcDiscoveryCache.entries[discoveryURL] = oidcDiscoveryCacheEntry{doc: doc, fetchedAt: time.Now()}
+	oidcDiscoveryCache.mu.Unlock()
+
+	return doc, nil
+}
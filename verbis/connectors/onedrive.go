@@ -0,0 +1,482 @@
+package connectors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	msal "github.com/AzureAD/microsoft-authentication-library-for-go/apps/public"
+	msgraph "github.com/microsoftgraph/msgraph-sdk-go"
+	graphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
+	msdrives "github.com/microsoftgraph/msgraph-sdk-go/drives"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/microsoft"
+
+	"github.com/verbis-ai/verbis/verbis/keychain"
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+func NewOneDriveConnector(creds types.BuildCredentials, st types.Store) types.Connector {
+	return &OneDriveConnector{
+		BaseConnector: BaseConnector{
+			connectorType: types.ConnectorTypeOneDrive,
+			store:         st,
+		},
+		secretValue: creds.AzureSecretValue,
+		secretID:    creds.AzureSecretID,
+	}
+}
+
+func init() {
+	types.RegisterConnector(types.ConnectorTypeOneDrive, NewOneDriveConnector)
+}
+
+type OneDriveConnector struct {
+	BaseConnector
+	secretValue string
+	secretID    string
+}
+
+var oneDriveScopes = []string{
+	"https://graph.microsoft.com/Files.Read",
+	"https://graph.microsoft.com/Sites.Read.All",
+	"https://graph.microsoft.com/User.Read",
+	"openid",
+	"email",
+}
+
+var oneDriveScopesPlusOffline = append(oneDriveScopes, "offline_access")
+
+func (o *OneDriveConnector) oneDriveConfig() (*oauth2.Config, error) {
+	return &oauth2.Config{
+		ClientID:     o.secretID,
+		ClientSecret: o.secretValue,
+		RedirectURL:  fmt.Sprintf("http://127.0.0.1:8081/connectors/%s/callback", o.Type()),
+		Scopes:       oneDriveScopesPlusOffline,
+		Endpoint:     microsoft.AzureADEndpoint("common"),
+	}, nil
+}
+
+func (o *OneDriveConnector) getClient(ctx context.Context, config *oauth2.Config) (*msgraph.GraphServiceClient, error) {
+	tok, err := keychain.TokenFromKeychain(o.ID(), o.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	tokenSource := config.TokenSource(ctx, tok)
+	authProvider := &OAuthAuthenticationProvider{TokenSource: tokenSource}
+	adapter, err := msgraph.NewGraphRequestAdapter(authProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	return msgraph.NewGraphServiceClient(adapter), nil
+}
+
+func (o *OneDriveConnector) requestOauthWeb(config *oauth2.Config) error {
+	log.Printf("Requesting token from web with redirectURL: %v", config.RedirectURL)
+	authURL := config.AuthCodeURL(o.ID(), oauth2.AccessTypeOffline)
+	fmt.Printf("Your browser has been opened to visit:\n%v\n", authURL)
+
+	// Open URL in the default browser
+	return exec.Command("open", authURL).Start()
+}
+
+func (o *OneDriveConnector) AuthSetup(ctx context.Context) error {
+	config, err := o.oneDriveConfig()
+	if err != nil {
+		return fmt.Errorf("unable to get onedrive config: %s", err)
+	}
+	_, err = keychain.TokenFromKeychain(o.ID(), o.Type())
+	if err == nil {
+		// TODO: check for expiry of refresh token
+		log.Print("Token found in keychain.")
+		return nil
+	}
+	log.Print("No token found in keychain. Getting token from web.")
+	err = o.requestOauthWeb(config)
+	if err != nil {
+		log.Printf("Unable to request token from web: %v", err)
+	}
+	return nil
+}
+
+// TODO: handle token expiries
+func (o *OneDriveConnector) AuthCallback(ctx context.Context, authCode string) error {
+	config, err := o.oneDriveConfig()
+	if err != nil {
+		return fmt.Errorf("unable to get onedrive config: %s", err)
+	}
+
+	clientApp, err := msal.New(o.secretID, msal.WithAuthority("https://login.microsoftonline.com/common"))
+	if err != nil {
+		return fmt.Errorf("failed to create client app: %v", err)
+	}
+
+	// MSAL automatically adds the offline_access scope
+	result, err := clientApp.AcquireTokenByAuthCode(ctx, authCode, "http://127.0.0.1:8081/connectors/onedrive/callback", oneDriveScopes)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve token from web: %v", err)
+	}
+
+	tok := &oauth2.Token{
+		AccessToken: result.AccessToken,
+	}
+
+	err = keychain.SaveTokenToKeychain(tok, o.ID(), o.Type())
+	if err != nil {
+		return fmt.Errorf("unable to save token to keychain: %v", err)
+	}
+
+	client, err := o.getClient(ctx, config)
+	if err != nil {
+		return fmt.Errorf("unable to get client: %v", err)
+	}
+
+	email, err := getOneDriveUserEmail(ctx, client)
+	if err != nil {
+		return fmt.Errorf("unable to get user email: %v", err)
+	}
+	log.Printf("User email: %s", email)
+	o.user = email
+
+	return o.MutateState(ctx, func(state *types.ConnectorState) error {
+		state.User = o.User()
+		return nil
+	})
+}
+
+func getOneDriveUserEmail(ctx context.Context, client *msgraph.GraphServiceClient) (string, error) {
+	userable, err := client.Me().Get(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+
+	email := userable.GetMail()
+	if email == nil {
+		email = userable.GetUserPrincipalName()
+	}
+	if email == nil {
+		return "", fmt.Errorf("unable to get user email")
+	}
+
+	return *email, nil
+}
+
+func (o *OneDriveConnector) Sync(ctx context.Context, lastSync time.Time, chunkChan chan types.ChunkSyncResult, errChan chan error) {
+	defer close(chunkChan)
+	if err := ctx.Err(); err != nil {
+		errChan <- fmt.Errorf("context error: %s", err)
+		return
+	}
+
+	// TotalItems is unknown up front: the delta listing is paged and
+	// doesn't report a total count.
+	o.startProgress(0)
+
+	log.Printf("Starting onedrive sync")
+	config, err := o.oneDriveConfig()
+	if err != nil {
+		errChan <- fmt.Errorf("unable to get onedrive config: %s", err)
+		return
+	}
+
+	client, err := o.getClient(ctx, config)
+	if err != nil {
+		errChan <- fmt.Errorf("unable to get client: %v", err)
+		return
+	}
+
+	err = o.syncDelta(ctx, client, chunkChan)
+	if err != nil {
+		errChan <- fmt.Errorf("unable to sync drive delta: %v", err)
+		return
+	}
+}
+
+// oneDriveCursorState is the JSON shape persisted in ConnectorState.Cursor.
+// Microsoft Graph hands back a separate delta link per drive, and once
+// SharePoint site drives are synced alongside the personal drive a single
+// opaque string isn't enough to resume all of them, so this tracks one per
+// drive, keyed by site ID for site drives ("" for the personal drive).
+type oneDriveCursorState struct {
+	Drive string            `json:"drive,omitempty"`
+	Sites map[string]string `json:"sites,omitempty"`
+}
+
+// loadOneDriveCursor unmarshals state.Cursor, falling back to treating it
+// as a bare pre-SharePoint-sync delta link (what this connector persisted
+// before oneDriveCursorState existed) if it isn't valid JSON.
+func loadOneDriveCursor(raw string) oneDriveCursorState {
+	c := oneDriveCursorState{Sites: map[string]string{}}
+	if raw == "" {
+		return c
+	}
+	if err := json.Unmarshal([]byte(raw), &c); err != nil {
+		c = oneDriveCursorState{Drive: raw, Sites: map[string]string{}}
+	}
+	if c.Sites == nil {
+		c.Sites = map[string]string{}
+	}
+	return c
+}
+
+// syncDelta walks the personal drive's delta feed, then every followed
+// SharePoint site's default drive, resuming each from the deltaLink
+// persisted on the connector state, the same cursor-based incremental sync
+// approach as googledrive.go's Changes API and dropbox.go's
+// list_folder/continue.
+func (o *OneDriveConnector) syncDelta(ctx context.Context, client *msgraph.GraphServiceClient, chunkChan chan types.ChunkSyncResult) error {
+	state, err := o.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get connector state: %v", err)
+	}
+	cursor := loadOneDriveCursor(state.Cursor)
+
+	driveLink, err := o.syncDriveDelta(ctx, client, "me", cursor.Drive, chunkChan)
+	if err != nil {
+		return fmt.Errorf("unable to sync personal drive delta: %v", err)
+	}
+	cursor.Drive = driveLink
+
+	sites, err := o.listFollowedSites(ctx, client)
+	if err != nil {
+		// SharePoint access is best-effort: a user/app without
+		// Sites.Read.All consent still gets their personal drive synced.
+		log.Printf("Unable to list followed SharePoint sites: %v", err)
+	}
+	for _, site := range sites {
+		siteLink, err := o.syncDriveDelta(ctx, client, site.driveID, cursor.Sites[site.id], chunkChan)
+		if err != nil {
+			log.Printf("Unable to sync SharePoint site %s delta: %v", site.name, err)
+			continue
+		}
+		cursor.Sites[site.id] = siteLink
+	}
+
+	raw, err := json.Marshal(cursor)
+	if err != nil {
+		return fmt.Errorf("unable to marshal onedrive cursor: %v", err)
+	}
+	state.Cursor = string(raw)
+	if err := o.UpdateConnectorState(ctx, state); err != nil {
+		log.Printf("Unable to persist onedrive delta cursor: %v", err)
+	}
+
+	return nil
+}
+
+// syncDriveDelta walks one drive's delta feed (the personal drive, aliased
+// as "me", or a SharePoint site's default drive) starting from deltaLink,
+// and returns the new deltaLink to resume from next sync.
+func (o *OneDriveConnector) syncDriveDelta(ctx context.Context, client *msgraph.GraphServiceClient, driveID string, deltaLink string, chunkChan chan types.ChunkSyncResult) (string, error) {
+	var result models.DriveItemCollectionResponseable
+	var err error
+	if deltaLink == "" {
+		result, err = client.Drives().ByDriveId(driveID).Items().ByDriveItemId("root").Delta().Get(ctx, nil)
+	} else {
+		requestBuilder := msdrives.NewItemItemsItemDeltaWithDeltaLinkRequestBuilder(deltaLink, client.GetAdapter())
+		result, err = requestBuilder.Get(ctx, nil)
+	}
+	if err != nil {
+		return deltaLink, fmt.Errorf("unable to fetch delta: %v", err)
+	}
+
+	pageIterator, err := graphcore.NewPageIterator[*models.DriveItem](
+		result,
+		client.GetAdapter(),
+		models.CreateDriveItemCollectionResponseFromDiscriminatorValue)
+	if err != nil {
+		return deltaLink, fmt.Errorf("unable to create page iterator: %v", err)
+	}
+
+	err = pageIterator.Iterate(
+		ctx,
+		func(item *models.DriveItem) bool {
+			// TODO: process many in parallel
+			o.processDriveItem(ctx, client, driveID, item, chunkChan)
+			return true
+		})
+	if err != nil {
+		return deltaLink, fmt.Errorf("unable to iterate over drive items: %v", err)
+	}
+
+	if newLink := pageIterator.GetOdataDeltaLink(); newLink != "" {
+		return newLink, nil
+	}
+	return deltaLink, nil
+}
+
+// oneDriveSite is a SharePoint site the connector syncs, resolved down to
+// its default document library drive ID so syncDriveDelta can walk it the
+// same way it walks the personal drive.
+type oneDriveSite struct {
+	id      string
+	driveID string
+	name    string
+}
+
+// listFollowedSites enumerates the SharePoint sites the user follows
+// (/me/followedSites) and resolves each one's default drive. This is
+// deliberately scoped to followed sites rather than a tenant-wide site
+// search, which typically needs broader admin consent than Sites.Read.All
+// grants on its own.
+func (o *OneDriveConnector) listFollowedSites(ctx context.Context, client *msgraph.GraphServiceClient) ([]oneDriveSite, error) {
+	resp, err := client.Me().FollowedSites().Get(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list followed sites: %v", err)
+	}
+
+	sites := []oneDriveSite{}
+	for _, s := range resp.GetValue() {
+		id := ""
+		if sid := s.GetId(); sid != nil {
+			id = *sid
+		}
+		if id == "" {
+			continue
+		}
+		name := ""
+		if n := s.GetDisplayName(); n != nil {
+			name = *n
+		}
+
+		drive, err := client.Sites().BySiteId(id).Drive().Get(ctx, nil)
+		if err != nil {
+			log.Printf("Unable to get default drive for site %s: %v", name, err)
+			continue
+		}
+		driveID := ""
+		if did := drive.GetId(); did != nil {
+			driveID = *did
+		}
+		if driveID == "" {
+			continue
+		}
+
+		sites = append(sites, oneDriveSite{id: id, driveID: driveID, name: name})
+	}
+	return sites, nil
+}
+
+func (o *OneDriveConnector) processDriveItem(ctx context.Context, client *msgraph.GraphServiceClient, driveID string, item *models.DriveItem, chunkChan chan types.ChunkSyncResult) {
+	if item.GetFolder() != nil || item.GetFile() == nil {
+		// Folders, and deletions (which carry neither Folder nor File
+		// facets), have no content to chunk.
+		return
+	}
+
+	itemID := ""
+	if id := item.GetId(); id != nil {
+		itemID = *id
+	}
+	name := ""
+	if n := item.GetName(); n != nil {
+		name = *n
+	}
+	if item.GetDeleted() != nil {
+		if err := o.store.DeleteDocumentChunks(ctx, itemID, o.ID()); err != nil {
+			log.Printf("Unable to delete chunks for removed document %s: %v", itemID, err)
+		}
+		return
+	}
+
+	mimeType := ""
+	if f := item.GetFile(); f != nil && f.GetMimeType() != nil {
+		mimeType = *f.GetMimeType()
+	}
+
+	tempFilePath, err := downloadOneDriveFile(ctx, client, driveID, itemID, name)
+	if err != nil {
+		o.recordProgressError()
+		chunkChan <- types.ChunkSyncResult{
+			Err: fmt.Errorf("unable to download onedrive file %s: %v", name, err),
+		}
+		return
+	}
+	defer os.Remove(tempFilePath)
+
+	var content string
+	if isSupportedMimeType(mimeType) {
+		blocks, err := extractBlocks(ctx, mimeType, tempFilePath)
+		if err != nil {
+			o.recordProgressError()
+			chunkChan <- types.ChunkSyncResult{
+				Err: fmt.Errorf("unable to extract onedrive file %s: %v", name, err),
+			}
+			return
+		}
+		texts := make([]string, len(blocks))
+		for i, b := range blocks {
+			texts[i] = b.Text
+		}
+		content = strings.Join(texts, "\n")
+	} else {
+		data, err := os.ReadFile(tempFilePath)
+		if err != nil {
+			o.recordProgressError()
+			chunkChan <- types.ChunkSyncResult{
+				Err: fmt.Errorf("unable to read onedrive file %s: %v", name, err),
+			}
+			return
+		}
+		content = string(data)
+	}
+
+	modifiedAt := time.Now()
+	if mod := item.GetLastModifiedDateTime(); mod != nil {
+		modifiedAt = *mod
+	}
+
+	webURL := ""
+	if u := item.GetWebUrl(); u != nil {
+		webURL = *u
+	}
+
+	document := types.Document{
+		UniqueID:      itemID,
+		Name:          name,
+		SourceURL:     webURL,
+		ConnectorID:   o.ID(),
+		ConnectorType: string(o.Type()),
+		CreatedAt:     modifiedAt,
+		UpdatedAt:     modifiedAt,
+	}
+
+	if err := o.store.DeleteDocumentChunks(ctx, document.UniqueID, o.ID()); err != nil {
+		log.Printf("Unable to delete chunks for document %s: %v", document.UniqueID, err)
+	}
+
+	EmitChunks(&o.BaseConnector, name, content, document, chunkChan)
+}
+
+func downloadOneDriveFile(ctx context.Context, client *msgraph.GraphServiceClient, driveID string, itemID string, name string) (string, error) {
+	content, err := client.Drives().ByDriveId(driveID).Items().ByDriveItemId(itemID).Content().Get(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("unable to download content: %v", err)
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %v", err)
+	}
+
+	tempDir := filepath.Join(homeDir, ".verbis", "tmp")
+	if err := os.MkdirAll(tempDir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+
+	tempFilePath := filepath.Join(tempDir, filepath.Base(name))
+	if err := os.WriteFile(tempFilePath, content, os.ModePerm); err != nil {
+		return "", fmt.Errorf("failed to write file to disk: %v", err)
+	}
+
+	return tempFilePath, nil
+}
@@ -1,14 +1,19 @@
 package connectors
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
 	"os/exec"
+	"strconv"
+	"sync"
 	"time"
 
-	msal "github.com/AzureAD/microsoft-authentication-library-for-go/apps/public"
 	abstractions "github.com/microsoft/kiota-abstractions-go"
+	jsonserialization "github.com/microsoft/kiota-serialization-json-go/json"
 	msgraph "github.com/microsoftgraph/msgraph-sdk-go"
 	graphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
@@ -16,6 +21,7 @@ import (
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/microsoft"
 
+	"github.com/verbis-ai/verbis/verbis/connectors/mailbody"
 	"github.com/verbis-ai/verbis/verbis/keychain"
 	"github.com/verbis-ai/verbis/verbis/types"
 )
@@ -31,6 +37,10 @@ func NewOutlookConnector(creds types.BuildCredentials, st types.Store) types.Con
 	}
 }
 
+func init() {
+	types.RegisterConnector(types.ConnectorTypeOutlook, NewOutlookConnector)
+}
+
 type OutlookConnector struct {
 	BaseConnector
 	secretValue string
@@ -51,22 +61,20 @@ func (a *OAuthAuthenticationProvider) AuthenticateRequest(ctx context.Context, r
 	return nil
 }
 
-func (o *OutlookConnector) getClient(ctx context.Context, config *oauth2.Config) (*msgraph.GraphServiceClient, error) {
-	// Token from Keychain
-	tok, err := keychain.TokenFromKeychain(o.ID(), o.Type())
+func (o *OutlookConnector) getClient(ctx context.Context, config *oauth2.Config) (*msgraph.GraphServiceClient, oauth2.TokenSource, error) {
+	tokenSource, err := o.TokenSource(config)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	tokenSource := config.TokenSource(ctx, tok)
 	authProvider := &OAuthAuthenticationProvider{TokenSource: tokenSource}
 	adapter, err := msgraph.NewGraphRequestAdapter(authProvider)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	graphClient := msgraph.NewGraphServiceClient(adapter)
-	return graphClient, nil
+	return graphClient, tokenSource, nil
 }
 
 func (o *OutlookConnector) requestOauthWeb(config *oauth2.Config) error {
@@ -85,6 +93,10 @@ var outlookScopes = []string{
 	"email",
 }
 
+// outlookOIDCDiscoveryURL is Microsoft's multi-tenant v2.0 OpenID Connect
+// discovery document, matching the "common" authority outlookConfig uses.
+const outlookOIDCDiscoveryURL = "https://login.microsoftonline.com/common/v2.0/.well-known/openid-configuration"
+
 var outlookScopesPlusOffline = append(outlookScopes, "offline_access")
 
 func (o *OutlookConnector) AuthSetup(ctx context.Context) error {
@@ -116,78 +128,51 @@ func (o *OutlookConnector) outlookConfig() (*oauth2.Config, error) {
 	}, nil
 }
 
-// TODO: handle token expiries
 func (o *OutlookConnector) AuthCallback(ctx context.Context, authCode string) error {
 	config, err := o.outlookConfig()
 	if err != nil {
 		return fmt.Errorf("unable to get outlook config: %s", err)
 	}
 
-	clientApp, err := msal.New(o.secretID, msal.WithAuthority("https://login.microsoftonline.com/common"))
-	if err != nil {
-		return fmt.Errorf("failed to create client app: %v", err)
-	}
-
-	// MSAL automatically adds the offline_access scope
-	result, err := clientApp.AcquireTokenByAuthCode(ctx, authCode, "http://127.0.0.1:8081/connectors/outlook/callback", outlookScopes)
+	// Exchanging through config directly (rather than MSAL, which keeps
+	// refresh tokens in its own internal cache and never hands them back)
+	// is what gets us a full oauth2.Token, refresh token included, to
+	// persist and later hand to TokenSource's RefreshingTokenSource.
+	tok, err := config.Exchange(ctx, authCode)
 	if err != nil {
 		return fmt.Errorf("unable to retrieve token from web: %v", err)
 	}
 
-	tok := &oauth2.Token{
-		AccessToken: result.AccessToken,
-	}
-
 	err = keychain.SaveTokenToKeychain(tok, o.ID(), o.Type())
 	if err != nil {
 		return fmt.Errorf("unable to save token to keychain: %v", err)
 	}
 
-	client, err := o.getClient(ctx, config)
-	if err != nil {
-		return fmt.Errorf("unable to get client: %v", err)
-	}
-
-	email, err := getOutlookUserEmail(ctx, client)
-	if err != nil {
-		return fmt.Errorf("unable to get user email: %v", err)
-	}
-	log.Printf("User email: %s", email)
-	o.user = email
-
-	state, err := o.Status(ctx)
+	identity, err := FetchOIDCIdentity(ctx, config.TokenSource(ctx, tok), outlookOIDCDiscoveryURL)
 	if err != nil {
-		return fmt.Errorf("unable to get connector state: %v", err)
+		return fmt.Errorf("unable to resolve user identity: %v", err)
 	}
+	log.Printf("User email: %s", identity.Email)
+	o.user = identity.Email
 
-	state.User = o.User()
-	return o.UpdateConnectorState(ctx, state)
-}
-
-func getOutlookUserEmail(ctx context.Context, client *msgraph.GraphServiceClient) (string, error) {
-	userable, err := client.Me().Get(ctx, nil)
-	if err != nil {
-		return "", err
-	}
-
-	email := userable.GetMail()
-	if email == nil {
-		email = userable.GetUserPrincipalName()
-	}
-	if email == nil {
-		return "", fmt.Errorf("unable to get user email")
-	}
-
-	return *email, nil
+	return o.MutateState(ctx, func(state *types.ConnectorState) error {
+		state.User = o.User()
+		state.UserKey = identity.Sub
+		return nil
+	})
 }
 
-func (o *OutlookConnector) Sync(lastSync time.Time, chunkChan chan types.ChunkSyncResult, errChan chan error) {
+func (o *OutlookConnector) Sync(ctx context.Context, lastSync time.Time, chunkChan chan types.ChunkSyncResult, errChan chan error) {
 	defer close(chunkChan)
-	if err := o.context.Err(); err != nil {
+	if err := ctx.Err(); err != nil {
 		errChan <- fmt.Errorf("context error: %s", err)
 		return
 	}
 
+	// TotalItems is unknown up front: the messages listing is paged and
+	// doesn't report a total count.
+	o.startProgress(0)
+
 	log.Printf("Starting outlook sync")
 	config, err := o.outlookConfig()
 	if err != nil {
@@ -195,13 +180,13 @@ func (o *OutlookConnector) Sync(lastSync time.Time, chunkChan chan types.ChunkSy
 		return
 	}
 
-	graphClient, err := o.getClient(o.context, config)
+	graphClient, tokenSource, err := o.getClient(ctx, config)
 	if err != nil {
 		errChan <- fmt.Errorf("unable to get client: %v", err)
 		return
 	}
 
-	err = o.listEmails(o.context, graphClient, lastSync, chunkChan)
+	err = o.listEmails(ctx, graphClient, tokenSource, lastSync, chunkChan)
 	if err != nil {
 		errChan <- fmt.Errorf("unable to list emails: %v", err)
 		return
@@ -209,7 +194,11 @@ func (o *OutlookConnector) Sync(lastSync time.Time, chunkChan chan types.ChunkSy
 }
 
 func (o *OutlookConnector) processEmail(ctx context.Context, email models.Messageable, chunkChan chan types.ChunkSyncResult) {
-	content := *email.GetBody().GetContent()
+	bodyContentType := "text/plain"
+	if bt := email.GetBody().GetContentType(); bt != nil && *bt == models.HTML_BODYTYPE {
+		bodyContentType = "text/html"
+	}
+	content := mailbody.Clean(*email.GetBody().GetContent(), bodyContentType)
 
 	receivedAt := *email.GetReceivedDateTime()
 	emailURL := fmt.Sprintf("https://outlook.live.com/mail/inbox/id/%s", *email.GetId())
@@ -250,20 +239,66 @@ func (o *OutlookConnector) processEmail(ctx context.Context, email models.Messag
 
 	log.Printf("Processing email of size %d: title: %s", len(content), document.Name)
 
-	emitChunks(email_subject, content, document, chunkChan)
-	chunkChan <- types.ChunkSyncResult{DocumentDone: document.UniqueID}
+	EmitChunks(&o.BaseConnector, email_subject, content, document, chunkChan)
+
+	for _, att := range email.GetAttachments() {
+		fileAtt, ok := att.(models.FileAttachmentable)
+		if !ok {
+			// Item/reference attachments (e.g. a forwarded calendar
+			// invite) carry no file content to extract.
+			continue
+		}
+		name := "attachment"
+		if n := fileAtt.GetName(); n != nil {
+			name = *n
+		}
+		mimeType := ""
+		if ct := fileAtt.GetContentType(); ct != nil {
+			mimeType = *ct
+		}
+		contentBytes := fileAtt.GetContentBytes()
+		if len(contentBytes) == 0 {
+			continue
+		}
+
+		extraction, err := ExtractAttachment(ctx, mimeType, name, bytes.NewReader(contentBytes))
+		if err != nil {
+			chunkChan <- types.ChunkSyncResult{Err: fmt.Errorf("unable to extract attachment %s: %v", name, err)}
+			continue
+		}
+		if extraction == nil {
+			continue
+		}
+
+		attachmentDoc := AttachmentDocument(document, name)
+		if err := o.store.DeleteDocumentChunks(ctx, attachmentDoc.UniqueID, o.ID()); err != nil {
+			log.Printf("Unable to delete chunks for attachment document %s: %v", attachmentDoc.UniqueID, err)
+		}
+		EmitChunks(&o.BaseConnector, name, extraction.Text, attachmentDoc, chunkChan)
+	}
 }
 
-func (o *OutlookConnector) listEmails(ctx context.Context, client *msgraph.GraphServiceClient, lastSync time.Time, chunkChan chan types.ChunkSyncResult) error {
-	headers := abstractions.NewRequestHeaders()
-	headers.Add("Prefer", "outlook.body-content-type=\"text\"")
+// outlookBatchSize is the most sub-requests Microsoft Graph's $batch
+// endpoint accepts in one call.
+const outlookBatchSize = 20
+
+// outlookBatchWorkers bounds how many $batch requests (each covering up to
+// outlookBatchSize messages) are in flight at once, so a large inbox
+// doesn't fan out hundreds of concurrent HTTP requests against Graph's
+// throttling limits.
+const outlookBatchWorkers = 4
 
+const outlookBatchEndpoint = "https://graph.microsoft.com/v1.0/$batch"
+
+func (o *OutlookConnector) listEmails(ctx context.Context, client *msgraph.GraphServiceClient, tokenSource oauth2.TokenSource, lastSync time.Time, chunkChan chan types.ChunkSyncResult) error {
+	// First pass only lists message IDs; bodies and attachments are
+	// fetched afterwards in batches of outlookBatchSize via $batch, which
+	// costs one HTTP round-trip per batch instead of one per message.
 	filter := fmt.Sprintf("receivedDateTime ge %s", lastSync.Format(time.RFC3339))
-	var top int32 = 10
+	var top int32 = 50
 	requestConfig := &msusers.ItemMailfoldersItemMessagesRequestBuilderGetRequestConfiguration{
-		Headers: headers,
 		QueryParameters: &msusers.ItemMailfoldersItemMessagesRequestBuilderGetQueryParameters{
-			Select:  []string{"id", "subject", "receivedDateTime", "body", "sender"},
+			Select:  []string{"id"},
 			Filter:  &filter,
 			Top:     &top,
 			Orderby: []string{"receivedDateTime DESC"},
@@ -282,13 +317,14 @@ func (o *OutlookConnector) listEmails(ctx context.Context, client *msgraph.Graph
 	if err != nil {
 		return fmt.Errorf("unable to create page iterator: %v", err)
 	}
-	pageIterator.SetHeaders(headers)
 
+	var ids []string
 	err = pageIterator.Iterate(
 		ctx,
 		func(message *models.Message) bool {
-			// TODO: process many in parallel
-			o.processEmail(ctx, message, chunkChan)
+			if id := message.GetId(); id != nil {
+				ids = append(ids, *id)
+			}
 			// Return true to continue the iteration
 			return true
 		})
@@ -296,5 +332,174 @@ func (o *OutlookConnector) listEmails(ctx context.Context, client *msgraph.Graph
 		return fmt.Errorf("unable to iterate over emails: %v", err)
 	}
 
+	var batches [][]string
+	for i := 0; i < len(ids); i += outlookBatchSize {
+		end := i + outlookBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batches = append(batches, ids[i:end])
+	}
+
+	batchChan := make(chan []string)
+	var wg sync.WaitGroup
+	for w := 0; w < outlookBatchWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batchChan {
+				messages, err := o.fetchMessageBatch(ctx, tokenSource, batch)
+				if err != nil {
+					log.Printf("unable to fetch outlook message batch: %v", err)
+					o.recordProgressError()
+					continue
+				}
+				for _, id := range batch {
+					message, ok := messages[id]
+					if !ok {
+						log.Printf("outlook batch response missing message %s", id)
+						continue
+					}
+					o.processEmail(ctx, message, chunkChan)
+				}
+			}
+		}()
+	}
+	for _, batch := range batches {
+		batchChan <- batch
+	}
+	close(batchChan)
+	wg.Wait()
+
 	return nil
 }
+
+type outlookBatchRequestItem struct {
+	ID      string            `json:"id"`
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+type outlookBatchRequestBody struct {
+	Requests []outlookBatchRequestItem `json:"requests"`
+}
+
+type outlookBatchResponseItem struct {
+	ID      string            `json:"id"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+type outlookBatchResponseBody struct {
+	Responses []outlookBatchResponseItem `json:"responses"`
+}
+
+// fetchMessageBatch fetches the bodies and attachments of up to
+// outlookBatchSize messages in a single HTTP round-trip via Graph's $batch
+// endpoint, keyed by message ID. A 429 on the outer request, or on an
+// individual batched response, is retried once after honoring its
+// Retry-After header.
+func (o *OutlookConnector) fetchMessageBatch(ctx context.Context, tokenSource oauth2.TokenSource, ids []string) (map[string]models.Messageable, error) {
+	reqBody := outlookBatchRequestBody{}
+	for i, id := range ids {
+		reqBody.Requests = append(reqBody.Requests, outlookBatchRequestItem{
+			ID:      strconv.Itoa(i),
+			Method:  http.MethodGet,
+			URL:     fmt.Sprintf("/me/messages/%s?$select=id,subject,receivedDateTime,body,sender&$expand=attachments", id),
+			Headers: map[string]string{"Prefer": `outlook.body-content-type="text"`},
+		})
+	}
+
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal batch request: %v", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, outlookBatchEndpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("unable to build batch request: %v", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	client := oauth2.NewClient(ctx, tokenSource)
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("unable to send batch request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		time.Sleep(retryAfterDelay(resp.Header.Get("Retry-After")))
+		return o.fetchMessageBatch(ctx, tokenSource, ids)
+	}
+
+	var batchResp outlookBatchResponseBody
+	if err := json.NewDecoder(resp.Body).Decode(&batchResp); err != nil {
+		return nil, fmt.Errorf("unable to decode batch response: %v", err)
+	}
+
+	idByRequestID := make(map[string]string, len(ids))
+	for i, id := range ids {
+		idByRequestID[strconv.Itoa(i)] = id
+	}
+
+	retry := []string{}
+	messages := make(map[string]models.Messageable, len(ids))
+	for _, item := range batchResp.Responses {
+		messageID := idByRequestID[item.ID]
+		if item.Status == http.StatusTooManyRequests {
+			time.Sleep(retryAfterDelay(item.Headers["Retry-After"]))
+			retry = append(retry, messageID)
+			continue
+		}
+		if item.Status >= 300 {
+			log.Printf("batch request for outlook message %s failed with status %d", messageID, item.Status)
+			continue
+		}
+
+		parseNode, err := jsonserialization.NewJsonParseNode(item.Body)
+		if err != nil {
+			log.Printf("unable to parse batch response for outlook message %s: %v", messageID, err)
+			continue
+		}
+		value, err := parseNode.GetObjectValue(models.CreateMessageFromDiscriminatorValue)
+		if err != nil {
+			log.Printf("unable to deserialize outlook message %s: %v", messageID, err)
+			continue
+		}
+		message, ok := value.(models.Messageable)
+		if !ok {
+			log.Printf("unexpected type deserializing outlook message %s", messageID)
+			continue
+		}
+		messages[messageID] = message
+	}
+
+	if len(retry) > 0 {
+		retried, err := o.fetchMessageBatch(ctx, tokenSource, retry)
+		if err != nil {
+			return nil, err
+		}
+		for id, message := range retried {
+			messages[id] = message
+		}
+	}
+
+	return messages, nil
+}
+
+// retryAfterDelay parses a Retry-After header value (Graph always sends it
+// in seconds) and falls back to a short default delay if it's missing or
+// unparsable.
+func retryAfterDelay(header string) time.Duration {
+	if header == "" {
+		return time.Second
+	}
+	seconds, err := strconv.Atoi(header)
+	if err != nil || seconds < 0 {
+		return time.Second
+	}
+	return time.Duration(seconds) * time.Second
+}
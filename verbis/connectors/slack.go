@@ -1,15 +1,18 @@
 package connectors
 
 import (
+	"bytes"
+	"container/list"
 	"context"
 	"fmt"
 	"log"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/slack-go/slack"
 	"golang.org/x/oauth2"
 	oauthslack "golang.org/x/oauth2/slack"
@@ -22,59 +25,140 @@ import (
 
 const (
 	slackRateLimitBackoff = 11 * time.Second
+
+	// liveUpdatesBufferSize bounds how many realtime chunk results can be
+	// queued for the syncer before processMessage-equivalent handlers
+	// block on a slow embedding/index step.
+	liveUpdatesBufferSize = 32
+
+	// nameCacheSize bounds the user/channel name caches below. Workspaces
+	// rarely have more than a few thousand users and channels combined, so
+	// this comfortably avoids refetching names on every message while
+	// keeping memory bounded.
+	nameCacheSize = 2000
+)
+
+// userNameCache and channelNameCache are shared across all SlackConnector
+// instances (there is normally just one per workspace) since resolved
+// names don't depend on connector identity, only on the Slack API token's
+// workspace.
+var (
+	userNameCache    = newNameCache(nameCacheSize)
+	channelNameCache = newNameCache(nameCacheSize)
 )
 
-func NewSlackConnector(creds types.BuildCredentials) types.Connector {
+// nameCache is a small bounded LRU cache mapping Slack user/channel IDs to
+// resolved human-readable names, so the sync and realtime paths don't each
+// pay for a GetUserInfo/GetConversationInfo call per message.
+type nameCache struct {
+	mu    sync.Mutex
+	order *list.List
+	items map[string]*list.Element
+	cap   int
+}
+
+type nameCacheEntry struct {
+	key   string
+	value string
+}
+
+func newNameCache(capacity int) *nameCache {
+	return &nameCache{
+		order: list.New(),
+		items: map[string]*list.Element{},
+		cap:   capacity,
+	}
+}
+
+func (c *nameCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*nameCacheEntry).value, true
+}
+
+func (c *nameCache) set(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		el.Value.(*nameCacheEntry).value = value
+		c.order.MoveToFront(el)
+		return
+	}
+
+	el := c.order.PushFront(&nameCacheEntry{key: key, value: value})
+	c.items[key] = el
+	if c.order.Len() > c.cap {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*nameCacheEntry).key)
+	}
+}
+
+// invalidate drops a cached entry so the next resolve re-fetches it. Used
+// on user_change/channel_rename realtime events.
+func (c *nameCache) invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.order.Remove(el)
+		delete(c.items, key)
+	}
+}
+
+func NewSlackConnector(creds types.BuildCredentials, st types.Store) types.Connector {
 	return &SlackConnector{
-		id:           "",
-		user:         "",
+		BaseConnector: BaseConnector{
+			connectorType: types.ConnectorTypeSlack,
+			store:         st,
+		},
 		clientID:     creds.SlackClientID,
 		clientSecret: creds.SlackClientSecret,
 	}
 }
 
+func init() {
+	types.RegisterConnector(types.ConnectorTypeSlack, NewSlackConnector)
+}
+
 type SlackConnector struct {
-	id           string
-	user         string
+	BaseConnector
 	clientID     string
 	clientSecret string
 
 	messageBuffer string
-}
-
-func (s *SlackConnector) ID() string {
-	return s.id
-}
-
-func (s *SlackConnector) User() string {
-	return s.user
-}
 
-func (s *SlackConnector) Type() types.ConnectorType {
-	return types.ConnectorTypeSlack
+	// liveChunks maps a Slack message timestamp to the hash of the chunk
+	// it produced, so a later message_changed/message_deleted event for
+	// that timestamp can find and update/remove the right chunk.
+	liveMu     sync.Mutex
+	liveChunks map[string]string
+	liveOnce   sync.Once
+	liveChan   chan types.ChunkSyncResult
 }
 
-func (s *SlackConnector) Status(ctx context.Context) (*types.ConnectorState, error) {
-	state, err := store.GetConnectorState(ctx, store.GetWeaviateClient(), s.ID())
+func (s *SlackConnector) getClient() (*rateLimitedSlackClient, error) {
+	config, err := s.slackConfig()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get connector state: %v", err)
+		return nil, err
 	}
-
-	if state == nil {
-		// No stored state, only happens if sync() is called before init()
-		return nil, fmt.Errorf("connector state not found")
+	tokenSource, err := s.TokenSource(config)
+	if err != nil {
+		return nil, err
 	}
-	return state, nil
-}
-
-func (s *SlackConnector) getClient() (*slack.Client, error) {
-	// Token from Keychain
-	tok, err := keychain.TokenFromKeychain(s.ID(), s.Type())
+	tok, err := tokenSource.Token()
 	if err != nil {
 		return nil, err
 	}
 
-	return slack.New(tok.AccessToken), nil
+	return newRateLimitedSlackClient(slack.New(tok.AccessToken)), nil
 }
 
 func (g *SlackConnector) requestOauthWeb(config *oauth2.Config) error {
@@ -108,40 +192,33 @@ func (s *SlackConnector) slackConfig() (*oauth2.Config, error) {
 }
 
 func (g *SlackConnector) Init(ctx context.Context, connectorID string) error {
-	if connectorID != "" {
-		// connectorID is passed only when Init is called to re-create the
-		// connector from a state object during initial load
-		g.id = connectorID
-	}
-	if g.id == "" {
-		g.id = uuid.New().String()
-	}
+	g.liveChunks = map[string]string{}
+	g.liveChan = make(chan types.ChunkSyncResult, liveUpdatesBufferSize)
 
-	state, err := store.GetConnectorState(ctx, store.GetWeaviateClient(), g.ID())
-	if err != nil && !store.IsStateNotFound(err) {
-		return fmt.Errorf("failed to get connector state: %v", err)
+	if err := g.BaseConnector.Init(ctx, connectorID); err != nil {
+		return err
 	}
 
-	if state == nil {
-		state = &types.ConnectorState{}
+	state, err := g.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connector state: %v", err)
 	}
 
-	state.ConnectorID = g.ID()
-	state.Syncing = false
-	// state.User is unknown until auth is complete
-	state.ConnectorType = string(g.Type())
-	token, err := keychain.TokenFromKeychain(g.ID(), g.Type())
-	state.AuthValid = (err == nil && token != nil) // TODO: check for expiry of refresh token
-
-	err = store.UpdateConnectorState(ctx, store.GetWeaviateClient(), state)
-	if err != nil {
-		return fmt.Errorf("failed to set connector state: %v", err)
+	if state.AuthValid {
+		// Re-created from existing, already-authed state (e.g. process
+		// restart): resume the live listener immediately rather than
+		// waiting for AuthCallback, which only runs on first auth.
+		g.startRealtime()
 	}
 	return nil
 }
 
-func (s *SlackConnector) UpdateConnectorState(ctx context.Context, state *types.ConnectorState) error {
-	return store.UpdateConnectorState(ctx, store.GetWeaviateClient(), state)
+// LiveUpdates returns the channel the realtime listener publishes new,
+// edited, and deleted messages to, as types.ChunkSyncResult values. The
+// Syncer drains it for the lifetime of the connector so that Slack
+// activity lands in Weaviate without waiting for the next sync tick.
+func (g *SlackConnector) LiveUpdates() <-chan types.ChunkSyncResult {
+	return g.liveChan
 }
 
 func (s *SlackConnector) AuthSetup(ctx context.Context) error {
@@ -164,7 +241,7 @@ func (s *SlackConnector) AuthSetup(ctx context.Context) error {
 	return nil
 }
 
-func (s *SlackConnector) getUserString(client *slack.Client) (string, error) {
+func (s *SlackConnector) getUserString(client *rateLimitedSlackClient) (string, error) {
 	resp, err := client.AuthTest()
 	if err != nil {
 		return "", fmt.Errorf("unable to get user identity: %v", err)
@@ -173,6 +250,59 @@ func (s *SlackConnector) getUserString(client *slack.Client) (string, error) {
 	return fmt.Sprintf("%s @ %s", resp.User, resp.Team), nil
 }
 
+// resolveUser returns a human-readable "@handle (Display Name)" string for
+// a Slack user ID, backed by userNameCache so repeated messages from the
+// same person don't each cost a GetUserInfo call. Falls back to the raw ID
+// if the lookup fails, so a transient API error never blocks indexing.
+func (s *SlackConnector) resolveUser(client *rateLimitedSlackClient, userID string) string {
+	if userID == "" {
+		return userID
+	}
+	if name, ok := userNameCache.get(userID); ok {
+		return name
+	}
+
+	info, err := client.GetUserInfo(userID)
+	if err != nil {
+		log.Printf("slack: unable to resolve user %s: %v", userID, err)
+		return userID
+	}
+
+	name := fmt.Sprintf("@%s (%s)", info.Name, info.RealName)
+	userNameCache.set(userID, name)
+	return name
+}
+
+// resolveChannelName returns a human-readable channel name (e.g.
+// "#engineering") or, for a DM, the other participant's resolved name,
+// backed by channelNameCache. Falls back to the raw ID if the lookup
+// fails.
+func (s *SlackConnector) resolveChannelName(client *rateLimitedSlackClient, channelID string) string {
+	if channelID == "" {
+		return channelID
+	}
+	if name, ok := channelNameCache.get(channelID); ok {
+		return name
+	}
+
+	info, err := client.GetConversationInfo(&slack.GetConversationInfoInput{ChannelID: channelID})
+	if err != nil {
+		log.Printf("slack: unable to resolve channel %s: %v", channelID, err)
+		return channelID
+	}
+
+	name := channelID
+	switch {
+	case info.IsIM:
+		name = s.resolveUser(client, info.User)
+	case info.Name != "":
+		name = "#" + info.Name
+	}
+
+	channelNameCache.set(channelID, name)
+	return name
+}
+
 // TODO: handle token expiries
 func (s *SlackConnector) AuthCallback(ctx context.Context, authCode string) error {
 	config, err := s.slackConfig()
@@ -199,13 +329,17 @@ func (s *SlackConnector) AuthCallback(ctx context.Context, authCode string) erro
 	log.Printf("User string: %s", user)
 	s.user = user
 
-	state, err := s.Status(ctx)
-	if err != nil {
-		return fmt.Errorf("unable to get connector state: %v", err)
+	if err := s.MutateState(ctx, func(state *types.ConnectorState) error {
+		state.User = s.user
+		return nil
+	}); err != nil {
+		return err
 	}
 
-	state.User = s.user
-	return s.UpdateConnectorState(ctx, state)
+	// Now that we have a valid token, start listening for realtime events
+	// instead of waiting for the first periodic Sync.
+	s.startRealtime()
+	return nil
 }
 
 func (s *SlackConnector) Sync(ctx context.Context, lastSync time.Time, chunkChan chan types.ChunkSyncResult, errChan chan error) {
@@ -224,7 +358,7 @@ func (s *SlackConnector) Sync(ctx context.Context, lastSync time.Time, chunkChan
 	}
 }
 
-func (s *SlackConnector) fetchAllMessages(ctx context.Context, client *slack.Client, lastSync time.Time, chunkChan chan types.ChunkSyncResult) error {
+func (s *SlackConnector) fetchAllMessages(ctx context.Context, client *rateLimitedSlackClient, lastSync time.Time, chunkChan chan types.ChunkSyncResult) error {
 	log.Printf("Fetching channels")
 	channels, err := s.fetchAllChannels(client)
 	if err != nil {
@@ -232,17 +366,20 @@ func (s *SlackConnector) fetchAllMessages(ctx context.Context, client *slack.Cli
 	}
 
 	log.Printf("Processing messages in %d channels", len(channels))
+	s.startProgress(len(channels))
 	for _, channel := range channels {
 		err = s.fetchAndProcessChannelMessages(ctx, client, channel, lastSync, chunkChan)
 		if err != nil {
+			s.recordProgressError()
 			return err
 		}
+		s.advanceProgress(channel.Name, 0)
 	}
 
 	return nil
 }
 
-func (s *SlackConnector) fetchAllChannels(client *slack.Client) ([]slack.Channel, error) {
+func (s *SlackConnector) fetchAllChannels(client *rateLimitedSlackClient) ([]slack.Channel, error) {
 	params := &slack.GetConversationsParameters{
 		Types: []string{"public_channel", "private_channel", "im"},
 		Limit: 100,
@@ -288,7 +425,7 @@ func IsErrSlackRateLimit(err error) bool {
 	return false
 }
 
-func (s *SlackConnector) fetchAndProcessChannelMessages(ctx context.Context, client *slack.Client, channel slack.Channel, lastSync time.Time, chunkChan chan types.ChunkSyncResult) error {
+func (s *SlackConnector) fetchAndProcessChannelMessages(ctx context.Context, client *rateLimitedSlackClient, channel slack.Channel, lastSync time.Time, chunkChan chan types.ChunkSyncResult) error {
 	since := "0"
 	if !lastSync.IsZero() {
 		since = fmt.Sprintf("%d", lastSync.UnixMilli())
@@ -301,35 +438,35 @@ func (s *SlackConnector) fetchAndProcessChannelMessages(ctx context.Context, cli
 
 	// Each channel is stored as a single document
 	var doc *types.Document
-	doc, err := store.GetDocument(ctx, channel.ID)
+	doc, err := s.store.GetDocument(ctx, channel.ID)
 	if err != nil && !store.IsErrDocumentNotFound(err) {
 		return fmt.Errorf("unable to get document: %v", err)
 	}
 
+	channelName := s.resolveChannelName(client, channel.ID)
 	if doc == nil {
 		if channel.ID == "" {
 			return fmt.Errorf("channel ID is empty")
 		}
 		doc = &types.Document{
 			UniqueID:      channel.ID,
-			Name:          channel.ID, // TODO: store channel name instead?
-			SourceURL:     "",         // Sent with the first chunk as it needs a timestamp
+			Name:          channelName,
+			SourceURL:     "", // Sent with the first chunk as it needs a timestamp
 			ConnectorID:   s.ID(),
 			ConnectorType: string(s.Type()),
 			// TODO: CreatedAt
 			UpdatedAt: time.Now(),
 		}
+	} else {
+		doc.Name = channelName
 	}
 
 	for {
 		log.Printf("Fetching conversation history for channel %s since %s", channel.ID, since)
+		// Rate limiting and transient-error retries are handled by client
+		// itself, so a returned error here is one we've given up on.
 		history, err := client.GetConversationHistory(&params)
 		if err != nil {
-			if IsErrSlackRateLimit(err) {
-				time.Sleep(slackRateLimitBackoff)
-				continue
-			}
-
 			return fmt.Errorf("error fetching channel history for channel %s: %v", channel.ID, err)
 		}
 		if !history.Ok {
@@ -368,14 +505,34 @@ func (s *SlackConnector) flushMessageBuffer(document types.Document, chunkChan c
 	}
 }
 
-func (s *SlackConnector) processMessage(document types.Document, client *slack.Client, channelID string, message slack.Message, chunkChan chan types.ChunkSyncResult) error {
+func (s *SlackConnector) processMessage(document types.Document, client *rateLimitedSlackClient, channelID string, message slack.Message, chunkChan chan types.ChunkSyncResult) error {
 
 	// In the slack connector we do not delete a previous document's chunks as
 	// we are not expecting to re-index the entire document/channel.
 	content := util.CleanChunk(message.Text)
 	log.Printf("Processing %s message %s: %s", document.UniqueID, message.User, content)
+
+	if message.ReplyCount > 0 {
+		// This message is the parent of a thread. The flat channel buffer
+		// below only ever sees the parent, so the replies would otherwise
+		// be lost entirely; fetch and emit them as their own chunk.
+		if err := s.processThread(document, client, channelID, message, chunkChan); err != nil {
+			return err
+		}
+	}
+
+	if len(message.Files) > 0 {
+		if err := s.processFiles(client, channelID, message, chunkChan); err != nil {
+			return err
+		}
+	}
+
+	if len(message.Attachments) > 0 {
+		s.processAttachments(client, document, message, chunkChan)
+	}
+
 	if len(content)+len(s.messageBuffer) <= MaxChunkSize {
-		s.messageBuffer += fmt.Sprintf("%s: %s \n", message.User, content)
+		s.messageBuffer += fmt.Sprintf("%s: %s \n", s.resolveUser(client, message.User), content)
 		return nil
 	}
 
@@ -390,6 +547,287 @@ func (s *SlackConnector) processMessage(document types.Document, client *slack.C
 
 	document.SourceURL = link
 	s.flushMessageBuffer(document, chunkChan)
-	s.messageBuffer = fmt.Sprintf("%s: %s | \n", message.User, content)
+	s.messageBuffer = fmt.Sprintf("%s: %s | \n", s.resolveUser(client, message.User), content)
 	return nil
 }
+
+// processThread fetches the full reply chain for a threaded message and
+// emits it as its own chunk, separate from the flat channel buffer, so a
+// thread's back-and-forth isn't lost in a single-author-per-line
+// concatenation of unrelated top-level messages.
+func (s *SlackConnector) processThread(document types.Document, client *rateLimitedSlackClient, channelID string, parent slack.Message, chunkChan chan types.ChunkSyncResult) error {
+	params := &slack.GetConversationRepliesParameters{
+		ChannelID: channelID,
+		Timestamp: parent.Timestamp,
+	}
+
+	var replies []slack.Message
+	for {
+		// Rate limiting and transient-error retries are handled by client
+		// itself, so a returned error here is one we've given up on.
+		msgs, hasMore, nextCursor, err := client.GetConversationReplies(params)
+		if err != nil {
+			return fmt.Errorf("error fetching thread replies for %s: %v", parent.Timestamp, err)
+		}
+		replies = append(replies, msgs...)
+		if !hasMore {
+			break
+		}
+		params.Cursor = nextCursor
+	}
+
+	// GetConversationReplies includes the parent message as the first
+	// reply, so speaker order and context are preserved without having to
+	// special-case it here.
+	var thread strings.Builder
+	for _, reply := range replies {
+		content := util.CleanChunk(reply.Text)
+		if content == "" {
+			continue
+		}
+		thread.WriteString(fmt.Sprintf("%s: %s \n", s.resolveUser(client, reply.User), content))
+	}
+	if thread.Len() == 0 {
+		return nil
+	}
+
+	link, err := client.GetPermalink(&slack.PermalinkParameters{
+		Channel: channelID,
+		Ts:      parent.Timestamp,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to get permalink for thread %s: %v", parent.Timestamp, err)
+	}
+
+	threadDoc := document
+	threadDoc.SourceURL = link
+
+	chunkChan <- types.ChunkSyncResult{
+		Chunk: types.Chunk{
+			Text:     thread.String(),
+			Document: threadDoc,
+		},
+		SkipClean: true,
+	}
+	return nil
+}
+
+// supportedFileExts lists the attachment file types we know how to pull
+// text out of today. Anything else is skipped with a log line rather than
+// indexed as a garbled binary chunk.
+var supportedFileExts = map[string]bool{
+	".txt": true,
+	".md":  true,
+}
+
+// processFiles downloads any files shared in a message and, for the
+// subset of types we can extract text from, emits each as its own
+// Document linked back to the message's permalink. Slack's file URLs
+// require the bot token on the request, so this goes through
+// client.GetFile rather than a bare http.Get.
+func (s *SlackConnector) processFiles(client *rateLimitedSlackClient, channelID string, message slack.Message, chunkChan chan types.ChunkSyncResult) error {
+	for _, file := range message.Files {
+		ext := strings.ToLower(filepath.Ext(file.Name))
+		if !supportedFileExts[ext] {
+			log.Printf("slack: skipping unsupported file type %s (%s)", file.Name, ext)
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := client.GetFile(file.URLPrivateDownload, &buf); err != nil {
+			if IsErrSlackRateLimit(err) {
+				time.Sleep(slackRateLimitBackoff)
+			}
+			return fmt.Errorf("unable to download file %s: %v", file.ID, err)
+		}
+
+		content := util.CleanChunk(buf.String())
+		if content == "" {
+			continue
+		}
+
+		link, err := client.GetPermalink(&slack.PermalinkParameters{
+			Channel: channelID,
+			Ts:      message.Timestamp,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to get permalink for file %s: %v", file.ID, err)
+		}
+
+		chunkChan <- types.ChunkSyncResult{
+			Chunk: types.Chunk{
+				Text: content,
+				Document: types.Document{
+					UniqueID:      file.ID,
+					Name:          file.Name,
+					SourceURL:     link,
+					ConnectorID:   s.ID(),
+					ConnectorType: string(s.Type()),
+					UpdatedAt:     time.Now(),
+				},
+			},
+			SkipClean: true,
+		}
+	}
+	return nil
+}
+
+// processAttachments indexes link-unfurl and attachment previews
+// (title/text/fallback), which often carry the actual content behind a
+// shared URL that we have no other way to fetch.
+func (s *SlackConnector) processAttachments(client *rateLimitedSlackClient, document types.Document, message slack.Message, chunkChan chan types.ChunkSyncResult) {
+	for _, att := range message.Attachments {
+		body := att.Text
+		if body == "" {
+			body = att.Fallback
+		}
+		parts := []string{att.Title, body}
+		content := util.CleanChunk(strings.Join(parts, "\n"))
+		if content == "" {
+			continue
+		}
+
+		chunkChan <- types.ChunkSyncResult{
+			Chunk: types.Chunk{
+				Text:     fmt.Sprintf("%s: %s \n", s.resolveUser(client, message.User), content),
+				Document: document,
+			},
+			SkipClean: true,
+		}
+	}
+}
+
+// startRealtime launches the long-lived RTM listener exactly once per
+// connector instance. It is called both from Init (on restart, when auth
+// is already valid) and from AuthCallback (after a fresh auth), so
+// liveOnce guards against starting it twice.
+func (s *SlackConnector) startRealtime() {
+	s.liveOnce.Do(func() {
+		go s.listenRealtime(s.context)
+	})
+}
+
+// listenRealtime keeps an RTM connection open for the life of ctx,
+// forwarding message, message_changed, and message_deleted events into
+// the same chunk pipeline a periodic Sync uses, via s.liveChan. This
+// turns Slack from a batch-polled source into a live index, reducing
+// staleness for the RAG prompt path in handlePrompt.
+func (s *SlackConnector) listenRealtime(ctx context.Context) {
+	defer close(s.liveChan)
+
+	client, err := s.getClient()
+	if err != nil {
+		log.Printf("slack: unable to start realtime listener: %v", err)
+		return
+	}
+
+	rtm := client.NewRTM()
+	go rtm.ManageConnection()
+	defer rtm.Disconnect()
+
+	log.Printf("slack: realtime listener started")
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("slack: realtime listener stopped")
+			return
+		case evt, ok := <-rtm.IncomingEvents:
+			if !ok {
+				return
+			}
+			switch data := evt.Data.(type) {
+			case *slack.MessageEvent:
+				s.handleRealtimeEvent(client, data)
+			case *slack.UserChangeEvent:
+				// A display name or handle change: drop the stale cached
+				// name so the next message from them re-resolves it.
+				userNameCache.invalidate(data.User.ID)
+			case *slack.ChannelRenameEvent:
+				channelNameCache.invalidate(data.Channel.ID)
+			}
+		}
+	}
+}
+
+// handleRealtimeEvent routes a single realtime Slack message event onto
+// s.liveChan. New messages are chunked individually, unlike the buffered
+// batching fetchAndProcessChannelMessages does for historical backfill,
+// which keeps a stable one-to-one mapping from message timestamp to chunk
+// hash so a later message_changed/message_deleted event can find the
+// right chunk.
+func (s *SlackConnector) handleRealtimeEvent(client *rateLimitedSlackClient, ev *slack.MessageEvent) {
+	switch ev.SubType {
+	case "message_deleted":
+		s.deleteLiveChunk(ev.DeletedTimestamp)
+	case "message_changed":
+		if ev.SubMessage == nil {
+			return
+		}
+		s.emitLiveMessage(client, ev.Channel, *ev.SubMessage)
+	case "":
+		s.emitLiveMessage(client, ev.Channel, ev.Msg)
+	default:
+		// channel_join, channel_topic, bot_message, etc carry no chunkable
+		// content we track.
+	}
+}
+
+// emitLiveMessage converts a single realtime message into a chunk and
+// remembers the hash it was assigned, so a later edit or delete for the
+// same timestamp can find it again.
+func (s *SlackConnector) emitLiveMessage(client *rateLimitedSlackClient, channelID string, message slack.Msg) {
+	content := util.CleanChunk(message.Text)
+	if content == "" {
+		return
+	}
+	text := fmt.Sprintf("%s: %s \n", s.resolveUser(client, message.User), content)
+
+	link, err := client.GetPermalink(&slack.PermalinkParameters{
+		Channel: channelID,
+		Ts:      message.Timestamp,
+	})
+	if err != nil {
+		log.Printf("slack: unable to get permalink for realtime message: %v", err)
+		return
+	}
+
+	s.liveMu.Lock()
+	prevHash := s.liveChunks[message.Timestamp]
+	s.liveChunks[message.Timestamp] = util.HashText(text)
+	s.liveMu.Unlock()
+
+	s.liveChan <- types.ChunkSyncResult{
+		Chunk: types.Chunk{
+			Text: text,
+			Document: types.Document{
+				UniqueID:      channelID,
+				Name:          s.resolveChannelName(client, channelID),
+				SourceURL:     link,
+				ConnectorID:   s.ID(),
+				ConnectorType: string(s.Type()),
+				UpdatedAt:     time.Now(),
+			},
+		},
+		SkipClean:  true,
+		DeleteHash: prevHash, // empty for a brand new message, a no-op delete
+	}
+}
+
+// deleteLiveChunk removes the chunk tracked for the given message
+// timestamp, if any. Used for message_deleted events.
+func (s *SlackConnector) deleteLiveChunk(ts string) {
+	if ts == "" {
+		return
+	}
+
+	s.liveMu.Lock()
+	prevHash, ok := s.liveChunks[ts]
+	delete(s.liveChunks, ts)
+	s.liveMu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	s.liveChan <- types.ChunkSyncResult{DeleteHash: prevHash}
+}
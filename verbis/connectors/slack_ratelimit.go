@@ -0,0 +1,190 @@
+package connectors
+
+import (
+	"log"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// slackAPITier identifies one of Slack's documented Tier 1-4 rate-limit
+// buckets. Most methods the connector calls fall under Tier 2 or Tier 3;
+// users.info is the one high-volume Tier 4 call (one per distinct author
+// per workspace, thanks to userNameCache).
+type slackAPITier int
+
+const (
+	slackTier2 slackAPITier = iota
+	slackTier3
+	slackTier4
+)
+
+// slackTierInterval is the minimum spacing between requests in a tier,
+// derived from Slack's documented per-minute budgets. Spacing calls this
+// way proactively keeps a normal sync under the limit, instead of relying
+// entirely on reacting to 429s after the fact.
+var slackTierInterval = map[slackAPITier]time.Duration{
+	slackTier2: 3 * time.Second,
+	slackTier3: 1200 * time.Millisecond,
+	slackTier4: 600 * time.Millisecond,
+}
+
+const (
+	// maxSlackRetries bounds how many times call retries a single request
+	// before giving up and surfacing the error to the caller.
+	maxSlackRetries = 5
+	// slackBaseBackoff is the starting backoff for transient (non-429)
+	// errors, such as a 5xx from Slack's edge. It doubles on each retry.
+	slackBaseBackoff = 500 * time.Millisecond
+)
+
+// rateLimitedSlackClient wraps *slack.Client so every call site goes
+// through the same proactive token-bucket throttling, Retry-After
+// handling, and jittered backoff for transient errors, rather than each
+// caller hand-rolling its own time.Sleep around a fixed backoff.
+type rateLimitedSlackClient struct {
+	*slack.Client
+
+	mu       sync.Mutex
+	lastCall map[slackAPITier]time.Time
+}
+
+func newRateLimitedSlackClient(client *slack.Client) *rateLimitedSlackClient {
+	return &rateLimitedSlackClient{
+		Client:   client,
+		lastCall: make(map[slackAPITier]time.Time),
+	}
+}
+
+// throttle blocks until tier's minimum interval has elapsed since the
+// last call in that tier, so we never even attempt requests fast enough
+// to draw a 429 under normal conditions.
+func (c *rateLimitedSlackClient) throttle(tier slackAPITier) {
+	c.mu.Lock()
+	wait := time.Duration(0)
+	if last, ok := c.lastCall[tier]; ok {
+		if elapsed := time.Since(last); elapsed < slackTierInterval[tier] {
+			wait = slackTierInterval[tier] - elapsed
+		}
+	}
+	c.lastCall[tier] = time.Now().Add(wait)
+	c.mu.Unlock()
+
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// call proactively throttles to tier's budget and runs fn, retrying on
+// rate-limit or transient 5xx errors: a 429 waits for exactly the
+// server-supplied Retry-After, anything transient backs off exponentially
+// with jitter, up to maxSlackRetries attempts.
+func (c *rateLimitedSlackClient) call(tier slackAPITier, name string, fn func() error) error {
+	backoff := slackBaseBackoff
+	for attempt := 0; ; attempt++ {
+		c.throttle(tier)
+
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		if rlErr, ok := err.(*slack.RateLimitedError); ok {
+			if attempt >= maxSlackRetries {
+				return err
+			}
+			log.Printf("slack: %s rate limited, retrying after %s", name, rlErr.RetryAfter)
+			time.Sleep(rlErr.RetryAfter)
+			continue
+		}
+
+		if attempt >= maxSlackRetries || !isTransientSlackError(err) {
+			return err
+		}
+
+		sleep := backoff + time.Duration(rand.Int63n(int64(backoff)/2+1))
+		log.Printf("slack: %s failed (%v), retrying in %s", name, err, sleep)
+		time.Sleep(sleep)
+		backoff *= 2
+	}
+}
+
+// isTransientSlackError reports whether err looks like a one-off server
+// hiccup (5xx) worth retrying, as opposed to a request we'll never
+// succeed at (bad auth, bad args, channel_not_found, etc).
+func isTransientSlackError(err error) bool {
+	statusErr, ok := err.(*slack.StatusCodeError)
+	if !ok {
+		return false
+	}
+	return statusErr.Code >= http.StatusInternalServerError
+}
+
+// GetConversations wraps slack.Client.GetConversations (conversations.list,
+// Tier 2).
+func (c *rateLimitedSlackClient) GetConversations(params *slack.GetConversationsParameters) (channels []slack.Channel, nextCursor string, err error) {
+	err = c.call(slackTier2, "conversations.list", func() error {
+		var callErr error
+		channels, nextCursor, callErr = c.Client.GetConversations(params)
+		return callErr
+	})
+	return channels, nextCursor, err
+}
+
+// GetConversationHistory wraps slack.Client.GetConversationHistory
+// (conversations.history, Tier 3).
+func (c *rateLimitedSlackClient) GetConversationHistory(params *slack.GetConversationHistoryParameters) (resp *slack.GetConversationHistoryResponse, err error) {
+	err = c.call(slackTier3, "conversations.history", func() error {
+		var callErr error
+		resp, callErr = c.Client.GetConversationHistory(params)
+		return callErr
+	})
+	return resp, err
+}
+
+// GetConversationReplies wraps slack.Client.GetConversationReplies
+// (conversations.replies, Tier 3).
+func (c *rateLimitedSlackClient) GetConversationReplies(params *slack.GetConversationRepliesParameters) (msgs []slack.Message, hasMore bool, nextCursor string, err error) {
+	err = c.call(slackTier3, "conversations.replies", func() error {
+		var callErr error
+		msgs, hasMore, nextCursor, callErr = c.Client.GetConversationReplies(params)
+		return callErr
+	})
+	return msgs, hasMore, nextCursor, err
+}
+
+// GetPermalink wraps slack.Client.GetPermalink (chat.getPermalink, Tier 3).
+func (c *rateLimitedSlackClient) GetPermalink(params *slack.PermalinkParameters) (link string, err error) {
+	err = c.call(slackTier3, "chat.getPermalink", func() error {
+		var callErr error
+		link, callErr = c.Client.GetPermalink(params)
+		return callErr
+	})
+	return link, err
+}
+
+// AuthTest wraps slack.Client.AuthTest (auth.test, Tier 2).
+func (c *rateLimitedSlackClient) AuthTest() (resp *slack.AuthTestResponse, err error) {
+	err = c.call(slackTier2, "auth.test", func() error {
+		var callErr error
+		resp, callErr = c.Client.AuthTest()
+		return callErr
+	})
+	return resp, err
+}
+
+// GetUserInfo wraps slack.Client.GetUserInfo (users.info, Tier 4). This is
+// the highest-volume call the connector makes, bounded in practice by
+// userNameCache so it's paid once per distinct author rather than once
+// per message.
+func (c *rateLimitedSlackClient) GetUserInfo(userID string) (user *slack.User, err error) {
+	err = c.call(slackTier4, "users.info", func() error {
+		var callErr error
+		user, callErr = c.Client.GetUserInfo(userID)
+		return callErr
+	})
+	return user, err
+}
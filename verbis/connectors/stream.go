@@ -0,0 +1,78 @@
+package connectors
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// streamWindowSize is the size of each bounded read issued against a
+// response body by readAllStreaming, so a large export is never buffered
+// into a single intermediate []byte the size of the whole file.
+const streamWindowSize = 64 * 1024
+
+// readAllStreaming reads r in streamWindowSize windows, decoding safely
+// at UTF-8 rune boundaries (a window may otherwise end in the middle of a
+// multi-byte rune, which would garble the last character of that
+// window), and accumulates the result. It exists for sources like
+// Drive's Files.Export, which has no Range-request support and so can't
+// be resumed, but which we still don't want to pull into memory via a
+// single io.ReadAll of a multi-GiB export.
+func readAllStreaming(r io.Reader) (string, error) {
+	br := bufio.NewReaderSize(r, streamWindowSize)
+	var out strings.Builder
+
+	buf := make([]byte, streamWindowSize)
+	var pending []byte
+	for {
+		n, err := br.Read(buf)
+		if n > 0 {
+			pending = append(pending, buf[:n]...)
+
+			// Hold back any trailing bytes that don't yet form a complete
+			// rune, so the next window can complete them instead of
+			// writing a truncated character.
+			valid := validUTF8Prefix(pending)
+			out.Write(pending[:valid])
+			pending = pending[valid:]
+		}
+		if err == io.EOF {
+			out.Write(pending)
+			return out.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// validUTF8Prefix returns the length of the longest prefix of b whose
+// last rune is either complete or genuinely invalid (as opposed to
+// merely truncated because the window ended mid-rune).
+func validUTF8Prefix(b []byte) int {
+	n := len(b)
+
+	// Walk back at most utf8.UTFMax bytes looking for the start of the
+	// trailing rune.
+	i := n
+	for i > 0 && n-i < utf8.UTFMax && !utf8.RuneStart(b[i-1]) {
+		i--
+	}
+	if i == 0 {
+		// No rune-start byte within range; nothing meaningful to hold back.
+		return n
+	}
+
+	r, size := utf8.DecodeRune(b[i-1:])
+	if r == utf8.RuneError && size <= 1 {
+		// Not a truncation, just an invalid byte sequence - pass it through
+		// as-is rather than buffering forever waiting for it to "complete".
+		return n
+	}
+	if i-1+size <= n {
+		// The trailing rune is already complete.
+		return n
+	}
+	return i - 1
+}
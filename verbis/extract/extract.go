@@ -0,0 +1,105 @@
+// Package extract turns a downloaded binary file (PDF, DOCX, PPTX, ...)
+// into a sequence of structural blocks, so that chunking can respect
+// document structure (tables, headings) instead of slicing raw characters.
+package extract
+
+import (
+	"context"
+	"io"
+)
+
+// ExtractedBlock is one structural unit of a parsed document, such as a
+// paragraph, a table, or a heading. Extractors emit blocks in document
+// order.
+type ExtractedBlock struct {
+	Text string
+
+	// Page is the 1-indexed page the block was found on, or 0 if the
+	// source format has no pagination (e.g. DOCX).
+	Page int
+
+	// HeadingLevel is non-zero when the block is a heading, with 1 being
+	// the topmost level.
+	HeadingLevel int
+
+	// IsTable marks a block whose Text is a flattened table, which
+	// ChunkBlocks will not split across chunks.
+	IsTable bool
+
+	// IsFigure marks a block that came from an image or figure caption.
+	IsFigure bool
+}
+
+// Extractor parses a file into ExtractedBlocks. mimeType is the source
+// file's MIME type, passed through unchanged from the connector that
+// downloaded it.
+type Extractor interface {
+	Extract(ctx context.Context, mimeType string, r io.Reader) ([]ExtractedBlock, error)
+}
+
+// ChunkBlocks packs blocks into chunks of at most maxChars runes each,
+// preferring to start a new chunk at a heading and never splitting a
+// single table or figure block across chunks (a table block that alone
+// exceeds maxChars is kept intact rather than truncated).
+func ChunkBlocks(blocks []ExtractedBlock, maxChars int) []string {
+	var chunks []string
+	var cur string
+
+	flush := func() {
+		if cur != "" {
+			chunks = append(chunks, cur)
+			cur = ""
+		}
+	}
+
+	for _, b := range blocks {
+		if b.Text == "" {
+			continue
+		}
+
+		if b.IsTable || b.IsFigure {
+			// Keep structural blocks intact: start a fresh chunk for the
+			// block unless it fits alongside what's already buffered.
+			if cur != "" && len(cur)+len(b.Text)+1 > maxChars {
+				flush()
+			}
+			if cur != "" {
+				cur += "\n" + b.Text
+			} else {
+				cur = b.Text
+			}
+			continue
+		}
+
+		if b.HeadingLevel > 0 {
+			flush()
+		}
+
+		if len(cur)+len(b.Text)+1 > maxChars {
+			flush()
+		}
+
+		if len(b.Text) > maxChars {
+			// A single paragraph bigger than maxChars: fall back to a
+			// fixed-size split so we still make forward progress.
+			flush()
+			for i := 0; i < len(b.Text); i += maxChars {
+				end := i + maxChars
+				if end > len(b.Text) {
+					end = len(b.Text)
+				}
+				chunks = append(chunks, b.Text[i:end])
+			}
+			continue
+		}
+
+		if cur != "" {
+			cur += "\n" + b.Text
+		} else {
+			cur = b.Text
+		}
+	}
+	flush()
+
+	return chunks
+}
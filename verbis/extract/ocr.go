@@ -0,0 +1,71 @@
+package extract
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/verbis-ai/verbis/verbis/util"
+)
+
+const (
+	tesseractPath = "tesseract/tesseract"
+
+	// ocrTimeout bounds a single OCR invocation, since Tesseract can spend
+	// a surprisingly long time on a dense scanned page.
+	ocrTimeout = 60 * time.Second
+)
+
+// OCRExtractor runs a bundled Tesseract binary over an image (or a
+// rasterized scanned PDF page) to recover text that has no machine-
+// readable layer at all. It has no notion of document structure, so
+// every input comes back as a single plain-text block.
+type OCRExtractor struct{}
+
+func NewOCRExtractor() *OCRExtractor {
+	return &OCRExtractor{}
+}
+
+func (e *OCRExtractor) Extract(ctx context.Context, mimeType string, r io.Reader) ([]ExtractedBlock, error) {
+	distPath, err := util.GetDistPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dist path: %v", err)
+	}
+
+	tmp, err := os.CreateTemp("", "verbis-ocr-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file for OCR input: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		return nil, fmt.Errorf("failed to write OCR input: %v", err)
+	}
+
+	ocrCtx, cancel := context.WithTimeout(ctx, ocrTimeout)
+	defer cancel()
+
+	binPath := filepath.Join(distPath, tesseractPath)
+	// "stdout" tells tesseract to write its output to stdout instead of a
+	// <name>.txt file alongside the input.
+	cmd := exec.CommandContext(ocrCtx, binPath, tmp.Name(), "stdout")
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("error executing tesseract: %v", err)
+	}
+
+	text := util.CleanChunk(stdout.String())
+	if text == "" {
+		return nil, nil
+	}
+
+	return []ExtractedBlock{{Text: text, IsFigure: true}}, nil
+}
@@ -0,0 +1,59 @@
+package extract
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// PDFExtractor is a pure-Go fallback for application/pdf used when the
+// unstructured binary is unavailable or fails. It has no notion of
+// headings or tables, so every page comes back as a single plain-text
+// block.
+type PDFExtractor struct{}
+
+func NewPDFExtractor() *PDFExtractor {
+	return &PDFExtractor{}
+}
+
+func (e *PDFExtractor) Extract(ctx context.Context, mimeType string, r io.Reader) ([]ExtractedBlock, error) {
+	if mimeType != "application/pdf" {
+		return nil, fmt.Errorf("PDFExtractor does not support mime type %s", mimeType)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pdf: %v", err)
+	}
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pdf: %v", err)
+	}
+
+	var blocks []ExtractedBlock
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+
+		text, err := page.GetPlainText(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract text from page %d: %v", i, err)
+		}
+		if text == "" {
+			continue
+		}
+
+		blocks = append(blocks, ExtractedBlock{
+			Text: text,
+			Page: i,
+		})
+	}
+
+	return blocks, nil
+}
@@ -0,0 +1,80 @@
+package extract
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/verbis-ai/verbis/verbis/util"
+)
+
+const (
+	unstructuredPath = "unstructured/unstructured"
+)
+
+// unstructuredElement mirrors the subset of the `unstructured` CLI's JSON
+// output we care about. The real tool emits several more fields (id,
+// coordinates, metadata.languages, ...) which we ignore.
+type unstructuredElement struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	Metadata struct {
+		PageNumber int `json:"page_number"`
+	} `json:"metadata"`
+}
+
+// UnstructuredExtractor shells out to a bundled `unstructured` binary,
+// following the same util.GetDistPath convention as the pdftotext binary
+// in connectors/binary.go. It handles PDF, DOCX, PPTX and XLSX files and
+// reports per-element structure (headings, tables) the plain-text
+// extractors can't.
+type UnstructuredExtractor struct{}
+
+func NewUnstructuredExtractor() *UnstructuredExtractor {
+	return &UnstructuredExtractor{}
+}
+
+func (e *UnstructuredExtractor) Extract(ctx context.Context, mimeType string, r io.Reader) ([]ExtractedBlock, error) {
+	distPath, err := util.GetDistPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dist path: %v", err)
+	}
+
+	binPath := filepath.Join(distPath, unstructuredPath)
+	cmd := exec.CommandContext(ctx, binPath, "-f", "json", "--mime-type", mimeType, "-")
+
+	var stdout bytes.Buffer
+	cmd.Stdin = r
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("error executing unstructured: %v", err)
+	}
+
+	var elements []unstructuredElement
+	if err := json.Unmarshal(stdout.Bytes(), &elements); err != nil {
+		return nil, fmt.Errorf("failed to parse unstructured output: %v", err)
+	}
+
+	blocks := make([]ExtractedBlock, 0, len(elements))
+	for _, el := range elements {
+		block := ExtractedBlock{
+			Text: el.Text,
+			Page: el.Metadata.PageNumber,
+		}
+		switch el.Type {
+		case "Title":
+			block.HeadingLevel = 1
+		case "Table":
+			block.IsTable = true
+		case "Image", "Figure", "FigureCaption":
+			block.IsFigure = true
+		}
+		blocks = append(blocks, block)
+	}
+
+	return blocks, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import "sync"
+
+// ConnectorIOStats is a cumulative, per-connector snapshot of the
+// embed/store throughput chunkAdder has pushed through a sync: bytes of
+// chunk text sent to the embedding model, bytes of embedding data written
+// to the store, and how many chunks have been added in total. Syncer
+// keeps one of these per connector ID so GetConnectorStates can surface
+// them without the store needing to track them itself. Bytes read from
+// the connector's source are tracked separately, on SyncProgress.
+type ConnectorIOStats struct {
+	BytesEmbedded int64 `json:"bytes_embedded"`
+	BytesStored   int64 `json:"bytes_stored"`
+	ChunksAdded   int64 `json:"chunks_added"`
+}
+
+// ioStatsRegistry is a mutex-guarded map of ConnectorIOStats, separate
+// from Syncer.mu since it's written from chunkAdder's flush goroutines
+// rather than from the connector-registry call paths that mu protects.
+type ioStatsRegistry struct {
+	mu   sync.Mutex
+	byID map[string]*ConnectorIOStats
+}
+
+func newIOStatsRegistry() *ioStatsRegistry {
+	return &ioStatsRegistry{byID: map[string]*ConnectorIOStats{}}
+}
+
+// get returns a copy of the current stats for connectorID, or a zero
+// value if none have been recorded yet.
+func (r *ioStatsRegistry) get(connectorID string) ConnectorIOStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.byID[connectorID]; ok {
+		return *s
+	}
+	return ConnectorIOStats{}
+}
+
+func (r *ioStatsRegistry) addFlush(connectorID string, bytesEmbedded, bytesStored, chunksAdded int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e := r.entry(connectorID)
+	e.BytesEmbedded += bytesEmbedded
+	e.BytesStored += bytesStored
+	e.ChunksAdded += chunksAdded
+}
+
+// entry returns (creating if absent) the stats slot for connectorID.
+// Caller must hold r.mu.
+func (r *ioStatsRegistry) entry(connectorID string) *ConnectorIOStats {
+	e, ok := r.byID[connectorID]
+	if !ok {
+		e = &ConnectorIOStats{}
+		r.byID[connectorID] = e
+	}
+	return e
+}
+
+// delete drops connectorID's stats, called when a connector is removed so
+// the registry doesn't grow unbounded across add/delete churn.
+func (r *ioStatsRegistry) delete(connectorID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.byID, connectorID)
+}
@@ -0,0 +1,59 @@
+package keychain
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+)
+
+// IMAPCredentials holds everything IMAPConnector needs to connect and
+// authenticate against a mailbox: the server to dial, the SASL mechanism
+// to use, and either a password/app-password or an OAuth bearer token as
+// the secret. Unlike the OAuth connectors, there's no shared app-level
+// client ID/secret to combine with a per-user token, so the whole set of
+// connection details lives here rather than split across
+// types.BuildCredentials and an oauth2.Token.
+type IMAPCredentials struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+
+	// Mechanism is one of "PLAIN", "LOGIN", "XOAUTH2", or "OAUTHBEARER".
+	// Empty is treated as "PLAIN".
+	Mechanism string `json:"mechanism"`
+
+	// Secret is the password/app-password for PLAIN/LOGIN, or the OAuth
+	// access token for XOAUTH2/OAUTHBEARER.
+	Secret string `json:"secret"`
+}
+
+func imapKey(connectorID string) string {
+	return fmt.Sprintf("imap-%s-credentials", connectorID)
+}
+
+// IMAPCredentialsFromKeychain returns the stored IMAP credentials for
+// connectorID, or an error if none have been saved yet.
+func IMAPCredentialsFromKeychain(connectorID string) (*IMAPCredentials, error) {
+	blob, err := keyring.Get(keyringService, imapKey(connectorID))
+	if err != nil {
+		return nil, fmt.Errorf("unable to get IMAP credentials from keyring: %s", err)
+	}
+	var creds IMAPCredentials
+	if err := json.Unmarshal([]byte(blob), &creds); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal IMAP credentials: %s", err)
+	}
+	return &creds, nil
+}
+
+// SaveIMAPCredentialsToKeychain persists creds for connectorID.
+func SaveIMAPCredentialsToKeychain(creds *IMAPCredentials, connectorID string) error {
+	blob, err := json.Marshal(creds)
+	if err != nil {
+		return fmt.Errorf("unable to marshal IMAP credentials: %v", err)
+	}
+	if err := keyring.Set(keyringService, imapKey(connectorID), string(blob)); err != nil {
+		return fmt.Errorf("unable to save IMAP credentials to keychain: %v", err)
+	}
+	return nil
+}
@@ -1,8 +1,15 @@
 package keychain
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
+	"strings"
+	"time"
 
 	"github.com/verbis-ai/verbis/verbis/types"
 	"github.com/zalando/go-keyring"
@@ -11,29 +18,199 @@ import (
 
 const (
 	keyringService = "VerbisAI"
+
+	// encryptionKeyItem is the keyring key under which the per-install
+	// AES-256 key used to encrypt stored tokens lives. It's shared across
+	// connectors (there's nothing connector-specific to derive it from),
+	// generated once on first use, and never rotated.
+	encryptionKeyItem = "encryption-key"
+
+	// chunkSize bounds how many base64 bytes go into a single keychain
+	// item. Some OS keychains (notably macOS's) reject items above a few
+	// KB, and encrypted Microsoft access tokens routinely blow past that,
+	// so SaveTokenToKeychain splits the encrypted payload into
+	// numbered chunks under separate items rather than one oversized one,
+	// mirroring the split-cookie pattern oauth2_proxy uses for oversized
+	// session payloads.
+	chunkSize = 2048
 )
 
+// storedToken is the on-disk representation of an oauth2.Token. oauth2.Token
+// keeps provider-specific fields like id_token in an unexported `raw` map
+// that encoding/json can't see via Extra(), so we carry IDToken alongside
+// the standard fields explicitly rather than losing it on every save/load
+// round trip.
+type storedToken struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+	IDToken      string    `json:"id_token,omitempty"`
+}
+
+// tokenManifest records how many chunks a token was split across, stored
+// under the token's own keychain key so TokenFromKeychain knows how many
+// numbered chunk items to read back.
+type tokenManifest struct {
+	Chunks int `json:"chunks"`
+}
+
+func tokenKey(connectorID string, connectorType types.ConnectorType) string {
+	return fmt.Sprintf("%s-%s-token", string(connectorType), connectorID)
+}
+
+func chunkKey(tokenKey string, i int) string {
+	return fmt.Sprintf("%s-chunk-%d", tokenKey, i)
+}
+
 func TokenFromKeychain(connectorID string, connectorType types.ConnectorType) (*oauth2.Token, error) {
-	tokenKey := fmt.Sprintf("%s-%s-token", string(connectorType), connectorID)
-	tokenJSON, err := keyring.Get(keyringService, tokenKey)
+	key := tokenKey(connectorID, connectorType)
+
+	manifestJSON, err := keyring.Get(keyringService, key)
 	if err != nil {
 		return nil, fmt.Errorf("unable to get token from keyring: %s", err)
 	}
-	var token oauth2.Token
-	err = json.Unmarshal([]byte(tokenJSON), &token)
-	return &token, err
+	var manifest tokenManifest
+	if err := json.Unmarshal([]byte(manifestJSON), &manifest); err != nil {
+		return nil, fmt.Errorf("unable to parse token manifest: %v", err)
+	}
+
+	var encoded strings.Builder
+	for i := 0; i < manifest.Chunks; i++ {
+		chunk, err := keyring.Get(keyringService, chunkKey(key, i))
+		if err != nil {
+			return nil, fmt.Errorf("unable to get token chunk %d from keyring: %s", i, err)
+		}
+		encoded.WriteString(chunk)
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded.String())
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode token payload: %v", err)
+	}
+	plaintext, err := decrypt(ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt token: %v", err)
+	}
+
+	var stored storedToken
+	if err := json.Unmarshal(plaintext, &stored); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal token: %v", err)
+	}
+
+	token := &oauth2.Token{
+		AccessToken:  stored.AccessToken,
+		TokenType:    stored.TokenType,
+		RefreshToken: stored.RefreshToken,
+		Expiry:       stored.Expiry,
+	}
+	if stored.IDToken != "" {
+		token = token.WithExtra(map[string]interface{}{"id_token": stored.IDToken})
+	}
+	return token, nil
 }
 
 func SaveTokenToKeychain(token *oauth2.Token, connectorID string, connectorType types.ConnectorType) error {
-	tokenKey := fmt.Sprintf("%s-%s-token", string(connectorType), connectorID)
-	bytes, err := json.Marshal(token)
+	stored := storedToken{
+		AccessToken:  token.AccessToken,
+		TokenType:    token.TokenType,
+		RefreshToken: token.RefreshToken,
+		Expiry:       token.Expiry,
+	}
+	if idToken, ok := token.Extra("id_token").(string); ok {
+		stored.IDToken = idToken
+	}
+
+	plaintext, err := json.Marshal(stored)
 	if err != nil {
 		return fmt.Errorf("unable to marshal token: %v", err)
 	}
-	err = keyring.Set(keyringService, tokenKey, string(bytes))
+	ciphertext, err := encrypt(plaintext)
 	if err != nil {
-		return fmt.Errorf("unable to save token to keychain: %v", err)
+		return fmt.Errorf("unable to encrypt token: %v", err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(ciphertext)
+
+	key := tokenKey(connectorID, connectorType)
+	numChunks := 0
+	for i := 0; i < len(encoded); i += chunkSize {
+		end := i + chunkSize
+		if end > len(encoded) {
+			end = len(encoded)
+		}
+		if err := keyring.Set(keyringService, chunkKey(key, numChunks), encoded[i:end]); err != nil {
+			return fmt.Errorf("unable to save token chunk %d to keychain: %v", numChunks, err)
+		}
+		numChunks++
+	}
+
+	manifestJSON, err := json.Marshal(tokenManifest{Chunks: numChunks})
+	if err != nil {
+		return fmt.Errorf("unable to marshal token manifest: %v", err)
+	}
+	if err := keyring.Set(keyringService, key, string(manifestJSON)); err != nil {
+		return fmt.Errorf("unable to save token manifest to keychain: %v", err)
 	}
 
 	return nil
 }
+
+// encryptionKey returns the per-install AES-256 key used to encrypt stored
+// tokens, generating and persisting one to the keyring on first use.
+func encryptionKey() ([]byte, error) {
+	encoded, err := keyring.Get(keyringService, encryptionKeyItem)
+	if err == nil {
+		return base64.StdEncoding.DecodeString(encoded)
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("unable to generate encryption key: %v", err)
+	}
+	if err := keyring.Set(keyringService, encryptionKeyItem, base64.StdEncoding.EncodeToString(key)); err != nil {
+		return nil, fmt.Errorf("unable to save encryption key to keychain: %v", err)
+	}
+	return key, nil
+}
+
+// encrypt seals plaintext with AES-256-GCM under the per-install key,
+// prepending the random nonce so decrypt doesn't need it passed separately.
+func encrypt(plaintext []byte) ([]byte, error) {
+	key, err := encryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func decrypt(ciphertext []byte) ([]byte, error) {
+	key, err := encryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, sealed, nil)
+}
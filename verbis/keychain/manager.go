@@ -0,0 +1,185 @@
+package keychain
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+const (
+	// managerScanInterval is how often TokenManager's background loop
+	// checks every registered connector's token for expiry, independent
+	// of whether anything is actively syncing, so a long-idle connector's
+	// token gets refreshed before the next sync tick needs it.
+	managerScanInterval = 30 * time.Second
+
+	// refreshMaxRetries bounds how many times the background loop retries
+	// a single connector's refresh before waiting for the next scan tick,
+	// so a connector whose IdP is temporarily down doesn't spin hot.
+	refreshMaxRetries = 3
+)
+
+// InvalidGrantHandler is called when a background refresh fails with
+// invalid_grant, the permanent failure a revoked or expired refresh token
+// produces, so the caller (the syncer) can flip ConnectorState.AuthValid
+// and let the user know re-auth is required.
+type InvalidGrantHandler func(ctx context.Context, connectorID string, connectorType types.ConnectorType)
+
+// TokenManager owns the one RefreshingTokenSource per connector, so
+// concurrent Sync goroutines for the same connector share a single
+// refresh-in-flight (RefreshingTokenSource's own mutex serializes it)
+// instead of each constructing its own throwaway instance and racing to
+// persist whichever refresh lands last. It also runs a background loop
+// that refreshes tokens proactively as they approach expiry, rather than
+// only on the next HTTP call that happens to need one.
+type TokenManager struct {
+	mu      sync.Mutex
+	sources map[string]*RefreshingTokenSource
+
+	onInvalidGrant InvalidGrantHandler
+}
+
+// NewTokenManager builds a TokenManager. onInvalidGrant may be nil, in
+// which case a permanently failed refresh is just logged.
+func NewTokenManager(onInvalidGrant InvalidGrantHandler) *TokenManager {
+	return &TokenManager{
+		sources:        map[string]*RefreshingTokenSource{},
+		onInvalidGrant: onInvalidGrant,
+	}
+}
+
+var (
+	defaultManagerOnce sync.Once
+	defaultManager     *TokenManager
+)
+
+// DefaultManager returns the process-wide TokenManager connectors share,
+// built lazily on first use since most connectors are constructed well
+// before the syncer has a chance to install an invalid-grant handler via
+// SetInvalidGrantHandler.
+func DefaultManager() *TokenManager {
+	defaultManagerOnce.Do(func() {
+		defaultManager = NewTokenManager(nil)
+	})
+	return defaultManager
+}
+
+// SetInvalidGrantHandler installs (or replaces) the handler called on a
+// permanently failed background refresh.
+func (m *TokenManager) SetInvalidGrantHandler(h InvalidGrantHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onInvalidGrant = h
+}
+
+// GetOrCreate returns the shared RefreshingTokenSource for connectorID,
+// constructing one (seeded from the keychain) on first use.
+func (m *TokenManager) GetOrCreate(connectorID string, connectorType types.ConnectorType, config *oauth2.Config) (*RefreshingTokenSource, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if src, ok := m.sources[connectorID]; ok {
+		return src, nil
+	}
+
+	tok, err := TokenFromKeychain(connectorID, connectorType)
+	if err != nil {
+		return nil, err
+	}
+	src := NewRefreshingTokenSource(config, tok, connectorID, connectorType)
+	m.sources[connectorID] = src
+	return src, nil
+}
+
+// Forget drops connectorID's cached token source, e.g. when the connector
+// is deleted, so a later re-add starts clean instead of reusing a stale
+// source under a reused connector ID.
+func (m *TokenManager) Forget(connectorID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sources, connectorID)
+}
+
+// Run scans every registered connector's token once per managerScanInterval
+// and proactively refreshes any that are due, until ctx is cancelled.
+func (m *TokenManager) Run(ctx context.Context) {
+	ticker := time.NewTicker(managerScanInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.refreshDueTokens(ctx)
+		}
+	}
+}
+
+func (m *TokenManager) refreshDueTokens(ctx context.Context) {
+	m.mu.Lock()
+	sources := make([]*RefreshingTokenSource, 0, len(m.sources))
+	for _, src := range m.sources {
+		sources = append(sources, src)
+	}
+	m.mu.Unlock()
+
+	for _, src := range sources {
+		m.refreshWithBackoff(ctx, src)
+	}
+}
+
+// refreshWithBackoff forces src to refresh if its token is due, retrying
+// transient failures with jittered exponential backoff. An invalid_grant
+// failure is reported to onInvalidGrant and not retried, since a revoked
+// or expired refresh token won't start working on the next attempt.
+func (m *TokenManager) refreshWithBackoff(ctx context.Context, src *RefreshingTokenSource) {
+	var lastErr error
+	for attempt := 0; attempt < refreshMaxRetries; attempt++ {
+		if attempt > 0 {
+			backoff := time.Duration(1<<uint(attempt-1)) * time.Second
+			jitter := time.Duration(rand.Int63n(int64(backoff/2) + 1))
+			select {
+			case <-time.After(backoff + jitter):
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		err := src.refreshIfDue()
+		if err == nil {
+			return
+		}
+		lastErr = err
+
+		if isInvalidGrant(err) {
+			log.Printf("token refresh for connector %s failed permanently: %v", src.connectorID, err)
+			m.mu.Lock()
+			handler := m.onInvalidGrant
+			m.mu.Unlock()
+			if handler != nil {
+				handler(ctx, src.connectorID, src.connectorType)
+			}
+			return
+		}
+	}
+	log.Printf("token refresh for connector %s failed after %d attempts: %v", src.connectorID, refreshMaxRetries, lastErr)
+}
+
+// isInvalidGrant reports whether err is an OAuth2 invalid_grant error, the
+// permanent failure mode a revoked or expired refresh token produces (as
+// opposed to a transient network/5xx error worth retrying).
+func isInvalidGrant(err error) bool {
+	var rErr *oauth2.RetrieveError
+	if errors.As(err, &rErr) {
+		return rErr.ErrorCode == "invalid_grant"
+	}
+	return strings.Contains(err.Error(), "invalid_grant")
+}
@@ -0,0 +1,174 @@
+package keychain
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+const (
+	// defaultLookahead is how far ahead of a token's actual expiry
+	// RefreshingTokenSource forces a refresh, so a connector never hands
+	// out a token that's about to die mid-request.
+	defaultLookahead = 2 * time.Minute
+
+	// DefaultRefreshTimeout bounds how long a single refresh attempt may
+	// take before RefreshingTokenSource gives up and returns the
+	// (possibly stale) token it already had, so a hung OAuth endpoint
+	// can't wedge a sync indefinitely.
+	DefaultRefreshTimeout = 30 * time.Second
+)
+
+// TokenState summarizes how much longer a connector's OAuth token is
+// expected to remain usable.
+type TokenState string
+
+const (
+	TokenStateValid        TokenState = "valid"
+	TokenStateExpiringSoon TokenState = "expiring_soon"
+	TokenStateInvalid      TokenState = "invalid"
+)
+
+// StateForToken classifies tok's remaining lifetime. A token with no
+// access token at all (e.g. one that failed to load) is Invalid; one
+// whose Expiry has already passed with no refresh token to recover it is
+// also Invalid; one expiring within defaultLookahead is ExpiringSoon.
+func StateForToken(tok *oauth2.Token) TokenState {
+	if tok == nil || tok.AccessToken == "" {
+		return TokenStateInvalid
+	}
+	if tok.Expiry.IsZero() {
+		return TokenStateValid
+	}
+
+	remaining := time.Until(tok.Expiry)
+	if remaining <= 0 && tok.RefreshToken == "" {
+		return TokenStateInvalid
+	}
+	if remaining < defaultLookahead {
+		return TokenStateExpiringSoon
+	}
+	return TokenStateValid
+}
+
+// RefreshingTokenSource wraps a connector's stored OAuth token with a
+// lookahead refresh: any Token() call within defaultLookahead of expiry
+// forces a refresh rather than handing back the about-to-die token, and
+// every successful refresh is persisted back to the keychain so the
+// rotated refresh token survives process restarts.
+type RefreshingTokenSource struct {
+	mu sync.Mutex
+
+	connectorID   string
+	connectorType types.ConnectorType
+	config        *oauth2.Config
+	current       *oauth2.Token
+
+	lookahead      time.Duration
+	refreshTimeout time.Duration
+}
+
+// NewRefreshingTokenSource builds a RefreshingTokenSource seeded with
+// token, the connector's last known-good token from the keychain.
+func NewRefreshingTokenSource(config *oauth2.Config, token *oauth2.Token, connectorID string, connectorType types.ConnectorType) *RefreshingTokenSource {
+	return &RefreshingTokenSource{
+		connectorID:    connectorID,
+		connectorType:  connectorType,
+		config:         config,
+		current:        token,
+		lookahead:      defaultLookahead,
+		refreshTimeout: DefaultRefreshTimeout,
+	}
+}
+
+// Token implements oauth2.TokenSource. It satisfies calls from the cached
+// token as long as that token isn't within the lookahead window of
+// expiring, and otherwise blocks for a refresh (bounded by
+// refreshTimeout).
+func (r *RefreshingTokenSource) Token() (*oauth2.Token, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if StateForToken(r.current) == TokenStateValid {
+		return r.current, nil
+	}
+
+	refreshed, err := r.refresh()
+	if err != nil {
+		if r.current != nil && r.current.AccessToken != "" {
+			// Better to hand back a soon-to-expire token than fail the
+			// whole request; the caller will surface a 401 if it's truly
+			// no good.
+			log.Printf("token refresh failed for connector %s, using existing token: %v", r.connectorID, err)
+			return r.current, nil
+		}
+		return nil, err
+	}
+
+	r.current = refreshed
+	return refreshed, nil
+}
+
+// refreshIfDue forces a refresh if the current token is ExpiringSoon or
+// Invalid, and is a no-op otherwise. Unlike Token(), it returns the real
+// refresh error instead of masking it behind the stale token, since its
+// caller (TokenManager's background loop) has no in-flight request to
+// protect and needs to know about a permanent failure like invalid_grant.
+func (r *RefreshingTokenSource) refreshIfDue() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if StateForToken(r.current) == TokenStateValid {
+		return nil
+	}
+
+	refreshed, err := r.refresh()
+	if err != nil {
+		return err
+	}
+	r.current = refreshed
+	return nil
+}
+
+// refresh forces a token exchange using the refresh token, bounded by
+// r.refreshTimeout so a hung OAuth endpoint can't wedge the caller.
+func (r *RefreshingTokenSource) refresh() (*oauth2.Token, error) {
+	if r.current == nil || r.current.RefreshToken == "" {
+		return nil, fmt.Errorf("no refresh token available for connector %s", r.connectorID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.refreshTimeout)
+	defer cancel()
+
+	type result struct {
+		token *oauth2.Token
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		// An oauth2.Token with no AccessToken/Expiry is never "valid", so
+		// this TokenSource always performs a live refresh.
+		forceToken := &oauth2.Token{RefreshToken: r.current.RefreshToken}
+		tok, err := r.config.TokenSource(ctx, forceToken).Token()
+		done <- result{token: tok, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("token refresh timed out after %s", r.refreshTimeout)
+	case res := <-done:
+		if res.err != nil {
+			return nil, fmt.Errorf("unable to refresh token: %v", res.err)
+		}
+		if err := SaveTokenToKeychain(res.token, r.connectorID, r.connectorType); err != nil {
+			log.Printf("failed to persist refreshed token for connector %s: %v", r.connectorID, err)
+		}
+		return res.token, nil
+	}
+}
@@ -0,0 +1,157 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/verbis-ai/verbis/verbis/metrics"
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+// llamaCppProvider talks to a llama.cpp server instance over its native
+// /completion and /embedding endpoints (not the OpenAI-compatible routes
+// some llama.cpp builds also expose, since those are already covered by
+// openAIProvider). Pull is a no-op: a llama.cpp server is started against
+// a single GGUF file chosen at launch time, not swapped per request.
+type llamaCppProvider struct {
+	baseURL string
+}
+
+func NewLlamaCppProvider(baseURL string) Provider {
+	return &llamaCppProvider{baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+func (p *llamaCppProvider) Pull(ctx context.Context, model string) error {
+	return nil
+}
+
+type llamaCppEmbedRequest struct {
+	Content string `json:"content"`
+}
+
+type llamaCppEmbedResponse struct {
+	Embedding []float32 `json:"embedding"`
+}
+
+func (p *llamaCppProvider) Embed(ctx context.Context, prompts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(prompts))
+	for i, prompt := range prompts {
+		jsonData, err := json.Marshal(llamaCppEmbedRequest{Content: prompt})
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/embedding", bytes.NewBuffer(jsonData))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		response, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		responseData, err := io.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		var apiResponse llamaCppEmbedResponse
+		if err := json.Unmarshal(responseData, &apiResponse); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal embedding response: %w", err)
+		}
+		embeddings[i] = apiResponse.Embedding
+	}
+	return embeddings, nil
+}
+
+type llamaCppCompletionRequest struct {
+	Prompt string `json:"prompt"`
+	Stream bool   `json:"stream"`
+}
+
+type llamaCppCompletionChunk struct {
+	Content string `json:"content"`
+	Stop    bool   `json:"stop"`
+}
+
+// renderPrompt flattens history and the final user turn into the plain
+// text llama.cpp's /completion endpoint expects, since (unlike Ollama and
+// OpenAI-compatible servers) it has no structured chat-message format of
+// its own.
+func renderPrompt(prompt string, history []types.HistoryItem) string {
+	var b strings.Builder
+	for _, h := range history {
+		fmt.Fprintf(&b, "%s: %s\n", h.Role, h.Content)
+	}
+	if prompt != "" {
+		fmt.Fprintf(&b, "user: %s\n", prompt)
+	}
+	b.WriteString("assistant:")
+	return b.String()
+}
+
+func (p *llamaCppProvider) Chat(ctx context.Context, prompt string, history []types.HistoryItem, opts ChatOptions, stream chan<- Token) (err error) {
+	defer func() {
+		outcome := "success"
+		if err != nil {
+			outcome = "failure"
+		}
+		metrics.ChatRequests.WithLabelValues(outcome).Inc()
+	}()
+
+	jsonData, err := json.Marshal(llamaCppCompletionRequest{
+		Prompt: renderPrompt(prompt, history),
+		Stream: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/completion", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	response, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		defer response.Body.Close()
+		decoder := json.NewDecoder(bufio.NewReader(response.Body))
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			var chunk llamaCppCompletionChunk
+			if err := decoder.Decode(&chunk); err != nil {
+				return
+			}
+			stream <- Token{Content: chunk.Content, Done: chunk.Stop}
+			if chunk.Stop {
+				close(stream)
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (p *llamaCppProvider) Rerank(ctx context.Context, chunks []*types.Chunk, query string) ([]*types.Chunk, error) {
+	return windowedRerank(ctx, p, "", chunks, query, defaultRerankWindow, defaultRerankStep, defaultRerankTopK)
+}
@@ -0,0 +1,223 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/verbis-ai/verbis/verbis/metrics"
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+// ollamaProvider talks to a local Ollama server over its native HTTP API.
+// It's the default Provider and the only one that actually pulls models,
+// since OpenAI-compatible and llama.cpp endpoints are assumed to already
+// have their models provisioned.
+type ollamaProvider struct {
+	host       string
+	embedModel string
+}
+
+func NewOllamaProvider(host, embedModel string) Provider {
+	return &ollamaProvider{host: host, embedModel: embedModel}
+}
+
+type ollamaPullRequest struct {
+	Name   string `json:"name"`
+	Stream bool   `json:"stream"`
+}
+
+type ollamaPullResponse struct {
+	Status string `json:"status"`
+}
+
+func (p *ollamaProvider) Pull(ctx context.Context, model string) (err error) {
+	defer func() {
+		outcome := "success"
+		if err != nil {
+			outcome = "failure"
+		}
+		metrics.ModelPulls.WithLabelValues(model, outcome).Inc()
+	}()
+
+	url := fmt.Sprintf("http://%s/api/pull", p.host)
+	jsonData, err := json.Marshal(ollamaPullRequest{Name: model, Stream: true})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	response, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	decoder := json.NewDecoder(response.Body)
+	var last ollamaPullResponse
+	for {
+		var resp ollamaPullResponse
+		if err := decoder.Decode(&resp); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		last = resp
+	}
+
+	if last.Status != "success" {
+		return fmt.Errorf("ollama pull response status is not 'success'")
+	}
+	return nil
+}
+
+type ollamaEmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type ollamaEmbedResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+func (p *ollamaProvider) Embed(ctx context.Context, prompts []string) ([][]float32, error) {
+	url := fmt.Sprintf("http://%s/api/embed", p.host)
+	jsonData, err := json.Marshal(ollamaEmbedRequest{Model: p.embedModel, Input: prompts})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	response, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	responseData, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse ollamaEmbedResponse
+	if err := json.Unmarshal(responseData, &apiResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal embeddings response: %w", err)
+	}
+	if len(apiResponse.Embeddings) != len(prompts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(prompts), len(apiResponse.Embeddings))
+	}
+	return apiResponse.Embeddings, nil
+}
+
+type ollamaChatRequest struct {
+	Model     string              `json:"model"`
+	Messages  []types.HistoryItem `json:"messages"`
+	Stream    bool                `json:"stream"`
+	KeepAlive string              `json:"keep_alive"`
+}
+
+type ollamaChatResponse struct {
+	Message types.HistoryItem `json:"message"`
+	Done    bool              `json:"done"`
+}
+
+func (p *ollamaProvider) Chat(ctx context.Context, prompt string, history []types.HistoryItem, opts ChatOptions, stream chan<- Token) (err error) {
+	defer func() {
+		outcome := "success"
+		if err != nil {
+			outcome = "failure"
+		}
+		metrics.ChatRequests.WithLabelValues(outcome).Inc()
+	}()
+
+	url := fmt.Sprintf("http://%s/api/chat", p.host)
+
+	messages := history
+	if prompt != "" {
+		messages = append(history, types.HistoryItem{Role: "user", Content: prompt})
+	}
+
+	jsonData, err := json.Marshal(ollamaChatRequest{
+		Model:     opts.Model,
+		Messages:  messages,
+		Stream:    true,
+		KeepAlive: opts.KeepAlive,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	response, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		defer response.Body.Close()
+		decoder := json.NewDecoder(bufio.NewReader(response.Body))
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			var resp ollamaChatResponse
+			if err := decoder.Decode(&resp); err != nil {
+				return
+			}
+			stream <- Token{Content: resp.Message.Content, Done: resp.Done}
+			if resp.Done {
+				close(stream)
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// rerankModelName is the same bundled RankGPT-style model
+// (custom-zephyr) the pre-abstraction rerankLLM used.
+const rerankModelName = "custom-zephyr"
+
+func (p *ollamaProvider) Rerank(ctx context.Context, chunks []*types.Chunk, query string) ([]*types.Chunk, error) {
+	return windowedRerank(ctx, p, rerankModelName, chunks, query, defaultRerankWindow, defaultRerankStep, defaultRerankTopK)
+}
+
+// chatSync drains a Chat call to completion and concatenates every
+// Token's Content, for Rerank implementations (windowedRerank) that need
+// a single response string rather than a stream.
+func chatSync(ctx context.Context, p Provider, prompt string, history []types.HistoryItem, opts ChatOptions) (string, error) {
+	stream := make(chan Token)
+	if err := p.Chat(ctx, prompt, history, opts, stream); err != nil {
+		return "", err
+	}
+
+	var content string
+	for tok := range stream {
+		content += tok.Content
+	}
+	return content, nil
+}
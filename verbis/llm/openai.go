@@ -0,0 +1,204 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/verbis-ai/verbis/verbis/metrics"
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+// openAIProvider talks to any OpenAI-compatible /chat/completions and
+// /embeddings endpoint: the public OpenAI API, or a self-hosted gateway
+// (vLLM, LiteLLM, etc.) that speaks the same wire format. Pull is a
+// no-op since these endpoints serve whatever model they're configured
+// with server-side; Verbis has no way to provision one remotely.
+type openAIProvider struct {
+	baseURL    string
+	apiKey     string
+	chatModel  string
+	embedModel string
+}
+
+func NewOpenAIProvider(baseURL, apiKey, chatModel, embedModel string) Provider {
+	return &openAIProvider{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKey:     apiKey,
+		chatModel:  chatModel,
+		embedModel: embedModel,
+	}
+}
+
+func (p *openAIProvider) Pull(ctx context.Context, model string) error {
+	return nil
+}
+
+func (p *openAIProvider) authedRequest(ctx context.Context, method, url string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+	return req, nil
+}
+
+type openAIEmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type openAIEmbedResponse struct {
+	Data []struct {
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (p *openAIProvider) Embed(ctx context.Context, prompts []string) ([][]float32, error) {
+	jsonData, err := json.Marshal(openAIEmbedRequest{Model: p.embedModel, Input: prompts})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := p.authedRequest(ctx, "POST", p.baseURL+"/embeddings", jsonData)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	responseData, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse openAIEmbedResponse
+	if err := json.Unmarshal(responseData, &apiResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal embeddings response: %w", err)
+	}
+	if len(apiResponse.Data) != len(prompts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(prompts), len(apiResponse.Data))
+	}
+
+	embeddings := make([][]float32, len(prompts))
+	for i, d := range apiResponse.Data {
+		embeddings[i] = d.Embedding
+	}
+	return embeddings, nil
+}
+
+type openAIChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIChatRequest struct {
+	Model    string              `json:"model"`
+	Messages []openAIChatMessage `json:"messages"`
+	Stream   bool                `json:"stream"`
+}
+
+type openAIChatStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+}
+
+func (p *openAIProvider) Chat(ctx context.Context, prompt string, history []types.HistoryItem, opts ChatOptions, stream chan<- Token) (err error) {
+	defer func() {
+		outcome := "success"
+		if err != nil {
+			outcome = "failure"
+		}
+		metrics.ChatRequests.WithLabelValues(outcome).Inc()
+	}()
+
+	messages := make([]openAIChatMessage, 0, len(history)+1)
+	for _, h := range history {
+		messages = append(messages, openAIChatMessage{Role: h.Role, Content: h.Content})
+	}
+	if prompt != "" {
+		messages = append(messages, openAIChatMessage{Role: "user", Content: prompt})
+	}
+
+	model := opts.Model
+	if model == "" {
+		model = p.chatModel
+	}
+
+	jsonData, err := json.Marshal(openAIChatRequest{Model: model, Messages: messages, Stream: true})
+	if err != nil {
+		return err
+	}
+
+	req, err := p.authedRequest(ctx, "POST", p.baseURL+"/chat/completions", jsonData)
+	if err != nil {
+		return err
+	}
+
+	response, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		defer response.Body.Close()
+		reader := bufio.NewReader(response.Body)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			if line == "" {
+				continue
+			}
+			if line == "[DONE]" {
+				stream <- Token{Done: true}
+				close(stream)
+				return
+			}
+
+			var chunk openAIChatStreamChunk
+			if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+				continue
+			}
+			if len(chunk.Choices) == 0 {
+				continue
+			}
+			done := chunk.Choices[0].FinishReason != nil
+			stream <- Token{Content: chunk.Choices[0].Delta.Content, Done: done}
+			if done {
+				close(stream)
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (p *openAIProvider) Rerank(ctx context.Context, chunks []*types.Chunk, query string) ([]*types.Chunk, error) {
+	return windowedRerank(ctx, p, p.chatModel, chunks, query, defaultRerankWindow, defaultRerankStep, defaultRerankTopK)
+}
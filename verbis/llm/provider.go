@@ -0,0 +1,84 @@
+// Package llm abstracts chat, embedding, model-pull, and rerank calls
+// behind a single Provider interface so the rest of Verbis isn't wired
+// directly to Ollama's HTTP shape. Ollama remains the default (and the
+// only provider that can actually pull a local model), with
+// OpenAI-compatible and llama.cpp server adapters available for pointing
+// Verbis at a remote or self-hosted inference endpoint instead.
+package llm
+
+import (
+	"context"
+
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+// Token is a single increment of a streamed Chat response: either a
+// content fragment, or the terminating message with Done set.
+type Token struct {
+	Content string
+	Done    bool
+}
+
+// ChatOptions tunes a single Chat call. Model is required; KeepAlive is
+// Ollama-specific (how long to keep the model warm in memory) and is
+// ignored by providers that don't support it.
+type ChatOptions struct {
+	Model     string
+	KeepAlive string
+}
+
+// Provider is the full surface a pluggable inference backend implements.
+// Chat always streams: a non-streaming caller can drain stream to EOF
+// and concatenate the Content of every Token before Done, the way
+// chatWithModel historically did ahead of this abstraction.
+type Provider interface {
+	// Pull fetches model into the provider's local serving directory, if
+	// the provider supports that notion (Ollama does; remote providers
+	// treat it as a no-op since their models are provisioned server-side).
+	Pull(ctx context.Context, model string) error
+
+	// Embed returns one embedding vector per prompt, in order.
+	Embed(ctx context.Context, prompts []string) ([][]float32, error)
+
+	// Chat streams the model's reply to prompt (appended to history as
+	// the final user turn) over stream, closing it once the final Token
+	// (Done: true) has been sent.
+	Chat(ctx context.Context, prompt string, history []types.HistoryItem, opts ChatOptions, stream chan<- Token) error
+
+	// Rerank reorders chunks by relevance to query, most relevant first.
+	Rerank(ctx context.Context, chunks []*types.Chunk, query string) ([]*types.Chunk, error)
+}
+
+// ProviderKind selects which Provider implementation NewFromConfig
+// constructs. "" is equivalent to ProviderOllama.
+type ProviderKind string
+
+const (
+	ProviderOllama   ProviderKind = "ollama"
+	ProviderOpenAI   ProviderKind = "openai"
+	ProviderLlamaCpp ProviderKind = "llamacpp"
+)
+
+// NewFromConfig builds the Provider selected by cfg's LLM* fields. A nil
+// cfg, or an empty/unrecognized cfg.LLMProvider, falls back to the
+// Ollama provider talking to ollamaHost so existing installs keep working
+// without any config changes. defaultEmbedModel is the Ollama embeddings
+// model Verbis bundles and pulls on boot, used when cfg doesn't override it.
+func NewFromConfig(cfg *types.Config, ollamaHost, defaultEmbedModel string) Provider {
+	if cfg == nil {
+		return NewOllamaProvider(ollamaHost, defaultEmbedModel)
+	}
+
+	switch ProviderKind(cfg.LLMProvider) {
+	case ProviderOpenAI:
+		return NewOpenAIProvider(cfg.LLMBaseURL, cfg.LLMAPIKey, cfg.LLMChatModel, cfg.LLMEmbedModel)
+	case ProviderLlamaCpp:
+		return NewLlamaCppProvider(cfg.LLMBaseURL)
+	default:
+		embedModel := cfg.LLMEmbedModel
+		if embedModel == "" {
+			embedModel = defaultEmbedModel
+		}
+		return NewOllamaProvider(ollamaHost, embedModel)
+	}
+}
@@ -0,0 +1,216 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+
+	"github.com/verbis-ai/verbis/verbis/metrics"
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+// Default sliding-window parameters for windowedRerank, following the
+// RankGPT paper: rank a window of defaultRerankWindow candidates at a
+// time, slide back by defaultRerankStep, and repeat until the window
+// reaches the head of the list.
+const (
+	defaultRerankWindow = 20
+	defaultRerankStep   = 10
+	defaultRerankTopK   = 10
+)
+
+// windowedRerank implements the RankGPT sliding-window listwise
+// reranking algorithm on top of any Provider's Chat method: a
+// single-pass prompt asking the model to rank all chunks at once
+// degrades badly past a handful of candidates, so instead it ranks a
+// window of chunks at a time, starting from the tail of the list, and
+// slides the window towards the head, re-ranking the overlapping region
+// each time for stability.
+func windowedRerank(ctx context.Context, p Provider, model string, chunks []*types.Chunk, query string, window, step, topK int) (res []*types.Chunk, err error) {
+	defer func() {
+		outcome := "success"
+		if err != nil {
+			outcome = "failure"
+		}
+		metrics.RerankInvocations.WithLabelValues(outcome).Inc()
+	}()
+
+	if len(chunks) == 0 {
+		return []*types.Chunk{}, nil
+	}
+	if window <= 0 {
+		window = defaultRerankWindow
+	}
+	if step <= 0 {
+		step = defaultRerankStep
+	}
+
+	ranked := make([]*types.Chunk, len(chunks))
+	copy(ranked, chunks)
+
+	end := len(ranked)
+	start := end - window
+	if start < 0 {
+		start = 0
+	}
+
+	for {
+		if err := rerankWindow(ctx, p, model, ranked[start:end], query); err != nil {
+			return nil, err
+		}
+
+		if start == 0 {
+			break
+		}
+		end -= step
+		start -= step
+		if start < 0 {
+			start = 0
+		}
+	}
+
+	if topK > 0 && topK < len(ranked) {
+		ranked = ranked[:topK]
+	}
+	return ranked, nil
+}
+
+// rerankWindow asks the LLM to rank the chunks in window and reorders
+// window in place according to the (sanitized) response.
+func rerankWindow(ctx context.Context, p Provider, model string, window []*types.Chunk, query string) error {
+	messages, err := makeRerankMessages(window, query)
+	if err != nil {
+		return fmt.Errorf("unable to create rerank messages: %w", err)
+	}
+
+	content, err := chatSync(ctx, p, "", messages, ChatOptions{Model: model})
+	if err != nil {
+		return fmt.Errorf("unable to generate rerank response: %w", err)
+	}
+
+	idxs, err := parseStringToIntArray(content)
+	if err != nil {
+		return fmt.Errorf("unable to parse rerank response: %w", err)
+	}
+
+	order := sanitizeRerankOrder(idxs, len(window))
+	reordered := make([]*types.Chunk, len(window))
+	for i, idx := range order {
+		reordered[i] = window[idx]
+	}
+	copy(window, reordered)
+	return nil
+}
+
+// sanitizeRerankOrder converts the model's 1-indexed "[i] > [j] > ..."
+// ranking into a valid 0-indexed permutation of [0, n): out-of-range
+// indices are dropped, duplicates are dropped after their first
+// occurrence, and any index the model never mentioned is appended in its
+// original order. This guarantees a usable ranking instead of silently
+// falling back to the unranked input on a malformed or hallucinated
+// response.
+func sanitizeRerankOrder(idxs []int, n int) []int {
+	seen := make([]bool, n)
+	order := make([]int, 0, n)
+	for _, idx := range idxs {
+		i := idx - 1
+		if i < 0 || i >= n || seen[i] {
+			continue
+		}
+		seen[i] = true
+		order = append(order, i)
+	}
+	for i := 0; i < n; i++ {
+		if !seen[i] {
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+// parseStringToIntArray parses a "[1] > [2] > [3]"-shaped ranking string
+// into the integer sequence [1, 2, 3].
+func parseStringToIntArray(input string) ([]int, error) {
+	parts := strings.Split(strings.ReplaceAll(input, "[", ""), "] > ")
+
+	var result []int
+	for _, part := range parts {
+		part = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(part), "> "), "]")
+		if part == "" {
+			continue
+		}
+		num, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, num)
+	}
+	return result, nil
+}
+
+func makeRerankMessages(chunks []*types.Chunk, query string) ([]types.HistoryItem, error) {
+	data := struct {
+		Num   int
+		Query string
+	}{
+		Num:   len(chunks),
+		Query: query,
+	}
+
+	tmpl := `I will provide you with {{ .Num }} passages, each indicated by number identifier [].	Rank the passages based on their relevance to query: {{.Query}}.`
+	t, err := template.New("passages").Parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	if err := t.Execute(&buffer, data); err != nil {
+		return nil, err
+	}
+
+	tmplSuffix := "Search Query: {{ .Num }}. \nRank the {num} passages above based on their relevance to the search query. The passages should be listed in descending order using identifiers. The most relevant passages should be listed first. The output format should be [] > [], e.g., [1] > [2]. Only response the ranking results, do not say any word or explain."
+	t, err = template.New("passages").Parse(tmplSuffix)
+	if err != nil {
+		return nil, err
+	}
+	var suffixBuf bytes.Buffer
+	if err := t.Execute(&suffixBuf, data); err != nil {
+		return nil, err
+	}
+
+	messages := []types.HistoryItem{
+		{
+			Role:    "system",
+			Content: "You are RankGPT, an intelligent assistant that can rank passages based on their relevancy to the query.",
+		},
+		{
+			Role:    "user",
+			Content: buffer.String(),
+		},
+		{
+			Role:    "assistant",
+			Content: "Okay, please provide the passages.",
+		},
+	}
+
+	for i, chunk := range chunks {
+		messages = append(messages, []types.HistoryItem{
+			{
+				Role:    "user",
+				Content: fmt.Sprintf("\n[%d] %s: %s\n", i+1, chunk.Name, chunk.Text),
+			},
+			{
+				Role:    "assistant",
+				Content: fmt.Sprintf("Received passage [%d].", i+1),
+			},
+		}...)
+	}
+	messages = append(messages, types.HistoryItem{
+		Role:    "user",
+		Content: suffixBuf.String(),
+	})
+
+	return messages, nil
+}
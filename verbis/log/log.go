@@ -0,0 +1,203 @@
+// Package log provides a small leveled, facet-scoped logger. Each package
+// obtains its own facet-scoped Logger via New(facet), whose Debugf calls
+// are no-ops unless that facet is enabled via the VERBIS_TRACE environment
+// variable, a comma-separated list of facet names (e.g.
+// "VERBIS_TRACE=boot,sync,ollama"), with "all" acting as a wildcard.
+// This mirrors syncthing's STTRACE-driven facet logging.
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Level string
+
+const (
+	LevelDebug Level = "debug"
+	LevelInfo  Level = "info"
+	LevelWarn  Level = "warn"
+	LevelError Level = "error"
+	LevelFatal Level = "fatal"
+)
+
+var (
+	mu       sync.Mutex
+	output   io.Writer = os.Stderr
+	jsonMode           = os.Getenv("VERBIS_LOG_JSON") != ""
+	facets             = parseFacets(os.Getenv("VERBIS_TRACE"))
+)
+
+func parseFacets(v string) map[string]bool {
+	f := map[string]bool{}
+	for _, name := range strings.Split(v, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			f[name] = true
+		}
+	}
+	return f
+}
+
+// SetOutput redirects all future log output (of every facet) to w. It is
+// used by the boot sequence to tee output to masterLogPath.
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	output = w
+}
+
+// SetFormat overrides the output format selected at startup from
+// VERBIS_LOG_JSON, e.g. from a --log-format text|json flag parsed in main.
+// Any format other than "json" selects the human-readable text format.
+func SetFormat(format string) {
+	mu.Lock()
+	defer mu.Unlock()
+	jsonMode = format == "json"
+}
+
+// requestIDKey is the context.Value key under which WithRequestID stores a
+// request ID, so it isn't confused with keys used by other packages.
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying id, so it can be attached to
+// every log line emitted while handling a single HTTP request as it flows
+// from the API surface down into model inference, retrieval and connector
+// Sync calls.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID attached by WithRequestID, if
+// any.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok
+}
+
+// Logger is a facet-scoped leveled logger. Debugf is silent unless its
+// facet is enabled via VERBIS_TRACE. fields are structured key/value pairs
+// attached via With, included in every line this Logger emits.
+type Logger struct {
+	facet  string
+	fields map[string]interface{}
+}
+
+// New returns a Logger scoped to facet, e.g. log.New("boot").
+func New(facet string) *Logger {
+	return &Logger{facet: facet}
+}
+
+// With returns a Logger that attaches fields, in addition to any already
+// attached to l, to every line it logs. Typical fields are connector_id,
+// connector_type, user, phase and request_id.
+func (l *Logger) With(fields map[string]interface{}) *Logger {
+	merged := make(map[string]interface{}, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &Logger{facet: l.facet, fields: merged}
+}
+
+// WithContext returns a Logger with the request_id field set from ctx, if
+// WithRequestID attached one, so handlers can log without separately
+// threading the ID through every call.
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	id, ok := RequestIDFromContext(ctx)
+	if !ok {
+		return l
+	}
+	return l.With(map[string]interface{}{"request_id": id})
+}
+
+func (l *Logger) debugEnabled() bool {
+	return facets["all"] || facets[l.facet]
+}
+
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	if !l.debugEnabled() {
+		return
+	}
+	l.write(LevelDebug, format, args...)
+}
+
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.write(LevelInfo, format, args...)
+}
+
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.write(LevelWarn, format, args...)
+}
+
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.write(LevelError, format, args...)
+}
+
+// Fatalf logs at LevelFatal and then terminates the process, mirroring the
+// standard library's log.Fatalf.
+func (l *Logger) Fatalf(format string, args ...interface{}) {
+	l.write(LevelFatal, format, args...)
+	os.Exit(1)
+}
+
+type jsonLine struct {
+	Time   time.Time              `json:"time"`
+	Level  Level                  `json:"level"`
+	Facet  string                 `json:"facet"`
+	Msg    string                 `json:"msg"`
+	Fields map[string]interface{} `json:"fields,omitempty"`
+}
+
+func (l *Logger) write(level Level, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if jsonMode {
+		line, err := json.Marshal(jsonLine{
+			Time:   time.Now(),
+			Level:  level,
+			Facet:  l.facet,
+			Msg:    msg,
+			Fields: l.fields,
+		})
+		if err != nil {
+			fmt.Fprintf(output, "failed to marshal log line: %s\n", err)
+			return
+		}
+		fmt.Fprintln(output, string(line))
+		return
+	}
+
+	fmt.Fprintf(output, "%s [%s] %s: %s%s\n", time.Now().Format(time.RFC3339), strings.ToUpper(string(level)), l.facet, msg, formatFields(l.fields))
+}
+
+// formatFields renders fields as " key=value" pairs in a deterministic
+// (sorted by key) order, for the text output format.
+func formatFields(fields map[string]interface{}) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, " %s=%v", k, fields[k])
+	}
+	return b.String()
+}
@@ -0,0 +1,43 @@
+package log
+
+import (
+	"bufio"
+	"io"
+)
+
+// PrefixWriter tags every line written to it with a source prefix (e.g.
+// "ollama" or "weaviate") before forwarding it to an underlying Logger, so
+// that concurrently running subprocesses can be told apart in a shared log
+// file instead of interleaving raw output.
+type PrefixWriter struct {
+	logger *Logger
+	pw     *io.PipeWriter
+}
+
+// NewPrefixWriter returns an io.Writer suitable for use as a *os.File
+// replacement (e.g. cmd.Stdout) that logs each line it receives through
+// logger at LevelInfo. Callers must arrange for the returned writer to be
+// closed once the subprocess exits.
+func NewPrefixWriter(logger *Logger) *PrefixWriter {
+	pr, pw := io.Pipe()
+	w := &PrefixWriter{logger: logger, pw: pw}
+
+	go func() {
+		scanner := bufio.NewScanner(pr)
+		// Subprocess lines (e.g. model download progress) can be long.
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			logger.Infof("%s", scanner.Text())
+		}
+	}()
+
+	return w
+}
+
+func (w *PrefixWriter) Write(p []byte) (int, error) {
+	return w.pw.Write(p)
+}
+
+func (w *PrefixWriter) Close() error {
+	return w.pw.Close()
+}
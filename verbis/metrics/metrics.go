@@ -0,0 +1,212 @@
+// Package metrics exposes Verbis's runtime behavior as Prometheus metrics,
+// mounted at /metrics on the main router (or a dedicated loopback port via
+// --metrics-addr) so that local operators can observe boot timing,
+// subprocess health, and RAG pipeline latency.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	BootPhaseDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "verbis",
+		Subsystem: "boot",
+		Name:      "phase_duration_seconds",
+		Help:      "Duration of each boot phase (onboard, syncing, gen).",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12),
+	}, []string{"phase"})
+
+	SubprocessRestarts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "verbis",
+		Subsystem: "subprocess",
+		Name:      "restarts_total",
+		Help:      "Total number of times a supervised subprocess has been restarted.",
+	}, []string{"name"})
+
+	SubprocessUp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "verbis",
+		Subsystem: "subprocess",
+		Name:      "up",
+		Help:      "Whether a supervised subprocess is currently running (1) or not (0).",
+	}, []string{"name"})
+
+	ProbeLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "verbis",
+		Subsystem: "probe",
+		Name:      "latency_seconds",
+		Help:      "Latency of readiness/liveness probes against Ollama and Weaviate.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"target"})
+
+	ModelPulls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "verbis",
+		Subsystem: "ollama",
+		Name:      "model_pulls_total",
+		Help:      "Total number of ollama model pull/create attempts, by outcome.",
+	}, []string{"model", "outcome"})
+
+	RerankInvocations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "verbis",
+		Subsystem: "rerank",
+		Name:      "invocations_total",
+		Help:      "Total number of reranker invocations, by outcome.",
+	}, []string{"outcome"})
+
+	ChatRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "verbis",
+		Subsystem: "chat",
+		Name:      "requests_total",
+		Help:      "Total number of chat/generation requests, by outcome.",
+	}, []string{"outcome"})
+
+	GCDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "verbis",
+		Subsystem: "connector_gc",
+		Name:      "duration_seconds",
+		Help:      "Duration of a single pending-deletion connector's cascading cleanup.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12),
+	})
+
+	GCDocumentsDeleted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "verbis",
+		Subsystem: "connector_gc",
+		Name:      "documents_deleted_total",
+		Help:      "Total number of documents removed by the connector GC pass.",
+	})
+
+	GCChunksDeleted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "verbis",
+		Subsystem: "connector_gc",
+		Name:      "chunks_deleted_total",
+		Help:      "Total number of chunks removed by the connector GC pass.",
+	})
+
+	ConnectorBytesRead = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "verbis",
+		Subsystem: "connector_io",
+		Name:      "bytes_read_total",
+		Help:      "Total bytes read from a connector's source during sync.",
+	}, []string{"connector_id"})
+
+	ConnectorBytesEmbedded = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "verbis",
+		Subsystem: "connector_io",
+		Name:      "bytes_embedded_total",
+		Help:      "Total chunk text bytes sent to the embedding model for a connector.",
+	}, []string{"connector_id"})
+
+	ConnectorBytesStored = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "verbis",
+		Subsystem: "connector_io",
+		Name:      "bytes_stored_total",
+		Help:      "Total embedding bytes written to the store for a connector.",
+	}, []string{"connector_id"})
+
+	ConnectorChunksAdded = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "verbis",
+		Subsystem: "connector_io",
+		Name:      "chunks_added_total",
+		Help:      "Total chunks embedded and stored for a connector.",
+	}, []string{"connector_id"})
+
+	ConnectorEmbedLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "verbis",
+		Subsystem: "connector_io",
+		Name:      "embed_latency_seconds",
+		Help:      "Latency of a single chunkAdder embed+store flush, by connector.",
+		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12),
+	}, []string{"connector_id"})
+
+	// PromptStageDuration covers the same pipeline stages handlePrompt
+	// already reports to PostHog (embed, search, rerank, ttft, gen), kept
+	// as one vector rather than five histograms so /metrics shows them as
+	// a single family with a "stage" label.
+	PromptStageDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "verbis",
+		Subsystem: "prompt",
+		Name:      "stage_duration_seconds",
+		Help:      "Duration of each handlePrompt/handlePromptStream pipeline stage.",
+		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12),
+	}, []string{"stage"})
+
+	// PromptResultCount tracks how many chunks came back at each stage
+	// (search vs. rerank), so a drop to zero results is visible without
+	// grepping logs.
+	PromptResultCount = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "verbis",
+		Subsystem: "prompt",
+		Name:      "result_count",
+		Help:      "Number of chunks returned at each retrieval stage.",
+		Buckets:   prometheus.LinearBuckets(0, 5, 20),
+	}, []string{"stage"})
+
+	// PromptStreamEvents is the number of streamed tokens handlePrompt wrote
+	// for a single response, the streaming counterpart to gen_duration.
+	PromptStreamEvents = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "verbis",
+		Subsystem: "prompt",
+		Name:      "stream_events",
+		Help:      "Number of streamed response chunks written for a single prompt.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+	})
+
+	// ConnectorSyncResult counts each connectorSync call by outcome, so
+	// operators can watch sync failure rate per connector type over time.
+	ConnectorSyncResult = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "verbis",
+		Subsystem: "connector_sync",
+		Name:      "results_total",
+		Help:      "Total number of connector sync passes, by connector type and outcome.",
+	}, []string{"connector_type", "outcome"})
+
+	// AuthCallbackResult counts handleConnectorCallback invocations by
+	// outcome, to watch OAuth callback failure rate.
+	AuthCallbackResult = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "verbis",
+		Subsystem: "connector_auth",
+		Name:      "callback_results_total",
+		Help:      "Total number of connector auth callbacks, by outcome.",
+	}, []string{"outcome"})
+
+	// OllamaPullRetries counts retry attempts (not the initial try) made by
+	// pullModelWithRetry, so repeated flaky pulls of a given model show up
+	// distinctly from ModelPulls' pass/fail outcome.
+	OllamaPullRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "verbis",
+		Subsystem: "ollama",
+		Name:      "pull_retries_total",
+		Help:      "Total number of ollama model pull retry attempts.",
+	}, []string{"model"})
+
+	// HTTPRequestDuration is recorded by Middleware for every request the
+	// API router serves, labeled by the matched route template (not the
+	// raw path, to keep cardinality bounded) so per-endpoint latency and
+	// status-code mix are visible without per-prompt PostHog events.
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "verbis",
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of HTTP requests served by the API router.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
+
+// Handler returns the HTTP handler to mount at /metrics, gathering from the
+// default (global) registry every promauto metric in this package registers
+// itself to.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// HandlerFor returns the HTTP handler to mount at /metrics when gathering
+// from reg instead of the default registry, e.g. an API.Registry a test
+// constructed so it can assert on emitted samples without sharing state
+// with other tests in the same process.
+func HandlerFor(reg prometheus.Gatherer) http.Handler {
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+}
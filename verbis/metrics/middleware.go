@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// statusRecorder wraps a ResponseWriter to capture the status code a
+// handler wrote, since http.ResponseWriter doesn't expose it after the
+// fact and Middleware needs it for the "status" label.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// Middleware records HTTPRequestDuration for every request, labeled by the
+// route template mux matched (rather than the raw path, which would blow
+// up cardinality with connector/conversation IDs) so per-endpoint latency
+// and status-code mix show up on /metrics. It must be registered after
+// mux has had a chance to match the route, i.e. via r.Use on the router
+// whose routes it's observing.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		route := "unmatched"
+		if matched := mux.CurrentRoute(r); matched != nil {
+			if tmpl, err := matched.GetPathTemplate(); err == nil {
+				route = tmpl
+			}
+		}
+		HTTPRequestDuration.WithLabelValues(r.Method, route, strconv.Itoa(rec.status)).Observe(time.Since(start).Seconds())
+	})
+}
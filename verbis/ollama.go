@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"html/template"
 	"io"
-	"log"
 	"net/http"
 	"os"
 	"os/exec"
@@ -17,6 +16,8 @@ import (
 	"strings"
 	"time"
 
+	vlog "github.com/verbis-ai/verbis/verbis/log"
+	"github.com/verbis-ai/verbis/verbis/metrics"
 	"github.com/verbis-ai/verbis/verbis/types"
 	"github.com/verbis-ai/verbis/verbis/util"
 )
@@ -26,6 +27,11 @@ const (
 	rerankDistPath    = "rerank/rerank"
 )
 
+// ollamaClientLog is scoped to the same "ollama" facet as boot.go's
+// ollamaLog, which covers the supervised subprocess's own stdout/stderr;
+// this one covers the HTTP client calls made against it.
+var ollamaClientLog = vlog.New("ollama")
+
 func IsCustomModel(modelName string) bool {
 	return strings.HasPrefix(modelName, "custom-")
 }
@@ -36,7 +42,15 @@ type ModelCreateRequest struct {
 	Stream    bool   `json:"stream"`
 }
 
-func createModel(modelName string) error {
+func createModel(modelName string) (err error) {
+	defer func() {
+		outcome := "success"
+		if err != nil {
+			outcome = "failure"
+		}
+		metrics.ModelPulls.WithLabelValues(modelName, outcome).Inc()
+	}()
+
 	url := "http://localhost:11434/api/create"
 
 	path, err := util.GetDistPath()
@@ -50,7 +64,7 @@ func createModel(modelName string) error {
 		return fmt.Errorf("unable to read modelfile: %v", err)
 	}
 
-	log.Printf("Modelfile contents: %s", string(modelFileData))
+	ollamaClientLog.Debugf("Modelfile contents: %s", string(modelFileData))
 
 	payload := ModelCreateRequest{
 		Name:      modelName,
@@ -84,10 +98,79 @@ func createModel(modelName string) error {
 	if err != nil {
 		return err
 	}
-	log.Printf("Response: %v", string(responseData))
+	ollamaClientLog.Debugf("Response: %v", string(responseData))
 	return nil
 }
 
+// EmbedApiResponse is the response shape of a single embedding request.
+type EmbedApiResponse struct {
+	Embedding []float32 `json:"embedding"`
+}
+
+type embedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embedApiBatchResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// EmbedFromModel requests an embedding for a single prompt. Callers that
+// need to embed many prompts at once (chunkAdder) should use
+// EmbedBatchFromModel instead, which does the same thing in one Ollama call.
+func EmbedFromModel(prompt string) (*EmbedApiResponse, error) {
+	embeddings, err := EmbedBatchFromModel([]string{prompt})
+	if err != nil {
+		return nil, err
+	}
+	return &EmbedApiResponse{Embedding: embeddings[0]}, nil
+}
+
+// EmbedBatchFromModel embeds every prompt in a single call to Ollama's
+// batch embeddings endpoint, in the order given, so a caller with many
+// chunks to embed doesn't pay per-chunk HTTP round trip overhead.
+func EmbedBatchFromModel(prompts []string) ([][]float32, error) {
+	url := fmt.Sprintf("http://%s/api/embed", OllamaHost)
+
+	payload := embedRequest{
+		Model: embeddingsModelName,
+		Input: prompts,
+	}
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	response, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	responseData, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse embedApiBatchResponse
+	if err := json.Unmarshal(responseData, &apiResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal embeddings response: %v", err)
+	}
+	if len(apiResponse.Embeddings) != len(prompts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(prompts), len(apiResponse.Embeddings))
+	}
+
+	return apiResponse.Embeddings, nil
+}
+
 type StreamResponse struct {
 	Model     string            `json:"model"`
 	CreatedAt time.Time         `json:"created_at"`
@@ -170,7 +253,15 @@ func chatWithModelStream(ctx context.Context, prompt string, model string, histo
 }
 
 // Function to call ollama model
-func chatWithModel(prompt string, model string, history []types.HistoryItem) (*ApiResponse, error) {
+func chatWithModel(ctx context.Context, prompt string, model string, history []types.HistoryItem) (resp *ApiResponse, err error) {
+	defer func() {
+		outcome := "success"
+		if err != nil {
+			outcome = "failure"
+		}
+		metrics.ChatRequests.WithLabelValues(outcome).Inc()
+	}()
+
 	// URL of the API endpoint
 	url := "http://localhost:11434/api/chat"
 
@@ -196,7 +287,7 @@ func chatWithModel(prompt string, model string, history []types.HistoryItem) (*A
 	}
 
 	// Create a new HTTP request
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, err
 	}
@@ -217,7 +308,7 @@ func chatWithModel(prompt string, model string, history []types.HistoryItem) (*A
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("Response: %v", string(responseData))
+	ollamaClientLog.Debugf("Response: %v", string(responseData))
 
 	// Unmarshal JSON data into ApiResponse struct
 	var apiResponse ApiResponse
@@ -229,6 +320,101 @@ func chatWithModel(prompt string, model string, history []types.HistoryItem) (*A
 	return &apiResponse, nil
 }
 
+// chatWarmupRequest is the minimal payload /api/chat accepts to load a
+// model into memory for keepAlive without asking it to generate anything:
+// an empty message list plus keep_alive.
+type chatWarmupRequest struct {
+	Model     string              `json:"model"`
+	Messages  []types.HistoryItem `json:"messages"`
+	Stream    bool                `json:"stream"`
+	KeepAlive string              `json:"keep_alive"`
+}
+
+// warmupChatModel loads a chat/generation-style model (anything served via
+// chatWithModel, i.e. everything but the embeddings model) into Ollama's
+// memory for keepAlive, so the first real prompt against it doesn't pay
+// the load cost.
+func warmupChatModel(ctx context.Context, model, keepAlive string) error {
+	url := "http://localhost:11434/api/chat"
+
+	payload := chatWarmupRequest{
+		Model:     model,
+		Messages:  []types.HistoryItem{},
+		Stream:    false,
+		KeepAlive: keepAlive,
+	}
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	response, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if _, err := io.Copy(io.Discard, response.Body); err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("ollama chat warmup for %s returned status %d", model, response.StatusCode)
+	}
+	return nil
+}
+
+// embedWarmupRequest is the minimal payload /api/embed accepts to load the
+// embeddings model into memory for keepAlive.
+type embedWarmupRequest struct {
+	Model     string   `json:"model"`
+	Input     []string `json:"input"`
+	KeepAlive string   `json:"keep_alive"`
+}
+
+// warmupEmbedModel loads the embeddings model into memory for keepAlive by
+// sending it a single empty-string embed request.
+func warmupEmbedModel(ctx context.Context, model, keepAlive string) error {
+	url := fmt.Sprintf("http://%s/api/embed", OllamaHost)
+
+	payload := embedWarmupRequest{
+		Model:     model,
+		Input:     []string{""},
+		KeepAlive: keepAlive,
+	}
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	response, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if _, err := io.Copy(io.Discard, response.Body); err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("ollama embed warmup for %s returned status %d", model, response.StatusCode)
+	}
+	return nil
+}
+
 func sourcesFromChunks(chunks []*types.Chunk) []map[string]string {
 	sources := []map[string]string{}
 	for _, chunk := range chunks {
@@ -304,7 +490,7 @@ func rerankBERT(ctx context.Context, chunks []*types.Chunk, query string) ([]*ty
 	var res []int
 	err = json.Unmarshal(output, &res)
 	if err != nil {
-		log.Printf("%s", string(output))
+		ollamaClientLog.Infof("%s", string(output))
 		return nil, fmt.Errorf("error unmarshaling JSON: %v", err)
 	}
 
@@ -316,7 +502,15 @@ func rerankBERT(ctx context.Context, chunks []*types.Chunk, query string) ([]*ty
 	return finalChunks, nil
 }
 
-func RunRerankModel(ctx context.Context, jsonData []byte) ([]byte, error) {
+func RunRerankModel(ctx context.Context, jsonData []byte) (out []byte, err error) {
+	defer func() {
+		outcome := "success"
+		if err != nil {
+			outcome = "failure"
+		}
+		metrics.RerankInvocations.WithLabelValues(outcome).Inc()
+	}()
+
 	// Execute the Python script and pass JSON data to stdin
 	distPath, err := util.GetDistPath()
 	if err != nil {
@@ -327,7 +521,7 @@ func RunRerankModel(ctx context.Context, jsonData []byte) ([]byte, error) {
 	cmd.Stdin = bytes.NewReader(jsonData)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		log.Print(string(output))
+		ollamaClientLog.Infof("%s", string(output))
 		return nil, fmt.Errorf("error executing script: %v", err)
 	}
 	return output, nil
@@ -362,37 +556,121 @@ func ParseStringToIntArray(input string) ([]int, error) {
 // faster and better performing
 const rerankModelName = "custom-zephyr"
 
-// Only used for Llama.cpp rerank models such as rerank-zephyr
-func rerankLLM(chunks []*types.Chunk, query string) ([]*types.Chunk, error) {
-	messages, err := MakeRerankMessages(chunks, query)
+// Default sliding-window parameters for rerankLLM, following the RankGPT
+// paper: rank a window of defaultRerankWindow candidates at a time, slide
+// back by defaultRerankStep, and repeat until the window reaches the head
+// of the list. defaultRerankTopK mirrors store.MaxNumSearchResults, the
+// number of results callers actually consume.
+const (
+	defaultRerankWindow = 20
+	defaultRerankStep   = 10
+	defaultRerankTopK   = 10
+)
+
+// Only used for Llama.cpp rerank models such as rerank-zephyr.
+//
+// rerankLLM implements the RankGPT sliding-window listwise reranking
+// algorithm: a single-pass prompt asking the model to rank all chunks at
+// once degrades badly past a handful of candidates, so instead we rank a
+// window of chunks at a time, starting from the tail of the list, and
+// slide the window towards the head, re-ranking the overlapping region
+// each time for stability.
+func rerankLLM(ctx context.Context, chunks []*types.Chunk, query string) ([]*types.Chunk, error) {
+	return rerankLLMWindowed(ctx, chunks, query, defaultRerankWindow, defaultRerankStep, defaultRerankTopK)
+}
+
+// rerankLLMWindowed is rerankLLM with the window size, slide step, and
+// result count configurable, so callers and tests don't need to depend on
+// the package-level defaults.
+func rerankLLMWindowed(ctx context.Context, chunks []*types.Chunk, query string, window, step, topK int) ([]*types.Chunk, error) {
+	if window <= 0 {
+		window = defaultRerankWindow
+	}
+	if step <= 0 {
+		step = defaultRerankStep
+	}
+
+	ranked := make([]*types.Chunk, len(chunks))
+	copy(ranked, chunks)
+
+	end := len(ranked)
+	start := end - window
+	if start < 0 {
+		start = 0
+	}
+
+	for {
+		if err := rerankWindow(ctx, ranked[start:end], query); err != nil {
+			return nil, err
+		}
+
+		if start == 0 {
+			break
+		}
+		end -= step
+		start -= step
+		if start < 0 {
+			start = 0
+		}
+	}
+
+	if topK > 0 && topK < len(ranked) {
+		ranked = ranked[:topK]
+	}
+	return ranked, nil
+}
+
+// rerankWindow asks the LLM to rank the chunks in window and reorders
+// window in place according to the (sanitized) response.
+func rerankWindow(ctx context.Context, window []*types.Chunk, query string) error {
+	messages, err := MakeRerankMessages(window, query)
 	if err != nil {
-		return nil, fmt.Errorf("unable to create rerank messages: %s", err)
+		return fmt.Errorf("unable to create rerank messages: %s", err)
 	}
-	log.Print(messages)
 
-	resp, err := chatWithModel("", rerankModelName, messages)
+	resp, err := chatWithModel(ctx, "", rerankModelName, messages)
 	if err != nil {
-		return nil, fmt.Errorf("unable to generate rerank response: %s", err)
+		return fmt.Errorf("unable to generate rerank response: %s", err)
 	}
-	log.Print(resp.Message.Content)
 
 	idxs, err := ParseStringToIntArray(resp.Message.Content)
 	if err != nil {
-		return nil, fmt.Errorf("unable to parse rerank response: %s", err)
+		return fmt.Errorf("unable to parse rerank response: %s", err)
 	}
-	log.Print(idxs)
-	if len(idxs) == 10 || (len(idxs) == 6 && idxs[0] == 6 && idxs[5] == 1) {
-		// default hallucination value, don't expect num chunks != 10
-		log.Printf("Rerank has hallucinated")
-		return chunks, nil
+
+	order := sanitizeRerankOrder(idxs, len(window))
+	reordered := make([]*types.Chunk, len(window))
+	for i, idx := range order {
+		reordered[i] = window[idx]
 	}
+	copy(window, reordered)
+	return nil
+}
 
-	reranked := []*types.Chunk{}
+// sanitizeRerankOrder converts the model's 1-indexed "[i] > [j] > ..."
+// ranking into a valid 0-indexed permutation of [0, n): out-of-range
+// indices are dropped, duplicates are dropped after their first
+// occurrence, and any index the model never mentioned is appended in its
+// original order. This guarantees a usable ranking instead of silently
+// falling back to the unranked input on a malformed or hallucinated
+// response.
+func sanitizeRerankOrder(idxs []int, n int) []int {
+	seen := make([]bool, n)
+	order := make([]int, 0, n)
 	for _, idx := range idxs {
-		reranked = append(reranked, chunks[idx-1])
+		i := idx - 1
+		if i < 0 || i >= n || seen[i] {
+			continue
+		}
+		seen[i] = true
+		order = append(order, i)
 	}
-
-	return reranked, nil
+	for i := 0; i < n; i++ {
+		if !seen[i] {
+			order = append(order, i)
+		}
+	}
+	return order
 }
 
 func MakeRerankMessages(chunks []*types.Chunk, query string) ([]types.HistoryItem, error) {
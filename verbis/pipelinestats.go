@@ -0,0 +1,63 @@
+package main
+
+import "sync"
+
+// ConnectorPipelineStats is a cumulative, per-connector snapshot of what
+// chunkpipeline.Run has done to a connector's chunks across all syncs:
+// how many were rejected outright (policy/language rules), how many had
+// PII redacted in place, and how many were dropped as duplicates before
+// ever reaching the embedder. Syncer keeps one of these per connector ID,
+// same as ioStatsRegistry, so GetConnectorStates can surface them without
+// the store needing to track them itself.
+type ConnectorPipelineStats struct {
+	ChunksRejected int64 `json:"chunks_rejected"`
+	ChunksRedacted int64 `json:"chunks_redacted"`
+	ChunksDeduped  int64 `json:"chunks_deduped"`
+}
+
+// pipelineStatsRegistry is a mutex-guarded map of ConnectorPipelineStats,
+// separate from Syncer.mu since it's written from each connectorSync's
+// pipeline goroutine rather than from the connector-registry call paths
+// that mu protects.
+type pipelineStatsRegistry struct {
+	mu   sync.Mutex
+	byID map[string]*ConnectorPipelineStats
+}
+
+func newPipelineStatsRegistry() *pipelineStatsRegistry {
+	return &pipelineStatsRegistry{byID: map[string]*ConnectorPipelineStats{}}
+}
+
+// get returns a copy of the current stats for connectorID, or a zero
+// value if none have been recorded yet.
+func (r *pipelineStatsRegistry) get(connectorID string) ConnectorPipelineStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.byID[connectorID]; ok {
+		return *s
+	}
+	return ConnectorPipelineStats{}
+}
+
+// add accumulates a completed run's rejected/redacted/deduped counts into
+// connectorID's cumulative totals.
+func (r *pipelineStatsRegistry) add(connectorID string, rejected, redacted, deduped int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.byID[connectorID]
+	if !ok {
+		e = &ConnectorPipelineStats{}
+		r.byID[connectorID] = e
+	}
+	e.ChunksRejected += rejected
+	e.ChunksRedacted += redacted
+	e.ChunksDeduped += deduped
+}
+
+// delete drops connectorID's stats, called when a connector is removed so
+// the registry doesn't grow unbounded across add/delete churn.
+func (r *pipelineStatsRegistry) delete(connectorID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.byID, connectorID)
+}
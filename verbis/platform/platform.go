@@ -0,0 +1,48 @@
+// Package platform isolates the OS-specific pieces of the boot sequence
+// (system probing, process termination, data directory layout and process
+// supervision) behind a single Provider interface, so that boot.go itself
+// stays platform-agnostic. Each OS gets its own build-tagged file exporting
+// a concrete New() constructor.
+package platform
+
+import (
+	"context"
+	"syscall"
+)
+
+// SystemStats holds the machine information reported to analytics at the
+// end of boot.
+type SystemStats struct {
+	Chipset string
+	OS      string
+	Memsize string
+}
+
+// Provider implements everything about the boot sequence that differs by
+// operating system.
+type Provider interface {
+	// SystemStats probes chipset, OS version and memory size.
+	SystemStats(ctx context.Context) (*SystemStats, error)
+
+	// KillProcessByName terminates any running process matching name,
+	// without shelling out to OS-specific utilities like pkill.
+	KillProcessByName(name string) error
+
+	// UserDataDir returns the directory Verbis should use to store its
+	// state (logs, models, synced data) for the given app name, honoring
+	// platform conventions (XDG on Linux, %LOCALAPPDATA% on Windows, the
+	// home directory on macOS).
+	UserDataDir(app string) (string, error)
+
+	// SysProcAttr returns the SysProcAttr that should be set on child
+	// processes so that they are placed in their own process group/job and
+	// are torn down together with the parent instead of being orphaned.
+	SysProcAttr() *syscall.SysProcAttr
+}
+
+// New returns the Provider for the current operating system. It is
+// implemented per-platform in platform_darwin.go, platform_linux.go and
+// platform_windows.go, each guarded by a build tag.
+func New() Provider {
+	return newProvider()
+}
@@ -0,0 +1,91 @@
+//go:build darwin
+
+package platform
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+type darwinProvider struct{}
+
+func newProvider() Provider {
+	return &darwinProvider{}
+}
+
+func (p *darwinProvider) SystemStats(ctx context.Context) (*SystemStats, error) {
+	chipsetCmd := exec.CommandContext(ctx, "sysctl", "-n", "machdep.cpu.brand_string")
+	chipsetOut, err := chipsetCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chipset info: %v", err)
+	}
+
+	versionCmd := exec.CommandContext(ctx, "sw_vers", "-productVersion")
+	versionOut, err := versionCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get macOS version: %v", err)
+	}
+
+	memCmd := exec.CommandContext(ctx, "sysctl", "-n", "hw.memsize")
+	memOut, err := memCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memory info: %v", err)
+	}
+
+	return &SystemStats{
+		Chipset: strings.TrimSpace(string(chipsetOut)),
+		OS:      "macOS " + strings.TrimSpace(string(versionOut)),
+		Memsize: strings.TrimSpace(string(memOut)),
+	}, nil
+}
+
+// KillProcessByName finds PIDs matching name via pgrep and signals them
+// directly, rather than shelling out to pkill.
+func (p *darwinProvider) KillProcessByName(name string) error {
+	out, err := exec.Command("pgrep", "-f", name).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			// No processes matched
+			return nil
+		}
+		return fmt.Errorf("failed to look up process %s: %v", name, err)
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line == "" {
+			continue
+		}
+		var pid int
+		if _, err := fmt.Sscanf(line, "%d", &pid); err != nil {
+			continue
+		}
+		proc, err := os.FindProcess(pid)
+		if err != nil {
+			continue
+		}
+		if err := proc.Signal(syscall.SIGTERM); err != nil {
+			return fmt.Errorf("failed to signal process %d (%s): %v", pid, name, err)
+		}
+	}
+	return nil
+}
+
+func (p *darwinProvider) UserDataDir(app string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to get user home directory: %w", err)
+	}
+	return filepath.Join(home, "."+app), nil
+}
+
+// SysProcAttr places children in their own process group so that they can
+// be killed as a group, and so orphaned children don't survive a SIGKILL
+// of the parent by the OS.
+func (p *darwinProvider) SysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setpgid: true}
+}
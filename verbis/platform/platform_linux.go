@@ -0,0 +1,117 @@
+//go:build linux
+
+package platform
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+type linuxProvider struct{}
+
+func newProvider() Provider {
+	return &linuxProvider{}
+}
+
+func (p *linuxProvider) SystemStats(ctx context.Context) (*SystemStats, error) {
+	chipset := "unknown"
+	if data, err := os.ReadFile("/proc/cpuinfo"); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.HasPrefix(line, "model name") {
+				if parts := strings.SplitN(line, ":", 2); len(parts) == 2 {
+					chipset = strings.TrimSpace(parts[1])
+				}
+				break
+			}
+		}
+	}
+
+	osVersion := "unknown"
+	if data, err := os.ReadFile("/etc/os-release"); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.HasPrefix(line, "PRETTY_NAME=") {
+				osVersion = strings.Trim(strings.TrimPrefix(line, "PRETTY_NAME="), `"`)
+				break
+			}
+		}
+	}
+
+	memsize := "unknown"
+	if data, err := os.ReadFile("/proc/meminfo"); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.HasPrefix(line, "MemTotal:") {
+				fields := strings.Fields(line)
+				if len(fields) >= 2 {
+					if kb, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+						memsize = strconv.FormatUint(kb*1024, 10)
+					}
+				}
+				break
+			}
+		}
+	}
+
+	return &SystemStats{
+		Chipset: chipset,
+		OS:      osVersion,
+		Memsize: memsize,
+	}, nil
+}
+
+// KillProcessByName walks /proc, matching against each process's cmdline,
+// and signals matches directly rather than shelling out to pkill.
+func (p *linuxProvider) KillProcessByName(name string) error {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return fmt.Errorf("failed to read /proc: %v", err)
+	}
+
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		cmdline, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "cmdline"))
+		if err != nil {
+			continue
+		}
+		if !strings.Contains(string(cmdline), name) {
+			continue
+		}
+
+		proc, err := os.FindProcess(pid)
+		if err != nil {
+			continue
+		}
+		if err := proc.Signal(syscall.SIGTERM); err != nil {
+			return fmt.Errorf("failed to signal process %d (%s): %v", pid, name, err)
+		}
+	}
+	return nil
+}
+
+// UserDataDir honors XDG_DATA_HOME when set, falling back to
+// ~/.local/share/<app> per the XDG base directory spec.
+func (p *linuxProvider) UserDataDir(app string) (string, error) {
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		return filepath.Join(xdg, app), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to get user home directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "share", app), nil
+}
+
+// SysProcAttr places children in their own process group so that the whole
+// group can be killed together and orphans don't outlive the parent.
+func (p *linuxProvider) SysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setpgid: true}
+}
@@ -0,0 +1,85 @@
+//go:build windows
+
+package platform
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+type windowsProvider struct{}
+
+func newProvider() Provider {
+	return &windowsProvider{}
+}
+
+func (p *windowsProvider) SystemStats(ctx context.Context) (*SystemStats, error) {
+	chipset := os.Getenv("PROCESSOR_IDENTIFIER")
+
+	verOut, err := exec.CommandContext(ctx, "cmd", "/C", "ver").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get windows version: %v", err)
+	}
+
+	memOut, err := exec.CommandContext(ctx, "wmic", "computersystem", "get", "TotalPhysicalMemory").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memory info: %v", err)
+	}
+	memsize := "unknown"
+	for _, line := range strings.Split(string(memOut), "\n") {
+		line = strings.TrimSpace(line)
+		if _, err := strconv.ParseUint(line, 10, 64); err == nil {
+			memsize = line
+			break
+		}
+	}
+
+	return &SystemStats{
+		Chipset: chipset,
+		OS:      strings.TrimSpace(string(verOut)),
+		Memsize: memsize,
+	}, nil
+}
+
+// KillProcessByName uses taskkill's image-name matching, which is the
+// closest Windows equivalent to a name->PID lookup without walking the
+// process snapshot API by hand.
+func (p *windowsProvider) KillProcessByName(name string) error {
+	cmd := exec.Command("taskkill", "/IM", name+".exe", "/F")
+	err := cmd.Run()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 128 {
+			// No matching process found
+			return nil
+		}
+		return fmt.Errorf("failed to kill process %s: %v", name, err)
+	}
+	return nil
+}
+
+// UserDataDir uses %LOCALAPPDATA%\<app>, the conventional per-user data
+// location on Windows.
+func (p *windowsProvider) UserDataDir(app string) (string, error) {
+	base := os.Getenv("LOCALAPPDATA")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("unable to get user home directory: %w", err)
+		}
+		base = filepath.Join(home, "AppData", "Local")
+	}
+	return filepath.Join(base, app), nil
+}
+
+// SysProcAttr creates a new process group so that ctrl events and job
+// teardown don't take down the parent, and so the child can be terminated
+// as a unit.
+func (p *windowsProvider) SysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
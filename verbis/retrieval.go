@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"sort"
+
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+// DefaultRetrievalTopK and DefaultRerankTopN are used when the stored
+// config doesn't override them. See types.Config.
+const (
+	DefaultRetrievalTopK = 20
+	DefaultRerankTopN    = 10
+)
+
+// rerankStrategyRRF is the types.Config.RerankStrategy value that selects
+// rrfReranker instead of the default bertReranker.
+const rerankStrategyRRF = "rrf"
+
+// Retriever fetches a candidate set of chunks for a query, before
+// reranking. This is the first stage of the retrieval-augmented generation
+// pipeline in handlePrompt.
+type Retriever interface {
+	Retrieve(ctx context.Context, query string, embedding []float32, topK int) ([]*types.Chunk, error)
+}
+
+// Reranker narrows a Retriever's candidate set down to the topN chunks
+// most relevant to the query. This is the second stage of the pipeline.
+type Reranker interface {
+	Rerank(ctx context.Context, chunks []*types.Chunk, query string, topN int) ([]*types.Chunk, error)
+}
+
+// PromptTemplate renders the final LLM prompt from the reranked chunks and
+// the user's query. This is the last stage before generation.
+type PromptTemplate interface {
+	Build(chunks []*types.Chunk, query string) string
+}
+
+// hybridSearchRetriever adapts types.Store.HybridSearch to the Retriever
+// interface, passing topK through as the store's own SearchOptions.Limit
+// rather than over-fetching and capping client-side.
+type hybridSearchRetriever struct {
+	store types.Store
+	opts  types.SearchOptions
+}
+
+func (h *hybridSearchRetriever) Retrieve(ctx context.Context, query string, embedding []float32, topK int) ([]*types.Chunk, error) {
+	opts := h.opts
+	if topK > 0 {
+		opts.Limit = topK
+	}
+	return h.store.HybridSearch(ctx, query, embedding, opts)
+}
+
+// bertReranker adapts Rerank, the cross-encoder pairwise reranker, to the
+// Reranker interface.
+type bertReranker struct{}
+
+func (bertReranker) Rerank(ctx context.Context, chunks []*types.Chunk, query string, topN int) ([]*types.Chunk, error) {
+	reranked, err := Rerank(ctx, chunks, query)
+	if err != nil {
+		return nil, err
+	}
+	if topN > 0 && len(reranked) > topN {
+		reranked = reranked[:topN]
+	}
+	return reranked, nil
+}
+
+// rrfRerankK is the Reciprocal Rank Fusion smoothing constant: it keeps a
+// narrow lead in rank 1 from dominating the merge the way summing raw
+// scores would, which matters since DenseScore and KeywordScore live on
+// unrelated scales (cosine similarity vs. bm25).
+const rrfRerankK = 60
+
+// rrfReranker reranks candidates by Reciprocal Rank Fusion instead of a
+// model call: it ranks the candidate set independently by DenseScore and
+// by KeywordScore, then scores each chunk as the sum of
+// 1/(rrfRerankK+rank) across both lists, favoring chunks that rank well
+// in both rather than one that only dominates a single signal.
+type rrfReranker struct{}
+
+func (rrfReranker) Rerank(ctx context.Context, chunks []*types.Chunk, query string, topN int) ([]*types.Chunk, error) {
+	denseRank := rankByScore(chunks, func(c *types.Chunk) float64 { return c.DenseScore })
+	keywordRank := rankByScore(chunks, func(c *types.Chunk) float64 { return c.KeywordScore })
+
+	type rrfScore struct {
+		chunk *types.Chunk
+		score float64
+	}
+	scored := make([]rrfScore, len(chunks))
+	for i, c := range chunks {
+		scored[i] = rrfScore{
+			chunk: c,
+			score: 1/float64(rrfRerankK+denseRank[c]) + 1/float64(rrfRerankK+keywordRank[c]),
+		}
+	}
+	sort.Slice(scored, func(i, j int) bool { return scored[i].score > scored[j].score })
+	if topN > 0 && len(scored) > topN {
+		scored = scored[:topN]
+	}
+
+	reranked := make([]*types.Chunk, len(scored))
+	for i, s := range scored {
+		reranked[i] = s.chunk
+	}
+	return reranked, nil
+}
+
+// rankByScore returns each chunk's 1-indexed rank when chunks is sorted by
+// score descending, breaking ties by original order so a backend that
+// leaves score at zero for every chunk (WeaviateStore has no independent
+// DenseScore/KeywordScore) doesn't get its candidates scrambled.
+func rankByScore(chunks []*types.Chunk, score func(*types.Chunk) float64) map[*types.Chunk]int {
+	order := make([]*types.Chunk, len(chunks))
+	copy(order, chunks)
+	sort.SliceStable(order, func(i, j int) bool { return score(order[i]) > score(order[j]) })
+
+	ranks := make(map[*types.Chunk]int, len(order))
+	for i, c := range order {
+		ranks[c] = i + 1
+	}
+	return ranks
+}
+
+// defaultPromptTemplate adapts MakePrompt to the PromptTemplate interface.
+type defaultPromptTemplate struct{}
+
+func (defaultPromptTemplate) Build(chunks []*types.Chunk, query string) string {
+	return MakePrompt(chunks, query)
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+// Backend selects which types.Store implementation New returns.
+type Backend string
+
+const (
+	BackendWeaviate Backend = "weaviate"
+	BackendSQLite   Backend = "sqlite"
+	BackendMilvus   Backend = "milvus"
+	BackendRedis    Backend = "redis"
+
+	// sqliteFileName is the database file created under dataDir when
+	// BackendSQLite is selected.
+	sqliteFileName = "verbis.db"
+)
+
+// BackendFromEnv reads VERBIS_STORE_BACKEND ("sqlite", "milvus", "redis",
+// or unset/"weaviate") and returns the corresponding Backend, defaulting
+// to BackendWeaviate.
+func BackendFromEnv() Backend {
+	switch os.Getenv("VERBIS_STORE_BACKEND") {
+	case "sqlite":
+		return BackendSQLite
+	case "milvus":
+		return BackendMilvus
+	case "redis":
+		return BackendRedis
+	default:
+		return BackendWeaviate
+	}
+}
+
+// New returns the Store selected by backend. ollamaURL and
+// embeddingsModelName are only used by BackendWeaviate, which embeds its
+// own vectors via Ollama at query and hybrid-search time; dataDir is only
+// used by BackendSQLite, which stores its database and vector index
+// underneath it. BackendMilvus instead reads VERBIS_MILVUS_ADDR
+// (host:port, defaulting to localhost:19530) and VERBIS_MILVUS_DIM (the
+// embedding model's output size) from the environment, since it has no
+// equivalent on-disk/ollama-derived config of its own yet. BackendRedis
+// similarly reads VERBIS_REDIS_ADDR (host:port, defaulting to
+// localhost:6379) and VERBIS_REDIS_DIM.
+func New(backend Backend, ollamaURL, embeddingsModelName, dataDir string) (types.Store, error) {
+	switch backend {
+	case BackendSQLite:
+		return NewSQLiteStore(filepath.Join(dataDir, sqliteFileName))
+	case BackendMilvus:
+		addr := os.Getenv("VERBIS_MILVUS_ADDR")
+		if addr == "" {
+			addr = "localhost:19530"
+		}
+		dim, _ := strconv.Atoi(os.Getenv("VERBIS_MILVUS_DIM"))
+		return NewMilvusStore(context.Background(), addr, dim)
+	case BackendRedis:
+		addr := os.Getenv("VERBIS_REDIS_ADDR")
+		if addr == "" {
+			addr = "localhost:6379"
+		}
+		dim, _ := strconv.Atoi(os.Getenv("VERBIS_REDIS_DIM"))
+		if dim == 0 {
+			dim = 1024
+		}
+		return NewRedisStore(context.Background(), addr, dim)
+	default:
+		return NewWeaviateStore(ollamaURL, embeddingsModelName, DefaultBloomEstimatedItems, DefaultBloomFalsePositiveRate), nil
+	}
+}
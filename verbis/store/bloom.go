@@ -0,0 +1,206 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/bits-and-blooms/bloom/v3"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate/graphql"
+)
+
+const (
+	// DefaultBloomEstimatedItems sizes the hash and unique_id bloom
+	// filters for a moderately large workspace. RebuildBloomFilters
+	// re-sizes them from the real count at startup, so this only matters
+	// for the window between NewWeaviateStore and the first rebuild.
+	DefaultBloomEstimatedItems = 1_000_000
+
+	// DefaultBloomFalsePositiveRate trades a small extra rate of
+	// falling through to the authoritative Weaviate query for memory: at
+	// 1M items this keeps both filters under a few MB.
+	DefaultBloomFalsePositiveRate = 0.01
+
+	// bloomPageSize is how many objects RebuildBloomFilters fetches per
+	// GraphQL page while walking the chunk and document classes.
+	bloomPageSize = 1000
+)
+
+// chunkHashMaybeExists tests hash against the in-memory chunk-hash bloom
+// filter before falling through to the authoritative GetChunkByHash
+// query. A negative is definitive ("definitely new"); a positive only
+// means "maybe exists", so the caller still needs the real check.
+func (w *WeaviateStore) chunkHashMaybeExists(hash string) bool {
+	w.hashFilterMu.Lock()
+	defer w.hashFilterMu.Unlock()
+	return w.hashFilter.TestString(hash)
+}
+
+func (w *WeaviateStore) addChunkHashToBloom(hash string) {
+	w.hashFilterMu.Lock()
+	defer w.hashFilterMu.Unlock()
+	w.hashFilter.AddString(hash)
+}
+
+// docIDMaybeExists tests uniqueID against the in-memory document
+// unique_id bloom filter, with the same negative-is-definitive semantics
+// as chunkHashMaybeExists.
+func (w *WeaviateStore) docIDMaybeExists(uniqueID string) bool {
+	w.docIDFilterMu.Lock()
+	defer w.docIDFilterMu.Unlock()
+	return w.docIDFilter.TestString(uniqueID)
+}
+
+func (w *WeaviateStore) addDocIDToBloom(uniqueID string) {
+	w.docIDFilterMu.Lock()
+	defer w.docIDFilterMu.Unlock()
+	w.docIDFilter.AddString(uniqueID)
+}
+
+// RebuildBloomFilters pages through every chunk hash and document
+// unique_id currently in Weaviate and repopulates both bloom filters from
+// scratch, resizing them to the real item counts. Bloom filters can't
+// remove entries, so callers (e.g. the scheduler, after a large deletion
+// pass) should call this instead of trying to keep the existing filters
+// in sync.
+func (w *WeaviateStore) RebuildBloomFilters(ctx context.Context) error {
+	hashCount, err := w.countClass(ctx, chunkClassName)
+	if err != nil {
+		return fmt.Errorf("failed to count %s: %v", chunkClassName, err)
+	}
+	docCount, err := w.countClass(ctx, documentClassName)
+	if err != nil {
+		return fmt.Errorf("failed to count %s: %v", documentClassName, err)
+	}
+
+	hashFilter := bloom.NewWithEstimates(estimateOrDefault(hashCount), w.bloomFalsePositiveRate)
+	if err := w.pageStringField(ctx, chunkClassName, "hash", func(hash string) {
+		hashFilter.AddString(hash)
+	}); err != nil {
+		return fmt.Errorf("failed to page %s hashes: %v", chunkClassName, err)
+	}
+
+	docIDFilter := bloom.NewWithEstimates(estimateOrDefault(docCount), w.bloomFalsePositiveRate)
+	if err := w.pageStringField(ctx, documentClassName, "unique_id", func(uniqueID string) {
+		docIDFilter.AddString(uniqueID)
+	}); err != nil {
+		return fmt.Errorf("failed to page %s unique_ids: %v", documentClassName, err)
+	}
+
+	w.hashFilterMu.Lock()
+	w.hashFilter = hashFilter
+	w.hashFilterMu.Unlock()
+
+	w.docIDFilterMu.Lock()
+	w.docIDFilter = docIDFilter
+	w.docIDFilterMu.Unlock()
+
+	return nil
+}
+
+func estimateOrDefault(n uint) uint {
+	if n == 0 {
+		return DefaultBloomEstimatedItems
+	}
+	return n
+}
+
+// countClass returns Weaviate's Aggregate meta count for className.
+func (w *WeaviateStore) countClass(ctx context.Context, className string) (uint, error) {
+	resp, err := w.client.GraphQL().Aggregate().
+		WithClassName(className).
+		WithFields(graphql.Field{
+			Name:   "meta",
+			Fields: []graphql.Field{{Name: "count"}},
+		}).
+		Do(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	get, ok := resp.Data["Aggregate"].(map[string]interface{})
+	if !ok {
+		return 0, nil
+	}
+	entries, ok := get[className].([]interface{})
+	if !ok || len(entries) == 0 {
+		return 0, nil
+	}
+	entry, ok := entries[0].(map[string]interface{})
+	if !ok {
+		return 0, nil
+	}
+	meta, ok := entry["meta"].(map[string]interface{})
+	if !ok {
+		return 0, nil
+	}
+	count, ok := meta["count"].(float64)
+	if !ok {
+		return 0, nil
+	}
+	return uint(count), nil
+}
+
+// pageStringField walks every object of className via Weaviate's cursor
+// API (WithAfter on the object's _additional.id), calling fn with the
+// value of field for each one.
+func (w *WeaviateStore) pageStringField(ctx context.Context, className, field string, fn func(string)) error {
+	after := ""
+	for {
+		query := w.client.GraphQL().Get().
+			WithClassName(className).
+			WithFields(
+				graphql.Field{Name: field},
+				graphql.Field{Name: "_additional", Fields: []graphql.Field{{Name: "id"}}},
+			).
+			WithLimit(bloomPageSize)
+		if after != "" {
+			query = query.WithAfter(after)
+		}
+
+		resp, err := query.Do(ctx)
+		if err != nil {
+			return err
+		}
+
+		get, ok := resp.Data["Get"].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		objs, ok := get[className].([]interface{})
+		if !ok || len(objs) == 0 {
+			return nil
+		}
+
+		for _, raw := range objs {
+			obj, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if v, ok := obj[field].(string); ok {
+				fn(v)
+			}
+			if additional, ok := obj["_additional"].(map[string]interface{}); ok {
+				if id, ok := additional["id"].(string); ok {
+					after = id
+				}
+			}
+		}
+
+		if len(objs) < bloomPageSize {
+			return nil
+		}
+	}
+}
+
+// bloomFilterState holds the two probabilistic membership filters
+// fronting ChunkHashExists and the unique_id -> document-id lookup in
+// AddVectors, plus the false-positive rate new filters are sized with on
+// rebuild.
+type bloomFilterState struct {
+	hashFilter             *bloom.BloomFilter
+	hashFilterMu           sync.Mutex
+	docIDFilter            *bloom.BloomFilter
+	docIDFilterMu          sync.Mutex
+	bloomFalsePositiveRate float64
+}
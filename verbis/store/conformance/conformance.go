@@ -0,0 +1,237 @@
+// Package conformance exercises the behavioral contract types.Store
+// documents but can't enforce through its method signatures alone: the
+// not-found sentinels, the CAS retry semantics of store.MutateConnectorState,
+// and conversation/chunk persistence round-tripping correctly. Every
+// backend (WeaviateStore, SQLiteStore, MilvusStore, RedisStore) is expected
+// to pass RunTests; a new backend should be run through it before it's
+// wired into store.New.
+package conformance
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/verbis-ai/verbis/verbis/store"
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+// RunTests exercises newStore (a constructor returning a fresh, empty
+// backend instance) against the behaviors every types.Store implementation
+// is expected to honor. Callers wire their backend in with a _test.go file
+// consisting of little more than a call to RunTests with a newStore closure
+// that builds and schema-initializes a store instance.
+func RunTests(t *testing.T, newStore func() types.Store) {
+	t.Run("ConnectorStateCRUD", func(t *testing.T) { testConnectorStateCRUD(t, newStore) })
+	t.Run("ConversationHistory", func(t *testing.T) { testConversationHistory(t, newStore) })
+	t.Run("ChunkHashMembership", func(t *testing.T) { testChunkHashMembership(t, newStore) })
+	t.Run("ConcurrentConnectorStateUpdates", func(t *testing.T) { testConcurrentConnectorStateUpdates(t, newStore) })
+}
+
+func testConnectorStateCRUD(t *testing.T, newStore func() types.Store) {
+	ctx := context.Background()
+	st := newStore()
+
+	if _, err := st.GetConnectorState(ctx, "does-not-exist"); !store.IsStateNotFound(err) {
+		t.Fatalf("GetConnectorState on an unknown connector: got err %v, want one satisfying store.IsStateNotFound", err)
+	}
+
+	state := &types.ConnectorState{ConnectorID: "conn-1", ConnectorType: "test", User: "alice@example.com"}
+	if err := st.UpdateConnectorState(ctx, state); err != nil {
+		t.Fatalf("UpdateConnectorState: %v", err)
+	}
+
+	got, err := st.GetConnectorState(ctx, "conn-1")
+	if err != nil {
+		t.Fatalf("GetConnectorState: %v", err)
+	}
+	if got.User != "alice@example.com" {
+		t.Fatalf("GetConnectorState: got User %q, want %q", got.User, "alice@example.com")
+	}
+
+	states, err := st.AllConnectorStates(ctx)
+	if err != nil {
+		t.Fatalf("AllConnectorStates: %v", err)
+	}
+	found := false
+	for _, s := range states {
+		if s.ConnectorID == "conn-1" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("AllConnectorStates: conn-1 missing from %v", states)
+	}
+
+	// A stale ResourceVersion must be rejected rather than silently
+	// overwriting a concurrent writer's update.
+	got.ResourceVersion = "stale"
+	if err := st.UpdateConnectorState(ctx, got); !store.IsErrConflict(err) {
+		t.Fatalf("UpdateConnectorState with a stale ResourceVersion: got err %v, want one satisfying store.IsErrConflict", err)
+	}
+}
+
+func testConversationHistory(t *testing.T, newStore func() types.Store) {
+	ctx := context.Background()
+	st := newStore()
+
+	convID, err := st.CreateConversation(ctx)
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+
+	chunk := &types.Chunk{Document: types.Document{UniqueID: "doc-1"}, Text: "hello", Hash: "hash-1"}
+	items := []types.HistoryItem{
+		{Role: "user", Content: "hi"},
+		{Role: "assistant", Content: "hello back"},
+	}
+	if err := st.ConversationAppend(ctx, convID, items, []*types.Chunk{chunk}); err != nil {
+		t.Fatalf("ConversationAppend: %v", err)
+	}
+
+	conv, err := st.GetConversation(ctx, convID)
+	if err != nil {
+		t.Fatalf("GetConversation: %v", err)
+	}
+	if len(conv.History) != 2 {
+		t.Fatalf("GetConversation: got %d history items, want 2", len(conv.History))
+	}
+	if len(conv.ChunkHashes) != 1 || conv.ChunkHashes[0] != "hash-1" {
+		t.Fatalf("GetConversation: got ChunkHashes %v, want [hash-1]", conv.ChunkHashes)
+	}
+
+	history, err := st.GetConversationHistory(ctx, convID, types.PageArgs{})
+	if err != nil {
+		t.Fatalf("GetConversationHistory: %v", err)
+	}
+	if len(history.Edges) != 2 {
+		t.Fatalf("GetConversationHistory: got %d edges, want 2", len(history.Edges))
+	}
+
+	if err := st.RenameConversation(ctx, convID, "renamed"); err != nil {
+		t.Fatalf("RenameConversation: %v", err)
+	}
+	conv, err = st.GetConversation(ctx, convID)
+	if err != nil {
+		t.Fatalf("GetConversation after rename: %v", err)
+	}
+	if conv.Title != "renamed" {
+		t.Fatalf("GetConversation after rename: got Title %q, want %q", conv.Title, "renamed")
+	}
+
+	if err := st.DeleteConversation(ctx, convID); err != nil {
+		t.Fatalf("DeleteConversation: %v", err)
+	}
+	if _, err := st.GetConversation(ctx, convID); err == nil {
+		t.Fatalf("GetConversation after DeleteConversation: got nil error, want not-found")
+	}
+}
+
+func testChunkHashMembership(t *testing.T, newStore func() types.Store) {
+	ctx := context.Background()
+	st := newStore()
+
+	const hash = "hash-membership-1"
+	exists, err := st.ChunkHashExists(ctx, hash)
+	if err != nil {
+		t.Fatalf("ChunkHashExists before insert: %v", err)
+	}
+	if exists {
+		t.Fatalf("ChunkHashExists before insert: got true, want false")
+	}
+	if _, err := st.GetChunkByHash(ctx, hash); !store.IsErrChunkNotFound(err) {
+		t.Fatalf("GetChunkByHash before insert: got err %v, want one satisfying store.IsErrChunkNotFound", err)
+	}
+
+	item := types.AddVectorItem{
+		Chunk: types.Chunk{
+			Document: types.Document{UniqueID: "doc-membership-1", ConnectorID: "conn-1", ConnectorType: "test"},
+			Text:     "some chunk text",
+			Hash:     hash,
+		},
+		Vector: []float32{0.1, 0.2, 0.3},
+	}
+	if _, err := st.AddVectors(ctx, []types.AddVectorItem{item}); err != nil {
+		t.Fatalf("AddVectors: %v", err)
+	}
+
+	exists, err = st.ChunkHashExists(ctx, hash)
+	if err != nil {
+		t.Fatalf("ChunkHashExists after insert: %v", err)
+	}
+	if !exists {
+		t.Fatalf("ChunkHashExists after insert: got false, want true")
+	}
+
+	chunk, err := st.GetChunkByHash(ctx, hash)
+	if err != nil {
+		t.Fatalf("GetChunkByHash after insert: %v", err)
+	}
+	if chunk.Text != "some chunk text" {
+		t.Fatalf("GetChunkByHash after insert: got Text %q, want %q", chunk.Text, "some chunk text")
+	}
+
+	if err := st.DeleteChunkByHash(ctx, hash); err != nil {
+		t.Fatalf("DeleteChunkByHash: %v", err)
+	}
+	exists, err = st.ChunkHashExists(ctx, hash)
+	if err != nil {
+		t.Fatalf("ChunkHashExists after delete: %v", err)
+	}
+	if exists {
+		t.Fatalf("ChunkHashExists after delete: got true, want false")
+	}
+}
+
+// testConcurrentConnectorStateUpdates hammers store.MutateConnectorState
+// for the same connectorID from many goroutines at once, each incrementing
+// NumDocuments by one. If the CAS retry loop in MutateConnectorState (or a
+// backend's UpdateConnectorState conflict check) ever drops a write, the
+// final count comes up short.
+func testConcurrentConnectorStateUpdates(t *testing.T, newStore func() types.Store) {
+	ctx := context.Background()
+	st := newStore()
+
+	const connectorID = "conn-concurrent"
+	const numWriters = 20
+
+	var wg sync.WaitGroup
+	errs := make(chan error, numWriters)
+	for i := 0; i < numWriters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := store.MutateConnectorState(ctx, st, connectorID, func(s *types.ConnectorState) error {
+				s.ConnectorType = "test"
+				s.NumDocuments++
+				return nil
+			})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("MutateConnectorState: %v", err)
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	var final *types.ConnectorState
+	for {
+		var err error
+		final, err = st.GetConnectorState(ctx, connectorID)
+		if err != nil {
+			t.Fatalf("GetConnectorState: %v", err)
+		}
+		if final.NumDocuments == numWriters || time.Now().After(deadline) {
+			break
+		}
+	}
+	if final.NumDocuments != numWriters {
+		t.Fatalf("NumDocuments after %d concurrent increments: got %d, want %d", numWriters, final.NumDocuments, numWriters)
+	}
+}
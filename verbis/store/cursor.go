@@ -0,0 +1,42 @@
+package store
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// cursorKey is the decoded form of a Relay pagination cursor: the
+// updated_at/id pair that uniquely and stably orders a row, so pagination
+// stays correct even as new rows are inserted between pages.
+type cursorKey struct {
+	UpdatedAt time.Time `json:"updated_at"`
+	ID        string    `json:"id"`
+}
+
+// encodeCursor base64-encodes a JSON {updated_at, id} pair into an opaque
+// cursor string suitable for PageInfo.EndCursor / ConversationEdge.Cursor.
+func encodeCursor(updatedAt time.Time, id string) string {
+	b, _ := json.Marshal(cursorKey{UpdatedAt: updatedAt, ID: id})
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// decodeCursor reverses encodeCursor. It returns an error for malformed or
+// tampered cursor strings rather than silently falling back to the first
+// page, since that would mask client bugs as missing data.
+func decodeCursor(cursor string) (cursorKey, error) {
+	var key cursorKey
+	b, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return key, fmt.Errorf("failed to decode cursor: %v", err)
+	}
+	if err := json.Unmarshal(b, &key); err != nil {
+		return key, fmt.Errorf("failed to unmarshal cursor: %v", err)
+	}
+	return key, nil
+}
+
+// DefaultPageSize is used when a PageArgs.First of zero or less is passed
+// to a Connection-returning Store method.
+const DefaultPageSize = 20
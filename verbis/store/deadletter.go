@@ -0,0 +1,110 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// failedChunkDelete is the on-disk shape of one dead-lettered chunk-delete
+// batch: a document whose batch deleter reported object-level failures,
+// kept around so a later pass can retry deleting its remaining chunks
+// instead of leaving them orphaned.
+type failedChunkDelete struct {
+	DocumentID string    `json:"document_id"`
+	Remaining  int64     `json:"remaining"`
+	FailedAt   time.Time `json:"failed_at"`
+}
+
+// chunkDeleteDeadLetterQueue appends failed chunk-delete batches to a local
+// JSONL file, one entry per line, mirroring analytics.jsonlSink. Draining it
+// truncates the file, so a retry that fails again is expected to call Add
+// itself rather than relying on the drained entry surviving.
+type chunkDeleteDeadLetterQueue struct {
+	mu   sync.Mutex
+	path string
+}
+
+// newChunkDeleteDeadLetterQueue returns a queue backed by the file at path,
+// creating its parent directory if necessary.
+func newChunkDeleteDeadLetterQueue(path string) (*chunkDeleteDeadLetterQueue, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create dead-letter directory: %w", err)
+	}
+	return &chunkDeleteDeadLetterQueue{path: path}, nil
+}
+
+// defaultChunkDeleteDeadLetterPath returns ~/.verbis/chunk_delete_deadletter.jsonl.
+func defaultChunkDeleteDeadLetterPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve home directory: %w", err)
+	}
+	return filepath.Join(home, ".verbis", "chunk_delete_deadletter.jsonl"), nil
+}
+
+func (q *chunkDeleteDeadLetterQueue) Add(documentID string, remaining int64) error {
+	data, err := json.Marshal(failedChunkDelete{
+		DocumentID: documentID,
+		Remaining:  remaining,
+		FailedAt:   time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead-letter entry: %w", err)
+	}
+	data = append(data, '\n')
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	f, err := os.OpenFile(q.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open dead-letter queue: %w", err)
+	}
+	defer f.Close()
+	_, err = f.Write(data)
+	return err
+}
+
+// Drain returns the distinct document IDs currently queued and truncates
+// the queue file. Callers that fail to retry an entry are expected to
+// Add it back themselves.
+func (q *chunkDeleteDeadLetterQueue) Drain() ([]string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	data, err := os.ReadFile(q.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead-letter queue: %w", err)
+	}
+
+	seen := map[string]bool{}
+	ids := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var entry failedChunkDelete
+		if err := json.Unmarshal(line, &entry); err != nil {
+			continue
+		}
+		if !seen[entry.DocumentID] {
+			seen[entry.DocumentID] = true
+			ids = append(ids, entry.DocumentID)
+		}
+	}
+
+	if err := os.Truncate(q.path, 0); err != nil {
+		return nil, fmt.Errorf("failed to truncate dead-letter queue: %w", err)
+	}
+	return ids, nil
+}
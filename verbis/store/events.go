@@ -0,0 +1,66 @@
+package store
+
+import (
+	"context"
+	"sync"
+
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+// subscriberBufferSize bounds each Subscribe channel. A subscriber that
+// falls behind by this many events has its oldest pending event dropped
+// rather than blocking publish for every other caller.
+const subscriberBufferSize = 32
+
+// eventBroker fans connector state changes out to any number of
+// subscribers and is embedded by every Store implementation, so
+// WeaviateStore/SQLiteStore/MilvusStore get Subscribe and a private
+// publish for free instead of each reimplementing fan-out.
+type eventBroker struct {
+	mu   sync.Mutex
+	subs map[chan types.ConnectorEvent]struct{}
+}
+
+func newEventBroker() eventBroker {
+	return eventBroker{subs: map[chan types.ConnectorEvent]struct{}{}}
+}
+
+func (b *eventBroker) Subscribe(ctx context.Context) (<-chan types.ConnectorEvent, error) {
+	ch := make(chan types.ConnectorEvent, subscriberBufferSize)
+
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(b.subs, ch)
+		close(ch)
+		b.mu.Unlock()
+	}()
+
+	return ch, nil
+}
+
+// publish fans ev out to every live subscriber. A subscriber whose channel
+// is full has its oldest pending event dropped to make room, so one slow
+// SSE client can't block a Store write on every other caller.
+func (b *eventBroker) publish(ev types.ConnectorEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- ev:
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- ev:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,1148 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+
+	"github.com/verbis-ai/verbis/verbis/types"
+	"github.com/verbis-ai/verbis/verbis/util"
+)
+
+var (
+	milvusChunkCollection          = "verbis_chunk"
+	milvusDocumentCollection       = "verbis_document"
+	milvusConversationCollection   = "verbis_conversation"
+	milvusConnectorStateCollection = "verbis_connector_state"
+
+	milvusIndexName = "chunk_embedding_hnsw"
+)
+
+// DefaultMilvusEmbeddingDim is used when MilvusStore isn't told the
+// embedding model's output size up front. CreateChunkClass re-creates the
+// collection against the real dimension once it's known, so this only
+// matters for the window before that happens.
+const DefaultMilvusEmbeddingDim = 768
+
+// MilvusStore is a types.Store backend built on Milvus, for installs that
+// want a dedicated vector database instead of Weaviate. It maps
+// VerbisChunk to a chunk collection with an HNSW ANN index over the
+// embedding field, and keeps documents and conversations in separate
+// scalar collections, mirroring WeaviateStore's class layout.
+type MilvusStore struct {
+	client       client.Client
+	embeddingDim int
+
+	// eventBroker publishes a ConnectorEvent for every successful
+	// UpdateConnectorState/DeleteConnector call; see Subscribe.
+	eventBroker
+
+	// stateLocks serializes UpdateConnectorState per connector ID, since
+	// Milvus has no atomic compare-and-swap write; see connectorStateLocks.
+	stateLocks *connectorStateLocks
+}
+
+// NewMilvusStore dials addr (host:port) and returns a Store backed by it.
+// embeddingDim sizes the chunk collection's embedding field; pass 0 to use
+// DefaultMilvusEmbeddingDim until CreateChunkClass(ctx, true) is called
+// with the real dimension of the configured Ollama embedding model.
+func NewMilvusStore(ctx context.Context, addr string, embeddingDim int) (types.Store, error) {
+	c, err := client.NewClient(ctx, client.Config{Address: addr})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to milvus: %v", err)
+	}
+	if embeddingDim <= 0 {
+		embeddingDim = DefaultMilvusEmbeddingDim
+	}
+	return &MilvusStore{
+		client:       c,
+		embeddingDim: embeddingDim,
+		eventBroker:  newEventBroker(),
+		stateLocks:   newConnectorStateLocks(),
+	}, nil
+}
+
+func (m *MilvusStore) recreateCollection(ctx context.Context, name string, schema *entity.Schema, force bool) error {
+	has, err := m.client.HasCollection(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to check collection %s: %v", name, err)
+	}
+	if has {
+		if !force {
+			return nil
+		}
+		if err := m.client.DropCollection(ctx, name); err != nil {
+			return fmt.Errorf("failed to drop collection %s: %v", name, err)
+		}
+	}
+	if err := m.client.CreateCollection(ctx, schema, 2); err != nil {
+		return fmt.Errorf("failed to create collection %s: %v", name, err)
+	}
+	return nil
+}
+
+// CreateChunkClass creates the chunk collection (hash varchar PK,
+// documentid, document_title, chunk text, embedding float_vector) and
+// builds an HNSW index over embedding, the Milvus analogue of
+// WeaviateStore.CreateChunkClass's class schema.
+func (m *MilvusStore) CreateChunkClass(ctx context.Context, force bool) error {
+	schema := &entity.Schema{
+		CollectionName: milvusChunkCollection,
+		Fields: []*entity.Field{
+			{Name: "hash", DataType: entity.FieldTypeVarChar, PrimaryKey: true, TypeParams: map[string]string{"max_length": "256"}},
+			{Name: "documentid", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "256"}},
+			{Name: "document_title", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "1024"}},
+			{Name: "chunk", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "65535"}},
+			// connector_id, fingerprint and band0-3 are denormalized from
+			// the chunk's document so FindNearDuplicateChunk can scope and
+			// band-probe a SimHash lookup without a join.
+			{Name: "connector_id", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "256"}},
+			{Name: "fingerprint", DataType: entity.FieldTypeInt64},
+			{Name: "band0", DataType: entity.FieldTypeInt64},
+			{Name: "band1", DataType: entity.FieldTypeInt64},
+			{Name: "band2", DataType: entity.FieldTypeInt64},
+			{Name: "band3", DataType: entity.FieldTypeInt64},
+			// acl is a JSON-encoded []string, the same denormalization
+			// reasoning as connector_id: HybridSearch needs it per
+			// candidate, and Milvus has no native array-overlap filter to
+			// push this down as a scalar expression instead.
+			{Name: "acl", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "4096"}},
+			{Name: "embedding", DataType: entity.FieldTypeFloatVector, TypeParams: map[string]string{"dim": fmt.Sprintf("%d", m.embeddingDim)}},
+		},
+	}
+	if err := m.recreateCollection(ctx, milvusChunkCollection, schema, force); err != nil {
+		return err
+	}
+
+	idx, err := entity.NewIndexHNSW(entity.COSINE, 16, 64)
+	if err != nil {
+		return fmt.Errorf("failed to build hnsw index params: %v", err)
+	}
+	if err := m.client.CreateIndex(ctx, milvusChunkCollection, "embedding", idx, false); err != nil {
+		return fmt.Errorf("failed to create index %s: %v", milvusIndexName, err)
+	}
+	return m.client.LoadCollection(ctx, milvusChunkCollection, false)
+}
+
+// CreateDocumentClass creates the scalar document collection.
+func (m *MilvusStore) CreateDocumentClass(ctx context.Context, force bool) error {
+	schema := &entity.Schema{
+		CollectionName: milvusDocumentCollection,
+		Fields: []*entity.Field{
+			{Name: "id", DataType: entity.FieldTypeVarChar, PrimaryKey: true, TypeParams: map[string]string{"max_length": "64"}},
+			{Name: "unique_id", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "1024"}},
+			{Name: "name", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "1024"}},
+			{Name: "source_url", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "2048"}},
+			{Name: "connector_id", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "256"}},
+			{Name: "connector_type", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "64"}},
+			{Name: "created_at", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "64"}},
+			{Name: "updated_at", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "64"}},
+			{Name: "status", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "32"}},
+			{Name: "deleted_at", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "64"}},
+		},
+	}
+	return m.recreateCollection(ctx, milvusDocumentCollection, schema, force)
+}
+
+// CreateConversationClass creates the scalar conversation collection.
+// History and chunk hashes are kept as JSON-encoded varchar fields, the
+// same denormalized shape WeaviateStore uses.
+func (m *MilvusStore) CreateConversationClass(ctx context.Context, force bool) error {
+	schema := &entity.Schema{
+		CollectionName: milvusConversationCollection,
+		Fields: []*entity.Field{
+			{Name: "id", DataType: entity.FieldTypeVarChar, PrimaryKey: true, TypeParams: map[string]string{"max_length": "64"}},
+			{Name: "title", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "1024"}},
+			{Name: "history", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "65535"}},
+			{Name: "chunk_hashes", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "65535"}},
+			{Name: "created_at", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "64"}},
+			{Name: "updated_at", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "64"}},
+		},
+	}
+	return m.recreateCollection(ctx, milvusConversationCollection, schema, force)
+}
+
+// CreateConnectorStateClass creates the scalar connector-state collection.
+func (m *MilvusStore) CreateConnectorStateClass(ctx context.Context, force bool) error {
+	schema := &entity.Schema{
+		CollectionName: milvusConnectorStateCollection,
+		Fields: []*entity.Field{
+			{Name: "connector_id", DataType: entity.FieldTypeVarChar, PrimaryKey: true, TypeParams: map[string]string{"max_length": "256"}},
+			{Name: "state", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "65535"}},
+		},
+	}
+	return m.recreateCollection(ctx, milvusConnectorStateCollection, schema, force)
+}
+
+func (m *MilvusStore) queryOne(ctx context.Context, collection, expr string, outputFields []string) (client.ResultSet, bool, error) {
+	res, err := m.client.Query(ctx, collection, nil, expr, outputFields)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(res) == 0 || res[0].Len() == 0 {
+		return nil, false, nil
+	}
+	return res, true, nil
+}
+
+func (m *MilvusStore) ChunkHashExists(ctx context.Context, hash string) (bool, error) {
+	_, found, err := m.queryOne(ctx, milvusChunkCollection, fmt.Sprintf(`hash == "%s"`, hash), []string{"hash"})
+	if err != nil {
+		return false, fmt.Errorf("failed to query chunk: %v", err)
+	}
+	return found, nil
+}
+
+func (m *MilvusStore) GetChunkByHash(ctx context.Context, hash string) (*types.Chunk, error) {
+	res, found, err := m.queryOne(ctx, milvusChunkCollection, fmt.Sprintf(`hash == "%s"`, hash), []string{"hash", "chunk", "documentid", "document_title", "acl"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query chunk: %v", err)
+	}
+	if !found {
+		return nil, ErrChunkNotFound
+	}
+	return chunkFromResultSet(res, 0)
+}
+
+// chunkFromResultSet reads row idx of a Query/Search result set back into
+// a types.Chunk. Column lookups use Milvus's typed accessor columns, which
+// return an error rather than panicking if a field wasn't projected.
+func chunkFromResultSet(res client.ResultSet, idx int) (*types.Chunk, error) {
+	hash, err := res.GetColumn("hash").GetAsString(idx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hash column: %v", err)
+	}
+	text, err := res.GetColumn("chunk").GetAsString(idx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chunk column: %v", err)
+	}
+	documentID, err := res.GetColumn("documentid").GetAsString(idx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read documentid column: %v", err)
+	}
+	title, err := res.GetColumn("document_title").GetAsString(idx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document_title column: %v", err)
+	}
+
+	var acl []string
+	if aclJSON, err := res.GetColumn("acl").GetAsString(idx); err == nil && aclJSON != "" {
+		if err := json.Unmarshal([]byte(aclJSON), &acl); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal acl column: %v", err)
+		}
+	}
+
+	return &types.Chunk{
+		Hash: hash,
+		Text: text,
+		Document: types.Document{
+			UniqueID: documentID,
+			Name:     title,
+			ACL:      acl,
+		},
+	}, nil
+}
+
+// FindNearDuplicateChunk probes all four SimHash bands of fingerprint
+// (see util.SimHashBands) scoped to connectorID, then filters the
+// candidates down to the first one within maxDistance Hamming distance,
+// since a band match only guarantees the two fingerprints share that
+// band, not that they're actually close.
+func (m *MilvusStore) FindNearDuplicateChunk(ctx context.Context, connectorID string, fingerprint uint64, maxDistance int) (*types.Chunk, error) {
+	bands := util.SimHashBands(fingerprint)
+	expr := fmt.Sprintf(`connector_id == "%s" and (band0 == %d or band1 == %d or band2 == %d or band3 == %d)`,
+		connectorID, bands[0], bands[1], bands[2], bands[3])
+	res, err := m.client.Query(ctx, milvusChunkCollection, nil, expr,
+		[]string{"hash", "chunk", "documentid", "document_title", "fingerprint", "acl"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query near-duplicate candidates: %v", err)
+	}
+	if len(res) == 0 {
+		return nil, nil
+	}
+
+	for i := 0; i < res[0].Len(); i++ {
+		candidateFP, err := res[0].GetColumn("fingerprint").GetAsInt64(i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fingerprint column: %v", err)
+		}
+		if util.HammingDistance(fingerprint, uint64(candidateFP)) > maxDistance {
+			continue
+		}
+		chunk, err := chunkFromResultSet(res, i)
+		if err != nil {
+			return nil, err
+		}
+		chunk.Fingerprint = uint64(candidateFP)
+		return chunk, nil
+	}
+
+	return nil, nil
+}
+
+func (m *MilvusStore) DeleteChunkByHash(ctx context.Context, hash string) error {
+	if err := m.client.Delete(ctx, milvusChunkCollection, "", fmt.Sprintf(`hash == "%s"`, hash)); err != nil {
+		return fmt.Errorf("failed to delete chunk: %v", err)
+	}
+	return nil
+}
+
+func (m *MilvusStore) GetDocument(ctx context.Context, uniqueID string) (*types.Document, error) {
+	res, found, err := m.queryOne(ctx, milvusDocumentCollection, fmt.Sprintf(`unique_id == "%s"`, uniqueID),
+		[]string{"name", "source_url", "connector_id", "connector_type", "created_at", "updated_at", "status", "deleted_at"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query document: %v", err)
+	}
+	if !found {
+		return nil, ErrDocumentNotFound
+	}
+	return documentFromResultSet(res, 0, uniqueID)
+}
+
+func documentFromResultSet(res client.ResultSet, idx int, uniqueID string) (*types.Document, error) {
+	name, _ := res.GetColumn("name").GetAsString(idx)
+	sourceURL, _ := res.GetColumn("source_url").GetAsString(idx)
+	connectorID, _ := res.GetColumn("connector_id").GetAsString(idx)
+	connectorType, _ := res.GetColumn("connector_type").GetAsString(idx)
+	createdAtStr, _ := res.GetColumn("created_at").GetAsString(idx)
+	updatedAtStr, _ := res.GetColumn("updated_at").GetAsString(idx)
+	createdAt, _ := time.Parse(time.RFC3339, createdAtStr)
+	updatedAt, _ := time.Parse(time.RFC3339, updatedAtStr)
+	status, _ := res.GetColumn("status").GetAsString(idx)
+	deletedAtStr, _ := res.GetColumn("deleted_at").GetAsString(idx)
+	deletedAt, _ := time.Parse(time.RFC3339, deletedAtStr)
+
+	return &types.Document{
+		UniqueID:      uniqueID,
+		Name:          name,
+		SourceURL:     sourceURL,
+		ConnectorID:   connectorID,
+		ConnectorType: connectorType,
+		CreatedAt:     createdAt,
+		UpdatedAt:     updatedAt,
+		Status:        types.DocumentStatus(status),
+		DeletedAt:     deletedAt,
+	}, nil
+}
+
+// AddVectors inserts each chunk's embedding into the chunk collection and
+// upserts its parent document into the document collection, counting a
+// document as added the first time its unique_id is seen.
+func (m *MilvusStore) AddVectors(ctx context.Context, items []types.AddVectorItem) (*types.AddVectorResponse, error) {
+	numDocsAdded := 0
+	seenDocs := map[string]bool{}
+
+	hashes := make([]string, 0, len(items))
+	documentIDs := make([]string, 0, len(items))
+	titles := make([]string, 0, len(items))
+	texts := make([]string, 0, len(items))
+	vectors := make([][]float32, 0, len(items))
+	connectorIDs := make([]string, 0, len(items))
+	fingerprints := make([]int64, 0, len(items))
+	band0s := make([]int64, 0, len(items))
+	band1s := make([]int64, 0, len(items))
+	band2s := make([]int64, 0, len(items))
+	band3s := make([]int64, 0, len(items))
+	acls := make([]string, 0, len(items))
+
+	for _, item := range items {
+		if !seenDocs[item.Document.UniqueID] {
+			seenDocs[item.Document.UniqueID] = true
+			_, found, err := m.queryOne(ctx, milvusDocumentCollection, fmt.Sprintf(`unique_id == "%s"`, item.Document.UniqueID), []string{"unique_id"})
+			if err != nil {
+				return nil, fmt.Errorf("failed to check document: %v", err)
+			}
+			if !found {
+				numDocsAdded++
+			}
+			if err := m.upsertDocument(ctx, item.Document); err != nil {
+				return nil, fmt.Errorf("failed to upsert document: %v", err)
+			}
+		}
+
+		hashes = append(hashes, item.Chunk.Hash)
+		documentIDs = append(documentIDs, item.Document.UniqueID)
+		titles = append(titles, item.Document.Name)
+		texts = append(texts, item.Chunk.Text)
+		vectors = append(vectors, item.Vector)
+		connectorIDs = append(connectorIDs, item.Document.ConnectorID)
+		fingerprints = append(fingerprints, int64(item.Chunk.Fingerprint))
+		bands := util.SimHashBands(item.Chunk.Fingerprint)
+		band0s = append(band0s, int64(bands[0]))
+		band1s = append(band1s, int64(bands[1]))
+		band2s = append(band2s, int64(bands[2]))
+		band3s = append(band3s, int64(bands[3]))
+		aclJSON, err := json.Marshal(item.Document.ACL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal chunk ACL: %v", err)
+		}
+		acls = append(acls, string(aclJSON))
+	}
+
+	if len(hashes) > 0 {
+		_, err := m.client.Insert(ctx, milvusChunkCollection, "",
+			entity.NewColumnVarChar("hash", hashes),
+			entity.NewColumnVarChar("documentid", documentIDs),
+			entity.NewColumnVarChar("document_title", titles),
+			entity.NewColumnVarChar("chunk", texts),
+			entity.NewColumnVarChar("connector_id", connectorIDs),
+			entity.NewColumnInt64("fingerprint", fingerprints),
+			entity.NewColumnInt64("band0", band0s),
+			entity.NewColumnInt64("band1", band1s),
+			entity.NewColumnInt64("band2", band2s),
+			entity.NewColumnInt64("band3", band3s),
+			entity.NewColumnVarChar("acl", acls),
+			entity.NewColumnFloatVector("embedding", m.embeddingDim, vectors),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert chunks: %v", err)
+		}
+	}
+
+	return &types.AddVectorResponse{
+		NumChunksAdded: len(items),
+		NumDocsAdded:   numDocsAdded,
+	}, nil
+}
+
+func (m *MilvusStore) upsertDocument(ctx context.Context, doc types.Document) error {
+	if err := m.client.Delete(ctx, milvusDocumentCollection, "", fmt.Sprintf(`unique_id == "%s"`, doc.UniqueID)); err != nil {
+		return fmt.Errorf("failed to clear existing document row: %v", err)
+	}
+	now := time.Now().Format(time.RFC3339)
+	_, err := m.client.Insert(ctx, milvusDocumentCollection, "",
+		entity.NewColumnVarChar("id", []string{uuid.NewString()}),
+		entity.NewColumnVarChar("unique_id", []string{doc.UniqueID}),
+		entity.NewColumnVarChar("name", []string{doc.Name}),
+		entity.NewColumnVarChar("source_url", []string{doc.SourceURL}),
+		entity.NewColumnVarChar("connector_id", []string{doc.ConnectorID}),
+		entity.NewColumnVarChar("connector_type", []string{doc.ConnectorType}),
+		entity.NewColumnVarChar("created_at", []string{now}),
+		entity.NewColumnVarChar("updated_at", []string{now}),
+		entity.NewColumnVarChar("status", []string{string(types.DocumentStatusActive)}),
+		entity.NewColumnVarChar("deleted_at", []string{""}),
+	)
+	return err
+}
+
+// tombstoneDocument re-upserts docUniqueID's row with its status flipped to
+// tombstoned, via the same delete-then-reinsert pattern upsertDocument
+// uses, since the Milvus client exposes no partial row update.
+func (m *MilvusStore) tombstoneDocument(ctx context.Context, docUniqueID string) error {
+	doc, err := m.GetDocument(ctx, docUniqueID)
+	if err != nil {
+		return fmt.Errorf("failed to get document: %v", err)
+	}
+	if err := m.client.Delete(ctx, milvusDocumentCollection, "", fmt.Sprintf(`unique_id == "%s"`, docUniqueID)); err != nil {
+		return fmt.Errorf("failed to clear existing document row: %v", err)
+	}
+	_, err = m.client.Insert(ctx, milvusDocumentCollection, "",
+		entity.NewColumnVarChar("id", []string{uuid.NewString()}),
+		entity.NewColumnVarChar("unique_id", []string{docUniqueID}),
+		entity.NewColumnVarChar("name", []string{doc.Name}),
+		entity.NewColumnVarChar("source_url", []string{doc.SourceURL}),
+		entity.NewColumnVarChar("connector_id", []string{doc.ConnectorID}),
+		entity.NewColumnVarChar("connector_type", []string{doc.ConnectorType}),
+		entity.NewColumnVarChar("created_at", []string{doc.CreatedAt.Format(time.RFC3339)}),
+		entity.NewColumnVarChar("updated_at", []string{time.Now().Format(time.RFC3339)}),
+		entity.NewColumnVarChar("status", []string{string(types.DocumentStatusTombstoned)}),
+		entity.NewColumnVarChar("deleted_at", []string{time.Now().Format(time.RFC3339)}),
+	)
+	return err
+}
+
+// ReconcileConnector tombstones every document under connectorID whose
+// unique_id isn't in liveUniqueIDs, mirroring WeaviateStore and
+// SQLiteStore's reconcile behavior.
+func (m *MilvusStore) ReconcileConnector(ctx context.Context, connectorID string, liveUniqueIDs []string) error {
+	live := map[string]bool{}
+	for _, id := range liveUniqueIDs {
+		live[id] = true
+	}
+
+	res, err := m.client.Query(ctx, milvusDocumentCollection, nil,
+		fmt.Sprintf(`connector_id == "%s" and status != "%s"`, connectorID, string(types.DocumentStatusTombstoned)),
+		[]string{"unique_id"})
+	if err != nil {
+		return fmt.Errorf("failed to list documents for connector: %v", err)
+	}
+	if len(res) == 0 {
+		return nil
+	}
+
+	for i := 0; i < res[0].Len(); i++ {
+		uniqueID, err := res[0].GetColumn("unique_id").GetAsString(i)
+		if err != nil {
+			return fmt.Errorf("failed to read unique_id column: %v", err)
+		}
+		if live[uniqueID] {
+			continue
+		}
+		if err := m.tombstoneDocument(ctx, uniqueID); err != nil {
+			return fmt.Errorf("unable to tombstone document %s: %v", uniqueID, err)
+		}
+	}
+
+	return nil
+}
+
+// HybridSearch blends a dense ANN search over embedding with a scalar
+// LIKE-based keyword filter over chunk/document_title, fusing the two
+// per-hash scores with opts.Alpha the same way SQLiteStore's FTS5-backed
+// HybridSearch does, since Milvus has no built-in BM25 scoring to combine
+// with its vector search natively. Fusion and FieldBoosts aren't honored:
+// the LIKE-based keyword match has no per-field ranking method or boost
+// target to apply. CreatedAfter/CreatedBefore/UpdatedAfter/UpdatedBefore
+// aren't honored either: the chunk collection doesn't denormalize the
+// parent document's timestamps the way it does connector_id, and adding
+// them would need a schema migration out of scope here.
+func (m *MilvusStore) HybridSearch(ctx context.Context, query string, vector []float32, opts types.SearchOptions) ([]*types.Chunk, error) {
+	alpha := HybridSearchAlpha
+	if opts.Alpha != 0 {
+		alpha = opts.Alpha
+	}
+	limit := MaxNumSearchResults
+	if opts.Limit > 0 {
+		limit = opts.Limit
+	}
+
+	connectorExpr := ""
+	if len(opts.ConnectorIDs) > 0 {
+		quoted := make([]string, len(opts.ConnectorIDs))
+		for i, id := range opts.ConnectorIDs {
+			quoted[i] = fmt.Sprintf(`connector_id == "%s"`, id)
+		}
+		connectorExpr = "(" + strings.Join(quoted, " or ") + ")"
+	}
+
+	sp, err := entity.NewIndexHNSWSearchParam(64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build search params: %v", err)
+	}
+
+	searchResult, err := m.client.Search(ctx, milvusChunkCollection, nil, connectorExpr, []string{"hash", "chunk", "documentid", "document_title", "acl"},
+		[]entity.Vector{entity.FloatVector(vector)}, "embedding", entity.COSINE, WeaviateMaxResults, sp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run ann search: %v", err)
+	}
+	if len(searchResult) == 0 {
+		return nil, fmt.Errorf("no chunks found")
+	}
+
+	denseScores := map[string]float64{}
+	chunksByHash := map[string]*types.Chunk{}
+	for i := 0; i < searchResult[0].ResultCount; i++ {
+		chunk, err := chunkFromResultSet(searchResult[0].Fields, i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read search result: %v", err)
+		}
+		denseScores[chunk.Hash] = float64(searchResult[0].Scores[i])
+		chunksByHash[chunk.Hash] = chunk
+	}
+
+	keywordScores := map[string]float64{}
+	if query != "" {
+		for _, term := range strings.Fields(query) {
+			term = strings.ReplaceAll(term, `"`, "")
+			expr := fmt.Sprintf(`chunk like "%%%s%%" or document_title like "%%%s%%"`, term, term)
+			if connectorExpr != "" {
+				expr = connectorExpr + " and (" + expr + ")"
+			}
+			res, err := m.client.Query(ctx, milvusChunkCollection, nil, expr, []string{"hash", "chunk", "documentid", "document_title", "acl"})
+			if err != nil {
+				return nil, fmt.Errorf("failed to run keyword search: %v", err)
+			}
+			if len(res) == 0 {
+				continue
+			}
+			for i := 0; i < res[0].Len(); i++ {
+				chunk, err := chunkFromResultSet(res, i)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read keyword result: %v", err)
+				}
+				keywordScores[chunk.Hash]++
+				if _, ok := chunksByHash[chunk.Hash]; !ok {
+					chunksByHash[chunk.Hash] = chunk
+				}
+			}
+		}
+	}
+
+	combined := map[string]float64{}
+	for hash, score := range denseScores {
+		combined[hash] += alpha * score
+	}
+	for hash, score := range keywordScores {
+		combined[hash] += (1 - alpha) * score
+	}
+
+	scored := make([]scoredHash, 0, len(combined))
+	for hash, score := range combined {
+		if score < opts.MinScore {
+			continue
+		}
+		scored = append(scored, scoredHash{hash, score})
+	}
+	sort.Slice(scored, func(i, j int) bool { return scored[i].score > scored[j].score })
+	if len(scored) > limit {
+		scored = scored[:limit]
+	}
+
+	res := make([]*types.Chunk, 0, len(scored))
+	for _, sh := range scored {
+		chunk := chunksByHash[sh.hash]
+		if opts.DocNameGlob != "" {
+			if ok, _ := path.Match(opts.DocNameGlob, chunk.Document.Name); !ok {
+				continue
+			}
+		}
+		if len(opts.RequesterIdentities) > 0 && len(chunk.Document.ACL) > 0 && !containsAny(chunk.Document.ACL, opts.RequesterIdentities) {
+			continue
+		}
+		chunk.Score = sh.score
+		chunk.DenseScore = denseScores[sh.hash]
+		chunk.KeywordScore = keywordScores[sh.hash]
+		chunk.ExplainScore = fmt.Sprintf("dense=%.4f keyword=%.4f alpha=%.2f", denseScores[sh.hash], keywordScores[sh.hash], alpha)
+		res = append(res, chunk)
+	}
+	return res, nil
+}
+
+func (m *MilvusStore) CreateConversation(ctx context.Context) (string, error) {
+	id := uuid.NewString()
+	now := time.Now().Format(time.RFC3339)
+	_, err := m.client.Insert(ctx, milvusConversationCollection, "",
+		entity.NewColumnVarChar("id", []string{id}),
+		entity.NewColumnVarChar("title", []string{""}),
+		entity.NewColumnVarChar("history", []string{"[]"}),
+		entity.NewColumnVarChar("chunk_hashes", []string{"[]"}),
+		entity.NewColumnVarChar("created_at", []string{now}),
+		entity.NewColumnVarChar("updated_at", []string{now}),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create conversation: %v", err)
+	}
+	return id, nil
+}
+
+func (m *MilvusStore) conversationFromResultSet(res client.ResultSet, idx int) (*types.Conversation, error) {
+	id, _ := res.GetColumn("id").GetAsString(idx)
+	title, _ := res.GetColumn("title").GetAsString(idx)
+	historyJSON, _ := res.GetColumn("history").GetAsString(idx)
+	chunkHashesJSON, _ := res.GetColumn("chunk_hashes").GetAsString(idx)
+	createdAtStr, _ := res.GetColumn("created_at").GetAsString(idx)
+	updatedAtStr, _ := res.GetColumn("updated_at").GetAsString(idx)
+
+	var history []types.HistoryItem
+	if err := json.Unmarshal([]byte(historyJSON), &history); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal conversation history: %v", err)
+	}
+	var chunkHashes []string
+	if err := json.Unmarshal([]byte(chunkHashesJSON), &chunkHashes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal conversation chunk hashes: %v", err)
+	}
+	createdAt, _ := time.Parse(time.RFC3339, createdAtStr)
+	updatedAt, _ := time.Parse(time.RFC3339, updatedAtStr)
+
+	return &types.Conversation{
+		ID:          id,
+		Title:       title,
+		History:     history,
+		ChunkHashes: chunkHashes,
+		CreatedAt:   createdAt,
+		UpdatedAt:   updatedAt,
+	}, nil
+}
+
+func (m *MilvusStore) GetConversation(ctx context.Context, conversationID string) (*types.Conversation, error) {
+	res, found, err := m.queryOne(ctx, milvusConversationCollection, fmt.Sprintf(`id == "%s"`, conversationID),
+		[]string{"id", "title", "history", "chunk_hashes", "created_at", "updated_at"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversation: %v", err)
+	}
+	if !found {
+		return nil, ErrConversationNotFound
+	}
+	return m.conversationFromResultSet(res, 0)
+}
+
+// ListConversations pages through conversations newest-updated first,
+// excluding history/chunk_hashes from the projection the same way
+// WeaviateStore and SQLiteStore do, since this is a list view rather than
+// a single-conversation fetch.
+func (m *MilvusStore) ListConversations(ctx context.Context, args types.PageArgs) (*types.ConversationConnection, error) {
+	first := pageSize(args.First)
+
+	expr := ""
+	if args.After != "" {
+		key, err := decodeCursor(args.After)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %v", err)
+		}
+		expr = fmt.Sprintf(`updated_at < "%s" or (updated_at == "%s" and id < "%s")`,
+			key.UpdatedAt.Format(time.RFC3339), key.UpdatedAt.Format(time.RFC3339), key.ID)
+	}
+
+	res, err := m.client.Query(ctx, milvusConversationCollection, nil, expr, []string{"id", "title", "created_at", "updated_at"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conversations: %v", err)
+	}
+
+	type row struct {
+		id, title            string
+		createdAt, updatedAt time.Time
+	}
+	rows := []row{}
+	if len(res) > 0 {
+		for i := 0; i < res[0].Len(); i++ {
+			id, _ := res[0].GetColumn("id").GetAsString(i)
+			title, _ := res[0].GetColumn("title").GetAsString(i)
+			createdAtStr, _ := res[0].GetColumn("created_at").GetAsString(i)
+			updatedAtStr, _ := res[0].GetColumn("updated_at").GetAsString(i)
+			createdAt, _ := time.Parse(time.RFC3339, createdAtStr)
+			updatedAt, _ := time.Parse(time.RFC3339, updatedAtStr)
+			rows = append(rows, row{id, title, createdAt, updatedAt})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].updatedAt.Equal(rows[j].updatedAt) {
+			return rows[i].updatedAt.After(rows[j].updatedAt)
+		}
+		return rows[i].id > rows[j].id
+	})
+
+	hasNext := len(rows) > first
+	if hasNext {
+		rows = rows[:first]
+	}
+
+	conn := &types.ConversationConnection{Edges: []types.ConversationEdge{}}
+	for _, r := range rows {
+		conn.Edges = append(conn.Edges, types.ConversationEdge{
+			Cursor: encodeCursor(r.updatedAt, r.id),
+			Node: &types.Conversation{
+				ID:        r.id,
+				Title:     r.title,
+				CreatedAt: r.createdAt,
+				UpdatedAt: r.updatedAt,
+			},
+		})
+	}
+	conn.PageInfo = types.PageInfo{HasNextPage: hasNext, HasPreviousPage: args.After != ""}
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.StartCursor = conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = conn.Edges[len(conn.Edges)-1].Cursor
+	}
+	return conn, nil
+}
+
+// GetConversationHistory pages through a single conversation's message
+// log in memory, the same way the other two backends do, since history is
+// stored as a single JSON array column rather than per-message rows.
+func (m *MilvusStore) GetConversationHistory(ctx context.Context, conversationID string, args types.PageArgs) (*types.HistoryConnection, error) {
+	conversation, err := m.GetConversation(ctx, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %v", err)
+	}
+
+	first := pageSize(args.First)
+	start := 0
+	if args.After != "" {
+		key, err := decodeCursor(args.After)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %v", err)
+		}
+		idx, err := parseHistoryIndex(key.ID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %v", err)
+		}
+		start = idx + 1
+	}
+
+	conn := &types.HistoryConnection{Edges: []types.HistoryEdge{}}
+	if start >= len(conversation.History) {
+		return conn, nil
+	}
+	end := start + first
+	hasNext := end < len(conversation.History)
+	if !hasNext {
+		end = len(conversation.History)
+	}
+	for i := start; i < end; i++ {
+		conn.Edges = append(conn.Edges, types.HistoryEdge{
+			Cursor: encodeCursor(conversation.UpdatedAt, historyIndexID(i)),
+			Node:   conversation.History[i],
+		})
+	}
+	conn.PageInfo = types.PageInfo{HasNextPage: hasNext, HasPreviousPage: start > 0}
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.StartCursor = conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = conn.Edges[len(conn.Edges)-1].Cursor
+	}
+	return conn, nil
+}
+
+func historyIndexID(i int) string { return fmt.Sprintf("%d", i) }
+
+func parseHistoryIndex(id string) (int, error) {
+	var idx int
+	if _, err := fmt.Sscanf(id, "%d", &idx); err != nil {
+		return 0, err
+	}
+	return idx, nil
+}
+
+// ListDocuments pages through every indexed document, newest-updated
+// first, using the same cursor shape as ListConversations.
+func (m *MilvusStore) ListDocuments(ctx context.Context, args types.PageArgs) (*types.DocumentConnection, error) {
+	first := pageSize(args.First)
+
+	expr := ""
+	if args.After != "" {
+		key, err := decodeCursor(args.After)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %v", err)
+		}
+		expr = fmt.Sprintf(`updated_at < "%s" or (updated_at == "%s" and id < "%s")`,
+			key.UpdatedAt.Format(time.RFC3339), key.UpdatedAt.Format(time.RFC3339), key.ID)
+	}
+
+	res, err := m.client.Query(ctx, milvusDocumentCollection, nil, expr,
+		[]string{"id", "unique_id", "name", "source_url", "connector_id", "connector_type", "created_at", "updated_at", "status", "deleted_at"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %v", err)
+	}
+
+	type row struct {
+		id      string
+		doc     *types.Document
+		updated time.Time
+	}
+	rows := []row{}
+	if len(res) > 0 {
+		for i := 0; i < res[0].Len(); i++ {
+			id, _ := res[0].GetColumn("id").GetAsString(i)
+			uniqueID, _ := res[0].GetColumn("unique_id").GetAsString(i)
+			doc, err := documentFromResultSet(res[0], i, uniqueID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read document: %v", err)
+			}
+			rows = append(rows, row{id, doc, doc.UpdatedAt})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].updated.Equal(rows[j].updated) {
+			return rows[i].updated.After(rows[j].updated)
+		}
+		return rows[i].id > rows[j].id
+	})
+
+	hasNext := len(rows) > first
+	if hasNext {
+		rows = rows[:first]
+	}
+
+	conn := &types.DocumentConnection{Edges: []types.DocumentEdge{}}
+	for _, r := range rows {
+		conn.Edges = append(conn.Edges, types.DocumentEdge{
+			Cursor: encodeCursor(r.updated, r.id),
+			Node:   r.doc,
+		})
+	}
+	conn.PageInfo = types.PageInfo{HasNextPage: hasNext, HasPreviousPage: args.After != ""}
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.StartCursor = conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = conn.Edges[len(conn.Edges)-1].Cursor
+	}
+	return conn, nil
+}
+
+func (m *MilvusStore) ConversationAppend(ctx context.Context, conversationID string, items []types.HistoryItem, chunks []*types.Chunk) error {
+	conversation, err := m.GetConversation(ctx, conversationID)
+	if err != nil {
+		return fmt.Errorf("unable to get conversation: %v", err)
+	}
+
+	items = attachChunkHashes(items, chunks)
+	conversation.History = append(conversation.History, items...)
+	for _, chunk := range chunks {
+		conversation.ChunkHashes = append(conversation.ChunkHashes, chunk.Hash)
+	}
+
+	if err := m.putConversation(ctx, conversation); err != nil {
+		return fmt.Errorf("failed to update conversation: %v", err)
+	}
+	return nil
+}
+
+// putConversation upserts conversation wholesale: Milvus has no in-place
+// column update, so every conversation write (append, rename, truncate,
+// branch) deletes the existing row (if any) and reinserts it the same way
+// ConversationAppend always has.
+func (m *MilvusStore) putConversation(ctx context.Context, conversation *types.Conversation) error {
+	historyJSON, err := json.Marshal(conversation.History)
+	if err != nil {
+		return fmt.Errorf("failed to marshal conversation history: %v", err)
+	}
+	chunkHashesJSON, err := json.Marshal(conversation.ChunkHashes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal conversation chunk hashes: %v", err)
+	}
+
+	if err := m.client.Delete(ctx, milvusConversationCollection, "", fmt.Sprintf(`id == "%s"`, conversation.ID)); err != nil {
+		return fmt.Errorf("failed to clear existing conversation row: %v", err)
+	}
+	_, err = m.client.Insert(ctx, milvusConversationCollection, "",
+		entity.NewColumnVarChar("id", []string{conversation.ID}),
+		entity.NewColumnVarChar("title", []string{conversation.Title}),
+		entity.NewColumnVarChar("history", []string{string(historyJSON)}),
+		entity.NewColumnVarChar("chunk_hashes", []string{string(chunkHashesJSON)}),
+		entity.NewColumnVarChar("created_at", []string{conversation.CreatedAt.Format(time.RFC3339)}),
+		entity.NewColumnVarChar("updated_at", []string{time.Now().Format(time.RFC3339)}),
+	)
+	return err
+}
+
+func (m *MilvusStore) DeleteConversation(ctx context.Context, conversationID string) error {
+	if err := m.client.Delete(ctx, milvusConversationCollection, "", fmt.Sprintf(`id == "%s"`, conversationID)); err != nil {
+		return fmt.Errorf("failed to delete conversation: %v", err)
+	}
+	return nil
+}
+
+func (m *MilvusStore) RenameConversation(ctx context.Context, conversationID string, title string) error {
+	conversation, err := m.GetConversation(ctx, conversationID)
+	if err != nil {
+		return fmt.Errorf("unable to get conversation: %v", err)
+	}
+	conversation.Title = title
+	if err := m.putConversation(ctx, conversation); err != nil {
+		return fmt.Errorf("failed to rename conversation: %v", err)
+	}
+	return nil
+}
+
+func (m *MilvusStore) TruncateConversationHistory(ctx context.Context, conversationID string, keep int) (*types.Conversation, error) {
+	conversation, err := m.GetConversation(ctx, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get conversation: %v", err)
+	}
+	conversation.History, conversation.ChunkHashes = truncateHistory(conversation.History, keep)
+	if err := m.putConversation(ctx, conversation); err != nil {
+		return nil, fmt.Errorf("failed to truncate conversation: %v", err)
+	}
+	conversation.UpdatedAt = time.Now()
+	return conversation, nil
+}
+
+func (m *MilvusStore) BranchConversation(ctx context.Context, conversationID string, atIndex int) (string, error) {
+	conversation, err := m.GetConversation(ctx, conversationID)
+	if err != nil {
+		return "", fmt.Errorf("unable to get conversation: %v", err)
+	}
+	history, chunkHashes := truncateHistory(conversation.History, atIndex)
+
+	branch := &types.Conversation{
+		ID:          uuid.NewString(),
+		Title:       conversation.Title,
+		History:     history,
+		ChunkHashes: chunkHashes,
+		CreatedAt:   time.Now(),
+	}
+	if err := m.putConversation(ctx, branch); err != nil {
+		return "", fmt.Errorf("failed to create branched conversation: %v", err)
+	}
+	return branch.ID, nil
+}
+
+func (m *MilvusStore) connectorStateRowID(connectorID string) string { return connectorID }
+
+func (m *MilvusStore) getConnectorStateRow(ctx context.Context, connectorID string) (*types.ConnectorState, bool, error) {
+	res, found, err := m.queryOne(ctx, milvusConnectorStateCollection, fmt.Sprintf(`connector_id == "%s"`, connectorID), []string{"state"})
+	if err != nil {
+		return nil, false, err
+	}
+	if !found {
+		return nil, false, nil
+	}
+	stateJSON, _ := res.GetColumn("state").GetAsString(0)
+	state := &types.ConnectorState{}
+	if err := json.Unmarshal([]byte(stateJSON), state); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal connector state: %v", err)
+	}
+	return state, true, nil
+}
+
+func (m *MilvusStore) GetConnectorState(ctx context.Context, connectorID string) (*types.ConnectorState, error) {
+	state, found, err := m.getConnectorStateRow(ctx, connectorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connector state: %v", err)
+	}
+	if !found {
+		return nil, ErrNoStateFound
+	}
+	return state, nil
+}
+
+func (m *MilvusStore) AllConnectorStates(ctx context.Context) ([]*types.ConnectorState, error) {
+	res, err := m.client.Query(ctx, milvusConnectorStateCollection, nil, "", []string{"state"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list connector states: %v", err)
+	}
+	states := []*types.ConnectorState{}
+	if len(res) == 0 {
+		return states, nil
+	}
+	for i := 0; i < res[0].Len(); i++ {
+		stateJSON, _ := res[0].GetColumn("state").GetAsString(i)
+		state := &types.ConnectorState{}
+		if err := json.Unmarshal([]byte(stateJSON), state); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal connector state: %v", err)
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// UpdateConnectorState replaces the single JSON-blob row for
+// state.ConnectorID, enforcing optimistic concurrency: an update to an
+// existing row must carry the ResourceVersion currently stored for it, else
+// ErrConflict is returned without writing anything. On success
+// state.ResourceVersion is bumped in place to the version just written.
+// The check-then-delete-then-insert below isn't atomic at the Milvus API
+// level, so it's serialized per connector ID via stateLocks instead, to
+// keep two in-process writers from both passing the version check and
+// clobbering each other.
+func (m *MilvusStore) UpdateConnectorState(ctx context.Context, state *types.ConnectorState) error {
+	defer m.stateLocks.lock(state.ConnectorID)()
+
+	existing, found, err := m.getConnectorStateRow(ctx, state.ConnectorID)
+	if err != nil {
+		return fmt.Errorf("failed to check existing connector state: %v", err)
+	}
+	if !found {
+		if state.ResourceVersion != "" {
+			return fmt.Errorf("%w: connector state not found", ErrConflict)
+		}
+	} else if existing.ResourceVersion != state.ResourceVersion {
+		return ErrConflict
+	}
+
+	newVersion := uuid.NewString()
+	state.ResourceVersion = newVersion
+	stateJSON, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal connector state: %v", err)
+	}
+	if err := m.client.Delete(ctx, milvusConnectorStateCollection, "", fmt.Sprintf(`connector_id == "%s"`, state.ConnectorID)); err != nil {
+		return fmt.Errorf("failed to clear existing connector state row: %v", err)
+	}
+	_, err = m.client.Insert(ctx, milvusConnectorStateCollection, "",
+		entity.NewColumnVarChar("connector_id", []string{state.ConnectorID}),
+		entity.NewColumnVarChar("state", []string{string(stateJSON)}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update connector state: %v", err)
+	}
+	m.publish(connectorStateChangedEvent(state))
+	return nil
+}
+
+func (m *MilvusStore) SetConnectorSyncing(ctx context.Context, connectorID string, syncing bool) (*types.ConnectorState, error) {
+	var state *types.ConnectorState
+	err := MutateConnectorState(ctx, m, connectorID, func(st *types.ConnectorState) error {
+		state = st
+		if st.Syncing == syncing {
+			return ErrSyncingAlreadyExpected
+		}
+		st.Syncing = syncing
+		return nil
+	})
+	return state, err
+}
+
+func (m *MilvusStore) DeleteDocumentById(ctx context.Context, documentId string) error {
+	if err := m.client.Delete(ctx, milvusDocumentCollection, "", fmt.Sprintf(`id == "%s"`, documentId)); err != nil {
+		return fmt.Errorf("failed to delete document: %v", err)
+	}
+	return nil
+}
+
+func (m *MilvusStore) DeleteDocumentChunksById(ctx context.Context, documentId string) error {
+	res, found, err := m.queryOne(ctx, milvusDocumentCollection, fmt.Sprintf(`id == "%s"`, documentId), []string{"unique_id"})
+	if err != nil {
+		return fmt.Errorf("failed to look up document: %v", err)
+	}
+	if !found {
+		return nil
+	}
+	uniqueID, _ := res.GetColumn("unique_id").GetAsString(0)
+	if err := m.client.Delete(ctx, milvusChunkCollection, "", fmt.Sprintf(`documentid == "%s"`, uniqueID)); err != nil {
+		return fmt.Errorf("failed to delete document chunks: %v", err)
+	}
+	return nil
+}
+
+// GetDocumentChunkHashes returns the Hash of every chunk currently stored
+// under uniqueID.
+func (m *MilvusStore) GetDocumentChunkHashes(ctx context.Context, uniqueID string) ([]string, error) {
+	res, found, err := m.queryOne(ctx, milvusChunkCollection, fmt.Sprintf(`documentid == "%s"`, uniqueID), []string{"hash"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query chunk hashes: %v", err)
+	}
+	if !found {
+		return nil, nil
+	}
+
+	hashes := make([]string, 0, res[0].Len())
+	for i := 0; i < res[0].Len(); i++ {
+		hash, err := res.GetColumn("hash").GetAsString(i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read hash column: %v", err)
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, nil
+}
+
+func (m *MilvusStore) DeleteDocumentChunks(ctx context.Context, uniqueID string, connectorID string) error {
+	if err := m.client.Delete(ctx, milvusChunkCollection, "", fmt.Sprintf(`documentid == "%s"`, uniqueID)); err != nil {
+		return fmt.Errorf("failed to delete document chunks: %v", err)
+	}
+	if err := m.client.Delete(ctx, milvusDocumentCollection, "", fmt.Sprintf(`unique_id == "%s" and connector_id == "%s"`, uniqueID, connectorID)); err != nil {
+		return fmt.Errorf("failed to delete document: %v", err)
+	}
+	return nil
+}
+
+func (m *MilvusStore) DeleteConnector(ctx context.Context, connector types.Connector) error {
+	connectorID := connector.ID()
+	if err := m.client.Delete(ctx, milvusConnectorStateCollection, "", fmt.Sprintf(`connector_id == "%s"`, connectorID)); err != nil {
+		return fmt.Errorf("failed to delete connector state: %v", err)
+	}
+	res, err := m.client.Query(ctx, milvusDocumentCollection, nil, fmt.Sprintf(`connector_id == "%s"`, connectorID), []string{"unique_id"})
+	if err != nil {
+		return fmt.Errorf("failed to list connector documents: %v", err)
+	}
+	if len(res) > 0 {
+		for i := 0; i < res[0].Len(); i++ {
+			uniqueID, _ := res[0].GetColumn("unique_id").GetAsString(i)
+			if err := m.client.Delete(ctx, milvusChunkCollection, "", fmt.Sprintf(`documentid == "%s"`, uniqueID)); err != nil {
+				return fmt.Errorf("failed to delete connector chunks: %v", err)
+			}
+		}
+	}
+	if err := m.client.Delete(ctx, milvusDocumentCollection, "", fmt.Sprintf(`connector_id == "%s"`, connectorID)); err != nil {
+		return fmt.Errorf("failed to delete connector documents: %v", err)
+	}
+	m.publish(types.ConnectorEvent{Type: types.ConnectorEventDeleted, ConnectorID: connectorID})
+	return nil
+}
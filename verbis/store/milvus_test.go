@@ -0,0 +1,41 @@
+package store_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/verbis-ai/verbis/verbis/store"
+	"github.com/verbis-ai/verbis/verbis/store/conformance"
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+// TestMilvusStoreConformance runs the same conformance suite as
+// TestSQLiteStoreConformance against a live Milvus instance, same
+// reachable-instance-or-skip pattern as TestRedisStoreConformance, since
+// this suite doesn't get to bring up a Milvus deployment in every
+// environment it runs in. It reads VERBIS_MILVUS_ADDR the same way
+// store.New's BackendMilvus case does (defaulting to localhost:19530).
+func TestMilvusStoreConformance(t *testing.T) {
+	addr := os.Getenv("VERBIS_MILVUS_ADDR")
+	if addr == "" {
+		addr = "localhost:19530"
+	}
+	const dim = 3
+
+	dialCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if _, err := store.NewMilvusStore(dialCtx, addr, dim); err != nil {
+		t.Skipf("no milvus reachable at %s, skipping: %v", addr, err)
+	}
+
+	conformance.RunTests(t, func() types.Store {
+		st, err := store.NewMilvusStore(context.Background(), addr, dim)
+		if err != nil {
+			t.Fatalf("NewMilvusStore: %v", err)
+		}
+		initSchema(t, st)
+		return st
+	})
+}
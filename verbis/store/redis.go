@@ -0,0 +1,1074 @@
+package store
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/verbis-ai/verbis/verbis/types"
+	"github.com/verbis-ai/verbis/verbis/util"
+)
+
+// Redis key and index naming. Documents, conversations, and connector
+// states are plain JSON strings under their own prefix; chunks are Redis
+// hashes under redisChunkPrefix so RediSearch can index them directly
+// (FT.CREATE ... ON HASH). The three "updated" sorted sets give
+// ListConversations/ListDocuments a stable newest-first ordering without a
+// secondary query engine.
+const (
+	redisChunkPrefix           = "verbis:chunk:"
+	redisDocPrefix             = "verbis:doc:"
+	redisConvPrefix            = "verbis:conv:"
+	redisConnStatePrefix       = "verbis:connstate:"
+	redisDocsUpdatedZSet       = "verbis:docs:updated"
+	redisConvsUpdatedZSet      = "verbis:convs:updated"
+	redisDocsByConnectorPrefix = "verbis:docs:by-connector:"
+	redisBandSetPrefix         = "verbis:band:"
+
+	redisChunkIndex = "verbis_chunk_idx"
+
+	// redisRRFK is Redis's own copy of the Reciprocal Rank Fusion constant
+	// used to blend the separate keyword and vector FT.SEARCH result lists
+	// (see rrfRerankK in retrieval.go, which fuses the same way over
+	// Chunk.DenseScore/KeywordScore once results leave the store).
+	redisRRFK = 60
+)
+
+func chunkKey(hash string) string         { return redisChunkPrefix + hash }
+func docKey(uniqueID string) string       { return redisDocPrefix + uniqueID }
+func convKey(id string) string            { return redisConvPrefix + id }
+func connStateKey(id string) string       { return redisConnStatePrefix + id }
+func docsByConnectorKey(id string) string { return redisDocsByConnectorPrefix + id }
+func bandKey(connectorID string, band int, value uint16) string {
+	return fmt.Sprintf("%s%s:%d:%d", redisBandSetPrefix, connectorID, band, value)
+}
+
+// RedisStore is a types.Store implementation backed by Redis and the
+// RediSearch module: it stores documents, conversations, and connector
+// state as JSON strings, and chunks as hash documents with a float32
+// vector field, letting RediSearch's HNSW index serve the dense half of
+// HybridSearch alongside a plain FT.SEARCH text query for the keyword
+// half. It's the lowest-ceremony self-hosted alternative to running
+// Weaviate or Milvus, for deployments that already run Redis.
+type RedisStore struct {
+	client *redis.Client
+	dim    int
+
+	// eventBroker publishes a ConnectorEvent for every successful
+	// UpdateConnectorState/DeleteConnector call; see Subscribe.
+	eventBroker
+}
+
+// NewRedisStore connects to the Redis instance at addr and returns a
+// RedisStore whose chunk vector field is sized for dim-dimensional
+// embeddings. It does not create the RediSearch index or any keyspace
+// data; callers must still call CreateChunkClass and friends, same as
+// every other Store implementation.
+func NewRedisStore(ctx context.Context, addr string, dim int) (types.Store, error) {
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis at %s: %v", addr, err)
+	}
+	return &RedisStore{client: client, dim: dim, eventBroker: newEventBroker()}, nil
+}
+
+// scanDelete deletes every key matching pattern, a non-blocking
+// alternative to `KEYS pattern` + DEL for the force=true "drop everything"
+// path of the CreateXClass methods.
+func (r *RedisStore) scanDelete(ctx context.Context, pattern string) error {
+	iter := r.client.Scan(ctx, 0, pattern, 0).Iterator()
+	keys := []string{}
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("failed to scan %s: %v", pattern, err)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := r.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to delete %s: %v", pattern, err)
+	}
+	return nil
+}
+
+func (r *RedisStore) CreateDocumentClass(ctx context.Context, force bool) error {
+	if !force {
+		return nil
+	}
+	if err := r.scanDelete(ctx, redisDocPrefix+"*"); err != nil {
+		return err
+	}
+	if err := r.scanDelete(ctx, redisDocsByConnectorPrefix+"*"); err != nil {
+		return err
+	}
+	return r.client.Del(ctx, redisDocsUpdatedZSet).Err()
+}
+
+// CreateChunkClass drops (if force) and recreates the RediSearch index
+// backing every chunk hash under redisChunkPrefix. FT.DROPINDEX ... DD also
+// deletes the hash documents themselves, matching the other backends'
+// "force truncates the underlying table" convention.
+func (r *RedisStore) CreateChunkClass(ctx context.Context, force bool) error {
+	if force {
+		if err := r.client.Do(ctx, "FT.DROPINDEX", redisChunkIndex, "DD").Err(); err != nil && !strings.Contains(err.Error(), "Unknown Index name") {
+			return fmt.Errorf("failed to drop chunk index: %v", err)
+		}
+		if err := r.scanDelete(ctx, redisBandSetPrefix+"*"); err != nil {
+			return err
+		}
+	}
+
+	err := r.client.Do(ctx, "FT.CREATE", redisChunkIndex,
+		"ON", "HASH", "PREFIX", "1", redisChunkPrefix,
+		"SCHEMA",
+		"text", "TEXT",
+		"document_id", "TAG",
+		"connector_id", "TAG",
+		"created_at_ts", "NUMERIC",
+		"updated_at_ts", "NUMERIC",
+		"vector", "VECTOR", "HNSW", "6", "TYPE", "FLOAT32", "DIM", strconv.Itoa(r.dim), "DISTANCE_METRIC", "COSINE",
+	).Err()
+	if err != nil && !strings.Contains(err.Error(), "Index already exists") {
+		return fmt.Errorf("failed to create chunk index: %v", err)
+	}
+	return nil
+}
+
+func (r *RedisStore) CreateConversationClass(ctx context.Context, force bool) error {
+	if !force {
+		return nil
+	}
+	if err := r.scanDelete(ctx, redisConvPrefix+"*"); err != nil {
+		return err
+	}
+	return r.client.Del(ctx, redisConvsUpdatedZSet).Err()
+}
+
+func (r *RedisStore) CreateConnectorStateClass(ctx context.Context, force bool) error {
+	if !force {
+		return nil
+	}
+	return r.scanDelete(ctx, redisConnStatePrefix+"*")
+}
+
+func (r *RedisStore) ChunkHashExists(ctx context.Context, hash string) (bool, error) {
+	n, err := r.client.Exists(ctx, chunkKey(hash)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check chunk hash: %v", err)
+	}
+	return n > 0, nil
+}
+
+func (r *RedisStore) GetChunkByHash(ctx context.Context, hash string) (*types.Chunk, error) {
+	fields, err := r.client.HGetAll(ctx, chunkKey(hash)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunk: %v", err)
+	}
+	if len(fields) == 0 {
+		return nil, ErrChunkNotFound
+	}
+
+	doc, err := r.GetDocument(ctx, fields["document_id"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document for chunk: %v", err)
+	}
+
+	fingerprint, _ := strconv.ParseUint(fields["fingerprint"], 10, 64)
+	return &types.Chunk{Document: *doc, Text: fields["text"], Hash: hash, Fingerprint: fingerprint}, nil
+}
+
+// FindNearDuplicateChunk mirrors SQLiteStore's band-probe: every chunk is
+// SADDed into one set per util.SimHashBands band at write time, so probing
+// the four bands here only has to re-check Hamming distance on the
+// (usually small) union of chunks that share at least one band with
+// fingerprint, rather than scanning every chunk under connectorID.
+func (r *RedisStore) FindNearDuplicateChunk(ctx context.Context, connectorID string, fingerprint uint64, maxDistance int) (*types.Chunk, error) {
+	bands := util.SimHashBands(fingerprint)
+	bandKeys := make([]string, len(bands))
+	for i, v := range bands {
+		bandKeys[i] = bandKey(connectorID, i, v)
+	}
+
+	candidates, err := r.client.SUnion(ctx, bandKeys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to union band sets: %v", err)
+	}
+
+	for _, hash := range candidates {
+		fpStr, err := r.client.HGet(ctx, chunkKey(hash), "fingerprint").Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read candidate fingerprint: %v", err)
+		}
+		candidateFP, _ := strconv.ParseUint(fpStr, 10, 64)
+		if util.HammingDistance(fingerprint, candidateFP) > maxDistance {
+			continue
+		}
+		return r.GetChunkByHash(ctx, hash)
+	}
+	return nil, nil
+}
+
+func (r *RedisStore) DeleteChunkByHash(ctx context.Context, hash string) error {
+	fields, err := r.client.HGetAll(ctx, chunkKey(hash)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to look up chunk: %v", err)
+	}
+	if len(fields) > 0 {
+		fingerprint, _ := strconv.ParseUint(fields["fingerprint"], 10, 64)
+		if err := r.removeFromBands(ctx, fields["connector_id"], fingerprint, hash); err != nil {
+			return err
+		}
+	}
+	if err := r.client.Del(ctx, chunkKey(hash)).Err(); err != nil {
+		return fmt.Errorf("failed to delete chunk: %v", err)
+	}
+	return nil
+}
+
+func (r *RedisStore) removeFromBands(ctx context.Context, connectorID string, fingerprint uint64, hash string) error {
+	bands := util.SimHashBands(fingerprint)
+	for i, v := range bands {
+		if err := r.client.SRem(ctx, bandKey(connectorID, i, v), hash).Err(); err != nil {
+			return fmt.Errorf("failed to remove chunk from band set: %v", err)
+		}
+	}
+	return nil
+}
+
+func (r *RedisStore) GetDocument(ctx context.Context, uniqueID string) (*types.Document, error) {
+	raw, err := r.client.Get(ctx, docKey(uniqueID)).Result()
+	if err == redis.Nil {
+		return nil, ErrDocumentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document: %v", err)
+	}
+	var doc types.Document
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal document: %v", err)
+	}
+	return &doc, nil
+}
+
+// getOrCreateDocument returns doc.UniqueID's current Document, creating
+// and indexing one from doc if it doesn't exist yet. created reports
+// whether a new document was written.
+func (r *RedisStore) getOrCreateDocument(ctx context.Context, doc types.Document) (created bool, err error) {
+	_, err = r.GetDocument(ctx, doc.UniqueID)
+	if err == nil {
+		return false, nil
+	}
+	if !IsErrDocumentNotFound(err) {
+		return false, fmt.Errorf("failed to look up document: %v", err)
+	}
+
+	if doc.Status == "" {
+		doc.Status = types.DocumentStatusActive
+	}
+	payload, err := json.Marshal(doc)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal document: %v", err)
+	}
+	if err := r.client.Set(ctx, docKey(doc.UniqueID), payload, 0).Err(); err != nil {
+		return false, fmt.Errorf("failed to store document: %v", err)
+	}
+	if err := r.client.ZAdd(ctx, redisDocsUpdatedZSet, redis.Z{Score: float64(doc.UpdatedAt.Unix()), Member: doc.UniqueID}).Err(); err != nil {
+		return false, fmt.Errorf("failed to index document: %v", err)
+	}
+	if err := r.client.SAdd(ctx, docsByConnectorKey(doc.ConnectorID), doc.UniqueID).Err(); err != nil {
+		return false, fmt.Errorf("failed to index document by connector: %v", err)
+	}
+	return true, nil
+}
+
+// float32sToBytes encodes vec as little-endian float32s, the layout
+// RediSearch's VECTOR field expects for a FLOAT32 blob.
+func float32sToBytes(vec []float32) []byte {
+	b := make([]byte, 4*len(vec))
+	for i, f := range vec {
+		binary.LittleEndian.PutUint32(b[i*4:], math.Float32bits(f))
+	}
+	return b
+}
+
+func (r *RedisStore) AddVectors(ctx context.Context, items []types.AddVectorItem) (*types.AddVectorResponse, error) {
+	numDocsAdded := 0
+	for _, item := range items {
+		created, err := r.getOrCreateDocument(ctx, item.Document)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get or create document: %v", err)
+		}
+		if created {
+			numDocsAdded++
+		}
+
+		fields := map[string]interface{}{
+			"text":          item.Chunk.Text,
+			"document_id":   item.Document.UniqueID,
+			"connector_id":  item.Document.ConnectorID,
+			"fingerprint":   strconv.FormatUint(item.Chunk.Fingerprint, 10),
+			"created_at_ts": item.Document.CreatedAt.Unix(),
+			"updated_at_ts": item.Document.UpdatedAt.Unix(),
+			"vector":        float32sToBytes(item.Vector),
+		}
+		if err := r.client.HSet(ctx, chunkKey(item.Chunk.Hash), fields).Err(); err != nil {
+			return nil, fmt.Errorf("failed to store chunk: %v", err)
+		}
+
+		bands := util.SimHashBands(item.Chunk.Fingerprint)
+		for i, v := range bands {
+			if err := r.client.SAdd(ctx, bandKey(item.Document.ConnectorID, i, v), item.Chunk.Hash).Err(); err != nil {
+				return nil, fmt.Errorf("failed to index chunk band: %v", err)
+			}
+		}
+	}
+
+	return &types.AddVectorResponse{
+		NumChunksAdded: len(items),
+		NumDocsAdded:   numDocsAdded,
+	}, nil
+}
+
+// redisEscape backslash-escapes RediSearch's reserved punctuation, so a
+// raw query string or tag value containing it (an email address's "@" and
+// ".", a Slack channel's "-") is treated as a literal rather than query
+// syntax.
+func redisEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '-', '@', '{', '}', '(', ')', '|', ' ', ':', '"', '\'', '~', '*', '/', '\\', ',', '.', '<', '>', '!', ';', '=', '+', '^', '$', '[', ']':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// buildFilterExpr turns the ConnectorIDs/date-range fields of opts into a
+// RediSearch filter expression ANDed onto the keyword/vector query.
+// FieldBoosts, DocNameGlob and RequesterIdentities aren't honored here: the
+// chunk index has no per-field weighting to boost, and RediSearch has no
+// native glob or array-overlap operator, so all three are instead applied
+// as a post-filter against the fetched Document, same as SQLiteStore and
+// MilvusStore. RequesterIdentities in particular falls out almost for
+// free: GetDocument already round-trips the whole Document, ACL included,
+// through a single JSON blob, so no chunk-hash schema change is needed.
+func buildFilterExpr(opts types.SearchOptions) string {
+	var clauses []string
+	if len(opts.ConnectorIDs) > 0 {
+		escaped := make([]string, len(opts.ConnectorIDs))
+		for i, id := range opts.ConnectorIDs {
+			escaped[i] = redisEscape(id)
+		}
+		clauses = append(clauses, fmt.Sprintf("@connector_id:{%s}", strings.Join(escaped, "|")))
+	}
+	if r := numericRangeClause("created_at_ts", opts.CreatedAfter, opts.CreatedBefore); r != "" {
+		clauses = append(clauses, r)
+	}
+	if r := numericRangeClause("updated_at_ts", opts.UpdatedAfter, opts.UpdatedBefore); r != "" {
+		clauses = append(clauses, r)
+	}
+	return strings.Join(clauses, " ")
+}
+
+func numericRangeClause(field string, after, before time.Time) string {
+	if after.IsZero() && before.IsZero() {
+		return ""
+	}
+	min := "-inf"
+	if !after.IsZero() {
+		min = strconv.FormatInt(after.Unix(), 10)
+	}
+	max := "+inf"
+	if !before.IsZero() {
+		// A leading "(" makes a RediSearch numeric range bound exclusive,
+		// matching SearchOptions' half-open [after, before) convention.
+		max = "(" + strconv.FormatInt(before.Unix(), 10)
+	}
+	return fmt.Sprintf("@%s:[%s %s]", field, min, max)
+}
+
+type redisSearchHit struct {
+	hash  string
+	score float64
+}
+
+// runFTSearch runs an FT.SEARCH command that returns NOCONTENT WITHSCORES
+// (the keyword path) or RETURN 1 <scoreField> (the vector path), and
+// parses the alternating docId/value pairs out of its raw reply. docId is
+// turned back into a chunk hash by trimming redisChunkPrefix.
+func (r *RedisStore) runFTSearch(ctx context.Context, args ...interface{}) ([]redisSearchHit, error) {
+	reply, err := r.client.Do(ctx, args...).Result()
+	if err != nil {
+		return nil, err
+	}
+	items, ok := reply.([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, nil
+	}
+
+	hits := make([]redisSearchHit, 0, len(items)/2)
+	for i := 1; i+1 < len(items); i += 2 {
+		docID := fmt.Sprintf("%v", items[i])
+		hash := strings.TrimPrefix(docID, redisChunkPrefix)
+
+		var score float64
+		switch v := items[i+1].(type) {
+		case []interface{}:
+			// RETURN 1 <field> shape: [field name, field value].
+			if len(v) == 2 {
+				score, _ = strconv.ParseFloat(fmt.Sprintf("%v", v[1]), 64)
+			}
+		default:
+			score, _ = strconv.ParseFloat(fmt.Sprintf("%v", v), 64)
+		}
+		hits = append(hits, redisSearchHit{hash: hash, score: score})
+	}
+	return hits, nil
+}
+
+// HybridSearch fuses a keyword-only FT.SEARCH text query and a vector-only
+// FT.SEARCH KNN query by Reciprocal Rank Fusion over each list's rank
+// rather than SearchOptions.Alpha/Fusion blending: RediSearch's BM25-style
+// text score and its COSINE vector distance live on different,
+// non-comparable scales, and RRF only needs each list's order. Alpha and
+// Fusion are therefore not honored by this backend.
+func (r *RedisStore) HybridSearch(ctx context.Context, query string, vector []float32, opts types.SearchOptions) ([]*types.Chunk, error) {
+	limit := MaxNumSearchResults
+	if opts.Limit > 0 {
+		limit = opts.Limit
+	}
+	filterExpr := buildFilterExpr(opts)
+	base := filterExpr
+	if base == "" {
+		base = "*"
+	}
+
+	var keywordHits []redisSearchHit
+	if query != "" {
+		q := fmt.Sprintf("@text:(%s)", redisEscape(query))
+		if filterExpr != "" {
+			q = q + " " + filterExpr
+		}
+		hits, err := r.runFTSearch(ctx, "FT.SEARCH", redisChunkIndex, q, "NOCONTENT", "WITHSCORES", "LIMIT", "0", strconv.Itoa(WeaviateMaxResults), "DIALECT", "2")
+		if err != nil {
+			return nil, fmt.Errorf("failed to run keyword search: %v", err)
+		}
+		keywordHits = hits
+	}
+
+	var denseHits []redisSearchHit
+	if len(vector) > 0 {
+		knnQuery := fmt.Sprintf("(%s)=>[KNN %d @vector $vec AS vector_score]", base, WeaviateMaxResults)
+		hits, err := r.runFTSearch(ctx, "FT.SEARCH", redisChunkIndex, knnQuery,
+			"PARAMS", "2", "vec", float32sToBytes(vector),
+			"SORTBY", "vector_score", "LIMIT", "0", strconv.Itoa(WeaviateMaxResults),
+			"RETURN", "1", "vector_score", "DIALECT", "2")
+		if err != nil {
+			return nil, fmt.Errorf("failed to run vector search: %v", err)
+		}
+		denseHits = hits
+	}
+
+	// Keyword scores are higher-is-better; vector_score is a COSINE
+	// distance, lower-is-better, so its rank order is sorted ascending.
+	sort.Slice(keywordHits, func(i, j int) bool { return keywordHits[i].score > keywordHits[j].score })
+	sort.Slice(denseHits, func(i, j int) bool { return denseHits[i].score < denseHits[j].score })
+
+	keywordScore := map[string]float64{}
+	denseScore := map[string]float64{}
+	rrf := map[string]float64{}
+	for rank, hit := range keywordHits {
+		keywordScore[hit.hash] = hit.score
+		rrf[hit.hash] += 1.0 / float64(redisRRFK+rank+1)
+	}
+	for rank, hit := range denseHits {
+		denseScore[hit.hash] = 1 - hit.score/2 // approximate cosine similarity from COSINE distance
+		rrf[hit.hash] += 1.0 / float64(redisRRFK+rank+1)
+	}
+
+	ranked := make([]redisSearchHit, 0, len(rrf))
+	for hash, score := range rrf {
+		if score < opts.MinScore {
+			continue
+		}
+		ranked = append(ranked, redisSearchHit{hash: hash, score: score})
+	}
+	sort.Slice(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	res := make([]*types.Chunk, 0, len(ranked))
+	for _, hit := range ranked {
+		chunk, err := r.GetChunkByHash(ctx, hit.hash)
+		if err != nil {
+			if IsErrChunkNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to load search result chunk: %v", err)
+		}
+		if chunk.Document.Status == types.DocumentStatusTombstoned {
+			continue
+		}
+		if opts.DocNameGlob != "" {
+			if ok, _ := path.Match(opts.DocNameGlob, chunk.Document.Name); !ok {
+				continue
+			}
+		}
+		if len(opts.RequesterIdentities) > 0 && len(chunk.Document.ACL) > 0 && !containsAny(chunk.Document.ACL, opts.RequesterIdentities) {
+			continue
+		}
+		chunk.Score = hit.score
+		chunk.DenseScore = denseScore[hit.hash]
+		chunk.KeywordScore = keywordScore[hit.hash]
+		chunk.ExplainScore = fmt.Sprintf("dense_score=%.4f keyword_score=%.4f rrf=%.4f", denseScore[hit.hash], keywordScore[hit.hash], hit.score)
+		res = append(res, chunk)
+	}
+	return res, nil
+}
+
+func (r *RedisStore) CreateConversation(ctx context.Context) (string, error) {
+	now := time.Now()
+	conv := types.Conversation{
+		ID:          uuid.NewString(),
+		History:     []types.HistoryItem{},
+		ChunkHashes: []string{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	payload, err := json.Marshal(conv)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal conversation: %v", err)
+	}
+	if err := r.client.Set(ctx, convKey(conv.ID), payload, 0).Err(); err != nil {
+		return "", fmt.Errorf("failed to create conversation: %v", err)
+	}
+	if err := r.client.ZAdd(ctx, redisConvsUpdatedZSet, redis.Z{Score: float64(now.Unix()), Member: conv.ID}).Err(); err != nil {
+		return "", fmt.Errorf("failed to index conversation: %v", err)
+	}
+	return conv.ID, nil
+}
+
+func (r *RedisStore) GetConversation(ctx context.Context, conversationID string) (*types.Conversation, error) {
+	raw, err := r.client.Get(ctx, convKey(conversationID)).Result()
+	if err == redis.Nil {
+		return nil, ErrConversationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %v", err)
+	}
+	var conv types.Conversation
+	if err := json.Unmarshal([]byte(raw), &conv); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal conversation: %v", err)
+	}
+	return &conv, nil
+}
+
+// scoredMember is a sorted-set member paired with its score, fetched in
+// bulk and re-sorted in Go so ties (two rows updated the same second) are
+// still broken deterministically by ID, the same composite order
+// SQLiteStore gets for free from `ORDER BY updated_at DESC, id DESC`.
+type scoredMember struct {
+	id    string
+	score float64
+}
+
+// pageMembers returns up to first+1 members of the zsetKey sorted set
+// ordered by (score, id) descending, skipping everything at or after the
+// position args.After's cursor names. Like flatVectorIndex, this re-sorts
+// the whole set in memory on every call rather than maintaining a proper
+// skip-list cursor; fine at the scale a single Redis instance is expected
+// to hold.
+func (r *RedisStore) pageMembers(ctx context.Context, zsetKey string, after string, first int) ([]scoredMember, error) {
+	raw, err := r.client.ZRevRangeWithScores(ctx, zsetKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %v", zsetKey, err)
+	}
+	members := make([]scoredMember, 0, len(raw))
+	for _, z := range raw {
+		id, _ := z.Member.(string)
+		members = append(members, scoredMember{id: id, score: z.Score})
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].score != members[j].score {
+			return members[i].score > members[j].score
+		}
+		return members[i].id > members[j].id
+	})
+
+	start := 0
+	if after != "" {
+		key, err := decodeCursor(after)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %v", err)
+		}
+		afterScore := float64(key.UpdatedAt.Unix())
+		start = len(members)
+		for i, m := range members {
+			if m.score < afterScore || (m.score == afterScore && m.id < key.ID) {
+				start = i
+				break
+			}
+		}
+	}
+	end := start + first + 1
+	if end > len(members) {
+		end = len(members)
+	}
+	if start > end {
+		start = end
+	}
+	return members[start:end], nil
+}
+
+func (r *RedisStore) ListConversations(ctx context.Context, args types.PageArgs) (*types.ConversationConnection, error) {
+	first := pageSize(args.First)
+	members, err := r.pageMembers(ctx, redisConvsUpdatedZSet, args.After, first)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &types.ConversationConnection{Edges: []types.ConversationEdge{}}
+	for _, m := range members {
+		conv, err := r.GetConversation(ctx, m.id)
+		if err != nil {
+			if IsErrConversationNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		// List pages omit History and ChunkHashes to stay small; callers
+		// page through the message log separately via GetConversationHistory.
+		conv.History = nil
+		conv.ChunkHashes = nil
+		conn.Edges = append(conn.Edges, types.ConversationEdge{Cursor: encodeCursor(conv.UpdatedAt, conv.ID), Node: conv})
+	}
+
+	hasNext := len(conn.Edges) > first
+	if hasNext {
+		conn.Edges = conn.Edges[:first]
+	}
+	conn.PageInfo = types.PageInfo{HasNextPage: hasNext, HasPreviousPage: args.After != ""}
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.StartCursor = conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = conn.Edges[len(conn.Edges)-1].Cursor
+	}
+	return conn, nil
+}
+
+func (r *RedisStore) GetConversationHistory(ctx context.Context, conversationID string, args types.PageArgs) (*types.HistoryConnection, error) {
+	conv, err := r.GetConversation(ctx, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %v", err)
+	}
+
+	first := pageSize(args.First)
+	start := 0
+	if args.After != "" {
+		key, err := decodeCursor(args.After)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %v", err)
+		}
+		idx, err := strconv.Atoi(key.ID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %v", err)
+		}
+		start = idx + 1
+	}
+
+	conn := &types.HistoryConnection{Edges: []types.HistoryEdge{}}
+	if start >= len(conv.History) {
+		return conn, nil
+	}
+	end := start + first
+	hasNext := end < len(conv.History)
+	if !hasNext {
+		end = len(conv.History)
+	}
+	for i := start; i < end; i++ {
+		conn.Edges = append(conn.Edges, types.HistoryEdge{Cursor: encodeCursor(conv.UpdatedAt, strconv.Itoa(i)), Node: conv.History[i]})
+	}
+	conn.PageInfo = types.PageInfo{HasNextPage: hasNext, HasPreviousPage: start > 0}
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.StartCursor = conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = conn.Edges[len(conn.Edges)-1].Cursor
+	}
+	return conn, nil
+}
+
+func (r *RedisStore) ListDocuments(ctx context.Context, args types.PageArgs) (*types.DocumentConnection, error) {
+	first := pageSize(args.First)
+	members, err := r.pageMembers(ctx, redisDocsUpdatedZSet, args.After, first)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &types.DocumentConnection{Edges: []types.DocumentEdge{}}
+	for _, m := range members {
+		doc, err := r.GetDocument(ctx, m.id)
+		if err != nil {
+			if IsErrDocumentNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		conn.Edges = append(conn.Edges, types.DocumentEdge{Cursor: encodeCursor(doc.UpdatedAt, doc.UniqueID), Node: doc})
+	}
+
+	hasNext := len(conn.Edges) > first
+	if hasNext {
+		conn.Edges = conn.Edges[:first]
+	}
+	conn.PageInfo = types.PageInfo{HasNextPage: hasNext, HasPreviousPage: args.After != ""}
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.StartCursor = conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = conn.Edges[len(conn.Edges)-1].Cursor
+	}
+	return conn, nil
+}
+
+func (r *RedisStore) ConversationAppend(ctx context.Context, conversationID string, items []types.HistoryItem, chunks []*types.Chunk) error {
+	conv, err := r.GetConversation(ctx, conversationID)
+	if err != nil {
+		return fmt.Errorf("unable to get conversation: %v", err)
+	}
+	items = attachChunkHashes(items, chunks)
+	conv.History = append(conv.History, items...)
+	for _, chunk := range chunks {
+		conv.ChunkHashes = append(conv.ChunkHashes, chunk.Hash)
+	}
+	conv.UpdatedAt = time.Now()
+	return r.putConversation(ctx, conv)
+}
+
+// putConversation writes conv back wholesale and re-indexes it in the
+// updated_at sorted set, the last step ConversationAppend, RenameConversation,
+// and TruncateConversationHistory all share.
+func (r *RedisStore) putConversation(ctx context.Context, conv *types.Conversation) error {
+	payload, err := json.Marshal(conv)
+	if err != nil {
+		return fmt.Errorf("failed to marshal conversation: %v", err)
+	}
+	if err := r.client.Set(ctx, convKey(conv.ID), payload, 0).Err(); err != nil {
+		return fmt.Errorf("failed to update conversation: %v", err)
+	}
+	return r.client.ZAdd(ctx, redisConvsUpdatedZSet, redis.Z{Score: float64(conv.UpdatedAt.Unix()), Member: conv.ID}).Err()
+}
+
+func (r *RedisStore) DeleteConversation(ctx context.Context, conversationID string) error {
+	if err := r.client.Del(ctx, convKey(conversationID)).Err(); err != nil {
+		return fmt.Errorf("failed to delete conversation: %v", err)
+	}
+	return r.client.ZRem(ctx, redisConvsUpdatedZSet, conversationID).Err()
+}
+
+func (r *RedisStore) RenameConversation(ctx context.Context, conversationID string, title string) error {
+	conv, err := r.GetConversation(ctx, conversationID)
+	if err != nil {
+		return fmt.Errorf("unable to get conversation: %v", err)
+	}
+	conv.Title = title
+	conv.UpdatedAt = time.Now()
+	if err := r.putConversation(ctx, conv); err != nil {
+		return fmt.Errorf("failed to rename conversation: %v", err)
+	}
+	return nil
+}
+
+func (r *RedisStore) TruncateConversationHistory(ctx context.Context, conversationID string, keep int) (*types.Conversation, error) {
+	conv, err := r.GetConversation(ctx, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get conversation: %v", err)
+	}
+	conv.History, conv.ChunkHashes = truncateHistory(conv.History, keep)
+	conv.UpdatedAt = time.Now()
+	if err := r.putConversation(ctx, conv); err != nil {
+		return nil, fmt.Errorf("failed to truncate conversation: %v", err)
+	}
+	return conv, nil
+}
+
+func (r *RedisStore) BranchConversation(ctx context.Context, conversationID string, atIndex int) (string, error) {
+	conv, err := r.GetConversation(ctx, conversationID)
+	if err != nil {
+		return "", fmt.Errorf("unable to get conversation: %v", err)
+	}
+	history, chunkHashes := truncateHistory(conv.History, atIndex)
+
+	now := time.Now()
+	branch := &types.Conversation{
+		ID:          uuid.NewString(),
+		Title:       conv.Title,
+		History:     history,
+		ChunkHashes: chunkHashes,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := r.putConversation(ctx, branch); err != nil {
+		return "", fmt.Errorf("failed to create branched conversation: %v", err)
+	}
+	return branch.ID, nil
+}
+
+func (r *RedisStore) GetConnectorState(ctx context.Context, connectorID string) (*types.ConnectorState, error) {
+	raw, err := r.client.Get(ctx, connStateKey(connectorID)).Result()
+	if err == redis.Nil {
+		return nil, ErrNoStateFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connector state: %v", err)
+	}
+	var state types.ConnectorState
+	if err := json.Unmarshal([]byte(raw), &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal connector state: %v", err)
+	}
+	return &state, nil
+}
+
+func (r *RedisStore) AllConnectorStates(ctx context.Context) ([]*types.ConnectorState, error) {
+	iter := r.client.Scan(ctx, 0, redisConnStatePrefix+"*", 0).Iterator()
+	ids := []string{}
+	for iter.Next(ctx) {
+		ids = append(ids, strings.TrimPrefix(iter.Val(), redisConnStatePrefix))
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list connector states: %v", err)
+	}
+
+	res := make([]*types.ConnectorState, 0, len(ids))
+	for _, id := range ids {
+		state, err := r.GetConnectorState(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get connector state %s: %v", id, err)
+		}
+		res = append(res, state)
+	}
+	return res, nil
+}
+
+// UpdateConnectorState upserts state inside a WATCH/MULTI transaction on
+// its key, enforcing the same optimistic-concurrency contract as every
+// other backend: a write whose ResourceVersion doesn't match what's
+// currently stored fails with ErrConflict without writing anything, and a
+// successful write bumps state.ResourceVersion in place.
+func (r *RedisStore) UpdateConnectorState(ctx context.Context, state *types.ConnectorState) error {
+	key := connStateKey(state.ConnectorID)
+	newVersion := uuid.NewString()
+
+	txf := func(tx *redis.Tx) error {
+		raw, err := tx.Get(ctx, key).Result()
+		exists := err != redis.Nil
+		if err != nil && err != redis.Nil {
+			return fmt.Errorf("failed to look up connector state: %v", err)
+		}
+
+		if exists {
+			var current types.ConnectorState
+			if err := json.Unmarshal([]byte(raw), &current); err != nil {
+				return fmt.Errorf("failed to unmarshal connector state: %v", err)
+			}
+			if current.ResourceVersion != state.ResourceVersion {
+				return ErrConflict
+			}
+		} else if state.ResourceVersion != "" {
+			return fmt.Errorf("%w: connector state not found", ErrConflict)
+		}
+
+		state.ResourceVersion = newVersion
+		payload, err := json.Marshal(state)
+		if err != nil {
+			return fmt.Errorf("failed to marshal connector state: %v", err)
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, payload, 0)
+			return nil
+		})
+		return err
+	}
+
+	if err := r.client.Watch(ctx, txf, key); err != nil {
+		if IsErrConflict(err) {
+			return err
+		}
+		if errors.Is(err, redis.TxFailedErr) {
+			// WATCH detected key actually changed between txf's Get and its
+			// TxPipelined: a real concurrent write, as opposed to the
+			// ResourceVersion mismatch txf checks for explicitly above.
+			// It's exactly as retryable as that case, so it needs the same
+			// ErrConflict MutateConnectorState retries on, not a permanent
+			// failure that silently drops this update.
+			return fmt.Errorf("%w: %s", ErrConflict, err)
+		}
+		return fmt.Errorf("failed to update connector state: %v", err)
+	}
+	r.publish(connectorStateChangedEvent(state))
+	return nil
+}
+
+func (r *RedisStore) SetConnectorSyncing(ctx context.Context, connectorID string, syncing bool) (*types.ConnectorState, error) {
+	var state *types.ConnectorState
+	err := MutateConnectorState(ctx, r, connectorID, func(st *types.ConnectorState) error {
+		state = st
+		if st.Syncing == syncing {
+			return ErrSyncingAlreadyExpected
+		}
+		st.Syncing = syncing
+		return nil
+	})
+	return state, err
+}
+
+// DeleteDocumentChunksById deletes every chunk whose document_id tag field
+// is documentId (which, for RedisStore, is the document's UniqueID: Redis
+// has no reason to keep a separate surrogate key the way SQLiteStore and
+// MilvusStore do). It finds them via the chunk index rather than keeping
+// a side index of document -> chunk hashes.
+func (r *RedisStore) DeleteDocumentChunksById(ctx context.Context, documentId string) error {
+	q := fmt.Sprintf("@document_id:{%s}", redisEscape(documentId))
+	hits, err := r.runFTSearch(ctx, "FT.SEARCH", redisChunkIndex, q, "NOCONTENT", "WITHSCORES", "LIMIT", "0", strconv.Itoa(WeaviateMaxResults), "DIALECT", "2")
+	if err != nil {
+		return fmt.Errorf("failed to list chunks for document: %v", err)
+	}
+	for _, hit := range hits {
+		if err := r.DeleteChunkByHash(ctx, hit.hash); err != nil {
+			return fmt.Errorf("failed to delete chunk %s: %v", hit.hash, err)
+		}
+	}
+	return nil
+}
+
+func (r *RedisStore) DeleteDocumentById(ctx context.Context, documentId string) error {
+	if err := r.DeleteDocumentChunksById(ctx, documentId); err != nil {
+		return fmt.Errorf("unable to delete document chunks: %v", err)
+	}
+	doc, err := r.GetDocument(ctx, documentId)
+	if err != nil && !IsErrDocumentNotFound(err) {
+		return fmt.Errorf("failed to look up document: %v", err)
+	}
+	if err == nil {
+		if err := r.client.SRem(ctx, docsByConnectorKey(doc.ConnectorID), documentId).Err(); err != nil {
+			return fmt.Errorf("failed to unindex document: %v", err)
+		}
+	}
+	if err := r.client.Del(ctx, docKey(documentId)).Err(); err != nil {
+		return fmt.Errorf("failed to delete document: %v", err)
+	}
+	return r.client.ZRem(ctx, redisDocsUpdatedZSet, documentId).Err()
+}
+
+// GetDocumentChunkHashes returns the Hash of every chunk currently stored
+// under uniqueID.
+func (r *RedisStore) GetDocumentChunkHashes(ctx context.Context, uniqueID string) ([]string, error) {
+	q := fmt.Sprintf("@document_id:{%s}", redisEscape(uniqueID))
+	hits, err := r.runFTSearch(ctx, "FT.SEARCH", redisChunkIndex, q, "NOCONTENT", "WITHSCORES", "LIMIT", "0", strconv.Itoa(WeaviateMaxResults), "DIALECT", "2")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chunk hashes for document: %v", err)
+	}
+	hashes := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		hashes = append(hashes, hit.hash)
+	}
+	return hashes, nil
+}
+
+func (r *RedisStore) DeleteDocumentChunks(ctx context.Context, uniqueID string, connectorID string) error {
+	q := fmt.Sprintf("@document_id:{%s}", redisEscape(uniqueID))
+	hits, err := r.runFTSearch(ctx, "FT.SEARCH", redisChunkIndex, q, "NOCONTENT", "WITHSCORES", "LIMIT", "0", strconv.Itoa(WeaviateMaxResults), "DIALECT", "2")
+	if err != nil {
+		return fmt.Errorf("failed to list chunks for document: %v", err)
+	}
+	if err := r.DeleteDocumentChunksById(ctx, uniqueID); err != nil {
+		return fmt.Errorf("unable to delete chunks: %v", err)
+	}
+
+	numDeleted := len(hits)
+	err = MutateConnectorState(ctx, r, connectorID, func(state *types.ConnectorState) error {
+		state.NumChunks -= numDeleted
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to update connector state: %v", err)
+	}
+	return nil
+}
+
+func (r *RedisStore) DeleteConnector(ctx context.Context, connector types.Connector) error {
+	connectorID := connector.ID()
+
+	docIDs, err := r.client.SMembers(ctx, docsByConnectorKey(connectorID)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to list documents for connector: %v", err)
+	}
+	for _, docID := range docIDs {
+		if err := r.DeleteDocumentById(ctx, docID); err != nil {
+			return fmt.Errorf("unable to delete document %s: %v", docID, err)
+		}
+	}
+
+	if err := r.client.Del(ctx, docsByConnectorKey(connectorID)).Err(); err != nil {
+		return fmt.Errorf("failed to delete connector document index: %v", err)
+	}
+	if err := r.client.Del(ctx, connStateKey(connectorID)).Err(); err != nil {
+		return fmt.Errorf("failed to delete connector state: %v", err)
+	}
+	r.publish(types.ConnectorEvent{Type: types.ConnectorEventDeleted, ConnectorID: connectorID})
+	return nil
+}
+
+// ReconcileConnector tombstones every document under connectorID whose
+// unique_id isn't in liveUniqueIDs, mirroring SQLiteStore and MilvusStore.
+func (r *RedisStore) ReconcileConnector(ctx context.Context, connectorID string, liveUniqueIDs []string) error {
+	live := map[string]bool{}
+	for _, id := range liveUniqueIDs {
+		live[id] = true
+	}
+
+	docIDs, err := r.client.SMembers(ctx, docsByConnectorKey(connectorID)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to list documents for connector: %v", err)
+	}
+
+	for _, docID := range docIDs {
+		if live[docID] {
+			continue
+		}
+		doc, err := r.GetDocument(ctx, docID)
+		if err != nil {
+			if IsErrDocumentNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get document %s: %v", docID, err)
+		}
+		if doc.Status == types.DocumentStatusTombstoned {
+			continue
+		}
+		doc.Status = types.DocumentStatusTombstoned
+		doc.DeletedAt = time.Now()
+		payload, err := json.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("failed to marshal document: %v", err)
+		}
+		if err := r.client.Set(ctx, docKey(docID), payload, 0).Err(); err != nil {
+			return fmt.Errorf("unable to tombstone document %s: %v", docID, err)
+		}
+	}
+	return nil
+}
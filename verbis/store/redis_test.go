@@ -0,0 +1,47 @@
+package store_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/verbis-ai/verbis/verbis/store"
+	"github.com/verbis-ai/verbis/verbis/store/conformance"
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+// TestRedisStoreConformance runs the same conformance suite as
+// TestSQLiteStoreConformance against a live Redis instance, so the two
+// backends are checked for behavioral drift against each other instead of
+// only against the interface's doc comments. It reads VERBIS_REDIS_ADDR
+// the same way store.New's BackendRedis case does (defaulting to
+// localhost:6379), and skips instead of failing when nothing is
+// listening there, since this suite doesn't get to bring up a Redis
+// container in every environment it runs in.
+func TestRedisStoreConformance(t *testing.T) {
+	addr := os.Getenv("VERBIS_REDIS_ADDR")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+
+	// dim matches the length of the vector conformance.RunTests embeds its
+	// test chunk with, so RediSearch's HNSW field isn't fed a blob of a
+	// different size than it was declared with.
+	const dim = 3
+
+	pingCtx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if _, err := store.NewRedisStore(pingCtx, addr, dim); err != nil {
+		t.Skipf("no redis reachable at %s, skipping: %v", addr, err)
+	}
+
+	conformance.RunTests(t, func() types.Store {
+		st, err := store.NewRedisStore(context.Background(), addr, dim)
+		if err != nil {
+			t.Fatalf("NewRedisStore: %v", err)
+		}
+		initSchema(t, st)
+		return st
+	})
+}
@@ -0,0 +1,1189 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	_ "modernc.org/sqlite"
+
+	"github.com/verbis-ai/verbis/verbis/types"
+	"github.com/verbis-ai/verbis/verbis/util"
+)
+
+type scoredHash struct {
+	hash  string
+	score float64
+}
+
+// SQLiteStore is a local, single-file implementation of types.Store, for
+// users who don't want to run the Weaviate container. Documents and chunks
+// live in SQLite tables (keyword search via an FTS5 virtual table), and
+// embeddings live in a sidecar flatVectorIndex, since SQLite itself has no
+// native vector search.
+type SQLiteStore struct {
+	db    *sql.DB
+	index *flatVectorIndex
+
+	// eventBroker publishes a ConnectorEvent for every successful
+	// UpdateConnectorState/DeleteConnector call; see Subscribe.
+	eventBroker
+}
+
+// NewSQLiteStore opens (creating if necessary) a SQLite database at
+// dbPath, plus its companion vector index at dbPath+".vectors".
+func NewSQLiteStore(dbPath string) (types.Store, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %v", err)
+	}
+
+	// SQLite only allows one writer at a time; serialize access rather
+	// than fight it with "database is locked" errors under concurrent
+	// syncs.
+	db.SetMaxOpenConns(1)
+
+	index, err := newFlatVectorIndex(dbPath + ".vectors")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open vector index: %v", err)
+	}
+
+	return &SQLiteStore{db: db, index: index, eventBroker: newEventBroker()}, nil
+}
+
+func (s *SQLiteStore) CreateDocumentClass(ctx context.Context, force bool) error {
+	if force {
+		if _, err := s.db.ExecContext(ctx, `DROP TABLE IF EXISTS documents`); err != nil {
+			return fmt.Errorf("failed to drop documents table: %v", err)
+		}
+	}
+	_, err := s.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS documents (
+			id TEXT PRIMARY KEY,
+			unique_id TEXT UNIQUE NOT NULL,
+			name TEXT,
+			source_url TEXT,
+			connector_id TEXT,
+			connector_type TEXT,
+			created_at TEXT,
+			updated_at TEXT,
+			status TEXT NOT NULL DEFAULT 'active',
+			deleted_at TEXT,
+			acl TEXT NOT NULL DEFAULT '[]'
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create documents table: %v", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) CreateChunkClass(ctx context.Context, force bool) error {
+	if force {
+		if _, err := s.db.ExecContext(ctx, `DROP TABLE IF EXISTS chunks`); err != nil {
+			return fmt.Errorf("failed to drop chunks table: %v", err)
+		}
+		if _, err := s.db.ExecContext(ctx, `DROP TABLE IF EXISTS chunks_fts`); err != nil {
+			return fmt.Errorf("failed to drop chunks_fts table: %v", err)
+		}
+	}
+	if _, err := s.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS chunks (
+			hash TEXT PRIMARY KEY,
+			document_id TEXT NOT NULL,
+			text TEXT NOT NULL,
+			connector_id TEXT NOT NULL DEFAULT '',
+			fingerprint INTEGER NOT NULL DEFAULT 0,
+			band0 INTEGER NOT NULL DEFAULT 0,
+			band1 INTEGER NOT NULL DEFAULT 0,
+			band2 INTEGER NOT NULL DEFAULT 0,
+			band3 INTEGER NOT NULL DEFAULT 0
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create chunks table: %v", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `
+		CREATE VIRTUAL TABLE IF NOT EXISTS chunks_fts USING fts5(hash UNINDEXED, text)
+	`); err != nil {
+		return fmt.Errorf("failed to create chunks_fts table: %v", err)
+	}
+	// One index per band, so FindNearDuplicateChunk's four-way OR probes
+	// each in O(log N) instead of a full table scan.
+	for i, col := range []string{"band0", "band1", "band2", "band3"} {
+		if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+			CREATE INDEX IF NOT EXISTS chunks_band%d_idx ON chunks(connector_id, %s)
+		`, i, col)); err != nil {
+			return fmt.Errorf("failed to create %s index: %v", col, err)
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) CreateConversationClass(ctx context.Context, force bool) error {
+	if force {
+		if _, err := s.db.ExecContext(ctx, `DROP TABLE IF EXISTS conversations`); err != nil {
+			return fmt.Errorf("failed to drop conversations table: %v", err)
+		}
+	}
+	_, err := s.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS conversations (
+			id TEXT PRIMARY KEY,
+			title TEXT,
+			history TEXT,
+			chunk_hashes TEXT,
+			created_at TEXT,
+			updated_at TEXT
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create conversations table: %v", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) CreateConnectorStateClass(ctx context.Context, force bool) error {
+	if force {
+		if _, err := s.db.ExecContext(ctx, `DROP TABLE IF EXISTS connector_states`); err != nil {
+			return fmt.Errorf("failed to drop connector_states table: %v", err)
+		}
+	}
+	_, err := s.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS connector_states (
+			connector_id TEXT PRIMARY KEY,
+			user TEXT,
+			connector_type TEXT,
+			auth_valid INTEGER,
+			syncing INTEGER,
+			last_sync TEXT,
+			num_documents INTEGER,
+			num_chunks INTEGER,
+			num_errors INTEGER,
+			cursor TEXT,
+			progress TEXT,
+			pending_deletion INTEGER NOT NULL DEFAULT 0,
+			deleted_at TEXT,
+			resource_version TEXT NOT NULL DEFAULT ''
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create connector_states table: %v", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) ChunkHashExists(ctx context.Context, hash string) (bool, error) {
+	var exists bool
+	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM chunks WHERE hash = ?)`, hash).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check chunk hash: %v", err)
+	}
+	return exists, nil
+}
+
+func (s *SQLiteStore) GetChunkByHash(ctx context.Context, hash string) (*types.Chunk, error) {
+	var text, documentID string
+	err := s.db.QueryRowContext(ctx, `SELECT text, document_id FROM chunks WHERE hash = ?`, hash).Scan(&text, &documentID)
+	if err == sql.ErrNoRows {
+		return nil, ErrChunkNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunk: %v", err)
+	}
+
+	doc, err := s.getDocumentByID(ctx, documentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document for chunk: %v", err)
+	}
+
+	return &types.Chunk{Document: *doc, Text: text, Hash: hash}, nil
+}
+
+// FindNearDuplicateChunk probes all four SimHash bands of fingerprint
+// (see util.SimHashBands) scoped to connectorID, then filters the
+// candidates the band indexes return down to the first one within
+// maxDistance Hamming distance, since a band match only guarantees the
+// two fingerprints share that band, not that they're actually close.
+func (s *SQLiteStore) FindNearDuplicateChunk(ctx context.Context, connectorID string, fingerprint uint64, maxDistance int) (*types.Chunk, error) {
+	bands := util.SimHashBands(fingerprint)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT hash, document_id, text, fingerprint FROM chunks
+		WHERE connector_id = ? AND (band0 = ? OR band1 = ? OR band2 = ? OR band3 = ?)
+	`, connectorID, bands[0], bands[1], bands[2], bands[3])
+	if err != nil {
+		return nil, fmt.Errorf("failed to query near-duplicate candidates: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hash, documentID, text string
+		var candidateFP int64
+		if err := rows.Scan(&hash, &documentID, &text, &candidateFP); err != nil {
+			return nil, fmt.Errorf("failed to scan near-duplicate candidate: %v", err)
+		}
+		if util.HammingDistance(fingerprint, uint64(candidateFP)) > maxDistance {
+			continue
+		}
+
+		doc, err := s.getDocumentByID(ctx, documentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get document for chunk: %v", err)
+		}
+		return &types.Chunk{Document: *doc, Text: text, Hash: hash, Fingerprint: uint64(candidateFP)}, nil
+	}
+
+	return nil, rows.Err()
+}
+
+func (s *SQLiteStore) DeleteChunkByHash(ctx context.Context, hash string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM chunks WHERE hash = ?`, hash); err != nil {
+		return fmt.Errorf("failed to delete chunk: %v", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM chunks_fts WHERE hash = ?`, hash); err != nil {
+		return fmt.Errorf("failed to delete chunk from fts index: %v", err)
+	}
+	return s.index.Delete(hash)
+}
+
+func (s *SQLiteStore) getDocumentByID(ctx context.Context, documentID string) (*types.Document, error) {
+	var uniqueID, name, sourceURL, connectorID, connectorType, createdAt, updatedAt, status string
+	var deletedAt, aclJSON sql.NullString
+	err := s.db.QueryRowContext(ctx, `
+		SELECT unique_id, name, source_url, connector_id, connector_type, created_at, updated_at, status, deleted_at, acl
+		FROM documents WHERE id = ?
+	`, documentID).Scan(&uniqueID, &name, &sourceURL, &connectorID, &connectorType, &createdAt, &updatedAt, &status, &deletedAt, &aclJSON)
+	if err == sql.ErrNoRows {
+		return nil, ErrDocumentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document: %v", err)
+	}
+
+	created, _ := time.Parse(time.RFC3339, createdAt)
+	updated, _ := time.Parse(time.RFC3339, updatedAt)
+	deleted, _ := time.Parse(time.RFC3339, deletedAt.String)
+	return &types.Document{
+		UniqueID:      uniqueID,
+		Name:          name,
+		SourceURL:     sourceURL,
+		ConnectorID:   connectorID,
+		ConnectorType: connectorType,
+		CreatedAt:     created,
+		UpdatedAt:     updated,
+		Status:        types.DocumentStatus(status),
+		DeletedAt:     deleted,
+		ACL:           unmarshalACL(aclJSON.String),
+	}, nil
+}
+
+func (s *SQLiteStore) GetDocument(ctx context.Context, uniqueID string) (*types.Document, error) {
+	var name, sourceURL, connectorID, connectorType, createdAt, updatedAt, status string
+	var deletedAt, aclJSON sql.NullString
+	err := s.db.QueryRowContext(ctx, `
+		SELECT name, source_url, connector_id, connector_type, created_at, updated_at, status, deleted_at, acl
+		FROM documents WHERE unique_id = ?
+	`, uniqueID).Scan(&name, &sourceURL, &connectorID, &connectorType, &createdAt, &updatedAt, &status, &deletedAt, &aclJSON)
+	if err == sql.ErrNoRows {
+		return nil, ErrDocumentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document: %v", err)
+	}
+
+	created, _ := time.Parse(time.RFC3339, createdAt)
+	updated, _ := time.Parse(time.RFC3339, updatedAt)
+	deleted, _ := time.Parse(time.RFC3339, deletedAt.String)
+	return &types.Document{
+		UniqueID:      uniqueID,
+		Name:          name,
+		SourceURL:     sourceURL,
+		ConnectorID:   connectorID,
+		ConnectorType: connectorType,
+		CreatedAt:     created,
+		UpdatedAt:     updated,
+		Status:        types.DocumentStatus(status),
+		DeletedAt:     deleted,
+		ACL:           unmarshalACL(aclJSON.String),
+	}, nil
+}
+
+// unmarshalACL parses a document row's acl column, added after some rows
+// were already written: an empty string (pre-migration row) means no ACL,
+// same as an explicit "[]".
+func unmarshalACL(aclJSON string) []string {
+	if aclJSON == "" {
+		return nil
+	}
+	var acl []string
+	if err := json.Unmarshal([]byte(aclJSON), &acl); err != nil {
+		return nil
+	}
+	return acl
+}
+
+// getOrCreateDocumentID returns the internal row ID for doc.UniqueID,
+// inserting a new row if one doesn't already exist. created reports
+// whether a new document row was inserted.
+func (s *SQLiteStore) getOrCreateDocumentID(ctx context.Context, doc types.Document) (id string, created bool, err error) {
+	err = s.db.QueryRowContext(ctx, `SELECT id FROM documents WHERE unique_id = ?`, doc.UniqueID).Scan(&id)
+	if err == nil {
+		return id, false, nil
+	}
+	if err != sql.ErrNoRows {
+		return "", false, fmt.Errorf("failed to look up document: %v", err)
+	}
+
+	aclJSON, err := json.Marshal(doc.ACL)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to marshal document ACL: %v", err)
+	}
+
+	id = uuid.NewString()
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO documents (id, unique_id, name, source_url, connector_id, connector_type, created_at, updated_at, status, acl)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, id, doc.UniqueID, doc.Name, doc.SourceURL, doc.ConnectorID, doc.ConnectorType,
+		doc.CreatedAt.Format(time.RFC3339), doc.UpdatedAt.Format(time.RFC3339), string(types.DocumentStatusActive), string(aclJSON))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create document: %v", err)
+	}
+	return id, true, nil
+}
+
+func (s *SQLiteStore) AddVectors(ctx context.Context, items []types.AddVectorItem) (*types.AddVectorResponse, error) {
+	numDocsAdded := 0
+	for _, item := range items {
+		docID, created, err := s.getOrCreateDocumentID(ctx, item.Document)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get document ID: %v", err)
+		}
+		if created {
+			numDocsAdded++
+		}
+
+		bands := util.SimHashBands(item.Chunk.Fingerprint)
+		if _, err := s.db.ExecContext(ctx, `
+			INSERT OR REPLACE INTO chunks (hash, document_id, text, connector_id, fingerprint, band0, band1, band2, band3)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, item.Chunk.Hash, docID, item.Chunk.Text, item.Document.ConnectorID, int64(item.Chunk.Fingerprint),
+			bands[0], bands[1], bands[2], bands[3]); err != nil {
+			return nil, fmt.Errorf("failed to insert chunk: %v", err)
+		}
+		if _, err := s.db.ExecContext(ctx, `
+			INSERT INTO chunks_fts (hash, text) VALUES (?, ?)
+		`, item.Chunk.Hash, item.Chunk.Text); err != nil {
+			return nil, fmt.Errorf("failed to index chunk: %v", err)
+		}
+		if err := s.index.Add(item.Chunk.Hash, item.Vector); err != nil {
+			return nil, fmt.Errorf("failed to add chunk to vector index: %v", err)
+		}
+	}
+
+	return &types.AddVectorResponse{
+		NumChunksAdded: len(items),
+		NumDocsAdded:   numDocsAdded,
+	}, nil
+}
+
+// HybridSearch blends a dense vector search (flatVectorIndex, cosine
+// similarity) with a keyword search (chunks_fts, bm25), combining the two
+// per-candidate scores with opts.Alpha weighting toward the vector score
+// and the remainder toward the keyword score, same as Weaviate's hybrid
+// search. Fusion and FieldBoosts aren't honored: chunks_fts indexes chunk
+// text as a single field, so there's no per-field ranking method or
+// boost target to apply.
+func (s *SQLiteStore) HybridSearch(ctx context.Context, query string, vector []float32, opts types.SearchOptions) ([]*types.Chunk, error) {
+	alpha := HybridSearchAlpha
+	if opts.Alpha != 0 {
+		alpha = opts.Alpha
+	}
+	limit := MaxNumSearchResults
+	if opts.Limit > 0 {
+		limit = opts.Limit
+	}
+
+	denseMatches := s.index.Search(vector, WeaviateMaxResults)
+	denseScores := map[string]float64{}
+	for _, m := range denseMatches {
+		denseScores[m.Hash] = m.Score
+	}
+
+	keywordScores := map[string]float64{}
+	if query != "" {
+		rows, err := s.db.QueryContext(ctx, `
+			SELECT hash, bm25(chunks_fts) FROM chunks_fts WHERE chunks_fts MATCH ? ORDER BY bm25(chunks_fts) LIMIT ?
+		`, query, WeaviateMaxResults)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run keyword search: %v", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var hash string
+			var bm25 float64
+			if err := rows.Scan(&hash, &bm25); err != nil {
+				return nil, fmt.Errorf("failed to scan keyword match: %v", err)
+			}
+			// bm25() returns lower-is-better, unbounded scores; negate so
+			// higher is better and roughly comparable to cosine similarity.
+			keywordScores[hash] = -bm25
+		}
+	}
+
+	combined := map[string]float64{}
+	for hash, score := range denseScores {
+		combined[hash] += alpha * score
+	}
+	for hash, score := range keywordScores {
+		combined[hash] += (1 - alpha) * score
+	}
+
+	scored := make([]scoredHash, 0, len(combined))
+	for hash, score := range combined {
+		if score < opts.MinScore {
+			continue
+		}
+		scored = append(scored, scoredHash{hash, score})
+	}
+	sort.Slice(scored, func(i, j int) bool { return scored[i].score > scored[j].score })
+	if len(scored) > limit {
+		scored = scored[:limit]
+	}
+
+	res := make([]*types.Chunk, 0, len(scored))
+	for _, sh := range scored {
+		chunk, err := s.GetChunkByHash(ctx, sh.hash)
+		if err != nil {
+			if IsErrChunkNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to load search result chunk: %v", err)
+		}
+		if chunk.Document.Status == types.DocumentStatusTombstoned {
+			continue
+		}
+		if len(opts.ConnectorIDs) > 0 && !containsString(opts.ConnectorIDs, chunk.Document.ConnectorID) {
+			continue
+		}
+		if !inDateRange(chunk.Document.CreatedAt, opts.CreatedAfter, opts.CreatedBefore) {
+			continue
+		}
+		if !inDateRange(chunk.Document.UpdatedAt, opts.UpdatedAfter, opts.UpdatedBefore) {
+			continue
+		}
+		if opts.DocNameGlob != "" {
+			if ok, _ := path.Match(opts.DocNameGlob, chunk.Document.Name); !ok {
+				continue
+			}
+		}
+		if len(opts.RequesterIdentities) > 0 && len(chunk.Document.ACL) > 0 && !containsAny(chunk.Document.ACL, opts.RequesterIdentities) {
+			continue
+		}
+		chunk.Score = sh.score
+		chunk.DenseScore = denseScores[sh.hash]
+		chunk.KeywordScore = keywordScores[sh.hash]
+		chunk.ExplainScore = fmt.Sprintf("dense=%.4f keyword=%.4f alpha=%.2f", denseScores[sh.hash], keywordScores[sh.hash], alpha)
+		res = append(res, chunk)
+	}
+	return res, nil
+}
+
+// containsString reports whether vals contains s.
+func containsString(vals []string, s string) bool {
+	for _, v := range vals {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAny reports whether vals and candidates share at least one
+// element, used by HybridSearch to check a document's ACL against a
+// requester's identity-plus-groups set.
+func containsAny(vals []string, candidates []string) bool {
+	for _, c := range candidates {
+		if containsString(vals, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// inDateRange reports whether t falls within [after, before), treating a
+// zero after or before as an open bound on that side.
+func inDateRange(t, after, before time.Time) bool {
+	if !after.IsZero() && t.Before(after) {
+		return false
+	}
+	if !before.IsZero() && !t.Before(before) {
+		return false
+	}
+	return true
+}
+
+func (s *SQLiteStore) CreateConversation(ctx context.Context) (string, error) {
+	id := uuid.NewString()
+	now := time.Now().Format(time.RFC3339)
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO conversations (id, title, history, chunk_hashes, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, id, "", "[]", "[]", now, now)
+	if err != nil {
+		return "", fmt.Errorf("failed to create conversation: %v", err)
+	}
+	return id, nil
+}
+
+func (s *SQLiteStore) scanConversation(row *sql.Row, id string) (*types.Conversation, error) {
+	var title, historyJSON, chunkHashesJSON, createdAt, updatedAt string
+	err := row.Scan(&title, &historyJSON, &chunkHashesJSON, &createdAt, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrConversationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan conversation: %v", err)
+	}
+
+	var history []types.HistoryItem
+	if err := json.Unmarshal([]byte(historyJSON), &history); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal conversation history: %v", err)
+	}
+	var chunkHashes []string
+	if err := json.Unmarshal([]byte(chunkHashesJSON), &chunkHashes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal conversation chunk hashes: %v", err)
+	}
+	created, _ := time.Parse(time.RFC3339, createdAt)
+	updated, _ := time.Parse(time.RFC3339, updatedAt)
+
+	return &types.Conversation{
+		ID:          id,
+		Title:       title,
+		History:     history,
+		ChunkHashes: chunkHashes,
+		CreatedAt:   created,
+		UpdatedAt:   updated,
+	}, nil
+}
+
+func (s *SQLiteStore) GetConversation(ctx context.Context, conversationID string) (*types.Conversation, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT title, history, chunk_hashes, created_at, updated_at FROM conversations WHERE id = ?
+	`, conversationID)
+	return s.scanConversation(row, conversationID)
+}
+
+// ListConversations pages through conversations newest-updated first.
+// Unlike GetConversation it doesn't select history/chunk_hashes, so list
+// pages stay small regardless of how long individual conversations run;
+// callers that need the message log page through it separately via
+// GetConversationHistory.
+func (s *SQLiteStore) ListConversations(ctx context.Context, args types.PageArgs) (*types.ConversationConnection, error) {
+	first := pageSize(args.First)
+
+	query := `SELECT id, title, created_at, updated_at FROM conversations`
+	sqlArgs := []interface{}{}
+	if args.After != "" {
+		key, err := decodeCursor(args.After)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %v", err)
+		}
+		query += ` WHERE (updated_at, id) < (?, ?)`
+		sqlArgs = append(sqlArgs, key.UpdatedAt.Format(time.RFC3339), key.ID)
+	}
+	query += ` ORDER BY updated_at DESC, id DESC LIMIT ?`
+	sqlArgs = append(sqlArgs, first+1)
+
+	rows, err := s.db.QueryContext(ctx, query, sqlArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conversations: %v", err)
+	}
+	defer rows.Close()
+
+	conn := &types.ConversationConnection{Edges: []types.ConversationEdge{}}
+	for rows.Next() {
+		var id, title, createdAtStr, updatedAtStr string
+		if err := rows.Scan(&id, &title, &createdAtStr, &updatedAtStr); err != nil {
+			return nil, fmt.Errorf("failed to scan conversation: %v", err)
+		}
+		createdAt, _ := time.Parse(time.RFC3339, createdAtStr)
+		updatedAt, _ := time.Parse(time.RFC3339, updatedAtStr)
+
+		conn.Edges = append(conn.Edges, types.ConversationEdge{
+			Cursor: encodeCursor(updatedAt, id),
+			Node: &types.Conversation{
+				ID:        id,
+				Title:     title,
+				CreatedAt: createdAt,
+				UpdatedAt: updatedAt,
+			},
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list conversations: %v", err)
+	}
+
+	hasNext := len(conn.Edges) > first
+	if hasNext {
+		conn.Edges = conn.Edges[:first]
+	}
+	conn.PageInfo = types.PageInfo{
+		HasNextPage:     hasNext,
+		HasPreviousPage: args.After != "",
+	}
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.StartCursor = conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = conn.Edges[len(conn.Edges)-1].Cursor
+	}
+
+	return conn, nil
+}
+
+// GetConversationHistory pages through a single conversation's message
+// log, reusing the updated_at/id cursor shape even though the id half is
+// just an index into the in-memory history slice: history is stored as a
+// single JSON array column rather than per-message rows.
+func (s *SQLiteStore) GetConversationHistory(ctx context.Context, conversationID string, args types.PageArgs) (*types.HistoryConnection, error) {
+	conversation, err := s.GetConversation(ctx, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %v", err)
+	}
+
+	first := pageSize(args.First)
+	start := 0
+	if args.After != "" {
+		key, err := decodeCursor(args.After)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %v", err)
+		}
+		idx, err := strconv.Atoi(key.ID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %v", err)
+		}
+		start = idx + 1
+	}
+
+	conn := &types.HistoryConnection{Edges: []types.HistoryEdge{}}
+	if start >= len(conversation.History) {
+		return conn, nil
+	}
+
+	end := start + first
+	hasNext := end < len(conversation.History)
+	if !hasNext {
+		end = len(conversation.History)
+	}
+
+	for i := start; i < end; i++ {
+		conn.Edges = append(conn.Edges, types.HistoryEdge{
+			Cursor: encodeCursor(conversation.UpdatedAt, strconv.Itoa(i)),
+			Node:   conversation.History[i],
+		})
+	}
+
+	conn.PageInfo = types.PageInfo{
+		HasNextPage:     hasNext,
+		HasPreviousPage: start > 0,
+	}
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.StartCursor = conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = conn.Edges[len(conn.Edges)-1].Cursor
+	}
+
+	return conn, nil
+}
+
+// ListDocuments pages through every indexed document, newest-updated
+// first, using the same cursor shape as ListConversations.
+func (s *SQLiteStore) ListDocuments(ctx context.Context, args types.PageArgs) (*types.DocumentConnection, error) {
+	first := pageSize(args.First)
+
+	query := `SELECT id, unique_id, name, source_url, connector_id, connector_type, created_at, updated_at FROM documents`
+	sqlArgs := []interface{}{}
+	if args.After != "" {
+		key, err := decodeCursor(args.After)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %v", err)
+		}
+		query += ` WHERE (updated_at, id) < (?, ?)`
+		sqlArgs = append(sqlArgs, key.UpdatedAt.Format(time.RFC3339), key.ID)
+	}
+	query += ` ORDER BY updated_at DESC, id DESC LIMIT ?`
+	sqlArgs = append(sqlArgs, first+1)
+
+	rows, err := s.db.QueryContext(ctx, query, sqlArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %v", err)
+	}
+	defer rows.Close()
+
+	conn := &types.DocumentConnection{Edges: []types.DocumentEdge{}}
+	for rows.Next() {
+		var id, uniqueID, name, sourceURL, connectorID, connectorType, createdAtStr, updatedAtStr string
+		if err := rows.Scan(&id, &uniqueID, &name, &sourceURL, &connectorID, &connectorType, &createdAtStr, &updatedAtStr); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %v", err)
+		}
+		createdAt, _ := time.Parse(time.RFC3339, createdAtStr)
+		updatedAt, _ := time.Parse(time.RFC3339, updatedAtStr)
+
+		conn.Edges = append(conn.Edges, types.DocumentEdge{
+			Cursor: encodeCursor(updatedAt, id),
+			Node: &types.Document{
+				UniqueID:      uniqueID,
+				Name:          name,
+				SourceURL:     sourceURL,
+				ConnectorID:   connectorID,
+				ConnectorType: connectorType,
+				CreatedAt:     createdAt,
+				UpdatedAt:     updatedAt,
+			},
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list documents: %v", err)
+	}
+
+	hasNext := len(conn.Edges) > first
+	if hasNext {
+		conn.Edges = conn.Edges[:first]
+	}
+	conn.PageInfo = types.PageInfo{
+		HasNextPage:     hasNext,
+		HasPreviousPage: args.After != "",
+	}
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.StartCursor = conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = conn.Edges[len(conn.Edges)-1].Cursor
+	}
+
+	return conn, nil
+}
+
+func (s *SQLiteStore) ConversationAppend(ctx context.Context, conversationID string, items []types.HistoryItem, chunks []*types.Chunk) error {
+	conversation, err := s.GetConversation(ctx, conversationID)
+	if err != nil {
+		return fmt.Errorf("unable to get conversation: %v", err)
+	}
+
+	items = attachChunkHashes(items, chunks)
+	conversation.History = append(conversation.History, items...)
+	for _, chunk := range chunks {
+		conversation.ChunkHashes = append(conversation.ChunkHashes, chunk.Hash)
+	}
+
+	historyJSON, err := json.Marshal(conversation.History)
+	if err != nil {
+		return fmt.Errorf("failed to marshal conversation history: %v", err)
+	}
+	chunkHashesJSON, err := json.Marshal(conversation.ChunkHashes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal conversation chunk hashes: %v", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE conversations SET history = ?, chunk_hashes = ?, updated_at = ? WHERE id = ?
+	`, string(historyJSON), string(chunkHashesJSON), time.Now().Format(time.RFC3339), conversationID)
+	if err != nil {
+		return fmt.Errorf("failed to update conversation: %v", err)
+	}
+	return nil
+}
+
+// DeleteConversation removes a conversation and its history outright.
+func (s *SQLiteStore) DeleteConversation(ctx context.Context, conversationID string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM conversations WHERE id = ?`, conversationID)
+	if err != nil {
+		return fmt.Errorf("failed to delete conversation: %v", err)
+	}
+	return nil
+}
+
+// RenameConversation sets a conversation's display title.
+func (s *SQLiteStore) RenameConversation(ctx context.Context, conversationID string, title string) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE conversations SET title = ?, updated_at = ? WHERE id = ?
+	`, title, time.Now().Format(time.RFC3339), conversationID)
+	if err != nil {
+		return fmt.Errorf("failed to rename conversation: %v", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrConversationNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStore) TruncateConversationHistory(ctx context.Context, conversationID string, keep int) (*types.Conversation, error) {
+	conversation, err := s.GetConversation(ctx, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get conversation: %v", err)
+	}
+
+	conversation.History, conversation.ChunkHashes = truncateHistory(conversation.History, keep)
+
+	historyJSON, err := json.Marshal(conversation.History)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal conversation history: %v", err)
+	}
+	chunkHashesJSON, err := json.Marshal(conversation.ChunkHashes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal conversation chunk hashes: %v", err)
+	}
+
+	now := time.Now()
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE conversations SET history = ?, chunk_hashes = ?, updated_at = ? WHERE id = ?
+	`, string(historyJSON), string(chunkHashesJSON), now.Format(time.RFC3339), conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update conversation: %v", err)
+	}
+	conversation.UpdatedAt = now
+	return conversation, nil
+}
+
+func (s *SQLiteStore) BranchConversation(ctx context.Context, conversationID string, atIndex int) (string, error) {
+	conversation, err := s.GetConversation(ctx, conversationID)
+	if err != nil {
+		return "", fmt.Errorf("unable to get conversation: %v", err)
+	}
+	history, chunkHashes := truncateHistory(conversation.History, atIndex)
+
+	id := uuid.NewString()
+	now := time.Now().Format(time.RFC3339)
+	historyJSON, err := json.Marshal(history)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal conversation history: %v", err)
+	}
+	chunkHashesJSON, err := json.Marshal(chunkHashes)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal conversation chunk hashes: %v", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO conversations (id, title, history, chunk_hashes, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, id, conversation.Title, string(historyJSON), string(chunkHashesJSON), now, now)
+	if err != nil {
+		return "", fmt.Errorf("failed to create branched conversation: %v", err)
+	}
+	return id, nil
+}
+
+func (s *SQLiteStore) scanConnectorState(row *sql.Row) (*types.ConnectorState, error) {
+	state := &types.ConnectorState{}
+	var authValid, syncing, pendingDeletion int
+	var lastSync, progressJSON string
+	var deletedAt sql.NullString
+	err := row.Scan(&state.ConnectorID, &state.User, &state.ConnectorType, &authValid, &syncing,
+		&lastSync, &state.NumDocuments, &state.NumChunks, &state.NumErrors, &state.Cursor, &progressJSON,
+		&pendingDeletion, &deletedAt, &state.ResourceVersion)
+	if err == sql.ErrNoRows {
+		return nil, ErrNoStateFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan connector state: %v", err)
+	}
+	state.AuthValid = authValid != 0
+	state.Syncing = syncing != 0
+	state.LastSync, _ = time.Parse(time.RFC3339, lastSync)
+	state.PendingDeletion = pendingDeletion != 0
+	state.DeletedAt, _ = time.Parse(time.RFC3339, deletedAt.String)
+	if progressJSON != "" {
+		if err := json.Unmarshal([]byte(progressJSON), &state.Progress); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal connector progress: %v", err)
+		}
+	}
+	return state, nil
+}
+
+func (s *SQLiteStore) GetConnectorState(ctx context.Context, connectorID string) (*types.ConnectorState, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT connector_id, user, connector_type, auth_valid, syncing, last_sync, num_documents, num_chunks, num_errors, cursor, progress, pending_deletion, deleted_at, resource_version
+		FROM connector_states WHERE connector_id = ?
+	`, connectorID)
+	return s.scanConnectorState(row)
+}
+
+func (s *SQLiteStore) AllConnectorStates(ctx context.Context) ([]*types.ConnectorState, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT connector_id FROM connector_states`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list connector states: %v", err)
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan connector id: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	res := make([]*types.ConnectorState, 0, len(ids))
+	for _, id := range ids {
+		state, err := s.GetConnectorState(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get connector state %s: %v", id, err)
+		}
+		res = append(res, state)
+	}
+	return res, nil
+}
+
+// UpdateConnectorState upserts state, enforcing optimistic concurrency: an
+// update to an existing row must carry the ResourceVersion currently stored
+// for it, else ErrConflict is returned without writing anything. On success
+// state.ResourceVersion is bumped in place to the version just written.
+func (s *SQLiteStore) UpdateConnectorState(ctx context.Context, state *types.ConnectorState) error {
+	progressJSON, err := json.Marshal(state.Progress)
+	if err != nil {
+		return fmt.Errorf("failed to marshal connector progress: %v", err)
+	}
+
+	var existingVersion sql.NullString
+	err = s.db.QueryRowContext(ctx, `SELECT resource_version FROM connector_states WHERE connector_id = ?`, state.ConnectorID).Scan(&existingVersion)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to look up connector state: %v", err)
+	}
+	exists := err == nil
+	newVersion := uuid.NewString()
+
+	if !exists {
+		if state.ResourceVersion != "" {
+			return fmt.Errorf("%w: connector state not found", ErrConflict)
+		}
+		_, err = s.db.ExecContext(ctx, `
+			INSERT INTO connector_states (connector_id, user, connector_type, auth_valid, syncing, last_sync, num_documents, num_chunks, num_errors, cursor, progress, pending_deletion, deleted_at, resource_version)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, state.ConnectorID, state.User, state.ConnectorType, boolToInt(state.AuthValid), boolToInt(state.Syncing),
+			state.LastSync.Format(time.RFC3339), state.NumDocuments, state.NumChunks, state.NumErrors, state.Cursor, string(progressJSON),
+			boolToInt(state.PendingDeletion), state.DeletedAt.Format(time.RFC3339), newVersion)
+		if err != nil {
+			return fmt.Errorf("failed to insert connector state: %v", err)
+		}
+		state.ResourceVersion = newVersion
+		s.publish(connectorStateChangedEvent(state))
+		return nil
+	}
+
+	if existingVersion.String != state.ResourceVersion {
+		return ErrConflict
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE connector_states SET
+			user = ?, connector_type = ?, auth_valid = ?, syncing = ?, last_sync = ?, num_documents = ?, num_chunks = ?, num_errors = ?,
+			cursor = ?, progress = ?, pending_deletion = ?, deleted_at = ?, resource_version = ?
+		WHERE connector_id = ? AND resource_version = ?
+	`, state.User, state.ConnectorType, boolToInt(state.AuthValid), boolToInt(state.Syncing),
+		state.LastSync.Format(time.RFC3339), state.NumDocuments, state.NumChunks, state.NumErrors, state.Cursor, string(progressJSON),
+		boolToInt(state.PendingDeletion), state.DeletedAt.Format(time.RFC3339), newVersion, state.ConnectorID, state.ResourceVersion)
+	if err != nil {
+		return fmt.Errorf("failed to update connector state: %v", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %v", err)
+	}
+	if affected == 0 {
+		return ErrConflict
+	}
+	state.ResourceVersion = newVersion
+	s.publish(connectorStateChangedEvent(state))
+	return nil
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (s *SQLiteStore) SetConnectorSyncing(ctx context.Context, connectorID string, syncing bool) (*types.ConnectorState, error) {
+	var state *types.ConnectorState
+	err := MutateConnectorState(ctx, s, connectorID, func(st *types.ConnectorState) error {
+		state = st
+		if st.Syncing == syncing {
+			return ErrSyncingAlreadyExpected
+		}
+		st.Syncing = syncing
+		return nil
+	})
+	return state, err
+}
+
+func (s *SQLiteStore) DeleteDocumentChunksById(ctx context.Context, documentId string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM chunks_fts WHERE hash IN (SELECT hash FROM chunks WHERE document_id = ?)`, documentId); err != nil {
+		return fmt.Errorf("failed to delete chunks from fts index: %v", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT hash FROM chunks WHERE document_id = ?`, documentId)
+	if err != nil {
+		return fmt.Errorf("failed to list chunks for document: %v", err)
+	}
+	hashes := []string{}
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan chunk hash: %v", err)
+		}
+		hashes = append(hashes, hash)
+	}
+	rows.Close()
+
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM chunks WHERE document_id = ?`, documentId); err != nil {
+		return fmt.Errorf("failed to delete chunks: %v", err)
+	}
+	for _, hash := range hashes {
+		if err := s.index.Delete(hash); err != nil {
+			return fmt.Errorf("failed to delete chunk from vector index: %v", err)
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) DeleteDocumentById(ctx context.Context, documentId string) error {
+	if err := s.DeleteDocumentChunksById(ctx, documentId); err != nil {
+		return fmt.Errorf("unable to delete document chunks: %v", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM documents WHERE id = ?`, documentId); err != nil {
+		return fmt.Errorf("failed to delete document: %v", err)
+	}
+	return nil
+}
+
+// GetDocumentChunkHashes returns the Hash of every chunk currently stored
+// under uniqueID.
+func (s *SQLiteStore) GetDocumentChunkHashes(ctx context.Context, uniqueID string) ([]string, error) {
+	var docID string
+	err := s.db.QueryRowContext(ctx, `SELECT id FROM documents WHERE unique_id = ?`, uniqueID).Scan(&docID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up document: %v", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT hash FROM chunks WHERE document_id = ?`, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chunk hashes: %v", err)
+	}
+	defer rows.Close()
+
+	hashes := []string{}
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, fmt.Errorf("failed to scan chunk hash: %v", err)
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, rows.Err()
+}
+
+func (s *SQLiteStore) DeleteDocumentChunks(ctx context.Context, uniqueID string, connectorID string) error {
+	var docID string
+	err := s.db.QueryRowContext(ctx, `SELECT id FROM documents WHERE unique_id = ?`, uniqueID).Scan(&docID)
+	if err == sql.ErrNoRows {
+		// Document doesn't exist yet, nothing to delete
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up document: %v", err)
+	}
+
+	var numDeleted int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM chunks WHERE document_id = ?`, docID).Scan(&numDeleted); err != nil {
+		return fmt.Errorf("failed to count chunks: %v", err)
+	}
+
+	if err := s.DeleteDocumentChunksById(ctx, docID); err != nil {
+		return fmt.Errorf("unable to delete chunks: %v", err)
+	}
+
+	err = MutateConnectorState(ctx, s, connectorID, func(state *types.ConnectorState) error {
+		state.NumChunks -= numDeleted
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to update connector state: %v", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) DeleteConnector(ctx context.Context, connector types.Connector) error {
+	connectorID := connector.ID()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT id FROM documents WHERE connector_id = ?`, connectorID)
+	if err != nil {
+		return fmt.Errorf("failed to list documents for connector: %v", err)
+	}
+	docIDs := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan document id: %v", err)
+		}
+		docIDs = append(docIDs, id)
+	}
+	rows.Close()
+
+	for _, docID := range docIDs {
+		if err := s.DeleteDocumentById(ctx, docID); err != nil {
+			return fmt.Errorf("unable to delete document %s: %v", docID, err)
+		}
+	}
+
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM connector_states WHERE connector_id = ?`, connectorID); err != nil {
+		return fmt.Errorf("failed to delete connector state: %v", err)
+	}
+	s.publish(types.ConnectorEvent{Type: types.ConnectorEventDeleted, ConnectorID: connectorID})
+	return nil
+}
+
+// ReconcileConnector tombstones every document under connectorID whose
+// unique_id isn't in liveUniqueIDs, so a connector that walks its entire
+// source tree each sync can catch removals without diffing cursors itself.
+func (s *SQLiteStore) ReconcileConnector(ctx context.Context, connectorID string, liveUniqueIDs []string) error {
+	live := map[string]bool{}
+	for _, id := range liveUniqueIDs {
+		live[id] = true
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, unique_id FROM documents WHERE connector_id = ? AND status != ?
+	`, connectorID, string(types.DocumentStatusTombstoned))
+	if err != nil {
+		return fmt.Errorf("failed to list documents for connector: %v", err)
+	}
+	type docRef struct{ id, uniqueID string }
+	var toTombstone []docRef
+	for rows.Next() {
+		var ref docRef
+		if err := rows.Scan(&ref.id, &ref.uniqueID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan document: %v", err)
+		}
+		if !live[ref.uniqueID] {
+			toTombstone = append(toTombstone, ref)
+		}
+	}
+	rows.Close()
+
+	for _, ref := range toTombstone {
+		if _, err := s.db.ExecContext(ctx, `
+			UPDATE documents SET status = ?, deleted_at = ? WHERE id = ?
+		`, string(types.DocumentStatusTombstoned), time.Now().Format(time.RFC3339), ref.id); err != nil {
+			return fmt.Errorf("unable to tombstone document %s: %v", ref.id, err)
+		}
+	}
+
+	return nil
+}
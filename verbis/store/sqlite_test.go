@@ -0,0 +1,42 @@
+package store_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/verbis-ai/verbis/verbis/store"
+	"github.com/verbis-ai/verbis/verbis/store/conformance"
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+func TestSQLiteStoreConformance(t *testing.T) {
+	conformance.RunTests(t, func() types.Store {
+		st, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "verbis.db"))
+		if err != nil {
+			t.Fatalf("NewSQLiteStore: %v", err)
+		}
+		initSchema(t, st)
+		return st
+	})
+}
+
+// initSchema runs every CreateXClass call store.New's callers make once at
+// boot (see boot.go), so a freshly constructed store is ready for
+// conformance.RunTests the same way a real instance would be.
+func initSchema(t *testing.T, st types.Store) {
+	t.Helper()
+	ctx := context.Background()
+	if err := st.CreateDocumentClass(ctx, false); err != nil {
+		t.Fatalf("CreateDocumentClass: %v", err)
+	}
+	if err := st.CreateConnectorStateClass(ctx, false); err != nil {
+		t.Fatalf("CreateConnectorStateClass: %v", err)
+	}
+	if err := st.CreateChunkClass(ctx, false); err != nil {
+		t.Fatalf("CreateChunkClass: %v", err)
+	}
+	if err := st.CreateConversationClass(ctx, false); err != nil {
+		t.Fatalf("CreateConversationClass: %v", err)
+	}
+}
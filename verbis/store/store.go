@@ -8,8 +8,10 @@ import (
 	"log"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/bits-and-blooms/bloom/v3"
 	"github.com/go-openapi/strfmt"
 	"github.com/google/uuid"
 	"github.com/weaviate/weaviate-go-client/v4/weaviate"
@@ -19,6 +21,7 @@ import (
 
 	"github.com/verbis-ai/verbis/verbis/keychain"
 	"github.com/verbis-ai/verbis/verbis/types"
+	"github.com/verbis-ai/verbis/verbis/util"
 )
 
 var (
@@ -40,13 +43,59 @@ type WeaviateStore struct {
 	client              *weaviate.Client
 	ollamaURL           string
 	embeddingsModelName string
+
+	// bloomFilterState fronts ChunkHashExists and the unique_id ->
+	// document-id lookup in AddVectors with in-memory probabilistic
+	// membership tests, to avoid a GraphQL round-trip per chunk on the
+	// common "brand new content" path. See bloom.go.
+	bloomFilterState
+
+	// chunkDeleteDLQ records documents whose batched chunk delete hit
+	// object-level failures, so RetryFailedChunkDeletes can pick them back
+	// up later. Left nil (with deletes simply not queuing failures) if the
+	// on-disk queue couldn't be opened at construction time.
+	chunkDeleteDLQ *chunkDeleteDeadLetterQueue
+
+	// eventBroker publishes a ConnectorEvent for every successful
+	// UpdateConnectorState/DeleteConnector call; see Subscribe.
+	eventBroker
+
+	// stateLocks serializes UpdateConnectorState per connector ID, since
+	// Weaviate's client has no atomic compare-and-swap write; see
+	// connectorStateLocks.
+	stateLocks *connectorStateLocks
 }
 
-func NewWeaviateStore(ollamaURL, embeddingsModelName string) types.Store {
+// NewWeaviateStore returns a WeaviateStore whose bloom filters are sized
+// from estimatedItems and falsePositiveRate. The filters start out empty;
+// call RebuildBloomFilters once at boot to populate them (and correctly
+// re-size them) from what's actually in Weaviate.
+func NewWeaviateStore(ollamaURL, embeddingsModelName string, estimatedItems uint, falsePositiveRate float64) types.Store {
+	if estimatedItems == 0 {
+		estimatedItems = DefaultBloomEstimatedItems
+	}
+	if falsePositiveRate <= 0 {
+		falsePositiveRate = DefaultBloomFalsePositiveRate
+	}
+	var dlq *chunkDeleteDeadLetterQueue
+	if path, err := defaultChunkDeleteDeadLetterPath(); err != nil {
+		log.Printf("Unable to resolve chunk-delete dead-letter path, failed batch deletes won't be retried: %s", err)
+	} else if dlq, err = newChunkDeleteDeadLetterQueue(path); err != nil {
+		log.Printf("Unable to open chunk-delete dead-letter queue, failed batch deletes won't be retried: %s", err)
+	}
+
 	return &WeaviateStore{
 		client:              GetWeaviateClient(),
 		ollamaURL:           ollamaURL,
 		embeddingsModelName: embeddingsModelName,
+		bloomFilterState: bloomFilterState{
+			hashFilter:             bloom.NewWithEstimates(estimatedItems, falsePositiveRate),
+			docIDFilter:            bloom.NewWithEstimates(estimatedItems, falsePositiveRate),
+			bloomFalsePositiveRate: falsePositiveRate,
+		},
+		chunkDeleteDLQ: dlq,
+		eventBroker:    newEventBroker(),
+		stateLocks:     newConnectorStateLocks(),
 	}
 }
 
@@ -65,6 +114,13 @@ func IsErrChunkNotFound(err error) bool {
 }
 
 func (w *WeaviateStore) ChunkHashExists(ctx context.Context, hash string) (bool, error) {
+	if !w.chunkHashMaybeExists(hash) {
+		// The bloom filter says this hash has definitely never been
+		// added, so there's no point spending a GraphQL round-trip to
+		// confirm it.
+		return false, nil
+	}
+
 	chunk, err := w.GetChunkByHash(ctx, hash)
 	if err != nil {
 		return false, err
@@ -118,6 +174,101 @@ func (w *WeaviateStore) GetChunkByHash(ctx context.Context, hash string) (*types
 	return parsedChunks[0], nil
 }
 
+// FindNearDuplicateChunk probes all four SimHash bands of fingerprint
+// (see util.SimHashBands) scoped to connectorID, then filters the
+// candidates down to the first one within maxDistance Hamming distance,
+// since a band match only guarantees the two fingerprints share that
+// band, not that they're actually close.
+func (w *WeaviateStore) FindNearDuplicateChunk(ctx context.Context, connectorID string, fingerprint uint64, maxDistance int) (*types.Chunk, error) {
+	bands := util.SimHashBands(fingerprint)
+	where := filters.Where().
+		WithOperator(filters.And).
+		WithOperands([]*filters.WhereBuilder{
+			filters.Where().
+				WithPath([]string{"connector_id"}).
+				WithOperator(filters.Equal).
+				WithValueString(connectorID),
+			filters.Where().
+				WithOperator(filters.Or).
+				WithOperands([]*filters.WhereBuilder{
+					filters.Where().WithPath([]string{"band0"}).WithOperator(filters.Equal).WithValueInt(int64(bands[0])),
+					filters.Where().WithPath([]string{"band1"}).WithOperator(filters.Equal).WithValueInt(int64(bands[1])),
+					filters.Where().WithPath([]string{"band2"}).WithOperator(filters.Equal).WithValueInt(int64(bands[2])),
+					filters.Where().WithPath([]string{"band3"}).WithOperator(filters.Equal).WithValueInt(int64(bands[3])),
+				}),
+		})
+
+	resp, err := w.client.GraphQL().Get().
+		WithClassName(chunkClassName).
+		WithFields([]graphql.Field{
+			{Name: "hash"},
+			{Name: "documentid"},
+			{Name: "document_title"},
+			{Name: "chunk"},
+			{Name: "fingerprint"},
+		}...).
+		WithWhere(where).
+		Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query near-duplicate candidates: %v", err)
+	}
+	if resp.Data["Get"] == nil {
+		return nil, nil
+	}
+	get := resp.Data["Get"].(map[string]interface{})
+	candidates, ok := get[chunkClassName].([]interface{})
+	if !ok || len(candidates) == 0 {
+		return nil, nil
+	}
+
+	for _, c := range candidates {
+		props := c.(map[string]interface{})
+		candidateFPHex, _ := props["fingerprint"].(string)
+		candidateFP, err := strconv.ParseUint(candidateFPHex, 16, 64)
+		if err != nil {
+			continue
+		}
+		if util.HammingDistance(fingerprint, candidateFP) > maxDistance {
+			continue
+		}
+		return &types.Chunk{
+			Hash:        props["hash"].(string),
+			Text:        props["chunk"].(string),
+			Fingerprint: candidateFP,
+			Document: types.Document{
+				UniqueID: props["documentid"].(string),
+				Name:     props["document_title"].(string),
+			},
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// DeleteChunkByHash removes the chunk with the given content hash, if any.
+// Unlike DeleteDocumentChunksById, this targets a single chunk rather than
+// a whole document, for connectors that can address one chunk at a time
+// (e.g. a live connector applying a message edit or delete).
+func (w *WeaviateStore) DeleteChunkByHash(ctx context.Context, hash string) error {
+	resp, err := w.client.Batch().ObjectsBatchDeleter().
+		WithClassName(chunkClassName).
+		WithOutput("verbose").
+		WithWhere(filters.Where().
+			WithPath([]string{"hash"}).
+			WithOperator(filters.Equal).
+			WithValueString(hash)).
+		Do(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to delete chunk: %v", err)
+	}
+
+	if resp.Results.Successful == 0 {
+		return ErrChunkNotFound
+	}
+	log.Printf("Deleted chunk %s", hash)
+	return nil
+}
+
 var ErrDocumentNotFound = errors.New("document not found")
 
 func IsErrDocumentNotFound(err error) bool {
@@ -305,10 +456,16 @@ func (w *WeaviateStore) AddVectors(ctx context.Context, items []types.AddVectorI
 	objects := []*models.Object{}
 
 	for _, item := range items {
-		// Look if a document with the same ID exists
-		docID, err := getDocumentIDFromUniqueID(ctx, w.client, item.Document.UniqueID)
-		if err != nil {
-			return nil, fmt.Errorf("unable to get document ID: %v", err)
+		// Look if a document with the same ID exists. A negative from the
+		// bloom filter is authoritative ("definitely new"), so only a
+		// maybe-positive needs the real GraphQL lookup.
+		var docID string
+		var err error
+		if w.docIDMaybeExists(item.Document.UniqueID) {
+			docID, err = getDocumentIDFromUniqueID(ctx, w.client, item.Document.UniqueID)
+			if err != nil {
+				return nil, fmt.Errorf("unable to get document ID: %v", err)
+			}
 		}
 
 		var documentObj *models.Object
@@ -328,6 +485,8 @@ func (w *WeaviateStore) AddVectors(ctx context.Context, items []types.AddVectorI
 					"summary":       "", // To be populated when the document is summarized
 					"createdAt":     item.Document.CreatedAt.Format(time.RFC3339),
 					"updatedAt":     item.Document.UpdatedAt.Format(time.RFC3339),
+					"status":        string(types.DocumentStatusActive),
+					"acl":           item.Document.ACL,
 				},
 			}
 			objects = append(objects, documentObj)
@@ -336,14 +495,26 @@ func (w *WeaviateStore) AddVectors(ctx context.Context, items []types.AddVectorI
 		// TODO: if the provided document sourceURL is different from the stored one, update it
 
 		// Create a new chunk
+		bands := util.SimHashBands(item.Chunk.Fingerprint)
 		chunkObj := &models.Object{
 			Class: chunkClassName,
 			ID:    strfmt.UUID(uuid.NewString()),
 			Properties: map[string]interface{}{
-				"chunk":          item.Chunk.Text,
-				"hash":           item.Chunk.Hash,
-				"documentid":     docID,
-				"document_title": item.Document.Name, // Stored both here and in document, to facilitate hybrid search
+				"chunk":           item.Chunk.Text,
+				"hash":            item.Chunk.Hash,
+				"documentid":      docID,
+				"document_title":  item.Document.Name, // Stored both here and in document, to facilitate hybrid search
+				"document_status": string(types.DocumentStatusActive),
+				"connector_id":    item.Document.ConnectorID,
+				"created_at":      item.Document.CreatedAt.Format(time.RFC3339),
+				"updated_at":      item.Document.UpdatedAt.Format(time.RFC3339),
+				"fingerprint":     fmt.Sprintf("%016x", item.Chunk.Fingerprint),
+				"band0":           int64(bands[0]),
+				"band1":           int64(bands[1]),
+				"band2":           int64(bands[2]),
+				"band3":           int64(bands[3]),
+				"acl":             item.Document.ACL,
+				"acl_restricted":  len(item.Document.ACL) > 0,
 			},
 		}
 		objects = append(objects, chunkObj)
@@ -354,6 +525,11 @@ func (w *WeaviateStore) AddVectors(ctx context.Context, items []types.AddVectorI
 		return nil, fmt.Errorf("failed to batch objects: %v", err)
 	}
 
+	for _, item := range items {
+		w.addChunkHashToBloom(item.Chunk.Hash)
+		w.addDocIDToBloom(item.Document.UniqueID)
+	}
+
 	return &types.AddVectorResponse{
 		NumChunksAdded: len(items),
 		NumDocsAdded:   len(objects) - len(items), // Total set of objects created versus the known num of chunks
@@ -377,7 +553,7 @@ func getDocument(ctx context.Context, client *weaviate.Client, docid string) (ma
 }
 
 // Search for a vector in Weaviate
-func (w *WeaviateStore) HybridSearch(ctx context.Context, query string, vector []float32) ([]*types.Chunk, error) {
+func (w *WeaviateStore) HybridSearch(ctx context.Context, query string, vector []float32, opts types.SearchOptions) ([]*types.Chunk, error) {
 	fmt.Println("Query vector length: ", len(vector))
 
 	_chunk_fields := []graphql.Field{
@@ -391,19 +567,108 @@ func (w *WeaviateStore) HybridSearch(ctx context.Context, query string, vector [
 		}},
 	}
 
+	alpha := HybridSearchAlpha
+	if opts.Alpha != 0 {
+		alpha = opts.Alpha
+	}
+	fusion := graphql.RelativeScore
+	if opts.Fusion == types.FusionRanked {
+		fusion = graphql.Ranked
+	}
+	limit := MaxNumSearchResults
+	if opts.Limit > 0 {
+		limit = opts.Limit
+	}
+	properties := []string{"chunk", "document_title^2"}
+	for field, boost := range opts.FieldBoosts {
+		properties = append(properties, fmt.Sprintf("%s^%g", field, boost))
+	}
+
 	log.Printf("Searching for chunks with query: %s\n", query)
 	hybrid := w.client.GraphQL().HybridArgumentBuilder().
 		WithQuery(query).
 		WithVector(vector).
-		WithAlpha(HybridSearchAlpha).
-		WithProperties([]string{"chunk", "document_title^2"}).
-		WithFusionType(graphql.RelativeScore)
+		WithAlpha(alpha).
+		WithProperties(properties).
+		WithFusionType(fusion)
+
+	// Exclude chunks whose parent document has been tombstoned. Chunks
+	// created before document_status existed read back as "" rather than
+	// "active", so this only excludes an explicit "tombstoned" mark.
+	notTombstoned := filters.Where().
+		WithPath([]string{"document_status"}).
+		WithOperator(filters.NotEqual).
+		WithValueText(string(types.DocumentStatusTombstoned))
+
+	clauses := []*filters.WhereBuilder{notTombstoned}
+	if len(opts.ConnectorIDs) > 0 {
+		connectorFilters := make([]*filters.WhereBuilder, 0, len(opts.ConnectorIDs))
+		for _, id := range opts.ConnectorIDs {
+			connectorFilters = append(connectorFilters, filters.Where().
+				WithPath([]string{"connector_id"}).
+				WithOperator(filters.Equal).
+				WithValueText(id))
+		}
+		clauses = append(clauses, filters.Where().WithOperator(filters.Or).WithOperands(connectorFilters))
+	}
+	if !opts.CreatedAfter.IsZero() {
+		clauses = append(clauses, filters.Where().
+			WithPath([]string{"created_at"}).
+			WithOperator(filters.GreaterThanEqual).
+			WithValueDate(opts.CreatedAfter))
+	}
+	if !opts.CreatedBefore.IsZero() {
+		clauses = append(clauses, filters.Where().
+			WithPath([]string{"created_at"}).
+			WithOperator(filters.LessThan).
+			WithValueDate(opts.CreatedBefore))
+	}
+	if !opts.UpdatedAfter.IsZero() {
+		clauses = append(clauses, filters.Where().
+			WithPath([]string{"updated_at"}).
+			WithOperator(filters.GreaterThanEqual).
+			WithValueDate(opts.UpdatedAfter))
+	}
+	if !opts.UpdatedBefore.IsZero() {
+		clauses = append(clauses, filters.Where().
+			WithPath([]string{"updated_at"}).
+			WithOperator(filters.LessThan).
+			WithValueDate(opts.UpdatedBefore))
+	}
+	if opts.DocNameGlob != "" {
+		clauses = append(clauses, filters.Where().
+			WithPath([]string{"document_title"}).
+			WithOperator(filters.Like).
+			WithValueText(opts.DocNameGlob))
+	}
+	if len(opts.RequesterIdentities) > 0 {
+		// A chunk is visible if its document never got an ACL (synced
+		// before ACLs existed, or by a connector that doesn't resolve
+		// one), or if the requester's own identity/group set overlaps the
+		// document's ACL.
+		clauses = append(clauses, filters.Where().WithOperator(filters.Or).WithOperands([]*filters.WhereBuilder{
+			filters.Where().
+				WithPath([]string{"acl_restricted"}).
+				WithOperator(filters.Equal).
+				WithValueBoolean(false),
+			filters.Where().
+				WithPath([]string{"acl"}).
+				WithOperator(filters.ContainsAny).
+				WithValueText(opts.RequesterIdentities...),
+		}))
+	}
+
+	where := clauses[0]
+	if len(clauses) > 1 {
+		where = filters.Where().WithOperator(filters.And).WithOperands(clauses)
+	}
 
 	resp, err := w.client.GraphQL().
 		Get().
 		WithClassName(chunkClassName).
 		WithHybrid(hybrid).
-		WithLimit(MaxNumSearchResults).
+		WithWhere(where).
+		WithLimit(limit).
 		WithFields(_chunk_fields...).
 		Do(ctx)
 	if err != nil {
@@ -420,7 +685,20 @@ func (w *WeaviateStore) HybridSearch(ctx context.Context, query string, vector [
 		return []*types.Chunk{}, nil
 	}
 
-	return parseChunks(ctx, w.client, get[chunkClassName].([]interface{}), true)
+	chunks, err := parseChunks(ctx, w.client, get[chunkClassName].([]interface{}), true)
+	if err != nil {
+		return nil, err
+	}
+	if opts.MinScore == 0 {
+		return chunks, nil
+	}
+	filtered := chunks[:0]
+	for _, c := range chunks {
+		if c.Score >= opts.MinScore {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered, nil
 }
 
 func parseChunks(ctx context.Context, client *weaviate.Client, chunks []interface{}, withScore bool) ([]*types.Chunk, error) {
@@ -454,6 +732,15 @@ func parseChunks(ctx context.Context, client *weaviate.Client, chunks []interfac
 		createdAt, _ := time.Parse(time.RFC3339, docData["createdAt"].(string))
 		updatedAt, _ := time.Parse(time.RFC3339, docData["updatedAt"].(string))
 
+		var acl []string
+		if raw, ok := docData["acl"].([]interface{}); ok {
+			for _, v := range raw {
+				if s, ok := v.(string); ok {
+					acl = append(acl, s)
+				}
+			}
+		}
+
 		chunk := &types.Chunk{
 			Document: types.Document{
 				Name:          docData["name"].(string),
@@ -463,6 +750,7 @@ func parseChunks(ctx context.Context, client *weaviate.Client, chunks []interfac
 				Summary:       docData["summary"].(string),
 				CreatedAt:     createdAt,
 				UpdatedAt:     updatedAt,
+				ACL:           acl,
 			},
 			Text: c["chunk"].(string),
 			Hash: c["hash"].(string),
@@ -514,6 +802,18 @@ func (w *WeaviateStore) CreateDocumentClass(ctx context.Context, force bool) err
 				Name:     "summary",
 				DataType: []string{"text"},
 			},
+			{
+				Name:     "status",
+				DataType: []string{"text"},
+			},
+			{
+				Name:     "deletedAt",
+				DataType: []string{"date"},
+			},
+			{
+				Name:     "acl", // Identities (user or group emails) allowed to see this document; empty means visible to everyone
+				DataType: []string{"text[]"},
+			},
 		},
 	}
 
@@ -559,6 +859,50 @@ func (w *WeaviateStore) CreateChunkClass(ctx context.Context, force bool) error
 				Name:     "document_title", // Stored both here and in document, to facilitate hybrid search
 				DataType: []string{"text"},
 			},
+			{
+				Name:     "document_status", // Denormalized from Document.status so HybridSearch can filter tombstoned documents without a cross-class lookup per result
+				DataType: []string{"text"},
+			},
+			{
+				Name:     "connector_id", // Denormalized from Document.connectorID so FindNearDuplicateChunk can scope its lookup without a cross-class lookup per candidate
+				DataType: []string{"text"},
+			},
+			{
+				Name:     "created_at", // Denormalized from Document.createdAt so HybridSearch can date-range filter without a cross-class lookup per candidate
+				DataType: []string{"date"},
+			},
+			{
+				Name:     "updated_at", // Denormalized from Document.updatedAt, same reason as created_at
+				DataType: []string{"date"},
+			},
+			{
+				Name:     "fingerprint", // Hex-encoded 64-bit SimHash; kept as text since a float64-backed int property would lose precision on the high bits
+				DataType: []string{"text"},
+			},
+			{
+				Name:     "band0",
+				DataType: []string{"int"},
+			},
+			{
+				Name:     "band1",
+				DataType: []string{"int"},
+			},
+			{
+				Name:     "band2",
+				DataType: []string{"int"},
+			},
+			{
+				Name:     "band3",
+				DataType: []string{"int"},
+			},
+			{
+				Name:     "acl", // Denormalized from Document.ACL so HybridSearch can filter on it without a cross-class lookup per candidate
+				DataType: []string{"text[]"},
+			},
+			{
+				Name:     "acl_restricted", // true iff acl is non-empty, so HybridSearch can test "visible to everyone" without a separate IsNull/empty-array filter
+				DataType: []string{"boolean"},
+			},
 		},
 	}
 
@@ -595,27 +939,94 @@ func IsErrConversationNotFound(err error) bool {
 	return errors.Is(err, ErrConversationNotFound)
 }
 
-func (w *WeaviateStore) ListConversations(ctx context.Context) ([]*types.Conversation, error) {
-	// TODO: Exclude 'history' and 'chunks' from list response. For long living convos this can really bulk up the response. Clients should be able to retrieve these via GET on individual convos instead. Excluding requires some refactoring since parseConversation method breaks currently.
-	resp, err := w.client.GraphQL().Get().
+// attachChunkHashes stamps the hashes of chunks onto the last item of
+// items (the assistant reply ConversationAppend is about to persist), so
+// a later TruncateConversationHistory/BranchConversation can recompute
+// Conversation.ChunkHashes from only the history items that survive the
+// cut instead of the whole conversation's flat, never-shrinking list.
+func attachChunkHashes(items []types.HistoryItem, chunks []*types.Chunk) []types.HistoryItem {
+	if len(items) == 0 || len(chunks) == 0 {
+		return items
+	}
+	hashes := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		hashes[i] = chunk.Hash
+	}
+	items[len(items)-1].ChunkHashes = hashes
+	return items
+}
+
+// truncateHistory drops every item at or after keep and returns the
+// surviving items alongside the union of their own ChunkHashes, in the
+// order first seen, for TruncateConversationHistory and BranchConversation
+// to persist.
+func truncateHistory(history []types.HistoryItem, keep int) ([]types.HistoryItem, []string) {
+	if keep < 0 {
+		keep = 0
+	}
+	if keep > len(history) {
+		keep = len(history)
+	}
+	kept := append([]types.HistoryItem{}, history[:keep]...)
+
+	seen := map[string]bool{}
+	var chunkHashes []string
+	for _, item := range kept {
+		for _, hash := range item.ChunkHashes {
+			if !seen[hash] {
+				seen[hash] = true
+				chunkHashes = append(chunkHashes, hash)
+			}
+		}
+	}
+	return kept, chunkHashes
+}
+
+// afterFilter builds the Weaviate where-clause for "everything strictly
+// before key on the (dateField desc, id desc) ordering used by every
+// Connection-returning list method": dateField < key.UpdatedAt, or
+// dateField == key.UpdatedAt and id < key.ID for same-timestamp ties.
+func afterFilter(dateField string, key cursorKey) *filters.WhereBuilder {
+	return filters.Where().
+		WithOperator(filters.Or).
+		WithOperands([]*filters.WhereBuilder{
+			filters.Where().
+				WithPath([]string{dateField}).
+				WithOperator(filters.LessThan).
+				WithValueDate(key.UpdatedAt),
+			filters.Where().
+				WithOperator(filters.And).
+				WithOperands([]*filters.WhereBuilder{
+					filters.Where().
+						WithPath([]string{dateField}).
+						WithOperator(filters.Equal).
+						WithValueDate(key.UpdatedAt),
+					filters.Where().
+						WithPath([]string{"id"}).
+						WithOperator(filters.LessThan).
+						WithValueText(key.ID),
+				}),
+		})
+}
+
+// pageSize returns first if positive, else DefaultPageSize.
+func pageSize(first int) int {
+	if first <= 0 {
+		return DefaultPageSize
+	}
+	return first
+}
+
+func (w *WeaviateStore) ListConversations(ctx context.Context, args types.PageArgs) (*types.ConversationConnection, error) {
+	first := pageSize(args.First)
+
+	query := w.client.GraphQL().Get().
 		WithClassName(conversationClassName).
 		WithFields(
 			[]graphql.Field{
-				{
-					Name: "history",
-				},
-				{
-					Name: "chunks",
-				},
-				{
-					Name: "created_at",
-				},
-				{
-					Name: "updated_at",
-				},
-				{
-					Name: "title",
-				},
+				{Name: "created_at"},
+				{Name: "updated_at"},
+				{Name: "title"},
 				{
 					Name: "_additional",
 					Fields: []graphql.Field{
@@ -624,32 +1035,222 @@ func (w *WeaviateStore) ListConversations(ctx context.Context) ([]*types.Convers
 				},
 			}...,
 		).
-		Do(ctx)
+		WithSort(graphql.Sort{Path: []string{"updated_at"}, Order: graphql.Desc}).
+		WithLimit(first + 1)
+
+	if args.After != "" {
+		key, err := decodeCursor(args.After)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %v", err)
+		}
+		query = query.WithWhere(afterFilter("updated_at", key))
+	}
+
+	resp, err := query.Do(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list conversations: %v", err)
 	}
 
+	conn := &types.ConversationConnection{Edges: []types.ConversationEdge{}}
 	if resp.Data["Get"] == nil {
-		return []*types.Conversation{}, nil
+		return conn, nil
 	}
-
 	get := resp.Data["Get"].(map[string]interface{})
 	if get[conversationClassName] == nil {
-		return []*types.Conversation{}, nil
+		return conn, nil
 	}
 
 	conversations := get[conversationClassName].([]interface{})
-	resConversations := []*types.Conversation{}
+	hasNext := len(conversations) > first
+	if hasNext {
+		conversations = conversations[:first]
+	}
+
 	for _, conversation := range conversations {
 		cMap := conversation.(map[string]interface{})
-		res, err := parseConversation("", cMap)
+		id := cMap["_additional"].(map[string]interface{})["id"].(string)
+
+		createdAt, err := time.Parse(time.RFC3339, cMap["created_at"].(string))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse created_at time: %v", err)
+		}
+		updatedAt, err := time.Parse(time.RFC3339, cMap["updated_at"].(string))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse updated_at time: %v", err)
+		}
+		title, _ := cMap["title"].(string)
+
+		conn.Edges = append(conn.Edges, types.ConversationEdge{
+			Cursor: encodeCursor(updatedAt, id),
+			Node: &types.Conversation{
+				ID:        id,
+				CreatedAt: createdAt,
+				UpdatedAt: updatedAt,
+				Title:     title,
+			},
+		})
+	}
+
+	conn.PageInfo = types.PageInfo{
+		HasNextPage:     hasNext,
+		HasPreviousPage: args.After != "",
+	}
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.StartCursor = conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = conn.Edges[len(conn.Edges)-1].Cursor
+	}
+
+	return conn, nil
+}
+
+// GetConversationHistory pages through a single conversation's message log.
+// History is stored as a single Weaviate array property rather than
+// per-message rows, so unlike ListConversations/ListDocuments this pages
+// in memory over the array already fetched by GetConversation; the cursor
+// format stays consistent with the other Connection methods even though
+// the id half of it is unused here.
+func (w *WeaviateStore) GetConversationHistory(ctx context.Context, conversationID string, args types.PageArgs) (*types.HistoryConnection, error) {
+	conversation, err := w.GetConversation(ctx, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %v", err)
+	}
+
+	first := pageSize(args.First)
+	start := 0
+	if args.After != "" {
+		key, err := decodeCursor(args.After)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %v", err)
+		}
+		idx, err := strconv.Atoi(key.ID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %v", err)
+		}
+		start = idx + 1
+	}
+
+	conn := &types.HistoryConnection{Edges: []types.HistoryEdge{}}
+	if start >= len(conversation.History) {
+		return conn, nil
+	}
+
+	end := start + first
+	hasNext := end < len(conversation.History)
+	if !hasNext {
+		end = len(conversation.History)
+	}
+
+	for i := start; i < end; i++ {
+		conn.Edges = append(conn.Edges, types.HistoryEdge{
+			Cursor: encodeCursor(conversation.UpdatedAt, strconv.Itoa(i)),
+			Node:   conversation.History[i],
+		})
+	}
+
+	conn.PageInfo = types.PageInfo{
+		HasNextPage:     hasNext,
+		HasPreviousPage: start > 0,
+	}
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.StartCursor = conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = conn.Edges[len(conn.Edges)-1].Cursor
+	}
+
+	return conn, nil
+}
+
+// ListDocuments pages through every indexed document, newest-updated
+// first, using the same opaque updated_at/id cursor as ListConversations.
+func (w *WeaviateStore) ListDocuments(ctx context.Context, args types.PageArgs) (*types.DocumentConnection, error) {
+	first := pageSize(args.First)
+
+	query := w.client.GraphQL().Get().
+		WithClassName(documentClassName).
+		WithFields(
+			[]graphql.Field{
+				{Name: "unique_id"},
+				{Name: "name"},
+				{Name: "sourceURL"},
+				{Name: "connectorID"},
+				{Name: "connectorType"},
+				{Name: "createdAt"},
+				{Name: "updatedAt"},
+				{
+					Name: "_additional",
+					Fields: []graphql.Field{
+						{Name: "id"},
+					},
+				},
+			}...,
+		).
+		WithSort(graphql.Sort{Path: []string{"updatedAt"}, Order: graphql.Desc}).
+		WithLimit(first + 1)
+
+	if args.After != "" {
+		key, err := decodeCursor(args.After)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %v", err)
+		}
+		query = query.WithWhere(afterFilter("updatedAt", key))
+	}
+
+	resp, err := query.Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %v", err)
+	}
+
+	conn := &types.DocumentConnection{Edges: []types.DocumentEdge{}}
+	if resp.Data["Get"] == nil {
+		return conn, nil
+	}
+	get := resp.Data["Get"].(map[string]interface{})
+	if get[documentClassName] == nil {
+		return conn, nil
+	}
+
+	docs := get[documentClassName].([]interface{})
+	hasNext := len(docs) > first
+	if hasNext {
+		docs = docs[:first]
+	}
+
+	for _, raw := range docs {
+		dMap := raw.(map[string]interface{})
+		id := dMap["_additional"].(map[string]interface{})["id"].(string)
+
+		createdAt, err := time.Parse(time.RFC3339, dMap["createdAt"].(string))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse createdAt time: %v", err)
+		}
+		updatedAt, err := time.Parse(time.RFC3339, dMap["updatedAt"].(string))
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse conversation: %v", err)
+			return nil, fmt.Errorf("failed to parse updatedAt time: %v", err)
 		}
-		resConversations = append(resConversations, res)
+
+		conn.Edges = append(conn.Edges, types.DocumentEdge{
+			Cursor: encodeCursor(updatedAt, id),
+			Node: &types.Document{
+				UniqueID:      dMap["unique_id"].(string),
+				Name:          dMap["name"].(string),
+				SourceURL:     dMap["sourceURL"].(string),
+				ConnectorID:   dMap["connectorID"].(string),
+				ConnectorType: dMap["connectorType"].(string),
+				CreatedAt:     createdAt,
+				UpdatedAt:     updatedAt,
+			},
+		})
+	}
+
+	conn.PageInfo = types.PageInfo{
+		HasNextPage:     hasNext,
+		HasPreviousPage: args.After != "",
+	}
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.StartCursor = conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = conn.Edges[len(conn.Edges)-1].Cursor
 	}
 
-	return resConversations, nil
+	return conn, nil
 }
 
 func (w *WeaviateStore) GetConversation(ctx context.Context, conversationID string) (*types.Conversation, error) {
@@ -733,6 +1334,7 @@ func (w *WeaviateStore) ConversationAppend(ctx context.Context, conversationID s
 		return fmt.Errorf("unable to get conversation: %v", err)
 	}
 
+	items = attachChunkHashes(items, chunks)
 	// Add chunk hashes to the conversation
 	for _, chunk := range chunks {
 		conversation.ChunkHashes = append(conversation.ChunkHashes, chunk.Hash)
@@ -802,29 +1404,129 @@ func (w *WeaviateStore) CreateConversationClass(ctx context.Context, force bool)
 	return w.client.Schema().ClassCreator().WithClass(class).Do(ctx)
 }
 
-// Create a Weaviate class schema for the connector state
-func (w *WeaviateStore) CreateConnectorStateClass(ctx context.Context, force bool) error {
-	// DEBUG: attempt to delete the class, don't fail if it doesn't exist
-	if force {
-		w.client.Schema().ClassDeleter().WithClassName(stateClassName).Do(ctx)
+func (w *WeaviateStore) DeleteConversation(ctx context.Context, conversationID string) error {
+	err := w.client.Data().Deleter().
+		WithClassName(conversationClassName).
+		WithID(conversationID).
+		Do(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to delete conversation: %v", err)
 	}
+	return nil
+}
 
-	class := &models.Class{
-		Class:      stateClassName,
-		Vectorizer: "none",
-		Properties: []*models.Property{
-			{
-				Name:     "connector_id",
-				DataType: []string{"text"},
-			},
-			{
-				Name:     "type",
-				DataType: []string{"text"},
-			},
-			{
-				Name:     "user",
-				DataType: []string{"text"},
-			},
+// putConversation replaces a conversation's object wholesale, the way
+// Weaviate's Updater works: every property has to be resent even though
+// only title/history/chunks is actually changing.
+func (w *WeaviateStore) putConversation(ctx context.Context, conversation *types.Conversation) error {
+	jsonHistory := make([]string, len(conversation.History))
+	for i, item := range conversation.History {
+		historyItemJSON, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("failed to marshal history item: %v", err)
+		}
+		jsonHistory[i] = string(historyItemJSON)
+	}
+
+	return w.client.Data().Updater().
+		WithID(conversation.ID).
+		WithClassName(conversationClassName).
+		WithProperties(map[string]interface{}{
+			"history":    jsonHistory,
+			"chunks":     conversation.ChunkHashes,
+			"updated_at": time.Now().Format(time.RFC3339),
+			"created_at": conversation.CreatedAt,
+			"title":      conversation.Title,
+		}).
+		Do(ctx)
+}
+
+func (w *WeaviateStore) RenameConversation(ctx context.Context, conversationID string, title string) error {
+	conversation, err := w.GetConversation(ctx, conversationID)
+	if err != nil {
+		return fmt.Errorf("unable to get conversation: %v", err)
+	}
+	conversation.Title = title
+	if err := w.putConversation(ctx, conversation); err != nil {
+		return fmt.Errorf("failed to rename conversation: %v", err)
+	}
+	return nil
+}
+
+func (w *WeaviateStore) TruncateConversationHistory(ctx context.Context, conversationID string, keep int) (*types.Conversation, error) {
+	conversation, err := w.GetConversation(ctx, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get conversation: %v", err)
+	}
+	conversation.History, conversation.ChunkHashes = truncateHistory(conversation.History, keep)
+	if err := w.putConversation(ctx, conversation); err != nil {
+		return nil, fmt.Errorf("failed to truncate conversation: %v", err)
+	}
+	conversation.UpdatedAt = time.Now()
+	return conversation, nil
+}
+
+func (w *WeaviateStore) BranchConversation(ctx context.Context, conversationID string, atIndex int) (string, error) {
+	conversation, err := w.GetConversation(ctx, conversationID)
+	if err != nil {
+		return "", fmt.Errorf("unable to get conversation: %v", err)
+	}
+	history, chunkHashes := truncateHistory(conversation.History, atIndex)
+
+	newID := uuid.NewString()
+	branch := &types.Conversation{
+		ID:          newID,
+		Title:       conversation.Title,
+		History:     history,
+		ChunkHashes: chunkHashes,
+		CreatedAt:   time.Now(),
+	}
+	jsonHistory := make([]string, len(branch.History))
+	for i, item := range branch.History {
+		historyItemJSON, err := json.Marshal(item)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal history item: %v", err)
+		}
+		jsonHistory[i] = string(historyItemJSON)
+	}
+
+	_, err = w.client.Data().Creator().WithClassName(conversationClassName).WithID(newID).
+		WithProperties(map[string]interface{}{
+			"history":    jsonHistory,
+			"chunks":     branch.ChunkHashes,
+			"created_at": branch.CreatedAt.Format(time.RFC3339),
+			"updated_at": branch.CreatedAt.Format(time.RFC3339),
+			"title":      branch.Title,
+		}).Do(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to create branched conversation: %v", err)
+	}
+	return newID, nil
+}
+
+// Create a Weaviate class schema for the connector state
+func (w *WeaviateStore) CreateConnectorStateClass(ctx context.Context, force bool) error {
+	// DEBUG: attempt to delete the class, don't fail if it doesn't exist
+	if force {
+		w.client.Schema().ClassDeleter().WithClassName(stateClassName).Do(ctx)
+	}
+
+	class := &models.Class{
+		Class:      stateClassName,
+		Vectorizer: "none",
+		Properties: []*models.Property{
+			{
+				Name:     "connector_id",
+				DataType: []string{"text"},
+			},
+			{
+				Name:     "type",
+				DataType: []string{"text"},
+			},
+			{
+				Name:     "user",
+				DataType: []string{"text"},
+			},
 			{
 				Name:     "syncing",
 				DataType: []string{"boolean"},
@@ -849,6 +1551,10 @@ func (w *WeaviateStore) CreateConnectorStateClass(ctx context.Context, force boo
 				Name:     "numErrors",
 				DataType: []string{"int"},
 			},
+			{
+				Name:     "resourceVersion",
+				DataType: []string{"text"},
+			},
 		},
 	}
 
@@ -862,23 +1568,109 @@ func IsSyncingAlreadyExpected(err error) bool {
 	return errors.Is(err, ErrSyncingAlreadyExpected)
 }
 
-func (w *WeaviateStore) SetConnectorSyncing(ctx context.Context, connectorID string, syncing bool) (*types.ConnectorState, error) {
-	state, err := w.GetConnectorState(ctx, connectorID)
-	if err != nil {
-		return nil, fmt.Errorf("unable to get connector state: %s", err)
+// ErrConflict is returned by UpdateConnectorState when state.ResourceVersion
+// doesn't match what's currently stored: someone else wrote a newer version
+// first. Callers should re-fetch and retry, which MutateConnectorState does
+// automatically.
+var ErrConflict = errors.New("connector state resource version conflict")
+
+func IsErrConflict(err error) bool {
+	return errors.Is(err, ErrConflict)
+}
+
+// connectorStateLocks serializes UpdateConnectorState's read-then-write
+// sequence per connector ID, for backends whose client has no atomic
+// compare-and-swap write of its own (WeaviateStore's Get+Updater,
+// MilvusStore's query+Delete+Insert): without it, two goroutines that both
+// read the same ResourceVersion could both pass the check and both write,
+// the second silently clobbering the first, defeating the point of
+// ResourceVersion. This only serializes callers within this process;
+// SQLiteStore's `UPDATE ... WHERE resource_version = ?` and RedisStore's
+// WATCH/MULTI transaction remain atomic at the storage layer itself, and
+// stay correct across multiple processes too.
+type connectorStateLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func newConnectorStateLocks() *connectorStateLocks {
+	return &connectorStateLocks{locks: map[string]*sync.Mutex{}}
+}
+
+// lock acquires the per-connectorID lock, creating it on first use, and
+// returns a func that releases it.
+func (c *connectorStateLocks) lock(connectorID string) func() {
+	c.mu.Lock()
+	l, ok := c.locks[connectorID]
+	if !ok {
+		l = &sync.Mutex{}
+		c.locks[connectorID] = l
 	}
+	c.mu.Unlock()
 
-	if state.Syncing == syncing {
-		return state, ErrSyncingAlreadyExpected
+	l.Lock()
+	return l.Unlock
+}
+
+// MutateConnectorState re-fetches connectorID's state, runs mutate over it,
+// and writes it back via st.UpdateConnectorState, retrying the whole
+// read-mutate-write cycle on ErrConflict so two goroutines racing to update
+// the same connector (a sync loop and a user-triggered delete, say) each
+// see the other's write instead of clobbering it. IsStateNotFound is
+// tolerated rather than returned: mutate is handed a blank state (with only
+// ConnectorID set) so a connector's first-ever state write, same as any
+// other, goes through this read-mutate-write path instead of needing its
+// own special case.
+func MutateConnectorState(ctx context.Context, st types.Store, connectorID string, mutate func(*types.ConnectorState) error) error {
+	const maxAttempts = 5
+	var lastErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		state, err := st.GetConnectorState(ctx, connectorID)
+		if err != nil && !IsStateNotFound(err) {
+			return fmt.Errorf("unable to get connector state: %w", err)
+		}
+		if state == nil {
+			state = &types.ConnectorState{ConnectorID: connectorID}
+		}
+		if err := mutate(state); err != nil {
+			return err
+		}
+		err = st.UpdateConnectorState(ctx, state)
+		if err == nil {
+			return nil
+		}
+		if !IsErrConflict(err) {
+			return err
+		}
+		lastErr = err
 	}
+	return fmt.Errorf("exceeded %d attempts: %w", maxAttempts, lastErr)
+}
 
-	state.Syncing = syncing
-	err = w.UpdateConnectorState(ctx, state)
+func (w *WeaviateStore) SetConnectorSyncing(ctx context.Context, connectorID string, syncing bool) (*types.ConnectorState, error) {
+	var state *types.ConnectorState
+	err := MutateConnectorState(ctx, w, connectorID, func(s *types.ConnectorState) error {
+		state = s
+		if s.Syncing == syncing {
+			return ErrSyncingAlreadyExpected
+		}
+		s.Syncing = syncing
+		return nil
+	})
 	return state, err
 }
 
-// Add or update the connector state in Weaviate
+// Add or update the connector state in Weaviate. state.ResourceVersion must
+// match what's currently stored (or be empty, for a brand new connector),
+// else ErrConflict is returned without writing anything; on success
+// state.ResourceVersion is bumped in place to the version just written.
+// The read-then-write below isn't atomic at the Weaviate API level (there's
+// no conditional Updater), so it's serialized per connector ID via
+// stateLocks instead, to keep two in-process writers from both passing the
+// version check and clobbering each other.
 func (w *WeaviateStore) UpdateConnectorState(ctx context.Context, state *types.ConnectorState) error {
+	defer w.stateLocks.lock(state.ConnectorID)()
+
 	where := filters.Where().
 		WithPath([]string{"connector_id"}).
 		WithOperator(filters.Equal).
@@ -887,6 +1679,7 @@ func (w *WeaviateStore) UpdateConnectorState(ctx context.Context, state *types.C
 	resp, err := w.client.GraphQL().Get().
 		WithClassName(stateClassName).
 		WithFields([]graphql.Field{
+			{Name: "resourceVersion"},
 			{Name: "_additional", Fields: []graphql.Field{{Name: "id"}}},
 		}...).
 		WithWhere(where).
@@ -895,22 +1688,33 @@ func (w *WeaviateStore) UpdateConnectorState(ctx context.Context, state *types.C
 		return err
 	}
 
+	newVersion := uuid.NewString()
+
 	if resp.Data["Get"] == nil || len(resp.Data["Get"].(map[string]interface{})[stateClassName].([]interface{})) == 0 {
+		if state.ResourceVersion != "" {
+			return fmt.Errorf("%w: connector state not found", ErrConflict)
+		}
 		log.Printf("Creating new connector state for %s %s", state.ConnectorType, state.ConnectorID)
 		_, err := w.client.Data().Creator().WithClassName(stateClassName).WithProperties(map[string]interface{}{
-			"connector_id": state.ConnectorID,
-			"type":         state.ConnectorType,
-			"user":         state.User,
-			"syncing":      state.Syncing,
-			"auth_valid":   state.AuthValid,
-			"lastSync":     state.LastSync,
-			"numDocuments": state.NumDocuments,
-			"numChunks":    state.NumChunks,
-			"numErrors":    state.NumErrors,
+			"connector_id":    state.ConnectorID,
+			"type":            state.ConnectorType,
+			"user":            state.User,
+			"syncing":         state.Syncing,
+			"auth_valid":      state.AuthValid,
+			"lastSync":        state.LastSync,
+			"numDocuments":    state.NumDocuments,
+			"numChunks":       state.NumChunks,
+			"numErrors":       state.NumErrors,
+			"resourceVersion": newVersion,
 		}).
 			WithID(state.ConnectorID).
 			Do(ctx)
-		return err
+		if err != nil {
+			return err
+		}
+		state.ResourceVersion = newVersion
+		w.publish(connectorStateChangedEvent(state))
+		return nil
 	}
 
 	get := resp.Data["Get"].(map[string]interface{})
@@ -918,24 +1722,49 @@ func (w *WeaviateStore) UpdateConnectorState(ctx context.Context, state *types.C
 	c := states[0].(map[string]interface{})
 	addl := c["_additional"].(map[string]interface{})
 	objID := addl["id"].(string)
+	storedVersion, _ := c["resourceVersion"].(string)
+
+	if storedVersion != state.ResourceVersion {
+		return ErrConflict
+	}
 
 	err = w.client.Data().Updater(). // replaces the entire object
 						WithID(objID).
 						WithClassName(stateClassName).
 						WithProperties(map[string]interface{}{
-			"connector_id": state.ConnectorID,
-			"type":         state.ConnectorType,
-			"user":         state.User,
-			"syncing":      state.Syncing,
-			"auth_valid":   state.AuthValid,
-			"lastSync":     state.LastSync,
-			"numDocuments": state.NumDocuments,
-			"numChunks":    state.NumChunks,
-			"numErrors":    state.NumErrors,
+			"connector_id":    state.ConnectorID,
+			"type":            state.ConnectorType,
+			"user":            state.User,
+			"syncing":         state.Syncing,
+			"auth_valid":      state.AuthValid,
+			"lastSync":        state.LastSync,
+			"numDocuments":    state.NumDocuments,
+			"numChunks":       state.NumChunks,
+			"numErrors":       state.NumErrors,
+			"resourceVersion": newVersion,
 		}).
 		Do(ctx)
+	if err != nil {
+		return err
+	}
+	state.ResourceVersion = newVersion
+	w.publish(connectorStateChangedEvent(state))
+	return nil
+}
 
-	return err
+// connectorStateChangedEvent builds the ConnectorEvent published after a
+// successful UpdateConnectorState, shared by all three backends' publish
+// call sites so the event shape stays consistent.
+func connectorStateChangedEvent(state *types.ConnectorState) types.ConnectorEvent {
+	return types.ConnectorEvent{
+		Type:         types.ConnectorEventStateChanged,
+		ConnectorID:  state.ConnectorID,
+		Syncing:      state.Syncing,
+		NumDocuments: state.NumDocuments,
+		NumChunks:    state.NumChunks,
+		NumErrors:    state.NumErrors,
+		LastSync:     state.LastSync,
+	}
 }
 
 // Fetches all stored connector states from Weaviate, used to initialize the syncer after restart
@@ -953,6 +1782,7 @@ func (w *WeaviateStore) AllConnectorStates(ctx context.Context) ([]*types.Connec
 				{Name: "numDocuments"},
 				{Name: "numChunks"},
 				{Name: "numErrors"},
+				{Name: "resourceVersion"},
 			}...).
 		Do(ctx)
 	if err != nil {
@@ -980,16 +1810,18 @@ func (w *WeaviateStore) AllConnectorStates(ctx context.Context) ([]*types.Connec
 		if err != nil {
 			log.Printf("Failed to parse last sync time: %s\n", err)
 		}
+		resourceVersion, _ := c["resourceVersion"].(string)
 		res = append(res, &types.ConnectorState{
-			ConnectorID:   c["connector_id"].(string),
-			ConnectorType: c["type"].(string),
-			User:          c["user"].(string),
-			Syncing:       c["syncing"].(bool),
-			AuthValid:     c["auth_valid"].(bool),
-			LastSync:      lastSync,
-			NumDocuments:  int(c["numDocuments"].(float64)),
-			NumChunks:     int(c["numChunks"].(float64)),
-			NumErrors:     int(c["numErrors"].(float64)),
+			ConnectorID:     c["connector_id"].(string),
+			ConnectorType:   c["type"].(string),
+			User:            c["user"].(string),
+			Syncing:         c["syncing"].(bool),
+			AuthValid:       c["auth_valid"].(bool),
+			LastSync:        lastSync,
+			NumDocuments:    int(c["numDocuments"].(float64)),
+			NumChunks:       int(c["numChunks"].(float64)),
+			NumErrors:       int(c["numErrors"].(float64)),
+			ResourceVersion: resourceVersion,
 		})
 	}
 	return res, nil
@@ -1022,6 +1854,7 @@ func (w *WeaviateStore) GetConnectorState(ctx context.Context, connectorID strin
 				{Name: "numDocuments"},
 				{Name: "numChunks"},
 				{Name: "numErrors"},
+				{Name: "resourceVersion"},
 			}...).
 		WithWhere(where).
 		Do(ctx)
@@ -1052,17 +1885,19 @@ func (w *WeaviateStore) GetConnectorState(ctx context.Context, connectorID strin
 	if err != nil {
 		log.Printf("Failed to parse last sync time: %s\n", err)
 	}
+	resourceVersion, _ := c["resourceVersion"].(string)
 
 	return &types.ConnectorState{
-		ConnectorID:   c["connector_id"].(string),
-		ConnectorType: c["type"].(string),
-		User:          c["user"].(string),
-		Syncing:       c["syncing"].(bool),
-		AuthValid:     c["auth_valid"].(bool),
-		LastSync:      lastSync,
-		NumDocuments:  int(c["numDocuments"].(float64)),
-		NumChunks:     int(c["numChunks"].(float64)),
-		NumErrors:     int(c["numErrors"].(float64)),
+		ConnectorID:     c["connector_id"].(string),
+		ConnectorType:   c["type"].(string),
+		User:            c["user"].(string),
+		Syncing:         c["syncing"].(bool),
+		AuthValid:       c["auth_valid"].(bool),
+		LastSync:        lastSync,
+		NumDocuments:    int(c["numDocuments"].(float64)),
+		NumChunks:       int(c["numChunks"].(float64)),
+		NumErrors:       int(c["numErrors"].(float64)),
+		ResourceVersion: resourceVersion,
 	}, nil
 }
 
@@ -1084,23 +1919,100 @@ func (w *WeaviateStore) DeleteDocumentById(ctx context.Context, documentId strin
 	return nil
 }
 
+// DeleteProgressFunc is invoked once per batch during a multi-batch chunk
+// delete, so a caller can report progress on documents with more chunks
+// than fit in a single Weaviate batch-delete call.
+type DeleteProgressFunc func(documentID string, deleted, remaining int64)
+
+// deleteChunksBatched deletes every chunk whose documentid property equals
+// documentId, looping the batch deleter until it reports no more matches.
+// By default Weaviate's batch deleter caps at 10K objects per call
+// (reference: https://weaviate.io/developers/weaviate/manage-data/delete#delete-multiple-objects),
+// so without this loop a document with more chunks than that silently
+// under-deleted. A batch that reports object-level failures is recorded in
+// the chunk-delete dead-letter queue instead of being dropped, so
+// RetryFailedChunkDeletes can pick it back up on a later pass.
+func (w *WeaviateStore) deleteChunksBatched(ctx context.Context, documentId string, onProgress DeleteProgressFunc) (int64, error) {
+	where := filters.Where().
+		WithPath([]string{"documentid"}).
+		WithOperator(filters.Equal).
+		WithValueText(documentId)
+
+	var totalDeleted int64
+	// prevRemaining tracks remaining across iterations so a batch of
+	// chunks that persistently fail to delete (and so keep matching the
+	// documentid filter on every pass) doesn't spin this loop forever:
+	// Matches alone never reaches zero in that case, since the same
+	// failed objects are matched again next time around.
+	prevRemaining := int64(-1)
+	for {
+		select {
+		case <-ctx.Done():
+			return totalDeleted, ctx.Err()
+		default:
+		}
+
+		response, err := w.client.Batch().ObjectsBatchDeleter().
+			WithClassName(chunkClassName).
+			WithOutput("verbose").
+			WithWhere(where).
+			Do(ctx)
+		if err != nil {
+			return totalDeleted, fmt.Errorf("unable to delete chunks: %v", err)
+		}
+
+		totalDeleted += response.Results.Successful
+		remaining := response.Results.Matches - response.Results.Successful - response.Results.Failed
+		if onProgress != nil {
+			onProgress(documentId, totalDeleted, remaining)
+		}
+
+		if response.Results.Failed > 0 {
+			log.Printf("For document %s, %d chunk deletes failed; queuing for retry", documentId, response.Results.Failed)
+			if w.chunkDeleteDLQ != nil {
+				if err := w.chunkDeleteDLQ.Add(documentId, remaining); err != nil {
+					log.Printf("Failed to queue dead-lettered chunk delete for %s: %s", documentId, err)
+				}
+			}
+		}
+
+		if response.Results.Matches == 0 {
+			break
+		}
+		if prevRemaining >= 0 && remaining >= prevRemaining {
+			log.Printf("For document %s, chunk delete made no further progress (%d remaining); leaving the rest to the dead-letter queue", documentId, remaining)
+			break
+		}
+		prevRemaining = remaining
+	}
+
+	log.Printf("For Document %s, deleted %d chunks", documentId, totalDeleted)
+	return totalDeleted, nil
+}
+
 func (w *WeaviateStore) DeleteDocumentChunksById(ctx context.Context, documentId string) error {
-	// Note: By default max objects that can be deleted is 10K
-	// Reference: https://weaviate.io/developers/weaviate/manage-data/delete#delete-multiple-objects
-	response, err := w.client.Batch().ObjectsBatchDeleter().
-		WithClassName(chunkClassName).
-		WithOutput("verbose").
-		WithWhere(filters.Where().
-			WithPath([]string{"documentid"}).
-			WithOperator(filters.Equal).
-			WithValueText(documentId)).
-		Do(ctx)
+	_, err := w.deleteChunksBatched(ctx, documentId, nil)
+	return err
+}
 
+// RetryFailedChunkDeletes drains the chunk-delete dead-letter queue and
+// retries each document's batched chunk delete. An entry that fails again
+// is re-queued by deleteChunksBatched itself, so the caller doesn't need to
+// handle that case specially.
+func (w *WeaviateStore) RetryFailedChunkDeletes(ctx context.Context) (int, error) {
+	if w.chunkDeleteDLQ == nil {
+		return 0, nil
+	}
+	documentIds, err := w.chunkDeleteDLQ.Drain()
 	if err != nil {
-		return fmt.Errorf("unable to delete chunks: %v", err)
+		return 0, fmt.Errorf("failed to drain chunk-delete dead-letter queue: %w", err)
 	}
-	log.Printf("For Document %s, deleted %v chunks", documentId, response.Results.Successful)
-	return nil
+	for _, documentId := range documentIds {
+		if _, err := w.deleteChunksBatched(ctx, documentId, nil); err != nil {
+			log.Printf("Failed to retry chunk delete for document %s: %s", documentId, err)
+		}
+	}
+	return len(documentIds), nil
 }
 
 func (w *WeaviateStore) DeleteDocumentChunks(ctx context.Context, uniqueID string, connectorID string) error {
@@ -1114,39 +2026,78 @@ func (w *WeaviateStore) DeleteDocumentChunks(ctx context.Context, uniqueID strin
 		return nil
 	}
 
-	resp, err := w.client.Batch().ObjectsBatchDeleter().
-		WithClassName(chunkClassName).
-		WithOutput("verbose").
-		WithWhere(filters.Where().
-			WithPath([]string{"documentid"}).
-			WithOperator(filters.Equal).
-			WithValueText(docid)).
-		Do(ctx)
+	numDeletedChunks, err := w.deleteChunksBatched(ctx, docid, nil)
 	if err != nil {
-		return fmt.Errorf("unable to delete chunks: %v", err)
+		return err
 	}
 
-	log.Printf("%+v", resp)
+	// Reduce the chunk count for the connector. MutateConnectorState retries
+	// the get-subtract-write cycle on a ResourceVersion conflict, so a sync
+	// loop bumping NumChunks concurrently can't clobber this decrement (or
+	// vice versa).
+	err = MutateConnectorState(ctx, w, connectorID, func(state *types.ConnectorState) error {
+		state.NumChunks = state.NumChunks - int(numDeletedChunks)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to update connector state: %v", err)
+	}
 
-	numDeletedChunks := resp.Results.Successful
+	return nil
+}
 
-	// Reduce the chunk count for the connector
-	state, err := w.GetConnectorState(ctx, connectorID)
+// GetDocumentChunkHashes returns the Hash of every chunk currently stored
+// under uniqueID, paging through chunkClassName 100 at a time the same way
+// DeleteConnector pages through documentClassName.
+func (w *WeaviateStore) GetDocumentChunkHashes(ctx context.Context, uniqueID string) ([]string, error) {
+	docid, err := getDocumentIDFromUniqueID(ctx, w.client, uniqueID)
 	if err != nil {
-		return fmt.Errorf("unable to get connector state: %v", err)
+		return nil, err
 	}
-
-	if state == nil {
-		return fmt.Errorf("connector state not found, unable to update chunk count")
+	if docid == "" {
+		return nil, nil
 	}
 
-	state.NumChunks = state.NumChunks - int(numDeletedChunks)
-	err = w.UpdateConnectorState(ctx, state)
-	if err != nil {
-		return fmt.Errorf("unable to update connector state: %v", err)
+	where := filters.Where().
+		WithPath([]string{"documentid"}).
+		WithOperator(filters.Equal).
+		WithValueText(docid)
+
+	hashes := []string{}
+	limit := 100
+	offset := 0
+	for {
+		resp, err := w.client.GraphQL().Get().
+			WithClassName(chunkClassName).
+			WithFields([]graphql.Field{{Name: "hash"}}...).
+			WithWhere(where).
+			WithLimit(limit).
+			WithOffset(offset).
+			Do(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query chunk hashes: %v", err)
+		}
+		if resp.Data["Get"] == nil {
+			break
+		}
+		get := resp.Data["Get"].(map[string]interface{})
+		chunks, ok := get[chunkClassName].([]interface{})
+		if !ok || len(chunks) == 0 {
+			break
+		}
+		for _, c := range chunks {
+			props, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if hash, ok := props["hash"].(string); ok {
+				hashes = append(hashes, hash)
+			}
+		}
+		offset += len(chunks)
 	}
 
-	return nil
+	return hashes, nil
 }
 
 func (w *WeaviateStore) DeleteConnector(ctx context.Context, connector types.Connector) error {
@@ -1246,5 +2197,181 @@ func (w *WeaviateStore) DeleteConnector(ctx context.Context, connector types.Con
 	if keychainDeletionErr != nil {
 		return fmt.Errorf("failed to delete credentials for connector %s: %v", connectorID, keychainDeletionErr)
 	}
+	w.publish(types.ConnectorEvent{Type: types.ConnectorEventDeleted, ConnectorID: connectorID})
+	return nil
+}
+
+func (w *WeaviateStore) ReconcileConnector(ctx context.Context, connectorID string, liveUniqueIDs []string) error {
+	live := map[string]bool{}
+	for _, id := range liveUniqueIDs {
+		live[id] = true
+	}
+
+	where := filters.Where().
+		WithPath([]string{"connectorID"}).
+		WithOperator(filters.Equal).
+		WithValueString(connectorID)
+
+	limit := 100
+	offset := 0
+
+	for {
+		resp, err := w.client.GraphQL().Get().
+			WithClassName(documentClassName).
+			WithFields([]graphql.Field{
+				{Name: "unique_id"},
+				{Name: "status"},
+				{
+					Name:   "_additional",
+					Fields: []graphql.Field{{Name: "id"}},
+				},
+			}...).
+			WithWhere(where).
+			WithLimit(limit).
+			WithOffset(offset).
+			Do(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to list documents for connector: %v", err)
+		}
+
+		if resp.Data["Get"] == nil {
+			break
+		}
+		getData, ok := resp.Data["Get"].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("failed to assert Get data as map[string]interface{}")
+		}
+		classDocs, ok := getData[documentClassName].([]interface{})
+		if !ok || len(classDocs) == 0 {
+			break
+		}
+
+		for _, doc := range classDocs {
+			document, ok := doc.(map[string]interface{})
+			if !ok {
+				log.Println("Failed to assert document as map[string]interface{}")
+				continue
+			}
+			uniqueID, ok := document["unique_id"].(string)
+			if !ok {
+				log.Println("Failed to assert unique_id as string")
+				continue
+			}
+			if live[uniqueID] {
+				continue
+			}
+			if status, _ := document["status"].(string); status == string(types.DocumentStatusTombstoned) {
+				// Already tombstoned by a previous reconcile, nothing to do.
+				continue
+			}
+			docID, ok := document["_additional"].(map[string]interface{})["id"].(string)
+			if !ok {
+				log.Println("Failed to assert document id as string")
+				continue
+			}
+			if err := w.tombstoneDocument(ctx, docID); err != nil {
+				return fmt.Errorf("unable to tombstone document %s: %v", docID, err)
+			}
+		}
+
+		offset += limit
+	}
+
+	return nil
+}
+
+// tombstoneDocument marks a document tombstoned in place (following
+// SetDocumentSummary's replace-the-entire-object convention) and propagates
+// the new status down to its chunks so HybridSearch can filter on it
+// without a per-result cross-class lookup.
+func (w *WeaviateStore) tombstoneDocument(ctx context.Context, docID string) error {
+	docData, err := getDocument(ctx, w.client, docID)
+	if err != nil {
+		return fmt.Errorf("unable to get document: %v", err)
+	}
+	createdAt, _ := time.Parse(time.RFC3339, docData["createdAt"].(string))
+
+	properties := map[string]interface{}{
+		"unique_id":     docData["unique_id"],
+		"name":          docData["name"],
+		"sourceURL":     docData["sourceURL"],
+		"connectorID":   docData["connectorID"],
+		"connectorType": docData["connectorType"],
+		"summary":       docData["summary"],
+		"createdAt":     createdAt.Format(time.RFC3339),
+		"updatedAt":     time.Now().Format(time.RFC3339),
+		"status":        string(types.DocumentStatusTombstoned),
+		"deletedAt":     time.Now().Format(time.RFC3339),
+	}
+	err = w.client.Data().Updater(). // replaces the entire object
+						WithID(docID).
+						WithClassName(documentClassName).
+						WithProperties(properties).
+						Do(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to update document: %v", err)
+	}
+
+	return w.setChunksDocumentStatus(ctx, docID, types.DocumentStatusTombstoned)
+}
+
+// setChunksDocumentStatus propagates status onto every chunk belonging to
+// docID via Merger, a partial-update API: unlike Updater (used everywhere
+// else in this file), it only touches the field given, so it doesn't
+// require re-reading and re-sending the chunk's text and hash.
+func (w *WeaviateStore) setChunksDocumentStatus(ctx context.Context, docID string, status types.DocumentStatus) error {
+	where := filters.Where().
+		WithPath([]string{"documentid"}).
+		WithOperator(filters.Equal).
+		WithValueString(docID)
+
+	resp, err := w.client.GraphQL().Get().
+		WithClassName(chunkClassName).
+		WithFields([]graphql.Field{
+			{
+				Name:   "_additional",
+				Fields: []graphql.Field{{Name: "id"}},
+			},
+		}...).
+		WithWhere(where).
+		WithLimit(WeaviateMaxResults).
+		Do(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to list chunks for document: %v", err)
+	}
+
+	if resp.Data["Get"] == nil {
+		return nil
+	}
+	getData, ok := resp.Data["Get"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("failed to assert Get data as map[string]interface{}")
+	}
+	classChunks, ok := getData[chunkClassName].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	for _, chunk := range classChunks {
+		c, ok := chunk.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		chunkID, ok := c["_additional"].(map[string]interface{})["id"].(string)
+		if !ok {
+			continue
+		}
+		err := w.client.Data().Merger().
+			WithID(chunkID).
+			WithClassName(chunkClassName).
+			WithProperties(map[string]interface{}{
+				"document_status": string(status),
+			}).
+			Do(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to update chunk %s: %v", chunkID, err)
+		}
+	}
+
 	return nil
 }
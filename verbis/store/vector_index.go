@@ -0,0 +1,114 @@
+package store
+
+import (
+	"encoding/gob"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"sync"
+)
+
+// flatVectorIndex is a minimal brute-force vector index: every Add/Delete
+// rewrites a gob-encoded snapshot to disk, and Search scores every stored
+// vector by cosine similarity. It stands in for a real index such as FAISS
+// or hnswlib, which would require cgo bindings this build doesn't carry.
+// At the scale of a single local install (tens to low hundreds of
+// thousands of chunks) a linear scan is fast enough; SQLiteStore is the
+// only caller.
+type flatVectorIndex struct {
+	mu      sync.RWMutex
+	path    string
+	vectors map[string][]float32 // keyed by chunk hash
+}
+
+type vectorMatch struct {
+	Hash  string
+	Score float64
+}
+
+func newFlatVectorIndex(path string) (*flatVectorIndex, error) {
+	idx := &flatVectorIndex{path: path, vectors: map[string][]float32{}}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open vector index: %v", err)
+	}
+	defer f.Close()
+
+	if err := gob.NewDecoder(f).Decode(&idx.vectors); err != nil {
+		return nil, fmt.Errorf("failed to decode vector index: %v", err)
+	}
+	return idx, nil
+}
+
+// persist rewrites the index snapshot via a temp file + rename so a crash
+// mid-write can't leave a truncated index behind.
+func (idx *flatVectorIndex) persist() error {
+	tmpPath := idx.path + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create vector index snapshot: %v", err)
+	}
+	if err := gob.NewEncoder(f).Encode(idx.vectors); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to encode vector index: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, idx.path)
+}
+
+func (idx *flatVectorIndex) Add(hash string, vector []float32) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.vectors[hash] = vector
+	return idx.persist()
+}
+
+func (idx *flatVectorIndex) Delete(hash string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if _, ok := idx.vectors[hash]; !ok {
+		return nil
+	}
+	delete(idx.vectors, hash)
+	return idx.persist()
+}
+
+// Search returns up to limit matches ranked by cosine similarity, highest
+// first.
+func (idx *flatVectorIndex) Search(query []float32, limit int) []vectorMatch {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	matches := make([]vectorMatch, 0, len(idx.vectors))
+	for hash, vec := range idx.vectors {
+		matches = append(matches, vectorMatch{Hash: hash, Score: cosineSimilarity(query, vec)})
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
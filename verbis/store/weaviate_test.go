@@ -0,0 +1,39 @@
+package store_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/verbis-ai/verbis/verbis/store"
+	"github.com/verbis-ai/verbis/verbis/store/conformance"
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+// weaviateReadyURL mirrors boot.go's own readiness probe
+// (http://localhost:8088/v1/.well-known/ready), the fixed address
+// NewWeaviateStore's client always dials.
+const weaviateReadyURL = "http://localhost:8088/v1/.well-known/ready"
+
+// TestWeaviateStoreConformance runs the same conformance suite as
+// TestSQLiteStoreConformance against a live Weaviate instance, same
+// reachable-instance-or-skip pattern as TestRedisStoreConformance, since
+// this suite doesn't get to bring up a Weaviate container in every
+// environment it runs in.
+func TestWeaviateStoreConformance(t *testing.T) {
+	httpClient := &http.Client{Timeout: 500 * time.Millisecond}
+	resp, err := httpClient.Get(weaviateReadyURL)
+	if err != nil {
+		t.Skipf("no weaviate reachable at %s, skipping: %v", weaviateReadyURL, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Skipf("weaviate at %s not ready, skipping: status %d", weaviateReadyURL, resp.StatusCode)
+	}
+
+	conformance.RunTests(t, func() types.Store {
+		st := store.NewWeaviateStore("http://localhost:11434", "nomic-embed-text", 0, 0)
+		initSchema(t, st)
+		return st
+	})
+}
@@ -0,0 +1,367 @@
+// Package supervisor owns the lifecycle of a single long-running child
+// process: starting it, probing it for health, restarting it with
+// exponential backoff when it dies, and tripping a crash-loop breaker when
+// restarts aren't converging on a healthy process.
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	vlog "github.com/verbis-ai/verbis/verbis/log"
+)
+
+// State is the observable lifecycle state of a supervised process.
+type State string
+
+const (
+	StateRunning    State = "Running"
+	StateRestarting State = "Restarting"
+	StateFailed     State = "Failed"
+)
+
+const (
+	minBackoff = 500 * time.Millisecond
+	maxBackoff = 30 * time.Second
+
+	// defaultShutdownGrace is how long runOnce waits for a SIGTERM'd
+	// process to exit on its own before escalating to SIGKILL.
+	defaultShutdownGrace = 15 * time.Second
+)
+
+// HealthCheck reports whether the supervised process is healthy. It is
+// used both to gate readiness after startup and for the ongoing liveness
+// loop.
+type HealthCheck func(ctx context.Context) error
+
+// CmdSpec describes the child process to launch.
+type CmdSpec struct {
+	Name        string
+	Args        []string
+	Env         []string
+	SysProcAttr *syscall.SysProcAttr
+}
+
+// Config configures a Supervisor.
+type Config struct {
+	Name string // Used for logging and for the /api/system/status keys
+	Cmd  CmdSpec
+
+	HealthCheck HealthCheck
+
+	// ReadinessTimeout bounds how long to wait for HealthCheck to succeed
+	// after the process starts before considering the startup failed.
+	ReadinessTimeout  time.Duration
+	ReadinessInterval time.Duration
+
+	// LivenessInterval is how often HealthCheck is polled once the process
+	// is up. After LivenessFailureThreshold consecutive failures the
+	// process is killed and restarted.
+	LivenessInterval         time.Duration
+	LivenessFailureThreshold int
+
+	// CrashLoopThreshold restarts within CrashLoopWindow trip the breaker,
+	// moving the supervisor to StateFailed permanently.
+	CrashLoopThreshold int
+	CrashLoopWindow    time.Duration
+
+	// ShutdownGrace bounds how long a SIGTERM'd process is given to exit
+	// cleanly (e.g. to flush an LSM or unload a model) before runOnce
+	// escalates to SIGKILL on context cancellation.
+	ShutdownGrace time.Duration
+
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Status is a snapshot of a Supervisor's state, safe to read concurrently.
+type Status struct {
+	Name         string
+	State        State
+	LastExitCode int
+	RestartCount int
+	StartedAt    time.Time
+	Uptime       time.Duration
+}
+
+// Supervisor runs Config.Cmd, restarting it with backoff until Run's
+// context is cancelled or the crash-loop breaker opens.
+type Supervisor struct {
+	cfg Config
+	log *vlog.Logger
+
+	mu     sync.Mutex
+	status Status
+
+	// fatal receives a single error if the crash-loop breaker opens, then
+	// is closed. Callers should select on it to surface a boot-time fatal
+	// error to the caller.
+	fatal chan error
+}
+
+// New returns a Supervisor for cfg. Zero-valued timing fields fall back to
+// sane defaults.
+func New(cfg Config) *Supervisor {
+	if cfg.ReadinessInterval == 0 {
+		cfg.ReadinessInterval = time.Second
+	}
+	if cfg.LivenessInterval == 0 {
+		cfg.LivenessInterval = 10 * time.Second
+	}
+	if cfg.LivenessFailureThreshold == 0 {
+		cfg.LivenessFailureThreshold = 3
+	}
+	if cfg.CrashLoopThreshold == 0 {
+		cfg.CrashLoopThreshold = 5
+	}
+	if cfg.CrashLoopWindow == 0 {
+		cfg.CrashLoopWindow = 2 * time.Minute
+	}
+	if cfg.ShutdownGrace == 0 {
+		cfg.ShutdownGrace = defaultShutdownGrace
+	}
+	return &Supervisor{
+		cfg:   cfg,
+		log:   vlog.New(cfg.Name),
+		fatal: make(chan error, 1),
+		status: Status{
+			Name:  cfg.Name,
+			State: StateRestarting,
+		},
+	}
+}
+
+// Status returns a snapshot of the supervisor's current state.
+func (s *Supervisor) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := s.status
+	if status.State == StateRunning {
+		status.Uptime = time.Since(status.StartedAt)
+	}
+	return status
+}
+
+// Fatal returns a channel that receives an error, then is closed, if the
+// crash-loop breaker opens. Boot code should select on it alongside its
+// own readiness wait.
+func (s *Supervisor) Fatal() <-chan error {
+	return s.fatal
+}
+
+func (s *Supervisor) setState(state State) {
+	s.mu.Lock()
+	s.status.State = state
+	s.mu.Unlock()
+}
+
+// Run starts the child process and keeps it running until ctx is
+// cancelled, restarting on exit with jittered exponential backoff. It
+// returns once ctx is done or the crash-loop breaker has opened.
+func (s *Supervisor) Run(ctx context.Context) {
+	backoff := minBackoff
+	var recentFailures []time.Time
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		exitCode, ranHealthy, err := s.runOnce(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			s.log.Errorf("failed to start %s: %s", s.cfg.Cmd.Name, err)
+		} else {
+			s.log.Warnf("%s exited with code %d", s.cfg.Cmd.Name, exitCode)
+		}
+
+		s.mu.Lock()
+		s.status.LastExitCode = exitCode
+		s.mu.Unlock()
+
+		// A process that ran long enough to become healthy resets the
+		// backoff and crash-loop window; a process that never got healthy
+		// counts as an immediate crash-loop failure.
+		if ranHealthy {
+			backoff = minBackoff
+			recentFailures = nil
+		} else {
+			now := time.Now()
+			recentFailures = append(recentFailures, now)
+			cutoff := now.Add(-s.cfg.CrashLoopWindow)
+			kept := recentFailures[:0]
+			for _, t := range recentFailures {
+				if t.After(cutoff) {
+					kept = append(kept, t)
+				}
+			}
+			recentFailures = kept
+
+			if len(recentFailures) >= s.cfg.CrashLoopThreshold {
+				s.setState(StateFailed)
+				s.fatal <- fmt.Errorf(
+					"%s crash-looped: %d failures within %s",
+					s.cfg.Cmd.Name, len(recentFailures), s.cfg.CrashLoopWindow,
+				)
+				close(s.fatal)
+				return
+			}
+		}
+
+		s.mu.Lock()
+		s.status.RestartCount++
+		s.mu.Unlock()
+		s.setState(StateRestarting)
+
+		sleep := backoff + time.Duration(rand.Int63n(int64(backoff)/2+1))
+		select {
+		case <-time.After(sleep):
+		case <-ctx.Done():
+			return
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// runOnce starts the child process once and blocks until it exits (or ctx
+// is cancelled). It returns the exit code and whether the process was
+// ever observed to become healthy.
+func (s *Supervisor) runOnce(ctx context.Context) (exitCode int, ranHealthy bool, startErr error) {
+	cmd := exec.Command(s.cfg.Cmd.Name, s.cfg.Cmd.Args...)
+	cmd.Env = append(os.Environ(), s.cfg.Cmd.Env...)
+	cmd.Stdout = s.cfg.Stdout
+	cmd.Stderr = s.cfg.Stderr
+	cmd.SysProcAttr = s.cfg.Cmd.SysProcAttr
+
+	if err := cmd.Start(); err != nil {
+		return -1, false, err
+	}
+
+	s.mu.Lock()
+	s.status.StartedAt = time.Now()
+	s.mu.Unlock()
+	s.setState(StateRunning)
+
+	procDone := make(chan error, 1)
+	go func() { procDone <- cmd.Wait() }()
+
+	livenessCtx, cancelLiveness := context.WithCancel(ctx)
+	defer cancelLiveness()
+
+	healthyChan := make(chan struct{})
+	if s.cfg.HealthCheck != nil {
+		go s.waitReadyThenWatch(livenessCtx, healthyChan, procDone, cmd)
+	} else {
+		close(healthyChan)
+	}
+
+	select {
+	case <-ctx.Done():
+		s.terminate(cmd, procDone)
+		return exitCodeOf(cmd), ranHealthy, nil
+	case err := <-procDone:
+		select {
+		case <-healthyChan:
+			ranHealthy = true
+		default:
+		}
+		_ = err
+		return exitCodeOf(cmd), ranHealthy, nil
+	}
+}
+
+// terminate sends SIGTERM to cmd and waits up to cfg.ShutdownGrace for it
+// to exit on its own, escalating to SIGKILL if the grace period elapses.
+// This lets Weaviate flush its LSM and Ollama unload models cleanly
+// instead of being hard-killed on every shutdown.
+func (s *Supervisor) terminate(cmd *exec.Cmd, procDone <-chan error) {
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		s.log.Warnf("failed to send SIGTERM to %s: %s, killing instead", s.cfg.Cmd.Name, err)
+		_ = cmd.Process.Kill()
+		<-procDone
+		return
+	}
+
+	select {
+	case <-procDone:
+		return
+	case <-time.After(s.cfg.ShutdownGrace):
+		s.log.Warnf("%s did not exit within %s of SIGTERM, sending SIGKILL", s.cfg.Cmd.Name, s.cfg.ShutdownGrace)
+		_ = cmd.Process.Kill()
+		<-procDone
+	}
+}
+
+// waitReadyThenWatch waits for the readiness deadline, then switches to a
+// liveness loop that kills the process after too many consecutive health
+// check failures.
+func (s *Supervisor) waitReadyThenWatch(ctx context.Context, healthy chan<- struct{}, procDone <-chan error, cmd *exec.Cmd) {
+	deadline := time.Now().Add(s.cfg.ReadinessTimeout)
+	if s.cfg.ReadinessTimeout <= 0 {
+		deadline = time.Now().Add(30 * time.Second)
+	}
+
+	for time.Now().Before(deadline) {
+		select {
+		case <-procDone:
+			return
+		case <-ctx.Done():
+			return
+		default:
+		}
+		if err := s.cfg.HealthCheck(ctx); err == nil {
+			close(healthy)
+			break
+		}
+		select {
+		case <-time.After(s.cfg.ReadinessInterval):
+		case <-ctx.Done():
+			return
+		case <-procDone:
+			return
+		}
+	}
+
+	consecutiveFailures := 0
+	ticker := time.NewTicker(s.cfg.LivenessInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-procDone:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.cfg.HealthCheck(ctx); err != nil {
+				consecutiveFailures++
+				s.log.Warnf("health check failed (%d/%d): %s", consecutiveFailures, s.cfg.LivenessFailureThreshold, err)
+				if consecutiveFailures >= s.cfg.LivenessFailureThreshold {
+					s.log.Errorf("liveness probe failed %d times, killing process", consecutiveFailures)
+					_ = cmd.Process.Kill()
+					return
+				}
+			} else {
+				consecutiveFailures = 0
+			}
+		}
+	}
+}
+
+func exitCodeOf(cmd *exec.Cmd) int {
+	if cmd.ProcessState == nil {
+		return -1
+	}
+	return cmd.ProcessState.ExitCode()
+}
@@ -2,63 +2,261 @@ package main
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"math/rand"
+	"net"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/posthog/posthog-go"
+	"github.com/verbis-ai/verbis/verbis/analytics"
+	vlog "github.com/verbis-ai/verbis/verbis/log"
 
+	"github.com/verbis-ai/verbis/verbis/chunkpipeline"
 	"github.com/verbis-ai/verbis/verbis/connectors"
+	"github.com/verbis-ai/verbis/verbis/keychain"
+	"github.com/verbis-ai/verbis/verbis/metrics"
 	"github.com/verbis-ai/verbis/verbis/store"
+	"github.com/verbis-ai/verbis/verbis/synclog"
 	"github.com/verbis-ai/verbis/verbis/types"
 	"github.com/verbis-ai/verbis/verbis/util"
 )
 
+var syncLog = vlog.New("sync")
+
 const (
 	MinChunkSize = 10
+
+	// DefaultMaxConcurrentEmbeddings bounds how many batches chunkAdder
+	// will have in flight to EmbedBatchFromModel/store.AddVectors at once,
+	// so a connector emitting thousands of chunks doesn't serialize the
+	// whole sync behind one batch at a time.
+	DefaultMaxConcurrentEmbeddings = 4
+
+	// DefaultEmbedBatchSize is how many sanitized chunks chunkAdder
+	// accumulates before issuing a single batched EmbedBatchFromModel call
+	// followed by a single batched store.AddVectors call, amortizing
+	// per-call overhead across many chunks.
+	DefaultEmbedBatchSize = 16
+
+	// DefaultEmbedFlushInterval bounds how long chunkAdder will hold a
+	// partially-filled batch before embedding and adding it anyway, so a
+	// connector that trickles chunks in slowly doesn't stall indefinitely
+	// waiting for embedBatchSize chunks to accumulate.
+	DefaultEmbedFlushInterval = 2 * time.Second
+
+	// DefaultGCInterval is how often Run sweeps connector states for ones
+	// pending deletion.
+	DefaultGCInterval = 5 * time.Minute
+
+	// DefaultGCBatchSize caps how many pending-deletion connectors a
+	// single GC sweep processes, so one sweep can't starve the next
+	// sync-check tick.
+	DefaultGCBatchSize = 10
+
+	// DefaultGCMaxRetries bounds how many times the GC pass retries a
+	// single connector's cascading delete before giving up until the next
+	// sweep.
+	DefaultGCMaxRetries = 3
+
+	// DefaultSyncRetryBaseDelay is the base delay for chunkAdder's
+	// exponential backoff retry of transient embed/store errors; attempt N
+	// waits roughly DefaultSyncRetryBaseDelay * 2^(N-1), plus jitter.
+	DefaultSyncRetryBaseDelay = 500 * time.Millisecond
+
+	// DefaultSyncRetryMaxAttempts bounds how many times chunkAdder retries
+	// a single embed/store call before giving up on that chunk/batch.
+	DefaultSyncRetryMaxAttempts = 4
+
+	// DefaultSyncRetryJitter is the fraction of each backoff delay added
+	// back on as random jitter, so retries from many concurrent batches
+	// don't all land on the backend at once.
+	DefaultSyncRetryJitter = 0.2
+
+	// DefaultSimHashShingleWidth is the number of words per shingle
+	// chunkAdder's near-duplicate SimHash is computed over.
+	DefaultSimHashShingleWidth = 4
+
+	// DefaultSimHashMaxDistance is the maximum Hamming distance between
+	// two chunks' SimHash fingerprints for chunkAdder to treat them as
+	// near-duplicates and skip re-embedding.
+	DefaultSimHashMaxDistance = 3
 )
 
 type Syncer struct {
-	connectors        map[string]types.Connector
+	// mu guards connectors and inFlight. Any HTTP handler can call
+	// AddConnector/DeleteConnector/GetConnector while Run's ticker is
+	// concurrently calling SyncNow, so every access to these maps has to
+	// take mu rather than relying on the single-goroutine assumption the
+	// rest of the package makes.
+	mu         sync.RWMutex
+	connectors map[string]types.Connector
+	// inFlight holds a channel per connector currently being synced by
+	// maybeSyncConnector's goroutine, closed when that goroutine returns.
+	// DeleteConnector waits on it after cancelling, so a connector's state
+	// is never removed out from under a write still in progress.
+	inFlight          map[string]chan struct{}
 	syncCheckPeriod   time.Duration
 	staleThreshold    time.Duration
-	posthogClient     posthog.Client
+	analytics         analytics.Sink
 	posthogDistinctID string
 	credentials       types.BuildCredentials
 	version           string
+	store             types.Store
+
+	// maxConcurrentEmbeddings, embedBatchSize, and embedFlushInterval
+	// configure the concurrency and batching of chunkAdder's embedding
+	// stage.
+	maxConcurrentEmbeddings int
+	embedBatchSize          int
+	embedFlushInterval      time.Duration
+
+	// simhashShingleWidth and simhashMaxDistance configure chunkAdder's
+	// near-duplicate suppression: the shingle width its SimHash fingerprint
+	// is computed over, and the max Hamming distance for two fingerprints
+	// to count as the same content.
+	simhashShingleWidth int
+	simhashMaxDistance  int
+
+	// gcInterval, gcBatchSize, and gcMaxRetries configure Run's periodic
+	// sweep of pending-deletion connectors.
+	gcInterval   time.Duration
+	gcBatchSize  int
+	gcMaxRetries int
+
+	// retryBaseDelay, retryMaxAttempts, and retryJitter configure
+	// chunkAdder's exponential backoff retry of transient embed/store
+	// errors.
+	retryBaseDelay   time.Duration
+	retryMaxAttempts int
+	retryJitter      float64
+
+	// faultInjector, if set, lets tests force a percentage of chunkAdder's
+	// embed/store calls to fail with a chosen error class, so the retry
+	// paths above can be exercised deterministically. Left nil in
+	// production.
+	faultInjector FaultInjector
+
+	// recLog records a machine-readable recfile audit trail of sync
+	// events alongside the human log. Best-effort: nil (a safe no-op) if
+	// it couldn't be opened.
+	recLog *synclog.Sink
+
+	// ioStats tracks cumulative per-connector bytes read/embedded/stored
+	// and chunks added, fed by chunkAdder and surfaced both on
+	// /metrics and as extra fields on GetConnectorStates' results.
+	ioStats *ioStatsRegistry
+
+	// pipelineStats tracks cumulative per-connector chunk-pipeline
+	// outcomes (rejected/redacted/deduped), fed by each connectorSync's
+	// chunkpipeline.Run and surfaced as extra fields on
+	// GetConnectorStates' results, same pattern as ioStats.
+	pipelineStats *pipelineStatsRegistry
+
+	// syncWG tracks in-flight connectorSync goroutines, so Drain can wait
+	// for the current batch to finish and checkpoint before returning.
+	syncWG sync.WaitGroup
+
+	// rootCtx is the long-lived boot context, used to drain a
+	// types.LiveConnector's event feed for the lifetime of the process
+	// rather than for a single sync tick. Set by Init.
+	rootCtx context.Context
+
+	// locker, if set, guards maybeSyncConnector with a cross-process
+	// exclusive lock keyed by connector ID, so two verbis replicas never
+	// both win SetConnectorSyncing's CAS for the same connector at once.
+	// SetConnectorSyncing remains the persisted durability record of
+	// syncing status (what the UI reads), but with locker set it's no
+	// longer the sole source of mutual exclusion. nil (the default) skips
+	// locking entirely, matching single-process deployments that don't
+	// run a shared lock backend.
+	locker connectors.Locker
 }
 
-func NewSyncer(posthogClient posthog.Client, posthogDistinctID string, creds types.BuildCredentials, version string) *Syncer {
+// SetLocker installs the cross-process lock maybeSyncConnector acquires
+// around each connector's sync. Called by boot once a connectors.Locker
+// backend is configured; left unset, Syncer falls back to relying solely
+// on SetConnectorSyncing's CAS, same as before this existed.
+func (s *Syncer) SetLocker(l connectors.Locker) {
+	s.locker = l
+}
+
+func NewSyncer(sink analytics.Sink, posthogDistinctID string, creds types.BuildCredentials, version string, st types.Store) *Syncer {
+	recLog, err := openDefaultSyncLog()
+	if err != nil {
+		syncLog.Errorf("Failed to open sync log, proceeding without one: %s", err)
+	}
+
 	return &Syncer{
-		connectors:        map[string]types.Connector{},
-		syncCheckPeriod:   1 * time.Minute,
-		staleThreshold:    1 * time.Minute,
-		posthogClient:     posthogClient,
-		posthogDistinctID: posthogDistinctID,
-		credentials:       creds,
-		version:           version,
+		recLog:                  recLog,
+		ioStats:                 newIOStatsRegistry(),
+		pipelineStats:           newPipelineStatsRegistry(),
+		connectors:              map[string]types.Connector{},
+		inFlight:                map[string]chan struct{}{},
+		syncCheckPeriod:         1 * time.Minute,
+		staleThreshold:          1 * time.Minute,
+		analytics:               sink,
+		posthogDistinctID:       posthogDistinctID,
+		credentials:             creds,
+		version:                 version,
+		store:                   st,
+		maxConcurrentEmbeddings: DefaultMaxConcurrentEmbeddings,
+		embedBatchSize:          DefaultEmbedBatchSize,
+		embedFlushInterval:      DefaultEmbedFlushInterval,
+		simhashShingleWidth:     DefaultSimHashShingleWidth,
+		simhashMaxDistance:      DefaultSimHashMaxDistance,
+		retryBaseDelay:          DefaultSyncRetryBaseDelay,
+		retryMaxAttempts:        DefaultSyncRetryMaxAttempts,
+		retryJitter:             DefaultSyncRetryJitter,
+		gcInterval:              DefaultGCInterval,
+		gcBatchSize:             DefaultGCBatchSize,
+		gcMaxRetries:            DefaultGCMaxRetries,
 	}
 }
 
+// openDefaultSyncLog opens the recfile audit trail at synclog.DefaultPath,
+// mirroring how the chunk-delete dead-letter queue is opened: best-effort,
+// with the caller logging and carrying on without one on failure.
+func openDefaultSyncLog() (*synclog.Sink, error) {
+	path, err := synclog.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return synclog.Open(path)
+}
+
 func (s *Syncer) Init(ctx context.Context) error {
+	s.mu.Lock()
 	s.connectors = map[string]types.Connector{}
+	s.inFlight = map[string]chan struct{}{}
+	s.mu.Unlock()
+	s.rootCtx = ctx
+
+	keychain.DefaultManager().SetInvalidGrantHandler(s.handleInvalidGrant)
+	go keychain.DefaultManager().Run(ctx)
 
-	states, err := store.AllConnectorStates(ctx, store.GetWeaviateClient())
+	chunkpipeline.RegisterDefaults()
+
+	states, err := s.store.AllConnectorStates(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get connector states: %s", err)
 	}
 	count := 0
 	for _, state := range states {
-		constructor, ok := connectors.AllConnectors[state.ConnectorType]
+		if state.PendingDeletion {
+			// Left pending from a previous run that didn't live long
+			// enough to see it through GC; Run's GC sweep will pick it
+			// back up without the syncer ever re-adding it live.
+			continue
+		}
+		constructor, ok := types.ConnectorFactory(types.ConnectorType(state.ConnectorType))
 		if !ok {
 			return fmt.Errorf("unknown connector type %s", state.ConnectorType)
 		}
-		c := constructor(s.credentials)
+		c := constructor(s.credentials, s.store)
 		err = c.Init(ctx, state.ConnectorID)
 		if err != nil {
 			return fmt.Errorf("failed to init connector %s: %s", state.ConnectorID, err)
@@ -70,39 +268,219 @@ func (s *Syncer) Init(ctx context.Context) error {
 		}
 	}
 
-	log.Printf("Syncer initialized with %d connectors from stored states", count)
+	syncLog.Infof("Syncer initialized with %d connectors from stored states", count)
 	return nil
 }
 
+// handleInvalidGrant is keychain.DefaultManager's InvalidGrantHandler: once
+// a connector's refresh token is permanently rejected, there's no point
+// retrying it in the background, so this flips AuthValid off (which also
+// publishes a ConnectorEvent via UpdateConnectorState) so the UI can prompt
+// the user to re-auth instead of syncs silently failing every tick.
+func (s *Syncer) handleInvalidGrant(ctx context.Context, connectorID string, connectorType types.ConnectorType) {
+	err := store.MutateConnectorState(ctx, s.store, connectorID, func(state *types.ConnectorState) error {
+		state.AuthValid = false
+		state.AuthState = string(keychain.TokenStateInvalid)
+		return nil
+	})
+	if err != nil {
+		syncLog.Errorf("failed to flip AuthValid for connector %s after invalid_grant: %s", connectorID, err)
+	}
+}
+
 func (s *Syncer) AddConnector(c types.Connector) error {
+	s.mu.Lock()
 	_, ok := s.connectors[c.ID()]
 	if !ok {
 		s.connectors[c.ID()] = c
 	}
+	s.mu.Unlock()
+
+	if live, ok := c.(types.LiveConnector); ok {
+		s.startLiveSync(live)
+	}
 	return nil
 }
 
+// startLiveSync continuously drains a LiveConnector's event feed and
+// routes each result through the same chunk-add/state-update pipeline as
+// a periodic sync, so edits land in Weaviate without waiting for the
+// next sync tick. It runs for the lifetime of the connector; the
+// connector's own Cancel stops the feed, which closes LiveUpdates and
+// lets this goroutine exit.
+func (s *Syncer) startLiveSync(c types.LiveConnector) {
+	ctx := s.rootCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	pipelineChan := make(chan types.ChunkSyncResult)
+	pipelineStats := &chunkpipeline.Stats{}
+
+	chunkAddResChan := make(chan chunkAddResult)
+	doneChan := make(chan struct{})
+
+	go chunkpipeline.Run(ctx, c.LiveUpdates(), pipelineChan, chunkpipeline.For(c.Type()), pipelineStats, c.ID(), string(c.Type()), s.recLog)
+	go chunkAdder(ctx, s.store, pipelineChan, chunkAddResChan, s.maxConcurrentEmbeddings, s.embedBatchSize, s.embedFlushInterval, s.simhashShingleWidth, s.simhashMaxDistance, s.retryConfig(), s.recLog, s.ioStats, c.ID(), string(c.Type()))
+	go stateUpdater(ctx, c, chunkAddResChan, doneChan, s.recLog)
+	go func() {
+		// Only flushed once the connector's live feed is torn down, since
+		// that's the only point chunkpipeline.Run for this feed stops
+		// accumulating; unlike a periodic sync, a live feed has no other
+		// natural checkpoint to flush at.
+		<-doneChan
+		rejected, redacted, deduped := pipelineStats.Snapshot()
+		s.pipelineStats.add(c.ID(), rejected, redacted, deduped)
+	}()
+}
+
+// retryConfig snapshots the Syncer's retry/fault-injection tunables for
+// chunkAdder, which is a free function so it can stay testable without a
+// full Syncer.
+func (s *Syncer) retryConfig() syncRetryConfig {
+	return syncRetryConfig{
+		baseDelay:   s.retryBaseDelay,
+		maxAttempts: s.retryMaxAttempts,
+		jitter:      s.retryJitter,
+		injector:    s.faultInjector,
+	}
+}
+
 func (s *Syncer) GetConnector(id string) types.Connector {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.connectors[id]
 }
 
+// DeleteConnector marks connectorID pending deletion and cancels its
+// in-flight sync context, but doesn't delete its documents/chunks inline:
+// that cascading delete can partially fail over paged queries, so it's
+// left to Run's periodic GC sweep, which retries it with backoff outside
+// of any request's timeout. If a sync for connectorID is in flight, it
+// waits for that goroutine to observe the cancel and return before
+// removing the map entry, so the goroutine's last state write can't race
+// with (or be lost to) the delete.
 func (s *Syncer) DeleteConnector(ctx context.Context, connectorID string) error {
+	s.mu.RLock()
 	connector, ok := s.connectors[connectorID]
+	done := s.inFlight[connectorID]
+	s.mu.RUnlock()
 	if !ok {
 		return fmt.Errorf("connector %s not found", connectorID)
 	}
-	connector.Cancel()
-	err := store.DeleteConnector(ctx, connector)
+	connector.Cancel(ctx)
+
+	if done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for connector %s's in-flight sync to stop: %w", connectorID, ctx.Err())
+		}
+	}
+
+	err := store.MutateConnectorState(ctx, s.store, connectorID, func(state *types.ConnectorState) error {
+		state.PendingDeletion = true
+		state.DeletedAt = time.Now()
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to delete connector %s: %s", connectorID, err)
+		return fmt.Errorf("failed to mark connector %s pending deletion: %s", connectorID, err)
 	}
+
+	s.mu.Lock()
 	delete(s.connectors, connectorID)
+	s.mu.Unlock()
 	return nil
 }
 
+// gcPendingConnectors snapshots the connector states currently pending
+// deletion, then processes each one outside that snapshot so a slow or
+// failing delete doesn't hold up observing newly-marked connectors on the
+// next sweep.
+func (s *Syncer) gcPendingConnectors(ctx context.Context) {
+	states, err := s.store.AllConnectorStates(ctx)
+	if err != nil {
+		syncLog.Errorf("GC: failed to list connector states: %s", err)
+		return
+	}
+
+	pending := []*types.ConnectorState{}
+	for _, state := range states {
+		if state.PendingDeletion {
+			pending = append(pending, state)
+		}
+	}
+	if len(pending) > s.gcBatchSize {
+		pending = pending[:s.gcBatchSize]
+	}
+
+	for _, state := range pending {
+		start := time.Now()
+		err := s.gcDeleteConnectorWithRetry(ctx, state)
+		metrics.GCDuration.Observe(time.Since(start).Seconds())
+		if err != nil {
+			syncLog.Errorf("GC: failed to delete connector %s: %s", state.ConnectorID, err)
+			continue
+		}
+		metrics.GCDocumentsDeleted.Add(float64(state.NumDocuments))
+		metrics.GCChunksDeleted.Add(float64(state.NumChunks))
+		s.ioStats.delete(state.ConnectorID)
+		s.pipelineStats.delete(state.ConnectorID)
+	}
+}
+
+// gcDeleteConnectorWithRetry re-inits a connector purely to get back its
+// ID()/Type() (needed by store.DeleteConnector and the keychain cleanup it
+// does internally) and retries the cascading delete with exponential
+// backoff, the same pattern downloadRangeWithRetry uses for transient
+// Drive download failures.
+func (s *Syncer) gcDeleteConnectorWithRetry(ctx context.Context, state *types.ConnectorState) error {
+	constructor, ok := types.ConnectorFactory(types.ConnectorType(state.ConnectorType))
+	if !ok {
+		return fmt.Errorf("unknown connector type %s", state.ConnectorType)
+	}
+	c := constructor(s.credentials, s.store)
+	if err := c.Init(ctx, state.ConnectorID); err != nil {
+		return fmt.Errorf("failed to init connector for GC: %s", err)
+	}
+	// Init can start real background work for some connector types (Slack's
+	// RTM goroutine, the filesystem connector's fsnotify watcher), even
+	// though all this GC path needs from c is ID()/Type() for
+	// store.DeleteConnector. Cancel releases that work once we're done with
+	// c, instead of leaking it on every GC pass over a pending connector.
+	defer c.Cancel(ctx)
+
+	var lastErr error
+	for attempt := 0; attempt < s.gcMaxRetries; attempt++ {
+		if attempt > 0 {
+			backoff := time.Duration(1<<uint(attempt-1)) * time.Second
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			syncLog.Infof("GC: retrying delete of connector %s (attempt %d/%d)", state.ConnectorID, attempt+1, s.gcMaxRetries)
+		}
+		if err := s.store.DeleteConnector(ctx, c); err == nil {
+			keychain.DefaultManager().Forget(state.ConnectorID)
+			return nil
+		} else {
+			lastErr = err
+		}
+	}
+	return fmt.Errorf("exceeded %d retries: %v", s.gcMaxRetries, lastErr)
+}
+
 func (s *Syncer) GetConnectorStates(ctx context.Context, fetch_all bool) ([]*types.ConnectorState, error) {
-	states := []*types.ConnectorState{}
+	s.mu.RLock()
+	snapshot := make([]types.Connector, 0, len(s.connectors))
 	for _, c := range s.connectors {
+		snapshot = append(snapshot, c)
+	}
+	s.mu.RUnlock()
+
+	states := []*types.ConnectorState{}
+	for _, c := range snapshot {
 		state, err := c.Status(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get state for %s: %s", c.ID(), err)
@@ -110,6 +488,16 @@ func (s *Syncer) GetConnectorStates(ctx context.Context, fetch_all bool) ([]*typ
 
 		// Fetch all if explicitly requested, else only ones with AuthValid
 		if fetch_all || state.AuthValid {
+			io := s.ioStats.get(c.ID())
+			state.BytesRead = state.Progress.BytesDownloaded
+			state.BytesEmbedded = io.BytesEmbedded
+			state.BytesStored = io.BytesStored
+			state.ChunksAdded = io.ChunksAdded
+
+			pipeline := s.pipelineStats.get(c.ID())
+			state.ChunksRejected = pipeline.ChunksRejected
+			state.ChunksRedacted = pipeline.ChunksRedacted
+			state.ChunksDeduped = pipeline.ChunksDeduped
 			states = append(states, state)
 		}
 	}
@@ -128,25 +516,162 @@ func (s *Syncer) GetConnectorStates(ctx context.Context, fetch_all bool) ([]*typ
 // On launch, and after every sync_period, find all connectors that are not
 // actively syncing
 func (s *Syncer) Run(ctx context.Context) error {
-	defer log.Printf("Syncer has stopped")
+	defer syncLog.Infof("Syncer has stopped")
+	gcTicker := time.NewTicker(s.gcInterval)
+	defer gcTicker.Stop()
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
 		case <-time.After(s.syncCheckPeriod):
-			// TODO clean stale connectors
 			err := s.SyncNow(ctx)
 			if err != nil {
-				log.Printf("Failed to sync: %s\n", err)
+				syncLog.Errorf("Failed to sync: %s", err)
 			}
+		case <-gcTicker.C:
+			s.gcPendingConnectors(ctx)
+			s.retryFailedChunkDeletes(ctx)
 		}
 	}
 }
 
+// chunkDeleteRetrier is implemented by store backends (currently only
+// WeaviateStore) whose batched chunk deletes can dead-letter individual
+// documents on object-level failure. Checked with a type assertion rather
+// than added to types.Store so backends without this failure mode don't
+// need a no-op implementation.
+type chunkDeleteRetrier interface {
+	RetryFailedChunkDeletes(ctx context.Context) (int, error)
+}
+
+func (s *Syncer) retryFailedChunkDeletes(ctx context.Context) {
+	retrier, ok := s.store.(chunkDeleteRetrier)
+	if !ok {
+		return
+	}
+	n, err := retrier.RetryFailedChunkDeletes(ctx)
+	if err != nil {
+		syncLog.Errorf("GC: failed to retry dead-lettered chunk deletes: %s", err)
+		return
+	}
+	if n > 0 {
+		syncLog.Infof("GC: retried %d dead-lettered chunk deletes", n)
+	}
+}
+
 func hash(text string) string {
-	h := sha256.New()
-	h.Write([]byte(text))
-	return base64.URLEncoding.EncodeToString(h.Sum(nil))
+	return util.HashText(text)
+}
+
+// FaultInjector lets tests force a percentage of chunkAdder's embed/store
+// calls to fail with a chosen error class, so the retry/backoff paths in
+// retryWithBackoff can be exercised deterministically instead of depending
+// on a real Ollama restart or Weaviate outage.
+type FaultInjector interface {
+	// MaybeFail is called immediately before op ("chunk_hash_exists",
+	// "embed", "add_vectors") runs. A non-nil return is used in place of
+	// actually making the call.
+	MaybeFail(op string) error
+}
+
+// RandomFaultInjector is a FaultInjector that fails a given op with
+// probability Rate, returning Err. It's the default injector used in
+// tests that need to exercise retryWithBackoff's behavior for a
+// particular error class (retryable vs. permanent).
+type RandomFaultInjector struct {
+	Op   string
+	Rate float64
+	Err  error
+}
+
+func (f *RandomFaultInjector) MaybeFail(op string) error {
+	if op != f.Op || f.Rate <= 0 {
+		return nil
+	}
+	if rand.Float64() < f.Rate {
+		return f.Err
+	}
+	return nil
+}
+
+// isRetryableSyncError classifies an embed/store error as transient
+// (network failures, context deadlines, 5xx-shaped backend errors) vs.
+// permanent (validation errors, bad input) so retryWithBackoff doesn't
+// waste attempts on an error retrying can never fix.
+func isRetryableSyncError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	msg := err.Error()
+	for _, substr := range []string{"server error", "status 5", "connection refused", "EOF", "timeout"} {
+		if strings.Contains(msg, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// syncRetryConfig bundles the backoff/fault-injection tunables chunkAdder
+// needs, so it can stay a free function (like the rest of the sync
+// pipeline) while still reading Syncer's configured values.
+type syncRetryConfig struct {
+	baseDelay   time.Duration
+	maxAttempts int
+	jitter      float64
+	injector    FaultInjector
+}
+
+// retryWithBackoff retries fn up to cfg.maxAttempts times with exponential
+// backoff (cfg.baseDelay doubling each attempt) plus up to cfg.jitter
+// fraction of random jitter, the same shape downloadRangeWithRetry uses
+// for transient Drive download failures. It stops early on a permanent
+// error per isRetryableSyncError. If cfg.injector is set, it's consulted
+// before fn on every attempt.
+func retryWithBackoff(ctx context.Context, cfg syncRetryConfig, op string, fn func() error) error {
+	maxAttempts := cfg.maxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultSyncRetryMaxAttempts
+	}
+	baseDelay := cfg.baseDelay
+	if baseDelay <= 0 {
+		baseDelay = DefaultSyncRetryBaseDelay
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if attempt > 0 {
+			backoff := baseDelay * time.Duration(1<<uint(attempt-1))
+			if cfg.jitter > 0 {
+				backoff += time.Duration(rand.Float64() * cfg.jitter * float64(backoff))
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			syncLog.Infof("Retrying %s (attempt %d/%d)", op, attempt+1, maxAttempts)
+		}
+
+		err := fn()
+		if err == nil && cfg.injector != nil {
+			err = cfg.injector.MaybeFail(op)
+		}
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !isRetryableSyncError(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("%s: exceeded %d retries: %w", op, maxAttempts, lastErr)
 }
 
 type chunkAddResult struct {
@@ -155,95 +680,362 @@ type chunkAddResult struct {
 	err          error
 }
 
-func chunkAdder(ctx context.Context, chunkChan chan types.ChunkSyncResult, resChan chan chunkAddResult) {
+// chunkAdder runs chunks through two pipelined stages: cleaning/dedup
+// (sequential, since the ChunkHashExists check must happen before a chunk
+// is accepted into a batch), then batching: chunks accumulate into a batch
+// until either embedBatchSize is reached or embedFlushInterval elapses
+// since the last flush, whichever comes first. Each flush issues one
+// EmbedBatchFromModel call and one store.AddVectors call for the whole
+// batch, rather than one round trip per chunk, and up to
+// maxConcurrentEmbeddings flushes run concurrently so a slow Ollama/store
+// call doesn't stall chunks accumulating for the next batch. Every
+// ChunkHashExists/EmbedBatchFromModel/AddVectors call is wrapped in
+// retryWithBackoff, so a transient error (Ollama restarting, Weaviate
+// briefly unavailable) doesn't drop the chunk/batch on the first failure.
+// Results and errors from every stage land on resChan. Every chunk added
+// or dropped with an error also lands a synclog.Record on recLog (a
+// no-op if recLog is nil), tagged with connectorID/connectorType.
+func chunkAdder(ctx context.Context, st types.Store, chunkChan <-chan types.ChunkSyncResult, resChan chan chunkAddResult, maxConcurrentEmbeddings, embedBatchSize int, embedFlushInterval time.Duration, simhashShingleWidth, simhashMaxDistance int, retryCfg syncRetryConfig, recLog *synclog.Sink, ioStats *ioStatsRegistry, connectorID, connectorType string) {
 	defer close(resChan)
-	// TODO: hold buffer and add vectors in batches
-	for res := range chunkChan {
-		if res.Err != nil {
-			resChan <- chunkAddResult{
-				err: fmt.Errorf("error processing chunk: %s", res.Err),
+
+	if maxConcurrentEmbeddings <= 0 {
+		maxConcurrentEmbeddings = DefaultMaxConcurrentEmbeddings
+	}
+	if embedBatchSize <= 0 {
+		embedBatchSize = DefaultEmbedBatchSize
+	}
+	if embedFlushInterval <= 0 {
+		embedFlushInterval = DefaultEmbedFlushInterval
+	}
+	if simhashShingleWidth <= 0 {
+		simhashShingleWidth = DefaultSimHashShingleWidth
+	}
+	if simhashMaxDistance <= 0 {
+		simhashMaxDistance = DefaultSimHashMaxDistance
+	}
+
+	preparedChan := make(chan types.Chunk)
+
+	go func() {
+		defer close(preparedChan)
+		for res := range chunkChan {
+			if res.Err != nil {
+				recLog.Write(synclog.Record{
+					"Event":          "chunk-error",
+					"Connector-ID":   connectorID,
+					"Connector-Type": connectorType,
+					"Error":          res.Err.Error(),
+				})
+				resChan <- chunkAddResult{
+					err: fmt.Errorf("error processing chunk: %s", res.Err),
+				}
+				continue
 			}
-			continue
-		}
-		chunk := res.Chunk
 
-		saneChunk := chunk.Text
-		saneName := chunk.Name
-		if !res.SkipClean {
-			saneChunk = util.CleanChunk(chunk.Text)
-			saneName = util.CleanChunk(chunk.Name)
-		}
-		log.Printf("New chunk, length: %d, sanitized: %d\n", len(chunk.Text), len(saneChunk))
-		if len(saneChunk) < MinChunkSize {
-			log.Printf("Skipping short chunk: %s\n", saneChunk)
-			continue
-		}
+			if res.DeleteHash != "" {
+				// A live connector is reporting that a message it
+				// previously emitted was edited or deleted; remove the
+				// chunk it produced before processing any replacement
+				// content below.
+				if err := st.DeleteChunkByHash(ctx, res.DeleteHash); err != nil && !store.IsErrChunkNotFound(err) {
+					recLog.Write(synclog.Record{
+						"Event":          "chunk-error",
+						"Connector-ID":   connectorID,
+						"Connector-Type": connectorType,
+						"Chunk-Hash":     res.DeleteHash,
+						"Error":          err.Error(),
+					})
+					resChan <- chunkAddResult{
+						err: fmt.Errorf("failed to delete chunk %s: %s", res.DeleteHash, err),
+					}
+					continue
+				}
+				if res.Chunk.Text == "" {
+					// Pure delete, no replacement content to add
+					continue
+				}
+			}
 
-		chunkHash := hash(saneChunk)
-		exists, err := store.ChunkHashExists(ctx, store.GetWeaviateClient(), chunkHash)
-		if err != nil && !store.IsErrChunkNotFound(err) {
-			resChan <- chunkAddResult{
-				err: fmt.Errorf("failed to check chunk hash: %s", err),
+			chunk := res.Chunk
+
+			saneChunk := chunk.Text
+			saneName := chunk.Name
+			if !res.SkipClean {
+				saneChunk = util.CleanChunk(chunk.Text)
+				saneName = util.CleanChunk(chunk.Name)
 			}
-			continue
+			syncLog.Debugf("New chunk, length: %d, sanitized: %d\n", len(chunk.Text), len(saneChunk))
+			if len(saneChunk) < MinChunkSize {
+				syncLog.Debugf("Skipping short chunk: %s\n", saneChunk)
+				continue
+			}
+
+			chunkHash := hash(saneChunk)
+			var exists bool
+			err := retryWithBackoff(ctx, retryCfg, "chunk_hash_exists", func() error {
+				var existsErr error
+				exists, existsErr = st.ChunkHashExists(ctx, chunkHash)
+				if existsErr != nil && store.IsErrChunkNotFound(existsErr) {
+					exists = false
+					return nil
+				}
+				return existsErr
+			})
+			if err != nil {
+				recLog.Write(synclog.Record{
+					"Event":          "chunk-error",
+					"Connector-ID":   connectorID,
+					"Connector-Type": connectorType,
+					"Chunk-Hash":     chunkHash,
+					"Error":          err.Error(),
+				})
+				resChan <- chunkAddResult{
+					err: fmt.Errorf("failed to check chunk hash: %s", err),
+				}
+				continue
+			}
+			if exists {
+				syncLog.Debugf("Chunk already exists: %s\n", chunkHash)
+				continue
+			}
+
+			fingerprint := util.SimHash(saneChunk, simhashShingleWidth)
+			var dup *types.Chunk
+			err = retryWithBackoff(ctx, retryCfg, "near_dup_lookup", func() error {
+				var dupErr error
+				dup, dupErr = st.FindNearDuplicateChunk(ctx, connectorID, fingerprint, simhashMaxDistance)
+				return dupErr
+			})
+			if err != nil {
+				recLog.Write(synclog.Record{
+					"Event":          "chunk-error",
+					"Connector-ID":   connectorID,
+					"Connector-Type": connectorType,
+					"Chunk-Hash":     chunkHash,
+					"Error":          err.Error(),
+				})
+				resChan <- chunkAddResult{
+					err: fmt.Errorf("failed to check for near-duplicate chunk: %s", err),
+				}
+				continue
+			}
+			if dup != nil {
+				syncLog.Debugf("Chunk %s is a near-duplicate of %s, skipping embedding\n", chunkHash, dup.Hash)
+				recLog.Write(synclog.Record{
+					"Event":            "chunk-dedup",
+					"Connector-ID":     connectorID,
+					"Connector-Type":   connectorType,
+					"Chunk-Hash":       chunkHash,
+					"Duplicate-Of":     dup.Hash,
+					"Hamming-Distance": fmt.Sprintf("%d", util.HammingDistance(fingerprint, dup.Fingerprint)),
+				})
+				continue
+			}
+
+			chunk.Text = saneChunk
+			chunk.Name = saneName
+			chunk.Hash = chunkHash
+			chunk.Fingerprint = fingerprint
+			preparedChan <- chunk
 		}
-		if exists {
-			log.Printf("Chunk already exists: %s\n", chunkHash)
-			continue
+	}()
+
+	// sem bounds how many flushes (one EmbedBatchFromModel call plus one
+	// AddVectors call each) run concurrently, so a connector emitting
+	// chunks faster than Ollama/the store can keep up doesn't pile up
+	// unbounded goroutines.
+	sem := make(chan struct{}, maxConcurrentEmbeddings)
+	var flushWG sync.WaitGroup
+
+	flush := func(batch []types.Chunk) {
+		if len(batch) == 0 {
+			return
 		}
+		flushWG.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer flushWG.Done()
+			defer func() { <-sem }()
 
-		resp, err := EmbedFromModel(saneChunk)
-		if err != nil {
-			resChan <- chunkAddResult{
-				err: fmt.Errorf("failed to get embeddings: %s", err),
+			flushStart := time.Now()
+
+			texts := make([]string, len(batch))
+			for i, chunk := range batch {
+				texts[i] = chunk.Text
+			}
+			var embeddings [][]float32
+			err := retryWithBackoff(ctx, retryCfg, "embed", func() error {
+				var embedErr error
+				embeddings, embedErr = EmbedBatchFromModel(texts)
+				return embedErr
+			})
+			if err != nil {
+				recLog.Write(synclog.Record{
+					"Event":          "chunk-error",
+					"Connector-ID":   connectorID,
+					"Connector-Type": connectorType,
+					"Error":          err.Error(),
+				})
+				resChan <- chunkAddResult{
+					err: fmt.Errorf("failed to get embeddings: %s", err),
+				}
+				return
 			}
-			continue
-		}
 
-		embedding := resp.Embedding
-		chunk.Text = saneChunk
-		chunk.Name = saneName
-		chunk.Hash = chunkHash
-		addResp, err := store.AddVectors(ctx, store.GetWeaviateClient(), []types.AddVectorItem{
-			{
-				Chunk:  chunk,
-				Vector: embedding,
-			},
-		})
-		if err != nil {
+			items := make([]types.AddVectorItem, len(batch))
+			for i, chunk := range batch {
+				items[i] = types.AddVectorItem{
+					Chunk:  chunk,
+					Vector: embeddings[i],
+				}
+			}
+			var addResp *types.AddVectorResponse
+			err = retryWithBackoff(ctx, retryCfg, "add_vectors", func() error {
+				var addErr error
+				addResp, addErr = st.AddVectors(ctx, items)
+				return addErr
+			})
+			if err != nil {
+				recLog.Write(synclog.Record{
+					"Event":          "chunk-error",
+					"Connector-ID":   connectorID,
+					"Connector-Type": connectorType,
+					"Error":          err.Error(),
+				})
+				resChan <- chunkAddResult{
+					err: fmt.Errorf("failed to add vectors: %s", err),
+				}
+				return
+			}
 			resChan <- chunkAddResult{
-				err: fmt.Errorf("failed to add vector: %s", err),
+				numChunks:    addResp.NumChunksAdded,
+				numDocuments: addResp.NumDocsAdded,
 			}
-			continue
-		}
+			syncLog.Debugf("Added %d chunks, %d documents in a batch of %d\n", addResp.NumChunksAdded, addResp.NumDocsAdded, len(batch))
+
+			var bytesEmbedded, bytesStored int64
+			for i, chunk := range batch {
+				bytesEmbedded += int64(len(chunk.Text))
+				bytesStored += int64(len(embeddings[i]) * 4)
+			}
+			flushDuration := time.Since(flushStart)
+			ioStats.addFlush(connectorID, bytesEmbedded, bytesStored, int64(addResp.NumChunksAdded))
+			metrics.ConnectorBytesEmbedded.WithLabelValues(connectorID).Add(float64(bytesEmbedded))
+			metrics.ConnectorBytesStored.WithLabelValues(connectorID).Add(float64(bytesStored))
+			metrics.ConnectorChunksAdded.WithLabelValues(connectorID).Add(float64(addResp.NumChunksAdded))
+			metrics.ConnectorEmbedLatency.WithLabelValues(connectorID).Observe(flushDuration.Seconds())
+
+			durationMs := fmt.Sprintf("%d", flushDuration.Milliseconds())
+			for i, chunk := range batch {
+				recLog.Write(synclog.Record{
+					"Event":               "chunk-added",
+					"Connector-ID":        connectorID,
+					"Connector-Type":      connectorType,
+					"Chunk-Hash":          chunk.Hash,
+					"Bytes-In":            fmt.Sprintf("%d", len(chunk.Text)),
+					"Bytes-Out-Embedding": fmt.Sprintf("%d", len(embeddings[i])*4),
+					"Duration-Ms":         durationMs,
+				})
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(embedFlushInterval)
+	defer ticker.Stop()
 
-		resChan <- chunkAddResult{
-			numChunks:    addResp.NumChunksAdded,
-			numDocuments: addResp.NumDocsAdded,
+	batch := make([]types.Chunk, 0, embedBatchSize)
+chunkLoop:
+	for {
+		select {
+		case chunk, ok := <-preparedChan:
+			if !ok {
+				break chunkLoop
+			}
+			batch = append(batch, chunk)
+			if len(batch) >= embedBatchSize {
+				flush(batch)
+				batch = make([]types.Chunk, 0, embedBatchSize)
+				ticker.Reset(embedFlushInterval)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				flush(batch)
+				batch = make([]types.Chunk, 0, embedBatchSize)
+			}
 		}
-		log.Printf("Added %d chunks, %d documents for source URL: %s\n", addResp.NumChunksAdded, addResp.NumDocsAdded, chunk.SourceURL)
 	}
+	flush(batch)
+	flushWG.Wait()
 
-	log.Printf("Chunk channel closed")
+	syncLog.Debugf("Chunk channel closed")
+}
+
+// mutateConnectorState is store.MutateConnectorState's read-mutate-write
+// retry loop, adapted to run through the types.Connector interface
+// (Status/UpdateConnectorState) instead of a types.Store directly, since
+// that's all the call sites in this file have on hand. progressUpdater and
+// stateUpdater run concurrently for the same connector for the duration of
+// a sync, so without retrying on store.ErrConflict one of their writes
+// would silently lose the other's NumChunks/NumErrors/Progress update.
+func mutateConnectorState(ctx context.Context, c types.Connector, mutate func(*types.ConnectorState) error) error {
+	const maxAttempts = 5
+	var lastErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		state, err := c.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to get connector state: %w", err)
+		}
+		if err := mutate(state); err != nil {
+			return err
+		}
+		err = c.UpdateConnectorState(ctx, state)
+		if err == nil {
+			return nil
+		}
+		if !store.IsErrConflict(err) {
+			return err
+		}
+		lastErr = err
+	}
+	return fmt.Errorf("exceeded %d attempts: %w", maxAttempts, lastErr)
 }
 
 func updateState(ctx context.Context, c types.Connector, numChunks, numDocs, numErrors int) {
-	state, err := c.Status(ctx)
+	err := mutateConnectorState(ctx, c, func(state *types.ConnectorState) error {
+		state.NumChunks += numChunks
+		state.NumDocuments += numDocs
+		state.NumErrors += numErrors
+		return nil
+	})
 	if err != nil {
-		log.Printf("Failed to get status: %s\n", err)
+		syncLog.Errorf("Failed to update status: %s", err)
 		return
 	}
-	state.NumChunks += numChunks
-	state.NumDocuments += numDocs
-	state.NumErrors += numErrors
-	err = c.UpdateConnectorState(ctx, state)
-	if err != nil {
-		log.Printf("Failed to update status: %s\n", err)
-		return
+}
+
+// progressUpdater drains a connector's Progress channel for the lifetime
+// of a sync and persists each snapshot into ConnectorState, so a
+// concurrent /connectors request (or a Cancel) observes up-to-date
+// progress without querying the connector directly. It exits when
+// doneChan is closed by stateUpdater at the end of the sync.
+func progressUpdater(ctx context.Context, c types.Connector, doneChan <-chan struct{}) {
+	for {
+		select {
+		case p := <-c.Progress():
+			err := mutateConnectorState(ctx, c, func(state *types.ConnectorState) error {
+				state.Progress = p
+				return nil
+			})
+			if err != nil {
+				syncLog.Errorf("Failed to persist sync progress: %s", err)
+			}
+		case <-doneChan:
+			return
+		}
 	}
 }
 
-func stateUpdater(ctx context.Context, c types.Connector, resChan chan chunkAddResult, doneChan chan struct{}) {
+func stateUpdater(ctx context.Context, c types.Connector, resChan chan chunkAddResult, doneChan chan struct{}, recLog *synclog.Sink) {
 	defer close(doneChan)
 
 	// countChan is expected to close before errChunkChan when the sync completes
@@ -253,11 +1045,29 @@ func stateUpdater(ctx context.Context, c types.Connector, resChan chan chunkAddR
 	updateEvery := 10 // Number of chunks after which we should update the state
 	counts := []chunkAddResult{}
 
+	flushUpdate := func() {
+		numChunks = 0
+		numDocs = 0
+		for _, prevCount := range counts {
+			numChunks += prevCount.numChunks
+			numDocs += prevCount.numDocuments
+		}
+		updateState(ctx, c, numChunks, numDocs, numErrors)
+		recLog.Write(synclog.Record{
+			"Event":          "state-updated",
+			"Connector-ID":   c.ID(),
+			"Connector-Type": string(c.Type()),
+			"New-Num-Chunks": fmt.Sprintf("%d", numChunks),
+			"New-Num-Docs":   fmt.Sprintf("%d", numDocs),
+			"New-Num-Errors": fmt.Sprintf("%d", numErrors),
+		})
+	}
+
 	for res := range resChan {
 		if res.err == nil {
 			counts = append(counts, res)
 		} else {
-			log.Printf("Error processing chunk: %s\n", res.err)
+			syncLog.Errorf("Error processing chunk: %s", res.err)
 			numErrors++
 		}
 
@@ -265,24 +1075,12 @@ func stateUpdater(ctx context.Context, c types.Connector, resChan chan chunkAddR
 			continue
 		}
 
-		numChunks = 0
-		numDocs = 0
-		for _, prevCount := range counts {
-			numChunks += prevCount.numChunks
-			numDocs += prevCount.numDocuments
-		}
-		updateState(ctx, c, numChunks, numDocs, numErrors)
+		flushUpdate()
 		counts = []chunkAddResult{}
 		numErrors = 0
 	}
 
-	numChunks = 0
-	numDocs = 0
-	for _, prevCount := range counts {
-		numChunks += prevCount.numChunks
-		numDocs += prevCount.numDocuments
-	}
-	updateState(ctx, c, numChunks, numDocs, numErrors)
+	flushUpdate()
 }
 
 func copyState(state *types.ConnectorState) (*types.ConnectorState, error) {
@@ -300,6 +1098,12 @@ func copyState(state *types.ConnectorState) (*types.ConnectorState, error) {
 }
 
 func (s *Syncer) connectorSync(ctx context.Context, c types.Connector, state *types.ConnectorState) error {
+	cLog := syncLog.With(map[string]interface{}{
+		"connector_id":   c.ID(),
+		"connector_type": string(c.Type()),
+		"user":           c.User(),
+	})
+
 	// Keep a copy of the current connector state to calculate diffs
 	prevState, err := copyState(state)
 	if err != nil {
@@ -308,6 +1112,12 @@ func (s *Syncer) connectorSync(ctx context.Context, c types.Connector, state *ty
 
 	syncStartTime := time.Now()
 
+	s.recLog.Write(synclog.Record{
+		"Event":          "sync-start",
+		"Connector-ID":   c.ID(),
+		"Connector-Type": string(c.Type()),
+	})
+
 	// The channel where all chunks are sent. Closed by c.Sync when done
 	chunkChan := make(chan types.ChunkSyncResult)
 
@@ -323,165 +1133,280 @@ func (s *Syncer) connectorSync(ctx context.Context, c types.Connector, state *ty
 	// update. Closed by the stateUpdater
 	doneChan := make(chan struct{})
 
+	// pipelineChan is what chunkAdder actually reads from: chunkpipeline.Run
+	// sits between the connector and the embedder, applying connector-type's
+	// registered middleware chain (redaction, dedup, policy, ...) to every
+	// chunk chunkChan carries before it's allowed through. Closed by Run once
+	// chunkChan is exhausted.
+	pipelineChan := make(chan types.ChunkSyncResult)
+	pipelineStats := &chunkpipeline.Stats{}
+
 	// Sync sends chunks to chunkChan, chunkAdder processes them and sends results to chunkAddResChan
 	// This allows the following to happen in parallel:
 	// - Fetches from the connector and document conversions (in Sync)
 	// - Embeddings generation and addition to weaviate (in chunkAdder)
 	// - Periodic updates to the connector state (in stateUpdater)
-	go c.Sync(state.LastSync, chunkChan, errChanSync)
-	go chunkAdder(ctx, chunkChan, chunkAddResChan)
-	go stateUpdater(ctx, c, chunkAddResChan, doneChan)
+	go c.Sync(ctx, state.LastSync, chunkChan, errChanSync)
+	go chunkpipeline.Run(ctx, chunkChan, pipelineChan, chunkpipeline.For(c.Type()), pipelineStats, c.ID(), string(c.Type()), s.recLog)
+	go chunkAdder(ctx, s.store, pipelineChan, chunkAddResChan, s.maxConcurrentEmbeddings, s.embedBatchSize, s.embedFlushInterval, s.simhashShingleWidth, s.simhashMaxDistance, s.retryConfig(), s.recLog, s.ioStats, c.ID(), string(c.Type()))
+	go stateUpdater(ctx, c, chunkAddResChan, doneChan, s.recLog)
+	go progressUpdater(ctx, c, doneChan)
 
 	syncError := ""
 	select {
 	case <-ctx.Done():
 		// The sync time has not been updated, so the next sync will pick up the same chunks
-		log.Printf("Syncer: Context cancelled")
-		state, err := c.Status(ctx)
-		if err != nil {
-			return fmt.Errorf("failed to get status for %s: %s", c.ID(), err)
-		}
-		state.Syncing = false
-		err = c.UpdateConnectorState(ctx, state)
+		cLog.Infof("Syncer: Context cancelled")
+		err := mutateConnectorState(ctx, c, func(state *types.ConnectorState) error {
+			state.Syncing = false
+			return nil
+		})
 		if err != nil {
 			return fmt.Errorf("unable to update last sync for %s: %s", c.ID(), err)
 		}
 		break
 	case err := <-errChanSync:
 		if err != nil {
-			log.Printf("Sync for connector %s %s completed with error: %s", c.Type(), c.ID(), err)
+			cLog.Errorf("Sync completed with error: %s", err)
 			syncError = err.Error()
 		} else {
-			log.Printf("Unexpected close for errChanSync")
+			cLog.Infof("Unexpected close for errChanSync")
 		}
 	case <-doneChan:
-		log.Printf("Sync for connector %s %s completed successfully", c.Type(), c.ID())
+		cLog.Infof("Sync completed successfully")
 	}
 
-	state, err = c.Status(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get status for %s: %s", c.ID(), err)
-	}
-	if syncError == "" {
-		// Only update the sync time if the overall sync was successful (even if there were chunk errors)
-		state.LastSync = syncStartTime
-	}
-	state.Syncing = false
-	err = c.UpdateConnectorState(ctx, state)
+	// doneChan/errChanSync only fire once chunkAdder has drained
+	// pipelineChan, which only happens once chunkpipeline.Run has closed
+	// it, so pipelineStats is final here on both of those paths.
+	rejected, redacted, deduped := pipelineStats.Snapshot()
+	s.pipelineStats.add(c.ID(), rejected, redacted, deduped)
+
+	err = mutateConnectorState(ctx, c, func(s *types.ConnectorState) error {
+		if syncError == "" {
+			// Only update the sync time if the overall sync was successful (even if there were chunk errors)
+			s.LastSync = syncStartTime
+		}
+		s.Syncing = false
+		state = s
+		return nil
+	})
 	if err != nil {
 		return fmt.Errorf("unable to update last sync for %s: %s", c.ID(), err)
 	}
 	syncDoneTime := time.Now()
 
+	syncOutcome := "success"
+	if syncError != "" {
+		syncOutcome = "failure"
+	}
+	metrics.ConnectorSyncResult.WithLabelValues(string(c.Type()), syncOutcome).Inc()
+
 	// Only report sync events if the state has changed to avoid spamming posthog
 	num_synced_chunks := state.NumChunks - prevState.NumChunks
 	num_synced_docs := state.NumDocuments - prevState.NumDocuments
 	num_synced_errors := state.NumErrors - prevState.NumErrors
 
-	log.Printf(
-		"Connector sync complete for %s %s: %d new_chunks, %d new_docs, %d new_errors",
-		c.Type(),
-		c.ID(),
+	s.recLog.Write(synclog.Record{
+		"Event":          "sync-finish",
+		"Connector-ID":   c.ID(),
+		"Connector-Type": string(c.Type()),
+		"Duration-Ms":    fmt.Sprintf("%d", syncDoneTime.Sub(syncStartTime).Milliseconds()),
+		"Error":          syncError,
+	})
+
+	cLog.Infof(
+		"Connector sync complete: %d new_chunks, %d new_docs, %d new_errors",
 		num_synced_chunks,
 		num_synced_docs,
 		num_synced_errors,
 	)
 	if num_synced_chunks == 0 && num_synced_docs == 0 && num_synced_errors == 0 {
-		log.Printf("Syncer: no new items found for %s %s\n", c.Type(), c.ID())
+		cLog.Infof("Syncer: no new items found")
 		return nil
 	}
 
-	err = s.posthogClient.Enqueue(posthog.Capture{
-		DistinctId: s.posthogDistinctID,
-		Event:      "Sync",
-		Properties: posthog.NewProperties().
-			Set("connector_id", c.ID()).
-			Set("connector_type", c.Type()).
-			Set("new_num_chunks", num_synced_chunks).
-			Set("new_num_documents", num_synced_docs).
-			Set("new_num_errors", num_synced_errors).
-			Set("total_num_chunks", state.NumChunks).
-			Set("total_num_documents", state.NumDocuments).
-			Set("total_num_errors", state.NumErrors).
-			Set("sync_duration", syncDoneTime.Sub(syncStartTime).String()).
-			Set("sync_error", syncError).
-			Set("version", s.version),
+	err = s.analytics.Capture(s.posthogDistinctID, "Sync", map[string]interface{}{
+		"connector_id":        c.ID(),
+		"connector_type":      c.Type(),
+		"new_num_chunks":      num_synced_chunks,
+		"new_num_documents":   num_synced_docs,
+		"new_num_errors":      num_synced_errors,
+		"total_num_chunks":    state.NumChunks,
+		"total_num_documents": state.NumDocuments,
+		"total_num_errors":    state.NumErrors,
+		"sync_duration":       syncDoneTime.Sub(syncStartTime).String(),
+		"sync_error":          syncError,
+		"version":             s.version,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to enqueue sync event: %s", err)
 	}
 
-	log.Printf("Posted Sync on posthog for %s %s\n", c.Type(), c.ID())
+	syncLog.Infof("Posted Sync event for %s %s\n", c.Type(), c.ID())
 	return nil
 }
 
 func (s *Syncer) ASyncNow(ctx context.Context) {
-	log.Printf("Attempting async sync")
+	syncLog.Infof("Attempting async sync")
 	go func() {
 		err := s.SyncNow(ctx)
 		if err != nil {
-			log.Printf("Failed to sync: %s\n", err)
+			syncLog.Errorf("Failed to sync: %s", err)
 		}
 	}()
 }
 
 // maybeSyncConnector returns an error only if the entire sync should halt
-func (s *Syncer) maybeSyncConnector(ctx context.Context, wg *sync.WaitGroup, c types.Connector) error {
-	log.Printf("Checking status for connector %s %s\n", c.Type(), c.ID())
+func (s *Syncer) maybeSyncConnector(ctx context.Context, c types.Connector) error {
+	syncLog.Infof("Checking status for connector %s %s\n", c.Type(), c.ID())
+
+	// If a Locker is configured, hold it for the rest of this function (and,
+	// if a sync is actually launched, for as long as that sync runs) so two
+	// verbis processes never both pass SetConnectorSyncing's CAS for the
+	// same connector at the same time. Without a Locker, that CAS is still
+	// the only mutual exclusion available, same as before locker existed.
+	var lockToken string
+	if s.locker != nil {
+		token, err := s.locker.Lock(ctx, c.ID())
+		if err != nil {
+			return fmt.Errorf("failed to acquire sync lock for connector %s %s: %s", c.Type(), c.ID(), err)
+		}
+		lockToken = token
+	}
+	releaseLock := func() {
+		if s.locker == nil {
+			return
+		}
+		if err := s.locker.Unlock(context.Background(), c.ID(), lockToken); err != nil {
+			syncLog.Errorf("Failed to release sync lock for connector %s %s: %s", c.Type(), c.ID(), err)
+		}
+	}
 
-	state, err := store.SetConnectorSyncing(ctx, store.GetWeaviateClient(), c.ID(), true)
+	state, err := s.store.SetConnectorSyncing(ctx, c.ID(), true)
 	if store.IsSyncingAlreadyExpected(err) {
-		log.Printf("Connector %s %s already syncing", c.Type(), c.ID())
+		syncLog.Infof("Connector %s %s already syncing", c.Type(), c.ID())
+		s.recLog.Write(synclog.Record{
+			"Event":          "sync-skip",
+			"Connector-ID":   c.ID(),
+			"Connector-Type": string(c.Type()),
+			"Reason":         "already-syncing",
+		})
+		releaseLock()
 		return nil
 	}
 	if err != nil {
+		releaseLock()
 		return fmt.Errorf("failed to set connector %s %s to syncing state: %s", c.Type(), c.ID(), err)
 	}
-	log.Printf("Connector %s %s set to syncing", c.Type(), c.ID())
+	syncLog.Infof("Connector %s %s set to syncing", c.Type(), c.ID())
 	unlock := true
 
 	if !state.AuthValid {
-		log.Printf("Auth required for %s %s", c.Type(), c.ID())
+		syncLog.Infof("Auth required for %s %s", c.Type(), c.ID())
+		s.recLog.Write(synclog.Record{
+			"Event":          "sync-skip",
+			"Connector-ID":   c.ID(),
+			"Connector-Type": string(c.Type()),
+			"Reason":         "auth-required",
+		})
 	} else {
 		if time.Since(state.LastSync) > s.staleThreshold {
-			log.Printf("Sync required for %s %s", c.Type(), c.ID())
+			syncLog.Infof("Sync required for %s %s", c.Type(), c.ID())
 			unlock = false
-			wg.Add(1)
+			done := make(chan struct{})
+			s.mu.Lock()
+			s.inFlight[c.ID()] = done
+			s.mu.Unlock()
+			s.syncWG.Add(1)
 			go func(c types.Connector) {
-				defer wg.Done()
+				defer s.syncWG.Done()
+				defer func() {
+					s.mu.Lock()
+					delete(s.inFlight, c.ID())
+					s.mu.Unlock()
+					close(done)
+					releaseLock()
+				}()
 				new_err := s.connectorSync(ctx, c, state)
 				if new_err != nil {
-					log.Printf("Error syncing %s %s: %s", c.Type(), c.ID(), new_err)
+					syncLog.Errorf("Error syncing %s %s: %s", c.Type(), c.ID(), new_err)
 				}
 			}(c)
 		} else {
-			log.Printf("Sync not required for %s", c.ID())
+			syncLog.Infof("Sync not required for %s", c.ID())
+			s.recLog.Write(synclog.Record{
+				"Event":          "sync-skip",
+				"Connector-ID":   c.ID(),
+				"Connector-Type": string(c.Type()),
+				"Reason":         "not-stale",
+			})
 		}
 	}
 
 	// Unlock syncing state
 	if unlock {
-		_, err = store.SetConnectorSyncing(ctx, store.GetWeaviateClient(), c.ID(), false)
+		_, err = s.store.SetConnectorSyncing(ctx, c.ID(), false)
 		if err != nil {
-			log.Printf("Failed to set connector %s %s to not syncing state: %s", c.Type(), c.ID(), err)
+			syncLog.Errorf("Failed to set connector %s %s to not syncing state: %s", c.Type(), c.ID(), err)
 		} else {
-			log.Printf("Connector %s %s set to not syncing", c.Type(), c.ID())
+			syncLog.Infof("Connector %s %s set to not syncing", c.Type(), c.ID())
 		}
+		releaseLock()
 	}
 
 	return nil
 }
 
 func (s *Syncer) SyncNow(ctx context.Context) error {
-	log.Printf("SyncNow started")
-	wg := sync.WaitGroup{}
+	syncLog.Infof("SyncNow started")
+	s.mu.RLock()
+	snapshot := make([]types.Connector, 0, len(s.connectors))
 	for _, c := range s.connectors {
-		err := s.maybeSyncConnector(ctx, &wg, c)
+		snapshot = append(snapshot, c)
+	}
+	s.mu.RUnlock()
+
+	for _, c := range snapshot {
+		err := s.maybeSyncConnector(ctx, c)
 		if err != nil {
 			return fmt.Errorf("failed to trigger sync for connector %s %s: %s", c.Type(), c.ID(), err)
 		}
 	}
-	wg.Wait()
-	log.Printf("SyncNow complete")
+	s.syncWG.Wait()
+	syncLog.Infof("SyncNow complete")
 	return nil
 }
+
+// Drain tells every registered connector to cancel its in-flight sync via
+// Cancel, which flushes its last known progress and lastSync watermark into
+// persisted state, then waits for their goroutines to actually return. It
+// returns early with an error if ctx is done before they do, in which case
+// the caller should proceed with a hard shutdown.
+func (s *Syncer) Drain(ctx context.Context) error {
+	syncLog.Infof("Draining syncer")
+
+	s.mu.RLock()
+	connectors := make([]types.Connector, 0, len(s.connectors))
+	for _, c := range s.connectors {
+		connectors = append(connectors, c)
+	}
+	s.mu.RUnlock()
+	for _, c := range connectors {
+		c.Cancel(ctx)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.syncWG.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		syncLog.Infof("Syncer drained")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for in-flight syncs to drain: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/verbis-ai/verbis/verbis/analytics"
+	"github.com/verbis-ai/verbis/verbis/store"
+	"github.com/verbis-ai/verbis/verbis/types"
+)
+
+// fakeConnector is the minimal types.Connector a Syncer test needs: it
+// never has valid auth, so maybeSyncConnector always takes the
+// "auth required" skip branch instead of launching a real connectorSync.
+// That keeps TestSyncerConcurrentAddDeleteSyncNow focused on the
+// Syncer's own mutex/inFlight bookkeeping rather than the chunk pipeline.
+type fakeConnector struct {
+	id string
+}
+
+func (f *fakeConnector) ID() string                                         { return f.id }
+func (f *fakeConnector) Type() types.ConnectorType                          { return "faketest" }
+func (f *fakeConnector) User() string                                       { return "fake@example.com" }
+func (f *fakeConnector) Init(ctx context.Context, connectorID string) error { return nil }
+func (f *fakeConnector) UpdateConnectorState(ctx context.Context, state *types.ConnectorState) error {
+	return nil
+}
+func (f *fakeConnector) Status(ctx context.Context) (*types.ConnectorState, error) {
+	return &types.ConnectorState{ConnectorID: f.id}, nil
+}
+func (f *fakeConnector) AuthSetup(ctx context.Context) error                 { return nil }
+func (f *fakeConnector) AuthCallback(ctx context.Context, code string) error { return nil }
+func (f *fakeConnector) Sync(ctx context.Context, lastSync time.Time, chunkChan chan types.ChunkSyncResult, errChan chan error) {
+	close(chunkChan)
+}
+func (f *fakeConnector) TokenSource(config *oauth2.Config) (oauth2.TokenSource, error) {
+	return nil, nil
+}
+func (f *fakeConnector) Progress() <-chan types.SyncProgress { return make(chan types.SyncProgress) }
+func (f *fakeConnector) Cancel(ctx context.Context)          {}
+
+// TestSyncerConcurrentAddDeleteSyncNow hammers AddConnector/DeleteConnector
+// against SyncNow from many goroutines at once, so `go test -race` catches
+// an unsynchronized access to Syncer.connectors/inFlight if one of those
+// three ever stops going through s.mu.
+func TestSyncerConcurrentAddDeleteSyncNow(t *testing.T) {
+	st, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "verbis.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	ctx := context.Background()
+	if err := st.CreateConnectorStateClass(ctx, false); err != nil {
+		t.Fatalf("CreateConnectorStateClass: %v", err)
+	}
+
+	s := NewSyncer(analytics.NewNoopSink(), "test-user", types.BuildCredentials{}, "test", st)
+
+	const numConnectors = 8
+	const iterations = 50
+
+	var wg sync.WaitGroup
+	for i := 0; i < numConnectors; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("conn-%d", i)
+			for j := 0; j < iterations; j++ {
+				if err := s.AddConnector(&fakeConnector{id: id}); err != nil {
+					t.Errorf("AddConnector: %v", err)
+					return
+				}
+				if err := s.DeleteConnector(ctx, id); err != nil {
+					t.Errorf("DeleteConnector: %v", err)
+					return
+				}
+			}
+		}(i)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < iterations; j++ {
+			if err := s.SyncNow(ctx); err != nil {
+				t.Errorf("SyncNow: %v", err)
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+}
+
+// permanentErr is a sentinel with no net.Error/timeout/status-5xx shape, to
+// confirm isRetryableSyncError treats it as non-retryable.
+var permanentErr = errors.New("invalid chunk: empty text")
+
+// fakeTimeoutErr implements net.Error so isRetryableSyncError's
+// errors.As(err, &netErr) branch can be exercised without a real socket.
+type fakeTimeoutErr struct{}
+
+func (fakeTimeoutErr) Error() string   { return "fake: i/o timeout" }
+func (fakeTimeoutErr) Timeout() bool   { return true }
+func (fakeTimeoutErr) Temporary() bool { return true }
+
+func TestIsRetryableSyncError(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"deadline exceeded", context.DeadlineExceeded, true},
+		{"net.Error", fakeTimeoutErr{}, true},
+		{"wrapped server error string", fmt.Errorf("embed failed: %s", "server error"), true},
+		{"connection refused string", errors.New("dial tcp: connection refused"), true},
+		{"permanent error", permanentErr, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isRetryableSyncError(tc.err); got != tc.want {
+				t.Errorf("isRetryableSyncError(%v) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestRetryWithBackoffRetriesTransientErrors forces the first two attempts
+// to fail with a retryable error via RandomFaultInjector (Rate: 1 pins it
+// to every call instead of leaving it to chance), then lets the third
+// attempt through, and checks fn actually ran three times.
+func TestRetryWithBackoffRetriesTransientErrors(t *testing.T) {
+	attempts := 0
+	injector := &RandomFaultInjector{Op: "embed", Rate: 1, Err: fakeTimeoutErr{}}
+
+	cfg := syncRetryConfig{
+		baseDelay:   time.Millisecond,
+		maxAttempts: 4,
+		injector:    injector,
+	}
+
+	err := retryWithBackoff(context.Background(), cfg, "embed", func() error {
+		attempts++
+		if attempts == 3 {
+			injector.Rate = 0
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("retryWithBackoff returned %v, want nil", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("fn ran %d times, want 3", attempts)
+	}
+}
+
+// TestRetryWithBackoffStopsOnPermanentError confirms retryWithBackoff
+// doesn't burn through maxAttempts on an error isRetryableSyncError
+// classifies as permanent.
+func TestRetryWithBackoffStopsOnPermanentError(t *testing.T) {
+	attempts := 0
+	cfg := syncRetryConfig{
+		baseDelay:   time.Millisecond,
+		maxAttempts: 5,
+	}
+
+	err := retryWithBackoff(context.Background(), cfg, "embed", func() error {
+		attempts++
+		return permanentErr
+	})
+	if !errors.Is(err, permanentErr) {
+		t.Fatalf("retryWithBackoff returned %v, want permanentErr", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("fn ran %d times, want 1 (no retry on a permanent error)", attempts)
+	}
+}
+
+// TestRetryWithBackoffExhaustsAttempts confirms a persistently retryable
+// error gives up after maxAttempts, wrapping the last error rather than
+// retrying forever.
+func TestRetryWithBackoffExhaustsAttempts(t *testing.T) {
+	attempts := 0
+	cfg := syncRetryConfig{
+		baseDelay:   time.Millisecond,
+		maxAttempts: 3,
+	}
+
+	err := retryWithBackoff(context.Background(), cfg, "embed", func() error {
+		attempts++
+		return fakeTimeoutErr{}
+	})
+	if err == nil {
+		t.Fatal("retryWithBackoff returned nil, want an error after exhausting retries")
+	}
+	if attempts != 3 {
+		t.Fatalf("fn ran %d times, want 3 (maxAttempts)", attempts)
+	}
+}
+
+// TestRandomFaultInjector checks MaybeFail only fires for the configured
+// op, and respects Rate 0/1 deterministically rather than only
+// probabilistically, so this test doesn't flake.
+func TestRandomFaultInjector(t *testing.T) {
+	injector := &RandomFaultInjector{Op: "embed", Rate: 1, Err: permanentErr}
+
+	if err := injector.MaybeFail("add_vectors"); err != nil {
+		t.Fatalf("MaybeFail for unconfigured op = %v, want nil", err)
+	}
+	if err := injector.MaybeFail("embed"); !errors.Is(err, permanentErr) {
+		t.Fatalf("MaybeFail with Rate 1 = %v, want permanentErr", err)
+	}
+
+	injector.Rate = 0
+	if err := injector.MaybeFail("embed"); err != nil {
+		t.Fatalf("MaybeFail with Rate 0 = %v, want nil", err)
+	}
+}
+
+var _ net.Error = fakeTimeoutErr{}
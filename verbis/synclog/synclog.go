@@ -0,0 +1,157 @@
+// Package synclog writes a machine-readable, recfile-style audit trail of
+// sync events (chunk adds, state updates, connector sync start/finish,
+// skip decisions) alongside the human-readable output the rest of the
+// syncer writes through github.com/verbis-ai/verbis/verbis/log. Each
+// record is one or more "Key: value" lines followed by a blank line, the
+// same shape GNU recutils uses, so records can be appended and later
+// parsed/filtered without a schema migration every time a field is added.
+// Fields are optional and readers must ignore unknown keys.
+package synclog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record is one recfile entry. Keys are arbitrary; unknown ones are kept
+// on read and simply ignored by filters/callers that don't recognize
+// them.
+type Record map[string]string
+
+// Sink appends Records to a recfile-format file.
+type Sink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// DefaultPath returns ~/.verbis/sync.rec, matching the ~/.verbis/...
+// convention already used for the chunk-delete dead-letter queue and the
+// connectors' temp directories.
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to get user home directory: %w", err)
+	}
+	return filepath.Join(home, ".verbis", "sync.rec"), nil
+}
+
+// Open creates path's parent directory if needed and returns a Sink
+// appending to it.
+func Open(path string) (*Sink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create sync log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sync log: %w", err)
+	}
+	return &Sink{file: f}, nil
+}
+
+// Write appends rec as one recfile record, adding a Timestamp field if
+// rec doesn't already have one. A nil Sink is a safe no-op, so callers
+// don't need to check whether the sink opened successfully before
+// writing to it.
+func (s *Sink) Write(rec Record) error {
+	if s == nil {
+		return nil
+	}
+	if _, ok := rec["Timestamp"]; !ok {
+		rec["Timestamp"] = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+
+	keys := make([]string, 0, len(rec))
+	for k := range rec {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		v := rec[k]
+		if v == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\n", k, v)
+	}
+	b.WriteString("\n")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.file.WriteString(b.String())
+	return err
+}
+
+func (s *Sink) Close() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// Parse reads recfile-format records from r. A blank line ends the
+// current record; a "Key: value" line sets that key on it. Lines with no
+// ": " separator are ignored rather than erroring the whole parse, since
+// this format is meant to tolerate partially-written or future fields.
+func Parse(r io.Reader) ([]Record, error) {
+	var records []Record
+	cur := Record{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			if len(cur) > 0 {
+				records = append(records, cur)
+				cur = Record{}
+			}
+			continue
+		}
+		parts := strings.SplitN(line, ": ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		cur[parts[0]] = parts[1]
+	}
+	if len(cur) > 0 {
+		records = append(records, cur)
+	}
+	return records, scanner.Err()
+}
+
+// Filter returns the records matching every non-empty constraint. An
+// empty connectorID/event skips that filter; a zero since/until skips
+// the time range check.
+func Filter(records []Record, connectorID, event string, since, until time.Time) []Record {
+	out := []Record{}
+	for _, rec := range records {
+		if connectorID != "" && rec["Connector-ID"] != connectorID {
+			continue
+		}
+		if event != "" && rec["Event"] != event {
+			continue
+		}
+		if !since.IsZero() || !until.IsZero() {
+			ts, err := time.Parse(time.RFC3339Nano, rec["Timestamp"])
+			if err != nil {
+				continue
+			}
+			if !since.IsZero() && ts.Before(since) {
+				continue
+			}
+			if !until.IsZero() && ts.After(until) {
+				continue
+			}
+		}
+		out = append(out, rec)
+	}
+	return out
+}
@@ -2,7 +2,10 @@ package types
 
 import (
 	"context"
+	"fmt"
 	"time"
+
+	"golang.org/x/oauth2"
 )
 
 type ConnectorType string
@@ -11,9 +14,46 @@ const (
 	ConnectorTypeGoogleDrive ConnectorType = "googledrive"
 	ConnectorTypeGmail       ConnectorType = "gmail"
 	ConnectorTypeOutlook     ConnectorType = "outlook"
+	ConnectorTypeDropbox     ConnectorType = "dropbox"
+	ConnectorTypeOneDrive    ConnectorType = "onedrive"
+	ConnectorTypeIMAP        ConnectorType = "imap"
+	ConnectorTypeSlack       ConnectorType = "slack"
 )
 
-type ConnectorConstructor func(BuildCredentials) Connector
+type ConnectorConstructor func(BuildCredentials, Store) Connector
+
+// connectorFactories is the process-wide connector type registry. Each
+// connector implementation registers itself here via RegisterConnector,
+// typically from an init() alongside its constructor, so that adding a new
+// connector type doesn't require editing types or boot code: an out-of-tree
+// connector package registers itself the same way database/sql drivers do,
+// via a blank import for its side effect.
+var connectorFactories = map[ConnectorType]ConnectorConstructor{}
+
+// RegisterConnector adds factory to the connector registry under t. It
+// panics on a duplicate registration for t, mirroring database/sql.Register,
+// since that can only happen from a programming mistake (two packages
+// claiming the same connector type) rather than something a caller should
+// handle at runtime.
+func RegisterConnector(t ConnectorType, factory ConnectorConstructor) {
+	if _, ok := connectorFactories[t]; ok {
+		panic(fmt.Sprintf("connector type %q already registered", t))
+	}
+	connectorFactories[t] = factory
+}
+
+// ConnectorFactory returns the factory registered for t, if any.
+func ConnectorFactory(t ConnectorType) (ConnectorConstructor, bool) {
+	factory, ok := connectorFactories[t]
+	return factory, ok
+}
+
+// IsConnectorType reports whether t has a registered factory.
+func IsConnectorType(t ConnectorType) bool {
+	_, ok := connectorFactories[t]
+	return ok
+}
+
 type Connector interface {
 	ID() string
 	Type() ConnectorType
@@ -31,9 +71,63 @@ type Connector interface {
 	AuthSetup(ctx context.Context) error
 	AuthCallback(ctx context.Context, code string) error
 	Sync(ctx context.Context, lastSync time.Time, chunkChan chan ChunkSyncResult, errChan chan error)
+
+	// TokenSource returns the connector's shared, proactively-refreshed
+	// oauth2.TokenSource for config, so the caller pulls a fresh token on
+	// every HTTP call across a long sync instead of caching a stale one,
+	// and concurrent Sync goroutines for the same connector share a
+	// single refresh-in-flight. Connectors that aren't OAuth-based (e.g.
+	// IMAP) never call this themselves but still satisfy it via
+	// BaseConnector.
+	TokenSource(config *oauth2.Config) (oauth2.TokenSource, error)
+
+	// Progress returns a channel of incremental SyncProgress snapshots for
+	// the connector's current (or most recently completed) sync. The
+	// channel is kept buffered to exactly the latest snapshot, so a
+	// subscriber that only checks in occasionally (e.g. an HTTP handler)
+	// always observes current progress rather than an arbitrarily stale
+	// or unread one.
+	Progress() <-chan SyncProgress
+
+	// Cancel stops any work the connector is doing in the background,
+	// including a live event listener registered via LiveConnector,
+	// flushes its last known progress into persisted state, and marks it
+	// as no longer syncing. Called when a connector is deleted or the
+	// syncer is shutting down.
+	Cancel(ctx context.Context)
+}
+
+// LiveConnector is implemented by connectors that maintain an event-driven
+// feed in addition to their periodic Sync, such as Slack's RTM/Socket Mode
+// events. The Syncer drains LiveUpdates for the lifetime of the connector
+// and routes each result through the same chunk pipeline as a periodic
+// sync, so new content lands in Weaviate without waiting for the next
+// sync tick.
+type LiveConnector interface {
+	Connector
+	LiveUpdates() <-chan ChunkSyncResult
 }
 
 type ChunkSyncResult struct {
 	Chunk Chunk
 	Err   error
+
+	// SkipClean indicates the chunk text has already been sanitized by the
+	// connector (e.g. buffered Slack messages) and should not be passed
+	// through util.CleanChunk again.
+	SkipClean bool
+
+	// DeleteHash, when set, means the chunk previously synced with this
+	// hash should be removed. If Chunk.Text is also set, the removal is
+	// treated as a replacement: the old chunk is deleted and the new one
+	// added. Used by live connectors to apply an edit or delete to a
+	// message they already synced, without waiting for the next sync tick.
+	DeleteHash string
 }
+
+// DownloadProgressFunc reports incremental progress on a single file
+// download, keyed by the connector-native file ID. fileName and totalBytes
+// may be empty/zero when the source API doesn't expose them up front.
+// Connectors that download large binary files in chunks call this after
+// each chunk lands so the UI can render a per-file progress bar.
+type DownloadProgressFunc func(fileID string, fileName string, bytesDownloaded int64, totalBytes int64)
@@ -1,21 +1,132 @@
 package types
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
+// FusionType selects how HybridSearch combines a vector score list with a
+// keyword score list. "" is equivalent to FusionRelativeScore.
+type FusionType string
+
+const (
+	// FusionRelativeScore normalizes each list to [0,1] before blending by
+	// Alpha. This is every Store's long-standing default.
+	FusionRelativeScore FusionType = "relative_score"
+	// FusionRanked blends by each result's rank within its own list rather
+	// than its raw score, which is less sensitive to one list's scores
+	// being on a wildly different scale than the other's.
+	FusionRanked FusionType = "ranked"
+)
+
+// SearchOptions tunes a HybridSearch call. It follows the same "zero value
+// means use the default" convention as PageArgs: a caller that only cares
+// about, say, Limit can leave every other field unset.
+type SearchOptions struct {
+	// Alpha weights the vector score against the keyword score: 1.0 is
+	// pure vector, 0.0 is pure keyword. 0 means the store's own default
+	// (store.HybridSearchAlpha).
+	Alpha float64
+	// Fusion selects how the vector and keyword score lists are combined.
+	// "" means FusionRelativeScore.
+	Fusion FusionType
+	// Limit caps how many results HybridSearch returns. 0 means the
+	// store's own default (store.MaxNumSearchResults).
+	Limit int
+	// MinScore drops any result scoring below this threshold after
+	// fusion. 0 means no cutoff.
+	MinScore float64
+	// ConnectorIDs, if non-empty, restricts results to chunks synced by
+	// one of these connectors.
+	ConnectorIDs []string
+	// FieldBoosts multiplies a per-field keyword match weight before
+	// fusion, keyed by field name (e.g. "document_title"). Only
+	// WeaviateStore honors this today: SQLiteStore and MilvusStore index
+	// chunk text as a single field with no per-field weighting to apply.
+	FieldBoosts map[string]float64
+
+	// CreatedAfter/CreatedBefore and UpdatedAfter/UpdatedBefore restrict
+	// results to chunks whose parent document's CreatedAt/UpdatedAt falls
+	// within the given bound. A zero time.Time leaves that bound open.
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
+
+	// DocNameGlob, if non-empty, restricts results to chunks whose parent
+	// document's Name matches the glob (path.Match syntax: "*", "?",
+	// character classes).
+	DocNameGlob string
+
+	// RequesterIdentities, if non-empty, restricts results to chunks whose
+	// parent document's ACL is either empty (synced before ACLs existed,
+	// or by a connector that doesn't resolve one) or shares at least one
+	// identity with this list. Callers populate it with the requesting
+	// user's own email plus their resolved Workspace group emails, the
+	// same identity shape connectors.resolveDocumentACL writes into
+	// Document.ACL.
+	RequesterIdentities []string
+}
+
+// Store is the full CRUD surface every persistence backend implements:
+// WeaviateStore (the default, vector search via a Weaviate container),
+// SQLiteStore (an embedded single-file backend with no external
+// dependency), MilvusStore (a self-hosted vector database), and
+// RedisStore (a RediSearch-backed implementation for deployments that
+// already run Redis). Callers hold a Store, never a concrete backend
+// type, so store.New's choice of backend is the only place
+// backend-specific code lives.
+//
+// Beyond the method signatures below, every backend is expected to honor
+// a few behaviors the method set alone doesn't capture: GetConnectorState
+// returns an error satisfying store.IsStateNotFound when connectorID has
+// no state yet (BaseConnector relies on this to distinguish "first sync"
+// from a real failure), GetChunkByHash/DeleteChunkByHash return an error
+// satisfying store.IsErrChunkNotFound rather than a generic error, and
+// UpdateConnectorState/AddVectors/ConversationAppend are safe to call
+// concurrently for different connectors/conversations. A new backend
+// should be checked against each of these before it's wired into
+// store.New.
 type Store interface {
 	ChunkHashExists(ctx context.Context, hash string) (bool, error)
 	GetChunkByHash(ctx context.Context, hash string) (*Chunk, error)
+	DeleteChunkByHash(ctx context.Context, hash string) error
+
+	// FindNearDuplicateChunk returns the first existing chunk, scoped to
+	// connectorID, whose Fingerprint is within maxDistance Hamming
+	// distance of fingerprint, or (nil, nil) if there isn't one.
+	// chunkAdder calls this before embedding so near-duplicate chunks
+	// (boilerplate footers, quoted replies) can be linked to the existing
+	// chunk's embedding instead of paying to embed and store another one.
+	FindNearDuplicateChunk(ctx context.Context, connectorID string, fingerprint uint64, maxDistance int) (*Chunk, error)
 	GetDocument(ctx context.Context, uniqueID string) (*Document, error)
 	AddVectors(ctx context.Context, items []AddVectorItem) (*AddVectorResponse, error)
-	HybridSearch(ctx context.Context, query string, vector []float32) ([]*Chunk, error)
+	HybridSearch(ctx context.Context, query string, vector []float32, opts SearchOptions) ([]*Chunk, error)
 	CreateDocumentClass(ctx context.Context, force bool) error
 	CreateChunkClass(ctx context.Context, force bool) error
 	CreateConversationClass(ctx context.Context, force bool) error
 	CreateConnectorStateClass(ctx context.Context, force bool) error
 	CreateConversation(ctx context.Context) (string, error)
-	ListConversations(ctx context.Context) ([]*Conversation, error)
+	ListConversations(ctx context.Context, args PageArgs) (*ConversationConnection, error)
 	GetConversation(ctx context.Context, conversationID string) (*Conversation, error)
+	GetConversationHistory(ctx context.Context, conversationID string, args PageArgs) (*HistoryConnection, error)
+	ListDocuments(ctx context.Context, args PageArgs) (*DocumentConnection, error)
 	ConversationAppend(ctx context.Context, conversationID string, items []HistoryItem, chunks []*Chunk) error
+	DeleteConversation(ctx context.Context, conversationID string) error
+	RenameConversation(ctx context.Context, conversationID string, title string) error
+
+	// TruncateConversationHistory drops every history item at or after
+	// keep (a History index), and recomputes ChunkHashes as the union of
+	// the surviving items' own HistoryItem.ChunkHashes, so a message edit
+	// that re-runs generation retrieves against only the context that's
+	// still part of the conversation. It returns the conversation
+	// post-truncation.
+	TruncateConversationHistory(ctx context.Context, conversationID string, keep int) (*Conversation, error)
+
+	// BranchConversation forks conversationID into a new conversation
+	// containing only History[:atIndex] and the corresponding subset of
+	// ChunkHashes, and returns the new conversation's ID.
+	BranchConversation(ctx context.Context, conversationID string, atIndex int) (string, error)
 	SetConnectorSyncing(ctx context.Context, connectorID string, syncing bool) (*ConnectorState, error)
 	UpdateConnectorState(ctx context.Context, state *ConnectorState) error
 	AllConnectorStates(ctx context.Context) ([]*ConnectorState, error)
@@ -24,9 +135,105 @@ type Store interface {
 	DeleteDocumentChunksById(ctx context.Context, documentId string) error
 	DeleteDocumentChunks(ctx context.Context, uniqueID string, connectorID string) error
 	DeleteConnector(ctx context.Context, connector Connector) error
+
+	// GetDocumentChunkHashes returns the Hash of every chunk currently
+	// stored under uniqueID (nil if the document doesn't exist yet), so a
+	// connector re-syncing a document it has already indexed can diff its
+	// freshly computed content hashes against what's stored and skip
+	// DeleteDocumentChunks/AddVectors entirely when nothing changed,
+	// instead of dropping and re-embedding every chunk on every sync.
+	GetDocumentChunkHashes(ctx context.Context, uniqueID string) ([]string, error)
+
+	// ReconcileConnector tombstones every document under connectorID
+	// whose unique_id is absent from liveUniqueIDs. Connectors that walk
+	// their entire source tree each sync (rather than receiving discrete
+	// delete events) call this once at the end of a full sync to catch
+	// removals they'd otherwise never observe.
+	ReconcileConnector(ctx context.Context, connectorID string, liveUniqueIDs []string) error
+
+	// Subscribe returns a channel of ConnectorEvents reflecting every
+	// successful UpdateConnectorState and DeleteConnector call, so a
+	// client (the /connectors/events SSE endpoint) can react to sync
+	// progress and deletions instead of polling AllConnectorStates. The
+	// channel is closed once ctx is done.
+	Subscribe(ctx context.Context) (<-chan ConnectorEvent, error)
+}
+
+// ConnectorEventType distinguishes the two kinds of change a Store
+// publishes through Subscribe.
+type ConnectorEventType string
+
+const (
+	ConnectorEventStateChanged ConnectorEventType = "connector_state_changed"
+	ConnectorEventDeleted      ConnectorEventType = "connector_deleted"
+)
+
+// ConnectorEvent is a single connector state change published by a Store.
+// For ConnectorEventDeleted, only Type and ConnectorID are populated.
+type ConnectorEvent struct {
+	Type         ConnectorEventType `json:"type"`
+	ConnectorID  string             `json:"connector_id"`
+	Syncing      bool               `json:"syncing"`
+	NumDocuments int                `json:"num_documents"`
+	NumChunks    int                `json:"num_chunks"`
+	NumErrors    int                `json:"num_errors"`
+	LastSync     time.Time          `json:"last_sync"`
 }
 
 type AddVectorResponse struct {
 	NumChunksAdded int
 	NumDocsAdded   int
 }
+
+// PageArgs is a forward-only Relay-style pagination cursor: First bounds
+// the page size and After, when set, is an opaque cursor string previously
+// returned as a PageInfo.EndCursor or an edge's Cursor.
+type PageArgs struct {
+	First int
+	After string
+}
+
+// PageInfo describes a Connection's position within its full result set,
+// per the Relay cursor connections spec.
+type PageInfo struct {
+	HasNextPage     bool
+	HasPreviousPage bool
+	StartCursor     string
+	EndCursor       string
+}
+
+type ConversationEdge struct {
+	Cursor string
+	Node   *Conversation
+}
+
+// ConversationConnection is the paginated result of ListConversations.
+// Nodes omit History and ChunkHashes to keep list pages small; callers
+// that need the message log should page through it separately via
+// GetConversationHistory.
+type ConversationConnection struct {
+	Edges    []ConversationEdge
+	PageInfo PageInfo
+}
+
+type DocumentEdge struct {
+	Cursor string
+	Node   *Document
+}
+
+// DocumentConnection is the paginated result of ListDocuments.
+type DocumentConnection struct {
+	Edges    []DocumentEdge
+	PageInfo PageInfo
+}
+
+type HistoryEdge struct {
+	Cursor string
+	Node   HistoryItem
+}
+
+// HistoryConnection is the paginated result of GetConversationHistory.
+type HistoryConnection struct {
+	Edges    []HistoryEdge
+	PageInfo PageInfo
+}
@@ -20,18 +20,106 @@ type HistoryItem struct {
 	Role    string   `json:"role"`
 	Content string   `json:"content"`
 	Sources []Source `json:"sources"`
+	// ChunkHashes are the hashes of the chunks retrieved to answer this
+	// turn (set on the assistant item ConversationAppend persists, empty
+	// on the user item). TruncateConversationHistory and BranchConversation
+	// recompute Conversation.ChunkHashes from the surviving items' hashes
+	// so a rewound conversation only carries retrieval context for turns
+	// that are still part of its history.
+	ChunkHashes []string `json:"chunk_hashes,omitempty"`
 }
 
 type ConnectorState struct {
-	ConnectorID   string    `json:"connector_id"`
-	User          string    `json:"user"`
-	ConnectorType string    `json:"connector_type"`
-	AuthValid     bool      `json:"auth_valid"`
-	Syncing       bool      `json:"syncing"`
-	LastSync      time.Time `json:"last_sync"`
-	NumDocuments  int       `json:"num_documents"`
-	NumChunks     int       `json:"num_chunks"`
-	NumErrors     int       `json:"num_errors"`
+	ConnectorID   string `json:"connector_id"`
+	User          string `json:"user"`
+	ConnectorType string `json:"connector_type"`
+	AuthValid     bool   `json:"auth_valid"`
+	// UserKey is the provider's stable per-account identifier (an OIDC
+	// `sub` claim, for connectors resolved via connectors.FetchOIDCIdentity),
+	// as opposed to User's human-readable email, which can change without
+	// the underlying account changing.
+	UserKey string `json:"user_key,omitempty"`
+	// AuthState is a finer-grained view of AuthValid (keychain.TokenState:
+	// "valid", "expiring_soon", or "invalid"), so the UI can prompt for
+	// re-auth before the next sync fails outright instead of only after.
+	AuthState    string    `json:"auth_state"`
+	Syncing      bool      `json:"syncing"`
+	LastSync     time.Time `json:"last_sync"`
+	NumDocuments int       `json:"num_documents"`
+	NumChunks    int       `json:"num_chunks"`
+	NumErrors    int       `json:"num_errors"`
+
+	// PendingDeletion and DeletedAt implement two-phase connector removal:
+	// a delete request marks the connector pending rather than deleting it
+	// inline, so the syncer can stop scheduling it and cancel its in-flight
+	// context immediately, while the actual cascading delete of its
+	// documents/chunks happens out-of-band on the next GC pass.
+	PendingDeletion bool      `json:"pending_deletion"`
+	DeletedAt       time.Time `json:"deleted_at,omitempty"`
+
+	// Cursor holds a connector-specific incremental sync position, such as
+	// a Dropbox list_folder cursor or a Gmail historyId. Connectors that
+	// page through a changes/delta API instead of filtering on LastSync
+	// persist their position here.
+	Cursor string `json:"cursor"`
+
+	// Progress is a snapshot of the most recent SyncProgress published by
+	// the connector, persisted here so /connectors can render "N/M files
+	// processed" without holding a live connection to the connector's
+	// Progress channel. It is stale (but not wrong) once Syncing is false.
+	Progress SyncProgress `json:"progress"`
+
+	// BytesRead, BytesEmbedded, and BytesStored are cumulative I/O
+	// counters for this connector across all syncs: bytes pulled from its
+	// source, bytes of chunk text sent to the embedding model, and bytes
+	// of embedding data written to the store. ChunksAdded is the matching
+	// count of chunks embedded and stored. Populated from the Syncer's
+	// in-memory ioStatsRegistry, not persisted with the rest of the
+	// state, so these reset if the process restarts.
+	BytesRead     int64 `json:"bytes_read"`
+	BytesEmbedded int64 `json:"bytes_embedded"`
+	BytesStored   int64 `json:"bytes_stored"`
+	ChunksAdded   int64 `json:"chunks_added"`
+
+	// ChunksRejected, ChunksRedacted, and ChunksDeduped are cumulative
+	// counts of what this connector's chunk-pipeline middleware chain
+	// (see chunkpipeline) has done to its chunks before they ever reached
+	// the embedder: rejected outright (policy or language rules),
+	// redacted in place (PII), or dropped as a duplicate of content
+	// already seen. Populated from the Syncer's in-memory
+	// pipelineStatsRegistry, same as the Bytes*/ChunksAdded fields above,
+	// so these also reset if the process restarts.
+	ChunksRejected int64 `json:"chunks_rejected"`
+	ChunksRedacted int64 `json:"chunks_redacted"`
+	ChunksDeduped  int64 `json:"chunks_deduped"`
+
+	// ResourceVersion is an opaque, store-assigned token that changes on
+	// every write. UpdateConnectorState rejects a write whose
+	// ResourceVersion doesn't match what's currently stored with
+	// store.ErrConflict, so two goroutines racing to update the same
+	// connector (a sync loop and a user-triggered delete, say) can't
+	// silently clobber each other's NumChunks/NumDocuments/Syncing.
+	ResourceVersion string `json:"resource_version"`
+}
+
+// SyncProgress is an incremental snapshot of an in-flight (or most
+// recently completed) connector sync. Connectors publish one over their
+// Progress channel as they work through Sync, and the syncer flushes the
+// latest snapshot into ConnectorState so it survives past the sync and is
+// visible over the API.
+type SyncProgress struct {
+	TotalItems      int       `json:"total_items"`
+	ProcessedItems  int       `json:"processed_items"`
+	BytesDownloaded int64     `json:"bytes_downloaded"`
+	CurrentFile     string    `json:"current_file"`
+	Errors          int       `json:"errors"`
+	StartedAt       time.Time `json:"started_at"`
+
+	// EstimatedRemaining is a rolling estimate of how much longer the
+	// sync will take, derived from an EWMA of items/sec once enough items
+	// have gone by to make the estimate meaningful. It is 0 until then,
+	// and whenever TotalItems is unknown.
+	EstimatedRemaining time.Duration `json:"estimated_remaining"`
 }
 
 type Chunk struct {
@@ -39,10 +127,42 @@ type Chunk struct {
 	Text     string `json:"text"`
 	Hash     string `json:"hash"`
 
+	// ChunkIndex is this chunk's position within its parent document,
+	// starting at 0, in the order the chunker produced it.
+	ChunkIndex int `json:"chunk_index"`
+
+	// ParentHash identifies the document this chunk was split from, so
+	// the retriever can merge adjacent sibling chunks back together when
+	// several of them co-occur in the same top-K result set.
+	ParentHash string `json:"parent_hash"`
+
+	// Fingerprint is a 64-bit SimHash over Text's token shingles, used by
+	// chunkAdder to find near-duplicate chunks (boilerplate footers,
+	// quoted replies) that have different exact text but overlapping
+	// content, which Hash alone can't catch.
+	Fingerprint uint64 `json:"fingerprint"`
+
 	// The following fields are only filled in when the chunk is a search result
 	Score        float64 `json:"score"`
 	ExplainScore string  `json:"explain_score"`
+
+	// DenseScore and KeywordScore are the two un-fused component scores
+	// HybridSearch blended into Score, for backends that compute them
+	// independently (SQLiteStore, MilvusStore) rather than fusing
+	// server-side (WeaviateStore, which leaves both at zero).
+	DenseScore   float64 `json:"dense_score"`
+	KeywordScore float64 `json:"keyword_score"`
 }
+
+// DocumentStatus distinguishes a live document from one that's been
+// tombstoned pending a hard purge by a later GC pass.
+type DocumentStatus string
+
+const (
+	DocumentStatusActive     DocumentStatus = "active"
+	DocumentStatusTombstoned DocumentStatus = "tombstoned"
+)
+
 type Document struct {
 	UniqueID      string    `json:"unique_id"` // Uniquely identifies the document in the connector's context
 	Name          string    `json:"name"`
@@ -51,6 +171,23 @@ type Document struct {
 	ConnectorType string    `json:"connector_type"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
+
+	// Status and DeletedAt implement two-phase deletion: a document is
+	// first tombstoned (filtered out of search, but still on disk) and
+	// later hard-purged by a separate GC pass. Status is empty/"active"
+	// for every document created before this field existed.
+	Status    DocumentStatus `json:"status"`
+	DeletedAt time.Time      `json:"deleted_at,omitempty"`
+
+	// ACL is the set of identities (user email addresses and/or Workspace
+	// group email addresses) allowed to see this document, resolved at
+	// sync time from the source system's own sharing data (Drive file
+	// permissions, Gmail message recipients) plus the signed-in user's own
+	// group memberships. Empty for every document synced before this field
+	// existed, and for connectors that don't resolve one: HybridSearch
+	// treats an empty ACL as visible to everyone, so this is additive and
+	// doesn't retroactively hide anything already synced.
+	ACL []string `json:"acl,omitempty"`
 }
 
 type Conversation struct {
@@ -63,12 +200,14 @@ type Conversation struct {
 }
 
 type BuildCredentials struct {
-	PosthogAPIKey     string
-	AzureSecretID     string
-	AzureSecretValue  string
-	SlackClientID     string
-	SlackClientSecret string
-	GoogleJSONCreds   string
+	PosthogAPIKey       string
+	AzureSecretID       string
+	AzureSecretValue    string
+	SlackClientID       string
+	SlackClientSecret   string
+	GoogleJSONCreds     string
+	DropboxClientID     string
+	DropboxClientSecret string
 }
 
 type Config struct {
@@ -78,4 +217,38 @@ type Config struct {
 	// (right now we're opt out telemetry)
 
 	EnableTelemetry bool `json:"enable_telemetry"`
+
+	// RetrievalTopK caps how many candidate chunks the retrieval stage
+	// returns before reranking. 0 means use the built-in default.
+	RetrievalTopK int `json:"retrieval_top_k"`
+	// RerankTopN caps how many chunks survive the rerank stage and are fed
+	// into the prompt template. 0 means use the built-in default.
+	RerankTopN int `json:"rerank_top_n"`
+	// RerankStrategy selects the Reranker boot wires into the API struct:
+	// "bert" (the default) scores each (query, chunk) pair with a
+	// cross-encoder model; "rrf" reranks by Reciprocal Rank Fusion over
+	// each candidate's dense and keyword scores, with no model call.
+	// "" means "bert".
+	RerankStrategy string `json:"rerank_strategy"`
+
+	// LLMProvider selects the llm.Provider the API struct talks to for
+	// chat, embedding, and LLM-based rerank calls: "ollama" (the
+	// default), "openai" for any OpenAI-compatible endpoint, or
+	// "llamacpp" for a llama.cpp server. "" means "ollama".
+	LLMProvider string `json:"llm_provider"`
+	// LLMBaseURL is the base URL of the remote endpoint for the "openai"
+	// and "llamacpp" providers. Ignored by "ollama", which always talks
+	// to the local Ollama host.
+	LLMBaseURL string `json:"llm_base_url"`
+	// LLMAPIKey authenticates to the "openai" provider as a bearer
+	// token. Ignored by the other providers.
+	LLMAPIKey string `json:"llm_api_key"`
+	// LLMChatModel is the chat/completion model name sent to the
+	// "openai" provider. Ignored by "ollama" (which is given its model
+	// per request) and "llamacpp" (which is configured with one model
+	// per server).
+	LLMChatModel string `json:"llm_chat_model"`
+	// LLMEmbedModel is the embedding model name used by the "ollama" and
+	// "openai" providers. "" means use the built-in default.
+	LLMEmbedModel string `json:"llm_embed_model"`
 }
@@ -1,12 +1,18 @@
 package util
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
 	"fmt"
+	"io"
 	"log"
+	"math/bits"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync/atomic"
 )
 
 var (
@@ -90,3 +96,108 @@ func CleanChunk(input string) string {
 
 	return input
 }
+
+// HashText returns the content hash used to identify a chunk in Weaviate.
+// Exported so that connectors which need to address a specific chunk
+// directly (e.g. to update or remove it in response to a live edit/delete
+// event) can compute the same hash the sync pipeline will, without
+// round-tripping through a sync.
+func HashText(text string) string {
+	h := sha256.New()
+	h.Write([]byte(text))
+	return base64.URLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// SimHash computes a 64-bit SimHash fingerprint over text's word shingles
+// of shingleWidth words each, weighting each distinct shingle by how many
+// times it recurs. Unlike HashText, near-duplicate inputs (the same
+// content with a different footer, a quoted reply appended) produce
+// fingerprints a small Hamming distance apart instead of a completely
+// different hash.
+func SimHash(text string, shingleWidth int) uint64 {
+	if shingleWidth < 1 {
+		shingleWidth = 1
+	}
+
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return 0
+	}
+
+	counts := map[string]int{}
+	for i := 0; i+shingleWidth <= len(words); i++ {
+		counts[strings.Join(words[i:i+shingleWidth], " ")]++
+	}
+	if len(counts) == 0 {
+		// Fewer words than one shingle; treat the whole text as a single
+		// shingle so short chunks still get a fingerprint.
+		counts[strings.Join(words, " ")] = 1
+	}
+
+	var weights [64]int
+	for shingle, count := range counts {
+		sum := sha256.Sum256([]byte(shingle))
+		h := binary.BigEndian.Uint64(sum[:8])
+		for bit := 0; bit < 64; bit++ {
+			if h&(1<<uint(bit)) != 0 {
+				weights[bit] += count
+			} else {
+				weights[bit] -= count
+			}
+		}
+	}
+
+	var fp uint64
+	for bit := 0; bit < 64; bit++ {
+		if weights[bit] > 0 {
+			fp |= 1 << uint(bit)
+		}
+	}
+	return fp
+}
+
+// HammingDistance returns the number of bits by which a and b differ.
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// CountingReader wraps an io.Reader and tallies the bytes that pass
+// through Read, so a connector can measure how much it has actually
+// pulled from its source (a Drive export, an IMAP fetch, ...) without
+// every Sync implementation reimplementing the bookkeeping by hand. The
+// count is safe to read from another goroutine while Read is in
+// progress, e.g. to sample it into a progress update mid-download.
+type CountingReader struct {
+	r     io.Reader
+	count int64
+}
+
+// NewCountingReader returns a CountingReader wrapping r.
+func NewCountingReader(r io.Reader) *CountingReader {
+	return &CountingReader{r: r}
+}
+
+func (c *CountingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	atomic.AddInt64(&c.count, int64(n))
+	return n, err
+}
+
+// Count returns the number of bytes read so far.
+func (c *CountingReader) Count() int64 {
+	return atomic.LoadInt64(&c.count)
+}
+
+// SimHashBands splits a SimHash fingerprint into four 16-bit bands, high
+// bits first. Indexing a fingerprint by each of its four bands and
+// probing all four on lookup guarantees recall for any pair within
+// Hamming distance 3: by pigeonhole, two 64-bit values differing in at
+// most 3 bits must agree on at least one of four 16-bit bands.
+func SimHashBands(fp uint64) [4]uint16 {
+	return [4]uint16{
+		uint16(fp >> 48),
+		uint16(fp >> 32),
+		uint16(fp >> 16),
+		uint16(fp),
+	}
+}